@@ -0,0 +1,76 @@
+package tempo
+
+import (
+	"sync"
+	"time"
+)
+
+type tagValuesCacheEntry struct {
+	values  []string
+	expires time.Time
+}
+
+// tagValuesCache caches tag values by tenant and tag for a short time. The Jaeger UI requests services and
+// operations on every page load.
+type tagValuesCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mtx     sync.Mutex
+	entries map[string]tagValuesCacheEntry
+}
+
+func newTagValuesCache(ttl time.Duration) *tagValuesCache {
+	return &tagValuesCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[string]tagValuesCacheEntry{},
+	}
+}
+
+func (c *tagValuesCache) get(tenantID, tagName string) ([]string, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	key := cacheKey(tenantID, tagName)
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if c.now().After(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	return e.values, true
+}
+
+func (c *tagValuesCache) set(tenantID, tagName string, values []string) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	now := c.now()
+	// expired entries are dropped here, there are few tenants and tags
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[cacheKey(tenantID, tagName)] = tagValuesCacheEntry{
+		values:  values,
+		expires: now.Add(c.ttl),
+	}
+}
+
+func cacheKey(tenantID, tagName string) string {
+	return tenantID + "/" + tagName
+}
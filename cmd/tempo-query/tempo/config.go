@@ -1,15 +1,26 @@
 package tempo
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
+const defaultTagValuesCacheTTL = 30 * time.Second
+
 // Config holds the configuration for redbull.
 type Config struct {
 	Backend string `yaml:"backend"`
+	// TagValuesCacheTTL is how long services and operations are cached. 0 disables the cache.
+	TagValuesCacheTTL time.Duration `yaml:"tag_values_cache_ttl"`
 }
 
 // InitFromViper initializes the options struct with values from Viper
 func (c *Config) InitFromViper(v *viper.Viper) {
 	c.Backend = v.GetString("backend")
+
+	c.TagValuesCacheTTL = defaultTagValuesCacheTTL
+	if v.IsSet("tag_values_cache_ttl") {
+		c.TagValuesCacheTTL = v.GetDuration("tag_values_cache_ttl")
+	}
 }
@@ -6,9 +6,12 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/go-logfmt/logfmt"
 	"github.com/gogo/protobuf/jsonpb"
 	"github.com/grafana/tempo/pkg/tempopb"
 	"github.com/opentracing/opentracing-go"
@@ -32,18 +35,28 @@ const (
 const (
 	serviceSearchTag     = "service.name"
 	operationSearchTag   = "name"
+	errorSearchTag       = "error"
+	statusCodeSearchTag  = "status.code"
+	tagsSearchTag        = "tags"
+	startSearchTag       = "start"
+	endSearchTag         = "end"
 	minDurationSearchTag = "minDuration"
 	maxDurationSearchTag = "maxDuration"
 	numTracesSearchTag   = "limit"
+
+	// otelStatusCodeTag is the tag the Jaeger translator stores the span status in
+	otelStatusCodeTag = "otel.status_code"
 )
 
 type Backend struct {
 	tempoBackend string
+	cache        *tagValuesCache
 }
 
 func New(cfg *Config) *Backend {
 	return &Backend{
 		tempoBackend: cfg.Backend,
+		cache:        newTagValuesCache(cfg.TagValuesCacheTTL),
 	}
 }
 
@@ -117,12 +130,14 @@ func (b *Backend) GetTrace(ctx context.Context, traceID jaeger.TraceID) (*jaeger
 }
 
 func (b *Backend) GetServices(ctx context.Context) ([]string, error) {
-	span, ctx := opentracing.StartSpanFromContext(ctx, "tempo-query.GetOperations")
+	span, ctx := opentracing.StartSpanFromContext(ctx, "tempo-query.GetServices")
 	defer span.Finish()
 
 	return b.lookupTagValues(ctx, span, serviceSearchTag)
 }
 
+// GetOperations returns the span names of all services. Tempo does not index span names by service, so
+// the service and span kind of the query are ignored.
 func (b *Backend) GetOperations(ctx context.Context, query jaeger_spanstore.OperationQueryParameters) ([]jaeger_spanstore.Operation, error) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "tempo-query.GetOperations")
 	defer span.Finish()
@@ -177,21 +192,17 @@ func (b *Backend) FindTraceIDs(ctx context.Context, query *jaeger_spanstore.Trac
 	span, ctx := opentracing.StartSpanFromContext(ctx, "tempo-query.FindTraceIDs")
 	defer span.Finish()
 
+	urlQuery, err := buildSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
 	url := url.URL{
-		Scheme: "http",
-		Host:   b.tempoBackend,
-		Path:   "api/search",
-	}
-	urlQuery := url.Query()
-	urlQuery.Set(serviceSearchTag, query.ServiceName)
-	urlQuery.Set(operationSearchTag, query.OperationName)
-	urlQuery.Set(minDurationSearchTag, query.DurationMin.String())
-	urlQuery.Set(maxDurationSearchTag, query.DurationMax.String())
-	urlQuery.Set(numTracesSearchTag, strconv.Itoa(query.NumTraces))
-	for k, v := range query.Tags {
-		urlQuery.Set(k, v)
+		Scheme:   "http",
+		Host:     b.tempoBackend,
+		Path:     "api/search",
+		RawQuery: urlQuery.Encode(),
 	}
-	url.RawQuery = urlQuery.Encode()
 
 	req, err := b.newGetRequest(ctx, url.String(), span)
 	if err != nil {
@@ -236,7 +247,91 @@ func (b *Backend) FindTraceIDs(ctx context.Context, query *jaeger_spanstore.Trac
 	return jaegerTraceIDs, nil
 }
 
+// buildSearchQuery maps the Jaeger query to the parameters of the Tempo search. The service, operation and
+// tags are passed in the tags parameter, the error tags of Jaeger are mapped to the status of the span.
+func buildSearchQuery(query *jaeger_spanstore.TraceQueryParameters) (url.Values, error) {
+	tags := map[string]string{}
+	for k, v := range query.Tags {
+		switch k {
+		case errorSearchTag:
+			// tempo can only search for spans with an error
+			if v != "true" {
+				return nil, fmt.Errorf("invalid tag %s=%s: only %s=true is supported", k, v, errorSearchTag)
+			}
+			tags[errorSearchTag] = v
+		case otelStatusCodeTag:
+			tags[statusCodeSearchTag] = strings.ToLower(v)
+		default:
+			tags[k] = v
+		}
+	}
+	if query.ServiceName != "" {
+		tags[serviceSearchTag] = query.ServiceName
+	}
+	if query.OperationName != "" {
+		tags[operationSearchTag] = query.OperationName
+	}
+
+	encodedTags, err := encodeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	urlQuery := url.Values{}
+	urlQuery.Set(tagsSearchTag, encodedTags)
+	if query.DurationMin != 0 {
+		urlQuery.Set(minDurationSearchTag, query.DurationMin.String())
+	}
+	if query.DurationMax != 0 {
+		urlQuery.Set(maxDurationSearchTag, query.DurationMax.String())
+	}
+	if query.NumTraces > 0 {
+		urlQuery.Set(numTracesSearchTag, strconv.Itoa(query.NumTraces))
+	}
+
+	// tempo searches by seconds, the end is rounded up to include the last second
+	if !query.StartTimeMin.IsZero() && !query.StartTimeMax.IsZero() {
+		start := query.StartTimeMin.Unix()
+		end := query.StartTimeMax.Add(time.Second - 1).Unix()
+		if end <= start {
+			end = start + 1
+		}
+
+		urlQuery.Set(startSearchTag, strconv.FormatInt(start, 10))
+		urlQuery.Set(endSearchTag, strconv.FormatInt(end, 10))
+	}
+
+	return urlQuery, nil
+}
+
+// encodeTags encodes the tags in logfmt ordered by key
+func encodeTags(tags map[string]string) (string, error) {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	builder := &strings.Builder{}
+	encoder := logfmt.NewEncoder(builder)
+	for _, k := range keys {
+		err := encoder.EncodeKeyval(k, tags[k])
+		if err != nil {
+			return "", fmt.Errorf("invalid tag %s=%s: %w", k, tags[k], err)
+		}
+	}
+
+	return builder.String(), nil
+}
+
+// lookupTagValues returns the values of the tag. They are cached per tenant for a short time.
 func (b *Backend) lookupTagValues(ctx context.Context, span opentracing.Span, tagName string) ([]string, error) {
+	tenantID, _ := extractBearerToken(ctx)
+	if values, ok := b.cache.get(tenantID, tagName); ok {
+		span.LogFields(ot_log.String("msg", "tag values cache hit"))
+		return values, nil
+	}
+
 	url := fmt.Sprintf("http://%s/api/search/tag/%s/values", b.tempoBackend, tagName)
 
 	req, err := b.newGetRequest(ctx, url, span)
@@ -269,7 +364,11 @@ func (b *Backend) lookupTagValues(ctx context.Context, span opentracing.Span, ta
 		return nil, fmt.Errorf("error unmarshaling Tempo response: %w", err)
 	}
 
-	return searchLookupResponse.TagValues, nil
+	values := searchLookupResponse.TagValues
+	sort.Strings(values)
+	b.cache.set(tenantID, tagName, values)
+
+	return values, nil
 }
 
 func (b *Backend) WriteSpan(ctx context.Context, span *jaeger.Span) error {
@@ -0,0 +1,147 @@
+package tempo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jaegertracing/jaeger/plugin/storage/grpc/shared"
+	jaeger_spanstore "github.com/jaegertracing/jaeger/storage/spanstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestBuildSearchQuery(t *testing.T) {
+	start := time.Unix(1000, 0)
+
+	tests := []struct {
+		name     string
+		query    *jaeger_spanstore.TraceQueryParameters
+		expected url.Values
+		err      string
+	}{
+		{
+			name:  "empty",
+			query: &jaeger_spanstore.TraceQueryParameters{},
+			expected: url.Values{
+				"tags": {""},
+			},
+		},
+		{
+			name: "all",
+			query: &jaeger_spanstore.TraceQueryParameters{
+				ServiceName:   "svc",
+				OperationName: "op",
+				Tags:          map[string]string{"http.status_code": "500", "foo": "bar baz"},
+				StartTimeMin:  start,
+				StartTimeMax:  start.Add(time.Hour),
+				DurationMin:   time.Second,
+				DurationMax:   time.Minute,
+				NumTraces:     20,
+			},
+			expected: url.Values{
+				"tags":        {`foo="bar baz" http.status_code=500 name=op service.name=svc`},
+				"start":       {"1000"},
+				"end":         {"4600"},
+				"minDuration": {"1s"},
+				"maxDuration": {"1m0s"},
+				"limit":       {"20"},
+			},
+		},
+		{
+			name: "end is rounded up",
+			query: &jaeger_spanstore.TraceQueryParameters{
+				StartTimeMin: start.Add(100 * time.Millisecond),
+				StartTimeMax: start.Add(200 * time.Millisecond),
+			},
+			expected: url.Values{
+				"tags":  {""},
+				"start": {"1000"},
+				"end":   {"1001"},
+			},
+		},
+		{
+			name: "error",
+			query: &jaeger_spanstore.TraceQueryParameters{
+				Tags: map[string]string{"error": "true"},
+			},
+			expected: url.Values{
+				"tags": {"error=true"},
+			},
+		},
+		{
+			name: "error false",
+			query: &jaeger_spanstore.TraceQueryParameters{
+				Tags: map[string]string{"error": "false"},
+			},
+			err: "invalid tag error=false: only error=true is supported",
+		},
+		{
+			name: "otel status code",
+			query: &jaeger_spanstore.TraceQueryParameters{
+				Tags: map[string]string{"otel.status_code": "ERROR"},
+			},
+			expected: url.Values{
+				"tags": {"status.code=error"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := buildSearchQuery(tc.query)
+			if tc.err != "" {
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestLookupTagValuesCache(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "/api/search/tag/service.name/values", r.URL.Path)
+		_, _ = w.Write([]byte(`{"tagValues":["b","a"]}`))
+	}))
+	defer srv.Close()
+
+	b := New(&Config{
+		Backend:           strings.TrimPrefix(srv.URL, "http://"),
+		TagValuesCacheTTL: time.Minute,
+	})
+	now := time.Now()
+	b.cache.now = func() time.Time { return now }
+
+	tenant1 := metadata.NewIncomingContext(context.Background(), metadata.Pairs(shared.BearerTokenKey, "1"))
+	tenant2 := metadata.NewIncomingContext(context.Background(), metadata.Pairs(shared.BearerTokenKey, "2"))
+
+	services, err := b.GetServices(tenant1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, services)
+	assert.Equal(t, 1, requests)
+
+	// cached
+	_, err = b.GetServices(tenant1)
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests)
+
+	// another tenant
+	_, err = b.GetServices(tenant2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+
+	// expired
+	now = now.Add(2 * time.Minute)
+	_, err = b.GetServices(tenant1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, requests)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"net/http"
+	"os"
 	"reflect"
 	"runtime"
 	"strings"
@@ -30,8 +31,13 @@ import (
 
 const (
 	envConfigPrefix = "TEMPO"
+	envConfigFile   = "TEMPO_CONFIG_FILE"
 )
 
+// ConfigFile is the path to an optional yaml file with the tempodb.Config. It defaults to the TEMPO_CONFIG_FILE
+// env var. Env vars take precedence over the values in the file. It must be set before the first request.
+var ConfigFile = os.Getenv(envConfigFile)
+
 // used to initialize a reader one time
 var (
 	reader       backend.Reader
@@ -111,6 +117,13 @@ func Handler(r *http.Request) (*tempopb.SearchResponse, *HTTPError) {
 	return resp, nil
 }
 
+// LoadBackend loads the config and creates the backend reader. It is called by the first request, calling it
+// on startup fails early on an invalid config.
+func LoadBackend() error {
+	_, _, err := loadBackend()
+	return err
+}
+
 func loadBackend() (backend.Reader, *tempodb.Config, error) {
 	readerOnce.Do(func() {
 		cfg, err := loadConfig()
@@ -172,6 +185,13 @@ func loadConfig() (*tempodb.Config, error) {
 		return nil, errors.Wrap(err, "failed to merge config")
 	}
 
+	if ConfigFile != "" {
+		v.SetConfigFile(ConfigFile)
+		if err = v.MergeInConfig(); err != nil {
+			return nil, errors.Wrapf(err, "failed to merge config file %s", ConfigFile)
+		}
+	}
+
 	v.AutomaticEnv()
 	v.SetEnvPrefix(envConfigPrefix)
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
@@ -1,7 +1,11 @@
 package serverless
 
 import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -77,3 +81,45 @@ func TestLoadConfigAzure(t *testing.T) {
 		t.Error("azure max buffers should be 3", cfg.Azure.MaxBuffers)
 	}
 }
+
+func TestLoadConfigFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	err := ioutil.WriteFile(file, []byte(`
+gcs:
+  endpoint: from-file
+  hedge_requests_up_to: 5
+`), 0644)
+	if err != nil {
+		t.Fatal("failed to write config file", err)
+	}
+
+	ConfigFile = file
+	defer func() { ConfigFile = "" }()
+	t.Setenv("TEMPO_GCS_HEDGE_REQUESTS_UP_TO", "3")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		t.Error("failed to load config", err)
+		return
+	}
+	if cfg.GCS.Endpoint != "from-file" {
+		t.Error("gcs endpoint should be from-file", cfg.GCS.Endpoint)
+	}
+	// env vars take precedence
+	if cfg.GCS.HedgeRequestsUpTo != 3 {
+		t.Error("gcs hedge requests up to should be 3", cfg.GCS.HedgeRequestsUpTo)
+	}
+	// defaults are kept
+	if cfg.Search.PrefetchTraceCount == 0 {
+		t.Error("search prefetch trace count should be the default")
+	}
+}
+
+func TestHTTPHandlerBadRequest(t *testing.T) {
+	w := httptest.NewRecorder()
+	HTTPHandler(w, httptest.NewRequest("GET", "/?start=1000", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Error("status code should be 400", w.Code)
+	}
+}
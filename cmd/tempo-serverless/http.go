@@ -0,0 +1,26 @@
+package serverless
+
+import (
+	"net/http"
+
+	"github.com/gogo/protobuf/jsonpb"
+)
+
+// HTTPHandler runs Handler and writes the search response as json. It can be served by any http server, like
+// the standalone server, Knative or OpenFaaS.
+func HTTPHandler(w http.ResponseWriter, r *http.Request) {
+	resp, httpErr := Handler(r)
+	if httpErr != nil {
+		http.Error(w, httpErr.Err.Error(), httpErr.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	marshaller := &jsonpb.Marshaler{}
+	err := marshaller.Marshal(w, resp)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	serverless "github.com/grafana/tempo/cmd/tempo-serverless"
+)
+
+const shutdownTimeout = 30 * time.Second
+
+// main runs the serverless handler in a plain http server. It is configured like the serverless functions through
+// TEMPO_ env vars and optionally a yaml file. Knative passes the port to listen on in the PORT env var.
+func main() {
+	defaultListenAddress := ":8080"
+	if port := os.Getenv("PORT"); port != "" {
+		defaultListenAddress = ":" + port
+	}
+
+	var listenAddress string
+	flag.StringVar(&listenAddress, "server.http-listen-address", defaultListenAddress, "The address to listen on. Defaults to the PORT env var if set.")
+	flag.StringVar(&serverless.ConfigFile, "config.file", serverless.ConfigFile, "Optional yaml file with the storage config. Defaults to the TEMPO_CONFIG_FILE env var.")
+	flag.Parse()
+
+	err := serverless.LoadBackend()
+	if err != nil {
+		log.Fatalf("failed to load backend: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ready", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ready"))
+	})
+	// the querier sends search requests to the configured external endpoint, every other path is a search
+	mux.HandleFunc("/", serverless.HTTPHandler)
+
+	srv := &http.Server{
+		Addr:    listenAddress,
+		Handler: mux,
+	}
+
+	go func() {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		<-sigs
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("failed to shut down: %v", err)
+		}
+	}()
+
+	log.Printf("tempo-serverless listening on %s", listenAddress)
+	err = srv.ListenAndServe()
+	if err != nil && err != http.ErrServerClosed {
+		log.Fatalf("failed to serve: %v", err)
+	}
+}
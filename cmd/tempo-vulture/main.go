@@ -38,6 +38,20 @@ var (
 	tempoReadBackoffDuration      time.Duration
 	tempoSearchBackoffDuration    time.Duration
 	tempoRetentionDuration        time.Duration
+	tempoPushProtocol             string
+
+	prometheusQueryURL          string
+	tempoMetricsBackoffDuration time.Duration
+	tempoMetricsWindow          time.Duration
+	tempoMetricsDelay           time.Duration
+	tempoMetricsTolerance       float64
+	tempoMetricsSelector        string
+
+	tempoRetentionBackoffDuration time.Duration
+	tempoRetentionTracesPerHour   int
+	tempoRetentionHoursPerCheck   int
+	tempoCompactedBlockRetention  time.Duration
+	tempoRetentionGracePeriod     time.Duration
 
 	logger *zap.Logger
 )
@@ -50,6 +64,49 @@ type traceMetrics struct {
 	requested               int
 	requestFailed           int
 	notFoundSearchAttribute int
+	notFoundSearchTags      int
+	notFoundSearchTagValues int
+	incorrectSearchDuration int
+	incorrectSpanMetrics    int
+	lost                    int
+	duplicated              int
+	overRetained            int
+}
+
+func addTraceMetrics(a, b traceMetrics) traceMetrics {
+	return traceMetrics{
+		incorrectResult:         a.incorrectResult + b.incorrectResult,
+		missingSpans:            a.missingSpans + b.missingSpans,
+		notFoundByID:            a.notFoundByID + b.notFoundByID,
+		notFoundSearch:          a.notFoundSearch + b.notFoundSearch,
+		requested:               a.requested + b.requested,
+		requestFailed:           a.requestFailed + b.requestFailed,
+		notFoundSearchAttribute: a.notFoundSearchAttribute + b.notFoundSearchAttribute,
+		notFoundSearchTags:      a.notFoundSearchTags + b.notFoundSearchTags,
+		notFoundSearchTagValues: a.notFoundSearchTagValues + b.notFoundSearchTagValues,
+		incorrectSearchDuration: a.incorrectSearchDuration + b.incorrectSearchDuration,
+		incorrectSpanMetrics:    a.incorrectSpanMetrics + b.incorrectSpanMetrics,
+		lost:                    a.lost + b.lost,
+		duplicated:              a.duplicated + b.duplicated,
+		overRetained:            a.overRetained + b.overRetained,
+	}
+}
+
+// failed returns true if any issue was found
+func (tm traceMetrics) failed() bool {
+	return tm.incorrectResult > 0 ||
+		tm.missingSpans > 0 ||
+		tm.notFoundByID > 0 ||
+		tm.notFoundSearch > 0 ||
+		tm.requestFailed > 0 ||
+		tm.notFoundSearchAttribute > 0 ||
+		tm.notFoundSearchTags > 0 ||
+		tm.notFoundSearchTagValues > 0 ||
+		tm.incorrectSearchDuration > 0 ||
+		tm.incorrectSpanMetrics > 0 ||
+		tm.lost > 0 ||
+		tm.duplicated > 0 ||
+		tm.overRetained > 0
 }
 
 func init() {
@@ -64,6 +121,19 @@ func init() {
 	flag.DurationVar(&tempoReadBackoffDuration, "tempo-read-backoff-duration", 30*time.Second, "The amount of time to pause between read Tempo calls")
 	flag.DurationVar(&tempoSearchBackoffDuration, "tempo-search-backoff-duration", 60*time.Second, "The amount of time to pause between search Tempo calls.  Set to 0s to disable search.")
 	flag.DurationVar(&tempoRetentionDuration, "tempo-retention-duration", 336*time.Hour, "The block retention that Tempo is using")
+	flag.StringVar(&tempoPushProtocol, "tempo-push-protocol", pushProtocolJaegerGRPC, "The protocol to push traces to Tempo with. One of jaeger-grpc, otlp-grpc, otlp-http or zipkin.")
+
+	flag.StringVar(&prometheusQueryURL, "prometheus-query-url", "", "The URL (scheme://hostname) of a Prometheus-compatible API to query the span metrics of the metrics-generator.")
+	flag.DurationVar(&tempoMetricsBackoffDuration, "tempo-metrics-backoff-duration", 0, "The amount of time to pause between span metrics checks. Set to 0s to disable the span metrics check.")
+	flag.DurationVar(&tempoMetricsWindow, "tempo-metrics-window", 5*time.Minute, "The window of pushed spans that is compared with the span metrics.")
+	flag.DurationVar(&tempoMetricsDelay, "tempo-metrics-delay", 1*time.Minute, "The time it takes for pushed spans to be counted by the span metrics.")
+	flag.Float64Var(&tempoMetricsTolerance, "tempo-metrics-tolerance", 0.1, "The fraction the span metrics may differ from the number of pushed spans.")
+	flag.DurationVar(&tempoRetentionBackoffDuration, "tempo-retention-check-backoff-duration", 0, "The amount of time to pause between retention checks. Set to 0s to disable the retention check.")
+	flag.IntVar(&tempoRetentionTracesPerHour, "tempo-retention-check-traces-per-hour", 1, "The number of traces per hour that are tracked by the retention check for the full retention.")
+	flag.IntVar(&tempoRetentionHoursPerCheck, "tempo-retention-check-hours-per-check", 24, "The number of hours of tracked traces that are checked per retention check. Consecutive checks rotate through all hours of the retention.")
+	flag.DurationVar(&tempoCompactedBlockRetention, "tempo-compacted-block-retention", time.Hour, "The compacted block retention that Tempo is using")
+	flag.DurationVar(&tempoRetentionGracePeriod, "tempo-retention-check-grace-period", 2*time.Hour, "The time after retention and compacted block retention until traces are expected to be deleted. Covers the block time range and the compaction and polling cycles.")
+	flag.StringVar(&tempoMetricsSelector, "tempo-metrics-selector", `{service="tempo-vulture"}`, "The label selector of the span metrics of the pushed spans.")
 }
 
 func main() {
@@ -92,8 +162,18 @@ func main() {
 		tickerSearch = time.NewTicker(tempoSearchBackoffDuration)
 	}
 
-	if tickerRead == nil && tickerSearch == nil {
-		log.Fatalf("at least one of tempo-search-backoff-duration or tempo-read-backoff-duration must be set")
+	var tickerMetrics *time.Ticker
+	if tempoMetricsBackoffDuration > 0 && prometheusQueryURL != "" {
+		tickerMetrics = time.NewTicker(tempoMetricsBackoffDuration)
+	}
+
+	var tickerRetention *time.Ticker
+	if tempoRetentionBackoffDuration > 0 {
+		tickerRetention = time.NewTicker(tempoRetentionBackoffDuration)
+	}
+
+	if tickerRead == nil && tickerSearch == nil && tickerMetrics == nil && tickerRetention == nil {
+		log.Fatalf("at least one of tempo-search-backoff-duration, tempo-read-backoff-duration, tempo-metrics-backoff-duration or tempo-retention-check-backoff-duration must be set")
 	}
 
 	interval := tempoWriteBackoffDuration
@@ -109,12 +189,21 @@ func main() {
 		return info.Ready(now, tempoWriteBackoffDuration, tempoLongWriteBackoffDuration)
 	}
 
+	// pushed spans are only counted for the span metrics check, which prunes them
+	var pushedSpans *spanCounter
+	if tickerMetrics != nil {
+		pushedSpans = &spanCounter{}
+	}
+
 	// Write
 	go func() {
-		client, err := newJaegerGRPCClient(tempoPushURL)
+		client, err := newPushClient(tempoPushProtocol, tempoPushURL)
 		if err != nil {
 			panic(err)
 		}
+		if pushedSpans != nil {
+			client = &countingClient{JaegerClient: client, spans: pushedSpans}
+		}
 
 		for now := range tickerWrite.C {
 			timestamp := now.Round(interval)
@@ -165,6 +254,7 @@ func main() {
 					)
 				}
 				pushMetrics(queryMetrics)
+				recordCheck("trace_by_id", queryMetrics, err)
 			}
 		}()
 	}
@@ -196,6 +286,108 @@ func main() {
 					)
 				}
 				pushMetrics(searchMetrics)
+				recordCheck("search", searchMetrics, err)
+
+				// query the same tag with duration filters around the duration of the trace
+				durationMetrics, err := searchDuration(client, seed)
+				if err != nil {
+					metricErrorTotal.Inc()
+					log.Error("search with duration for metrics failed",
+						zap.Error(err),
+					)
+				}
+				pushMetrics(durationMetrics)
+				recordCheck("search_duration", durationMetrics, err)
+
+				// the tags endpoints only search recent traces, so they are checked against the newest trace
+				recent, ok := newestReadySeed(now, interval, actualStartTime, ready)
+				if !ok {
+					continue
+				}
+
+				tagsMetrics, err := searchTags(client, recent)
+				if err != nil {
+					metricErrorTotal.Inc()
+					log.Error("search tags for metrics failed",
+						zap.Int64("recent_seed", recent.Unix()),
+						zap.Error(err),
+					)
+				}
+				pushMetrics(tagsMetrics)
+				recordCheck("search_tags", tagsMetrics, err)
+
+				tagValuesMetrics, err := searchTagValues(client, recent)
+				if err != nil {
+					metricErrorTotal.Inc()
+					log.Error("search tag values for metrics failed",
+						zap.Int64("recent_seed", recent.Unix()),
+						zap.Error(err),
+					)
+				}
+				pushMetrics(tagValuesMetrics)
+				recordCheck("search_tag_values", tagValuesMetrics, err)
+			}
+		}()
+	}
+
+	// Span metrics
+	if tickerMetrics != nil {
+		go func() {
+			for now := range tickerMetrics.C {
+				oldest := now.Add(-tempoMetricsDelay - tempoMetricsWindow)
+				pushedSpans.prune(oldest)
+
+				// the window must not start before the first push
+				if oldest.Before(actualStartTime) {
+					continue
+				}
+
+				spanMetrics, err := checkSpanMetrics(pushedSpans, now)
+				if err != nil {
+					metricErrorTotal.Inc()
+					logger.Error("span metrics check failed",
+						zap.Error(err),
+					)
+				}
+				pushMetrics(spanMetrics)
+				recordCheck("span_metrics", spanMetrics, err)
+			}
+		}()
+	}
+
+	// Retention
+	if tickerRetention != nil {
+		go func() {
+			// traces written before the start were pushed by a previous vulture and don't have to wait for
+			// the first writes of this one
+			retentionReady := func(info *util.TraceInfo, now time.Time) bool {
+				if info.Timestamp().Before(actualStartTime) {
+					return info.Ready(now, tempoWriteBackoffDuration, tempoLongWriteBackoffDuration)
+				}
+				return ready(info, now)
+			}
+
+			var next, start time.Time
+			for now := range tickerRetention.C {
+				client := util.NewClient(tempoQueryURL, tempoOrgID)
+
+				// only check traces within retention that were written without a gap up to the start
+				if start.IsZero() {
+					start = retentionStart(client, now, actualStartTime)
+					logger.Info("retention check start", zap.Time("start", start))
+				}
+
+				var retentionMetrics traceMetrics
+				var err error
+				retentionMetrics, next, err = checkRetention(client, now, next, tempoRetentionHoursPerCheck, start, retentionReady)
+				if err != nil {
+					metricErrorTotal.Inc()
+					logger.Error("retention check failed",
+						zap.Error(err),
+					)
+				}
+				pushMetrics(retentionMetrics)
+				recordCheck("retention", retentionMetrics, err)
 			}
 		}()
 	}
@@ -204,7 +396,7 @@ func main() {
 	log.Fatal(http.ListenAndServe(prometheusListenAddress, nil))
 }
 
-func queueFutureBatches(client *jaeger_grpc.Reporter, info *util.TraceInfo) {
+func queueFutureBatches(client util.JaegerClient, info *util.TraceInfo) {
 	if info.LongWritesRemaining() == 0 {
 		return
 	}
@@ -249,6 +441,32 @@ func pushMetrics(metrics traceMetrics) {
 	metricTracesErrors.WithLabelValues("notfound_byid").Add(float64(metrics.notFoundByID))
 	metricTracesErrors.WithLabelValues("requestfailed").Add(float64(metrics.requestFailed))
 	metricTracesErrors.WithLabelValues("notfound_search_attribute").Add(float64(metrics.notFoundSearchAttribute))
+	metricTracesErrors.WithLabelValues("notfound_search_tags").Add(float64(metrics.notFoundSearchTags))
+	metricTracesErrors.WithLabelValues("notfound_search_tag_values").Add(float64(metrics.notFoundSearchTagValues))
+	metricTracesErrors.WithLabelValues("incorrect_search_duration").Add(float64(metrics.incorrectSearchDuration))
+	metricTracesErrors.WithLabelValues("incorrect_span_metrics").Add(float64(metrics.incorrectSpanMetrics))
+	metricTracesErrors.WithLabelValues("lost").Add(float64(metrics.lost))
+	metricTracesErrors.WithLabelValues("duplicated").Add(float64(metrics.duplicated))
+	metricTracesErrors.WithLabelValues("over_retained").Add(float64(metrics.overRetained))
+}
+
+func recordCheck(check string, metrics traceMetrics, err error) {
+	result := "pass"
+	if err != nil || metrics.failed() {
+		result = "fail"
+	}
+	metricChecks.WithLabelValues(check, result).Inc()
+}
+
+// newestReadySeed returns the newest seed that is ready to be read and not older than oldest
+func newestReadySeed(now time.Time, interval time.Duration, oldest time.Time, ready func(*util.TraceInfo, time.Time) bool) (time.Time, bool) {
+	for seed := now.Round(interval); !seed.Before(oldest); seed = seed.Add(-interval) {
+		if ready(util.NewTraceInfo(seed, tempoOrgID), now) {
+			return seed, true
+		}
+	}
+
+	return time.Time{}, false
 }
 
 func selectPastTimestamp(start, stop time.Time, interval time.Duration, retention time.Duration) (newStart, ts time.Time) {
@@ -299,28 +517,12 @@ func searchTag(client *util.Client, seed time.Time) (traceMetrics, error) {
 	hexID := info.HexID()
 
 	// Get the expected
-	expected, err := info.ConstructTraceFromEpoch()
+	expected, err := expectedTrace(info, tempoPushProtocol)
 	if err != nil {
 		logger.Error("unable to construct trace from epoch", zap.Error(err))
 		return traceMetrics{}, err
 	}
 
-	traceInTraces := func(traceID string, traces []*tempopb.TraceSearchMetadata) bool {
-		for _, t := range traces {
-			equal, err := util.EqualHexStringTraceIDs(t.TraceID, traceID)
-			if err != nil {
-				logger.Error("error comparing trace IDs", zap.Error(err))
-				continue
-			}
-
-			if equal {
-				return true
-			}
-		}
-
-		return false
-	}
-
 	attr := util.RandomAttrFromTrace(expected)
 	if attr == nil {
 		tm.notFoundSearchAttribute++
@@ -355,6 +557,206 @@ func searchTag(client *util.Client, seed time.Time) (traceMetrics, error) {
 	return tm, nil
 }
 
+// searchDuration searches for a tag of the trace with duration filters. The trace must be found with a
+// maxDuration above its duration and must not be found with the same value as minDuration.
+func searchDuration(client *util.Client, seed time.Time) (traceMetrics, error) {
+	tm := traceMetrics{
+		requested: 1,
+	}
+
+	info := util.NewTraceInfo(seed, tempoOrgID)
+	hexID := info.HexID()
+
+	expected, err := expectedTrace(info, tempoPushProtocol)
+	if err != nil {
+		logger.Error("unable to construct trace from epoch", zap.Error(err))
+		return traceMetrics{}, err
+	}
+
+	attr := util.RandomAttrFromTrace(expected)
+	if attr == nil {
+		tm.notFoundSearchAttribute++
+		return tm, fmt.Errorf("no search attr selected from trace")
+	}
+
+	// the api accepts milliseconds, the bound is the next full millisecond above the duration
+	bound := traceDuration(expected).Truncate(time.Millisecond) + time.Millisecond
+	tags := fmt.Sprintf("%s=%s", attr.Key, util.StringifyAnyValue(attr.Value))
+
+	logger := logger.With(
+		zap.Int64("seed", seed.Unix()),
+		zap.String("hexID", hexID),
+		zap.Duration("ago", time.Since(seed)),
+		zap.String("key", attr.Key),
+		zap.String("value", util.StringifyAnyValue(attr.Value)),
+		zap.Duration("bound", bound),
+	)
+	logger.Info("searching Tempo with duration")
+
+	start := seed.Add(-30 * time.Minute).Unix()
+	end := seed.Add(30 * time.Minute).Unix()
+	resp, err := client.SearchWithDuration(tags, start, end, 0, bound)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to search traces with tag %s and maxDuration: %s", attr.Key, err.Error()))
+		tm.requestFailed++
+		return tm, err
+	}
+
+	if !traceInTraces(hexID, resp.Traces) {
+		tm.incorrectSearchDuration++
+		return tm, fmt.Errorf("trace %s not found in search response with maxDuration %s: %+v", hexID, bound, resp.Traces)
+	}
+
+	resp, err = client.SearchWithDuration(tags, start, end, bound, 0)
+	if err != nil {
+		logger.Error(fmt.Sprintf("failed to search traces with tag %s and minDuration: %s", attr.Key, err.Error()))
+		tm.requestFailed++
+		return tm, err
+	}
+
+	if traceInTraces(hexID, resp.Traces) {
+		tm.incorrectSearchDuration++
+		return tm, fmt.Errorf("trace %s found in search response with minDuration %s", hexID, bound)
+	}
+
+	return tm, nil
+}
+
+// searchTags checks that the tags endpoint returns the key of an attribute of the trace
+func searchTags(client *util.Client, seed time.Time) (traceMetrics, error) {
+	tm := traceMetrics{
+		requested: 1,
+	}
+
+	info := util.NewTraceInfo(seed, tempoOrgID)
+
+	expected, err := expectedTrace(info, tempoPushProtocol)
+	if err != nil {
+		logger.Error("unable to construct trace from epoch", zap.Error(err))
+		return traceMetrics{}, err
+	}
+
+	attr := util.RandomAttrFromTrace(expected)
+	if attr == nil {
+		tm.notFoundSearchAttribute++
+		return tm, fmt.Errorf("no search attr selected from trace")
+	}
+
+	logger := logger.With(
+		zap.Int64("seed", seed.Unix()),
+		zap.String("hexID", info.HexID()),
+		zap.String("key", attr.Key),
+	)
+	logger.Info("searching Tempo tags")
+
+	resp, err := client.SearchTags()
+	if err != nil {
+		logger.Error("failed to search tags", zap.Error(err))
+		tm.requestFailed++
+		return tm, err
+	}
+
+	if !stringInStrings(attr.Key, resp.TagNames) {
+		tm.notFoundSearchTags++
+		return tm, fmt.Errorf("tag %s not found in search tags response: %v", attr.Key, resp.TagNames)
+	}
+
+	return tm, nil
+}
+
+// searchTagValues checks that the tag values endpoint returns the value of an attribute of the trace
+func searchTagValues(client *util.Client, seed time.Time) (traceMetrics, error) {
+	tm := traceMetrics{
+		requested: 1,
+	}
+
+	info := util.NewTraceInfo(seed, tempoOrgID)
+
+	expected, err := expectedTrace(info, tempoPushProtocol)
+	if err != nil {
+		logger.Error("unable to construct trace from epoch", zap.Error(err))
+		return traceMetrics{}, err
+	}
+
+	attr := util.RandomAttrFromTrace(expected)
+	if attr == nil {
+		tm.notFoundSearchAttribute++
+		return tm, fmt.Errorf("no search attr selected from trace")
+	}
+	value := util.StringifyAnyValue(attr.Value)
+
+	logger := logger.With(
+		zap.Int64("seed", seed.Unix()),
+		zap.String("hexID", info.HexID()),
+		zap.String("key", attr.Key),
+		zap.String("value", value),
+	)
+	logger.Info("searching Tempo tag values")
+
+	resp, err := client.SearchTagValues(attr.Key)
+	if err != nil {
+		logger.Error("failed to search tag values", zap.Error(err))
+		tm.requestFailed++
+		return tm, err
+	}
+
+	if !stringInStrings(value, resp.TagValues) {
+		tm.notFoundSearchTagValues++
+		return tm, fmt.Errorf("value %s of tag %s not found in search tag values response", value, attr.Key)
+	}
+
+	return tm, nil
+}
+
+func traceInTraces(traceID string, traces []*tempopb.TraceSearchMetadata) bool {
+	for _, t := range traces {
+		equal, err := util.EqualHexStringTraceIDs(t.TraceID, traceID)
+		if err != nil {
+			logger.Error("error comparing trace IDs", zap.Error(err))
+			continue
+		}
+
+		if equal {
+			return true
+		}
+	}
+
+	return false
+}
+
+func stringInStrings(s string, strs []string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// traceDuration returns the time from the first span start to the last span end
+func traceDuration(t *tempopb.Trace) time.Duration {
+	var start, end uint64
+
+	for _, b := range t.Batches {
+		for _, ils := range b.InstrumentationLibrarySpans {
+			for _, s := range ils.Spans {
+				if start == 0 || s.StartTimeUnixNano < start {
+					start = s.StartTimeUnixNano
+				}
+				if s.EndTimeUnixNano > end {
+					end = s.EndTimeUnixNano
+				}
+			}
+		}
+	}
+
+	if end < start {
+		return 0
+	}
+	return time.Duration(end - start)
+}
+
 func queryTrace(client *util.Client, info *util.TraceInfo) (traceMetrics, error) {
 	tm := traceMetrics{
 		requested: 1,
@@ -394,7 +796,7 @@ func queryTrace(client *util.Client, info *util.TraceInfo) (traceMetrics, error)
 	}
 
 	// Get the expected
-	expected, err := info.ConstructTraceFromEpoch()
+	expected, err := expectedTrace(info, tempoPushProtocol)
 	if err != nil {
 		logger.Error("unable to construct trace from epoch", zap.Error(err))
 		return tm, err
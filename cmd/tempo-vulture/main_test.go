@@ -3,6 +3,9 @@ package main
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
@@ -111,3 +114,127 @@ func TestEqualTraces(t *testing.T) {
 
 	require.True(t, equalTraces(a, b))
 }
+
+func TestTraceDuration(t *testing.T) {
+	trace := &tempopb.Trace{
+		Batches: []*v1.ResourceSpans{
+			{
+				InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{
+					{
+						Spans: []*v1.Span{
+							{StartTimeUnixNano: 1000, EndTimeUnixNano: 3000},
+							{StartTimeUnixNano: 500, EndTimeUnixNano: 2000},
+						},
+					},
+				},
+			},
+		},
+	}
+	assert.Equal(t, 2500*time.Nanosecond, traceDuration(trace))
+	assert.Equal(t, time.Duration(0), traceDuration(&tempopb.Trace{}))
+
+	// vulture traces are shorter than the minimum duration filter of 1ms
+	info := util.NewTraceInfo(time.Unix(1636729665, 0), "")
+	generated, err := info.ConstructTraceFromEpoch()
+	require.NoError(t, err)
+	assert.Less(t, int64(traceDuration(generated)), int64(time.Millisecond))
+}
+
+func TestSpanCounter(t *testing.T) {
+	c := &spanCounter{}
+	start := time.Unix(1000, 0)
+
+	c.add(start.Add(1*time.Second), 1)
+	c.add(start.Add(2*time.Second), 2)
+	c.add(start.Add(3*time.Second), 4)
+
+	assert.Equal(t, 7, c.count(start, start.Add(3*time.Second)))
+	assert.Equal(t, 6, c.count(start.Add(1*time.Second), start.Add(3*time.Second)))
+	assert.Equal(t, 3, c.count(start, start.Add(2*time.Second)))
+
+	c.prune(start.Add(2 * time.Second))
+	assert.Equal(t, 6, c.count(start, start.Add(3*time.Second)))
+}
+
+func TestWithinTolerance(t *testing.T) {
+	assert.True(t, withinTolerance(100, 100, 0))
+	assert.True(t, withinTolerance(100, 109, 0.1))
+	assert.True(t, withinTolerance(100, 91, 0.1))
+	assert.False(t, withinTolerance(100, 111, 0.1))
+	assert.True(t, withinTolerance(0, 0, 0.1))
+	assert.False(t, withinTolerance(0, 1, 0.1))
+}
+
+func TestQueryPrometheus(t *testing.T) {
+	var query, orgID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("query")
+		orgID = r.Header.Get("X-Scope-OrgID")
+		_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1636729665,"42.5"]}]}}`))
+	}))
+	defer srv.Close()
+
+	q := spanMetricsQuery(`{service="tempo-vulture"}`, 5*time.Minute)
+	assert.Equal(t, `sum(increase(traces_spanmetrics_calls_total{service="tempo-vulture"}[300s]))`, q)
+
+	v, err := queryPrometheus(srv.URL, "test", q, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 42.5, v)
+	assert.Equal(t, q, query)
+	assert.Equal(t, "test", orgID)
+}
+
+func TestHTTPPushClient(t *testing.T) {
+	var orgID string
+	var received *tempopb.Trace
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		orgID = r.Header.Get("X-Scope-OrgID")
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		received = &tempopb.Trace{}
+		require.NoError(t, received.Unmarshal(body))
+	}))
+	defer srv.Close()
+
+	c := &httpPushClient{
+		url:         srv.URL,
+		contentType: "application/x-protobuf",
+		marshal:     marshalOTLP,
+		client:      http.DefaultClient,
+	}
+	spans := &spanCounter{}
+	client := &countingClient{JaegerClient: c, spans: spans}
+
+	info := util.NewTraceInfo(time.Unix(1636729665, 0), "test")
+	require.NoError(t, info.EmitAllBatches(client))
+
+	expected, err := expectedTrace(util.NewTraceInfo(time.Unix(1636729665, 0), "test"), pushProtocolOTLPHTTP)
+	require.NoError(t, err)
+
+	assert.Equal(t, "test", orgID)
+	require.NotNil(t, received)
+	assert.Equal(t, countSpans(expected), spans.count(time.Time{}, time.Now()))
+}
+
+func TestExpectedTraceZipkin(t *testing.T) {
+	info := util.NewTraceInfo(time.Unix(1636729665, 0), "")
+
+	expected, err := expectedTrace(info, pushProtocolJaegerGRPC)
+	require.NoError(t, err)
+	zipkin, err := expectedTrace(info, pushProtocolZipkin)
+	require.NoError(t, err)
+
+	assert.Equal(t, len(expected.Batches), len(zipkin.Batches))
+	assert.Equal(t, countSpans(expected), countSpans(zipkin))
+}
+
+func countSpans(t *tempopb.Trace) int {
+	count := 0
+	for _, b := range t.Batches {
+		for _, ils := range b.InstrumentationLibrarySpans {
+			count += len(ils.Spans)
+		}
+	}
+	return count
+}
@@ -34,10 +34,21 @@ var (
 		},
 		[]string{"error"},
 	)
+
+	// metricChecks is a prometheus counter that indicates the number of passed and failed checks.
+	metricChecks = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "check_total",
+			Help:      "total number of checks by tempo vulture by result",
+		},
+		[]string{"check", "result"},
+	)
 )
 
 func init() {
 	prometheus.MustRegister(metricErrorTotal)
 	prometheus.MustRegister(metricTracesInspected)
 	prometheus.MustRegister(metricTracesErrors)
+	prometheus.MustRegister(metricChecks)
 }
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	thrift "github.com/jaegertracing/jaeger/thrift-gen/jaeger"
+	jaegerTrans "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/jaeger"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/zipkin/zipkinv2"
+	"github.com/weaveworks/common/user"
+	"go.opentelemetry.io/collector/model/otlp"
+	"go.opentelemetry.io/collector/model/pdata"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+	"github.com/grafana/tempo/pkg/util"
+)
+
+const (
+	pushProtocolJaegerGRPC = "jaeger-grpc"
+	pushProtocolOTLPGRPC   = "otlp-grpc"
+	pushProtocolOTLPHTTP   = "otlp-http"
+	pushProtocolZipkin     = "zipkin"
+
+	otlpGRPCExportMethod = "/opentelemetry.proto.collector.trace.v1.TraceService/Export"
+)
+
+// newPushClient returns the client that pushes traces to Tempo with the given protocol. Every protocol pushes
+// to the default port of its receiver.
+func newPushClient(protocol, endpoint string) (util.JaegerClient, error) {
+	var (
+		client util.JaegerClient
+		err    error
+	)
+
+	switch protocol {
+	case pushProtocolJaegerGRPC:
+		client, err = newJaegerGRPCClient(endpoint)
+	case pushProtocolOTLPGRPC:
+		client, err = newOTLPGRPCClient(endpoint)
+	case pushProtocolOTLPHTTP:
+		client, err = newHTTPPushClient(endpoint, "4318", "/v1/traces", "application/x-protobuf", marshalOTLP)
+	case pushProtocolZipkin:
+		client, err = newHTTPPushClient(endpoint, "9411", "/api/v2/spans", "application/json", zipkinv2.NewJSONTracesMarshaler().MarshalTraces)
+	default:
+		return nil, fmt.Errorf("unknown push protocol %s", protocol)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// otlpGRPCClient pushes batches to the OTLP gRPC receiver. tempopb.Trace has the wire format of the OTLP export
+// request, so there is no need for the OTLP gRPC client.
+type otlpGRPCClient struct {
+	conn *grpc.ClientConn
+}
+
+func newOTLPGRPCClient(endpoint string) (*otlpGRPCClient, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("dialing grpc",
+		zap.String("endpoint", fmt.Sprintf("%s:4317", u.Host)),
+	)
+
+	conn, err := grpc.Dial(u.Host+":4317", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	return &otlpGRPCClient{conn: conn}, nil
+}
+
+func (c *otlpGRPCClient) EmitBatch(ctx context.Context, b *thrift.Batch) error {
+	buff, err := marshalThriftBatch(b, marshalOTLP)
+	if err != nil {
+		return err
+	}
+
+	req := &tempopb.Trace{}
+	err = req.Unmarshal(buff)
+	if err != nil {
+		return err
+	}
+
+	// the org id is already injected into the grpc metadata
+	return c.conn.Invoke(ctx, otlpGRPCExportMethod, req, &tempopb.PushResponse{})
+}
+
+// httpPushClient posts batches to a receiver that accepts traces over HTTP.
+type httpPushClient struct {
+	url         string
+	contentType string
+	marshal     func(td pdata.Traces) ([]byte, error)
+	client      *http.Client
+}
+
+func newHTTPPushClient(endpoint, port, path, contentType string, marshal func(td pdata.Traces) ([]byte, error)) (*httpPushClient, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	pushURL := fmt.Sprintf("%s://%s:%s%s", u.Scheme, u.Host, port, path)
+	logger.Info("pushing over http",
+		zap.String("endpoint", pushURL),
+	)
+
+	return &httpPushClient{
+		url:         pushURL,
+		contentType: contentType,
+		marshal:     marshal,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (c *httpPushClient) EmitBatch(ctx context.Context, b *thrift.Batch) error {
+	buff, err := marshalThriftBatch(b, c.marshal)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(buff))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", c.contentType)
+
+	orgID, err := user.ExtractOrgID(ctx)
+	if err == nil && orgID != "" {
+		req.Header.Set(user.OrgIDHeaderName, orgID)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("POST request to %s failed with response: %d body: %s", c.url, resp.StatusCode, string(body))
+	}
+
+	return nil
+}
+
+func marshalOTLP(td pdata.Traces) ([]byte, error) {
+	return otlp.NewProtobufTracesMarshaler().MarshalTraces(td)
+}
+
+func marshalThriftBatch(b *thrift.Batch, marshal func(td pdata.Traces) ([]byte, error)) ([]byte, error) {
+	td, err := jaegerTrans.ThriftToTraces(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshal(td)
+}
+
+// expectedTrace returns the trace Tempo is expected to return for the given trace info after it was pushed with
+// the protocol. Zipkin does not support all of the OTLP data model, so the trace is converted the same way the
+// zipkin receiver converts it.
+func expectedTrace(info *util.TraceInfo, protocol string) (*tempopb.Trace, error) {
+	expected, err := info.ConstructTraceFromEpoch()
+	if err != nil {
+		return nil, err
+	}
+
+	if protocol != pushProtocolZipkin {
+		return expected, nil
+	}
+
+	converted := &tempopb.Trace{}
+	for _, b := range expected.Batches {
+		// every batch is converted on its own, the zipkin receiver doesn't merge batches
+		buff, err := (&tempopb.Trace{Batches: []*v1.ResourceSpans{b}}).Marshal()
+		if err != nil {
+			return nil, err
+		}
+
+		td, err := otlp.NewProtobufTracesUnmarshaler().UnmarshalTraces(buff)
+		if err != nil {
+			return nil, err
+		}
+
+		buff, err = zipkinv2.NewJSONTracesMarshaler().MarshalTraces(td)
+		if err != nil {
+			return nil, err
+		}
+
+		td, err = zipkinv2.NewJSONTracesUnmarshaler(false).UnmarshalTraces(buff)
+		if err != nil {
+			return nil, err
+		}
+
+		buff, err = marshalOTLP(td)
+		if err != nil {
+			return nil, err
+		}
+
+		t := &tempopb.Trace{}
+		err = t.Unmarshal(buff)
+		if err != nil {
+			return nil, err
+		}
+
+		// see ConstructTraceFromEpoch
+		for _, b := range t.Batches {
+			for _, l := range b.InstrumentationLibrarySpans {
+				for _, s := range l.Spans {
+					if len(s.GetParentSpanId()) == 0 {
+						s.ParentSpanId = nil
+					}
+				}
+			}
+		}
+
+		converted.Batches = append(converted.Batches, t.Batches...)
+	}
+
+	return converted, nil
+}
+
+// countingClient records the spans that are pushed successfully
+type countingClient struct {
+	util.JaegerClient
+	spans *spanCounter
+}
+
+func (c *countingClient) EmitBatch(ctx context.Context, b *thrift.Batch) error {
+	err := c.JaegerClient.EmitBatch(ctx, b)
+	if err == nil {
+		c.spans.add(time.Now(), len(b.Spans))
+	}
+	return err
+}
+
+type spanSample struct {
+	ts    time.Time
+	count int
+}
+
+// spanCounter holds the number of spans pushed over time
+type spanCounter struct {
+	mtx     sync.Mutex
+	samples []spanSample
+}
+
+func (c *spanCounter) add(ts time.Time, count int) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.samples = append(c.samples, spanSample{ts: ts, count: count})
+}
+
+// count returns the number of spans pushed in (start, end]
+func (c *spanCounter) count(start, end time.Time) int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	total := 0
+	for _, s := range c.samples {
+		if s.ts.After(start) && !s.ts.After(end) {
+			total += s.count
+		}
+	}
+	return total
+}
+
+// prune drops the samples before the given time
+func (c *spanCounter) prune(before time.Time) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	i := 0
+	for i < len(c.samples) && c.samples[i].ts.Before(before) {
+		i++
+	}
+	c.samples = c.samples[i:]
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util"
+)
+
+// overRetentionCheckWindow is how long traces are checked to stay deleted after they are expected to be
+// deleted
+const overRetentionCheckWindow = 24 * time.Hour
+
+// retentionSeeds returns the seeds of the traces that are tracked by the retention check in the hour. The seeds
+// only depend on the hour, so the same traces are checked on every run.
+func retentionSeeds(hour time.Time, perHour int, interval time.Duration) []time.Time {
+	writesPerHour := int64(time.Hour / interval)
+	if writesPerHour <= 0 {
+		return nil
+	}
+	if int64(perHour) > writesPerHour {
+		perHour = int(writesPerHour)
+	}
+
+	r := rand.New(rand.NewSource(hour.Unix()))
+	seen := map[int64]struct{}{}
+	seeds := make([]time.Time, 0, perHour)
+	for len(seeds) < perHour {
+		i := r.Int63n(writesPerHour)
+		if _, ok := seen[i]; ok {
+			continue
+		}
+		seen[i] = struct{}{}
+
+		seeds = append(seeds, hour.Add(time.Duration(i)*interval))
+	}
+
+	return seeds
+}
+
+// retentionExpiry is the age after which a trace must be deleted: the retention, the time until compacted
+// blocks are deleted and a grace period for the block window and the compaction and polling cycles.
+func retentionExpiry() time.Duration {
+	return tempoRetentionDuration + tempoCompactedBlockRetention + tempoRetentionGracePeriod
+}
+
+// retentionHours returns the oldest and the newest hour with traces tracked by the retention check at now
+func retentionHours(now time.Time) (oldest, newest time.Time) {
+	return now.Add(-retentionExpiry() - overRetentionCheckWindow).Truncate(time.Hour), now.Truncate(time.Hour)
+}
+
+// retentionStart returns the time since which traces were written without a gap. Seeds only depend on the hour,
+// so the tracked traces before start may have been written by a previous vulture. They are probed from the newest
+// to the oldest and the first one that is not found marks a gap, e.g. a fresh deployment or a downtime of the
+// vulture. A trace that was lost right before start is taken for a gap as well.
+func retentionStart(client *util.Client, now time.Time, start time.Time) time.Time {
+	oldest := now.Add(-tempoRetentionDuration).Truncate(time.Hour)
+	for hour := start.Truncate(time.Hour); !hour.Before(oldest); hour = hour.Add(-time.Hour) {
+		seeds := retentionSeeds(hour, tempoRetentionTracesPerHour, tempoWriteBackoffDuration)
+		sort.Slice(seeds, func(i, j int) bool {
+			return seeds[i].After(seeds[j])
+		})
+
+		for _, seed := range seeds {
+			if !seed.Before(start) || now.Sub(seed) >= tempoRetentionDuration {
+				continue
+			}
+
+			_, err := client.QueryTrace(util.NewTraceInfo(seed, tempoOrgID).HexID())
+			if err != nil {
+				return start
+			}
+			start = seed
+		}
+	}
+
+	return start
+}
+
+// checkRetention checks the tracked traces of up to hours hours, starting at next. It returns the hour to continue
+// with on the next run, so consecutive runs rotate through all tracked hours. Traces within retention must be
+// found with all spans and traces past their expiry must not be found anymore. Traces in between are not
+// checked, they can be deleted at any time.
+//
+// Traces within retention are only checked if they were written after start, see retentionStart. Traces past
+// their expiry are checked regardless of who wrote them.
+func checkRetention(client *util.Client, now time.Time, next time.Time, hours int, start time.Time, ready func(*util.TraceInfo, time.Time) bool) (traceMetrics, time.Time, error) {
+	tm := traceMetrics{}
+	var lastErr error
+
+	oldest, newest := retentionHours(now)
+	hour := next.Truncate(time.Hour)
+	for i := 0; i < hours; i++ {
+		if hour.Before(oldest) || hour.After(newest) {
+			hour = oldest
+		}
+
+		for _, seed := range retentionSeeds(hour, tempoRetentionTracesPerHour, tempoWriteBackoffDuration) {
+			info := util.NewTraceInfo(seed, tempoOrgID)
+			age := now.Sub(seed)
+
+			var (
+				m   traceMetrics
+				err error
+			)
+			switch {
+			case age < tempoRetentionDuration:
+				// the trace may not have been written
+				if seed.Before(start) || !ready(info, now) {
+					continue
+				}
+				m, err = checkRetainedTrace(client, info)
+			case age > retentionExpiry():
+				m, err = checkExpiredTrace(client, info)
+			default:
+				continue
+			}
+
+			tm = addTraceMetrics(tm, m)
+			if err != nil {
+				lastErr = err
+			}
+		}
+
+		hour = hour.Add(time.Hour)
+	}
+
+	return tm, hour, lastErr
+}
+
+// checkRetainedTrace checks that the trace is found with all spans and no span is returned twice
+func checkRetainedTrace(client *util.Client, info *util.TraceInfo) (traceMetrics, error) {
+	tm := traceMetrics{
+		requested: 1,
+	}
+
+	hexID := info.HexID()
+	logger := logger.With(
+		zap.Int64("seed", info.Timestamp().Unix()),
+		zap.String("hexID", hexID),
+		zap.Duration("ago", time.Since(info.Timestamp())),
+	)
+
+	expected, err := expectedTrace(info, tempoPushProtocol)
+	if err != nil {
+		logger.Error("unable to construct trace from epoch", zap.Error(err))
+		return tm, err
+	}
+
+	trace, err := client.QueryTrace(hexID)
+	if err == util.ErrTraceNotFound {
+		logger.Error("retained trace lost")
+		tm.lost++
+		return tm, fmt.Errorf("trace %s within retention not found", hexID)
+	}
+	if err != nil {
+		logger.Error("error querying Tempo", zap.Error(err))
+		tm.requestFailed++
+		return tm, err
+	}
+
+	unique, duplicated := countSpanIDs(trace)
+	if duplicated > 0 {
+		logger.Error("retained trace has duplicated spans", zap.Int("duplicated", duplicated))
+		tm.duplicated++
+	}
+	if want, _ := countSpanIDs(expected); unique < want {
+		logger.Error("retained trace lost spans", zap.Int("expected", want), zap.Int("found", unique))
+		tm.lost++
+	}
+
+	return tm, nil
+}
+
+// checkExpiredTrace checks that the trace is not found anymore
+func checkExpiredTrace(client *util.Client, info *util.TraceInfo) (traceMetrics, error) {
+	tm := traceMetrics{
+		requested: 1,
+	}
+
+	hexID := info.HexID()
+	_, err := client.QueryTrace(hexID)
+	if err == util.ErrTraceNotFound {
+		return tm, nil
+	}
+	if err != nil {
+		tm.requestFailed++
+		return tm, err
+	}
+
+	logger.Error("expired trace still found",
+		zap.Int64("seed", info.Timestamp().Unix()),
+		zap.String("hexID", hexID),
+		zap.Duration("ago", time.Since(info.Timestamp())),
+	)
+	tm.overRetained++
+	return tm, fmt.Errorf("trace %s past retention found", hexID)
+}
+
+// countSpanIDs returns the number of distinct span ids and the number of spans with an id that was already seen
+func countSpanIDs(t *tempopb.Trace) (unique int, duplicated int) {
+	seen := map[string]struct{}{}
+
+	for _, b := range t.Batches {
+		for _, ils := range b.InstrumentationLibrarySpans {
+			for _, s := range ils.Spans {
+				if _, ok := seen[string(s.SpanId)]; ok {
+					duplicated++
+					continue
+				}
+				seen[string(s.SpanId)] = struct{}{}
+			}
+		}
+	}
+
+	return len(seen), duplicated
+}
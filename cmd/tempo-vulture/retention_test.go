@@ -0,0 +1,221 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+	"github.com/grafana/tempo/pkg/util"
+)
+
+func TestRetentionSeeds(t *testing.T) {
+	hour := time.Unix(1636729200, 0)
+	interval := 10 * time.Second
+
+	seeds := retentionSeeds(hour, 5, interval)
+	require.Len(t, seeds, 5)
+	assert.Equal(t, seeds, retentionSeeds(hour, 5, interval))
+
+	seen := map[time.Time]struct{}{}
+	for _, s := range seeds {
+		assert.False(t, s.Before(hour))
+		assert.True(t, s.Before(hour.Add(time.Hour)))
+		assert.Equal(t, s, s.Round(interval))
+		seen[s] = struct{}{}
+	}
+	assert.Len(t, seen, 5)
+
+	// no more seeds than writes per hour
+	assert.Len(t, retentionSeeds(hour, 10, 20*time.Minute), 3)
+}
+
+func TestCountSpanIDs(t *testing.T) {
+	trace := &tempopb.Trace{
+		Batches: []*v1.ResourceSpans{
+			{
+				InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{
+					{
+						Spans: []*v1.Span{
+							{SpanId: []byte{0x01}},
+							{SpanId: []byte{0x02}},
+							{SpanId: []byte{0x01}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	unique, duplicated := countSpanIDs(trace)
+	assert.Equal(t, 2, unique)
+	assert.Equal(t, 1, duplicated)
+}
+
+func TestCheckRetention(t *testing.T) {
+	logger = zap.NewNop()
+
+	now := time.Now().Truncate(time.Hour).Add(30 * time.Minute)
+	oldest, newest := retentionHours(now)
+	hours := int(newest.Sub(oldest)/time.Hour) + 1
+	ready := func(*util.TraceInfo, time.Time) bool { return true }
+
+	// all tracked traces by id and the number expected within and past retention
+	traces := map[string]*tempopb.Trace{}
+	retained, expired := 0, 0
+	for hour := oldest; !hour.After(newest); hour = hour.Add(time.Hour) {
+		for _, seed := range retentionSeeds(hour, tempoRetentionTracesPerHour, tempoWriteBackoffDuration) {
+			info := util.NewTraceInfo(seed, "")
+			trace, err := expectedTrace(info, pushProtocolJaegerGRPC)
+			require.NoError(t, err)
+			traces[info.HexID()] = trace
+
+			age := now.Sub(seed)
+			if age < tempoRetentionDuration {
+				retained++
+			} else if age > retentionExpiry() {
+				expired++
+			}
+		}
+	}
+	require.Greater(t, retained, 0)
+	require.Greater(t, expired, 0)
+
+	tests := []struct {
+		name     string
+		found    bool
+		expected traceMetrics
+	}{
+		{
+			name:  "nothing found",
+			found: false,
+			expected: traceMetrics{
+				requested: retained + expired,
+				lost:      retained,
+			},
+		},
+		{
+			name:  "everything found",
+			found: true,
+			expected: traceMetrics{
+				requested:    retained + expired,
+				overRetained: expired,
+			},
+		},
+	}
+
+	newServer := func(found bool) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			trace, ok := traces[strings.TrimPrefix(r.URL.Path, util.QueryTraceEndpoint+"/")]
+			if !ok || !found {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			require.NoError(t, (&jsonpb.Marshaler{}).Marshal(w, trace))
+		}))
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := newServer(tc.found)
+			defer srv.Close()
+
+			tm, next, err := checkRetention(util.NewClient(srv.URL, ""), now, time.Time{}, hours, time.Time{}, ready)
+			assert.Error(t, err)
+			assert.Equal(t, tc.expected, tm)
+			assert.Equal(t, newest.Add(time.Hour), next)
+		})
+	}
+
+	t.Run("rotating", func(t *testing.T) {
+		srv := newServer(true)
+		defer srv.Close()
+		client := util.NewClient(srv.URL, "")
+
+		// consecutive checks of a few hours cover all hours once and then start over
+		perCheck := 10
+		total := traceMetrics{}
+		next := time.Time{}
+		for checked := 0; checked < hours; checked += perCheck {
+			n := perCheck
+			if hours-checked < n {
+				n = hours - checked
+			}
+
+			var tm traceMetrics
+			tm, next, _ = checkRetention(client, now, next, n, time.Time{}, ready)
+			assert.LessOrEqual(t, tm.requested, n*tempoRetentionTracesPerHour)
+			total = addTraceMetrics(total, tm)
+		}
+		assert.Equal(t, traceMetrics{requested: retained + expired, overRetained: expired}, total)
+
+		tm, next, _ := checkRetention(client, now, next, 1, time.Time{}, ready)
+		assert.Equal(t, 1, tm.requested)
+		assert.Equal(t, oldest.Add(time.Hour), next)
+	})
+
+	t.Run("not written before start", func(t *testing.T) {
+		srv := newServer(false)
+		defer srv.Close()
+
+		// a fresh vulture doesn't count the traces before its start as lost
+		tm, _, err := checkRetention(util.NewClient(srv.URL, ""), now, time.Time{}, hours, now, ready)
+		assert.NoError(t, err)
+		assert.Equal(t, traceMetrics{requested: expired}, tm)
+	})
+}
+
+func TestRetentionStart(t *testing.T) {
+	logger = zap.NewNop()
+
+	now := time.Now().Truncate(time.Hour).Add(30 * time.Minute)
+	start := now.Add(-time.Hour)
+	gap := now.Add(-tempoRetentionDuration / 2)
+
+	// traces were written since the gap
+	var written []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for hour := gap.Truncate(time.Hour); !hour.After(start); hour = hour.Add(time.Hour) {
+			for _, seed := range retentionSeeds(hour, tempoRetentionTracesPerHour, tempoWriteBackoffDuration) {
+				info := util.NewTraceInfo(seed, tempoOrgID)
+				if seed.Before(gap) || strings.TrimPrefix(r.URL.Path, util.QueryTraceEndpoint+"/") != info.HexID() {
+					continue
+				}
+				trace, err := expectedTrace(info, pushProtocolJaegerGRPC)
+				require.NoError(t, err)
+				require.NoError(t, (&jsonpb.Marshaler{}).Marshal(w, trace))
+				written = append(written, seed)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	// the oldest found trace before the gap is the start
+	actual := retentionStart(util.NewClient(srv.URL, tempoOrgID), now, start)
+	require.NotEmpty(t, written)
+	oldestWritten := written[len(written)-1]
+	for _, seed := range written {
+		if seed.Before(oldestWritten) {
+			oldestWritten = seed
+		}
+	}
+	assert.Equal(t, oldestWritten, actual)
+	assert.False(t, actual.Before(gap))
+
+	// nothing was written before a fresh start
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer empty.Close()
+	assert.Equal(t, start, retentionStart(util.NewClient(empty.URL, tempoOrgID), now, start))
+}
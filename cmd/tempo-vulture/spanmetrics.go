@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// promQueryResponse is the response of the instant query endpoint of a Prometheus-compatible API
+type promQueryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// queryPrometheus runs an instant query that is expected to return a single sample, like a sum. An empty
+// result is 0.
+func queryPrometheus(baseURL, orgID, query string, ts time.Time) (float64, error) {
+	u := baseURL + "/api/v1/query?query=" + url.QueryEscape(query) + "&time=" + strconv.FormatInt(ts.Unix(), 10)
+
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return 0, err
+	}
+	if len(orgID) > 0 {
+		req.Header.Set("X-Scope-OrgID", orgID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	if resp.StatusCode >= 400 && resp.StatusCode <= 599 {
+		return 0, fmt.Errorf("GET request to %s failed with response: %d body: %s", u, resp.StatusCode, string(body))
+	}
+
+	r := promQueryResponse{}
+	err = json.Unmarshal(body, &r)
+	if err != nil {
+		return 0, fmt.Errorf("error decoding query response, err: %v body: %s", err, string(body))
+	}
+	if r.Status != "success" {
+		return 0, fmt.Errorf("query failed: %s", r.Error)
+	}
+	if r.Data.ResultType != "vector" {
+		return 0, fmt.Errorf("unexpected result type %s", r.Data.ResultType)
+	}
+
+	switch len(r.Data.Result) {
+	case 0:
+		return 0, nil
+	case 1:
+	default:
+		return 0, fmt.Errorf("expected a single sample, got %d", len(r.Data.Result))
+	}
+
+	value := r.Data.Result[0].Value
+	if len(value) != 2 {
+		return 0, fmt.Errorf("unexpected sample %v", value)
+	}
+	s, ok := value[1].(string)
+	if !ok {
+		return 0, fmt.Errorf("unexpected sample value %v", value[1])
+	}
+
+	return strconv.ParseFloat(s, 64)
+}
+
+// spanMetricsQuery returns the query of the number of spans counted by the span metrics processor in the window
+func spanMetricsQuery(selector string, window time.Duration) string {
+	return fmt.Sprintf("sum(increase(traces_spanmetrics_calls_total%s[%ds]))", selector, int64(window.Seconds()))
+}
+
+// checkSpanMetrics compares the spans counted by the metrics-generator with the spans pushed in the window that
+// ended delay ago. The metrics-generator needs some time to count and remote write the spans, so the query is
+// evaluated now.
+func checkSpanMetrics(spans *spanCounter, now time.Time) (traceMetrics, error) {
+	tm := traceMetrics{
+		requested: 1,
+	}
+
+	end := now.Add(-tempoMetricsDelay)
+	start := end.Add(-tempoMetricsWindow)
+	expected := spans.count(start, end)
+
+	query := spanMetricsQuery(tempoMetricsSelector, tempoMetricsWindow)
+
+	logger := logger.With(
+		zap.String("query", query),
+		zap.Int("expected", expected),
+	)
+	logger.Info("querying span metrics")
+
+	actual, err := queryPrometheus(prometheusQueryURL, tempoOrgID, query, now)
+	if err != nil {
+		logger.Error("error querying span metrics", zap.Error(err))
+		tm.requestFailed++
+		return tm, err
+	}
+
+	if !withinTolerance(float64(expected), actual, tempoMetricsTolerance) {
+		tm.incorrectSpanMetrics++
+		return tm, fmt.Errorf("span metrics counted %f spans, expected %d", actual, expected)
+	}
+
+	return tm, nil
+}
+
+// withinTolerance returns true if actual differs by at most the fraction tolerance from expected. increase()
+// extrapolates, so the span metrics are never exact.
+func withinTolerance(expected, actual, tolerance float64) bool {
+	return math.Abs(actual-expected) <= expected*tolerance
+}
@@ -69,6 +69,7 @@ type Config struct {
 	Generator       generator.Config        `yaml:"metrics_generator,omitempty"`
 	StorageConfig   storage.Config          `yaml:"storage,omitempty"`
 	LimitsConfig    overrides.Limits        `yaml:"overrides,omitempty"`
+	OverridesAPI    overrides.APIConfig     `yaml:"overrides_api,omitempty"`
 	MemberlistKV    memberlist.KVConfig     `yaml:"memberlist,omitempty"`
 }
 
@@ -124,6 +125,7 @@ func (c *Config) RegisterFlagsAndApplyDefaults(prefix string, f *flag.FlagSet) {
 	c.Frontend.RegisterFlagsAndApplyDefaults(util.PrefixConfig(prefix, "frontend"), f)
 	c.Compactor.RegisterFlagsAndApplyDefaults(util.PrefixConfig(prefix, "compactor"), f)
 	c.StorageConfig.RegisterFlagsAndApplyDefaults(util.PrefixConfig(prefix, "storage"), f)
+	c.OverridesAPI.RegisterFlagsAndApplyDefaults(util.PrefixConfig(prefix, "overrides-api"), f)
 
 }
 
@@ -278,6 +280,7 @@ func (t *App) Run() error {
 	t.Server.HTTP.Path("/ready").Handler(t.readyHandler(sm))
 	t.Server.HTTP.Path("/status").Handler(t.statusHandler()).Methods("GET")
 	t.Server.HTTP.Path("/status/{endpoint}").Handler(t.statusHandler()).Methods("GET")
+	t.Server.HTTP.Path("/status/overrides/{tenant}").Handler(t.statusHandler()).Methods("GET")
 	grpc_health_v1.RegisterHealthServer(t.Server.GRPC, grpcutil.NewHealthCheck(sm))
 
 	// Let's listen for events from this manager, and log them.
@@ -432,6 +435,14 @@ func (t *App) writeRuntimeConfig(w io.Writer, r *http.Request) error {
 	return t.overrides.WriteStatusRuntimeConfig(w, r)
 }
 
+func (t *App) writeStatusOverrides(w io.Writer, r *http.Request) error {
+	if t.overrides == nil {
+		_, err := w.Write([]byte(fmt.Sprintf("overrides module not loaded in %s\n", t.cfg.Target)))
+		return err
+	}
+	return t.overrides.WriteStatusOverrides(w, r, mux.Vars(r)["tenant"])
+}
+
 func (t *App) statusHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var errs []error
@@ -457,6 +468,11 @@ func (t *App) statusHandler() http.HandlerFunc {
 				if err != nil {
 					errs = append(errs, err)
 				}
+			case "overrides":
+				err := t.writeStatusOverrides(&msg, r)
+				if err != nil {
+					errs = append(errs, err)
+				}
 			default:
 				err := simpleEndpoints[endpoint](&msg)
 				if err != nil {
@@ -467,7 +483,9 @@ func (t *App) statusHandler() http.HandlerFunc {
 
 		vars := mux.Vars(r)
 
-		if endpoint, ok := vars["endpoint"]; ok {
+		if _, ok := vars["tenant"]; ok {
+			wrapStatus("overrides")
+		} else if endpoint, ok := vars["endpoint"]; ok {
 			wrapStatus(endpoint)
 		} else {
 			wrapStatus("version")
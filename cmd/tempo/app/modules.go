@@ -102,7 +102,11 @@ func (t *App) initGeneratorRing() (services.Service, error) {
 }
 
 func (t *App) initOverrides() (services.Service, error) {
-	overrides, err := overrides.NewOverrides(t.cfg.LimitsConfig)
+	// the overrides API is only served by the query-frontend, all other targets load the overrides it shares
+	serveOverridesAPI := t.cfg.OverridesAPI.Enabled && (t.cfg.Target == QueryFrontend || t.cfg.Target == SingleBinary || t.cfg.Target == ScalableSingleBinary)
+	t.cfg.OverridesAPI.Serve = serveOverridesAPI
+
+	overrides, err := overrides.NewOverridesWithAPI(t.cfg.LimitsConfig, t.cfg.OverridesAPI)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create overrides %w", err)
 	}
@@ -110,10 +114,16 @@ func (t *App) initOverrides() (services.Service, error) {
 
 	prometheus.MustRegister(&t.cfg.LimitsConfig)
 
-	if t.cfg.LimitsConfig.PerTenantOverrideConfig != "" {
+	if t.cfg.LimitsConfig.PerTenantOverrideConfig != "" || t.cfg.OverridesAPI.Enabled {
 		prometheus.MustRegister(t.overrides)
 	}
 
+	if serveOverridesAPI {
+		t.Server.HTTP.Path(addHTTPAPIPrefix(&t.cfg, api.PathOverrides)).
+			Handler(http.HandlerFunc(t.overrides.APIHandler)).
+			Methods(http.MethodGet, http.MethodPut, http.MethodPatch, http.MethodDelete)
+	}
+
 	return t.overrides, nil
 }
 
@@ -134,7 +144,7 @@ func (t *App) initDistributor() (services.Service, error) {
 
 func (t *App) initIngester() (services.Service, error) {
 	t.cfg.Ingester.LifecyclerConfig.ListenPort = t.cfg.Server.GRPCListenPort
-	ingester, err := ingester.New(t.cfg.Ingester, t.store, t.overrides, prometheus.DefaultRegisterer)
+	ingester, err := ingester.New(t.cfg.Ingester, t.cfg.IngesterClient, t.ring, t.store, t.overrides, prometheus.DefaultRegisterer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ingester: %w", err)
 	}
@@ -142,8 +152,11 @@ func (t *App) initIngester() (services.Service, error) {
 
 	tempopb.RegisterPusherServer(t.Server.GRPC, t.ingester)
 	tempopb.RegisterQuerierServer(t.Server.GRPC, t.ingester)
+	tempopb.RegisterIngesterServer(t.Server.GRPC, t.ingester)
 	t.Server.HTTP.Path("/flush").Handler(http.HandlerFunc(t.ingester.FlushHandler))
 	t.Server.HTTP.Path("/shutdown").Handler(http.HandlerFunc(t.ingester.ShutdownHandler))
+	t.Server.HTTP.Path(api.PathIngesterTenantStatus).Methods(http.MethodGet).Handler(http.HandlerFunc(t.ingester.TenantStatusHandler))
+	t.Server.HTTP.Path(api.PathIngesterTenantTrace).Methods(http.MethodGet).Handler(http.HandlerFunc(t.ingester.TenantTraceHandler))
 	return t.ingester, nil
 }
 
@@ -208,7 +221,7 @@ func (t *App) initQuerier() (services.Service, error) {
 func (t *App) initQueryFrontend() (services.Service, error) {
 	// cortexTripper is a bridge between http and httpgrpc. it does the job of passing data to the cortex
 	// frontend code
-	cortexTripper, v1, _, err := frontend.InitFrontend(t.cfg.Frontend.Config, frontend.CortexNoQuerierLimits{}, 0, log.Logger, prometheus.DefaultRegisterer)
+	cortexTripper, v1, _, err := frontend.InitFrontend(t.cfg.Frontend.Config, t.overrides, 0, log.Logger, prometheus.DefaultRegisterer)
 	if err != nil {
 		return nil, err
 	}
@@ -266,7 +279,13 @@ func (t *App) initCompactor() (services.Service, error) {
 }
 
 func (t *App) initStore() (services.Service, error) {
-	store, err := tempo_storage.NewStore(t.cfg.StorageConfig, log.Logger)
+	var store tempo_storage.Store
+	var err error
+	if t.cfg.StorageConfig.Trace.BlocklistNotifications {
+		store, err = tempo_storage.NewStoreWithBlocklistNotifications(t.cfg.StorageConfig, t.MemberlistKV.GetMemberlistKV, log.Logger)
+	} else {
+		store, err = tempo_storage.NewStore(t.cfg.StorageConfig, log.Logger)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create store %w", err)
 	}
@@ -281,6 +300,7 @@ func (t *App) initMemberlistKV() (services.Service, error) {
 	t.cfg.MemberlistKV.MetricsNamespace = metricsNamespace
 	t.cfg.MemberlistKV.Codecs = []codec.Codec{
 		ring.GetCodec(),
+		tempo_storage.BlocklistNotificationsCodec,
 	}
 
 	dnsProviderReg := prometheus.WrapRegistererWithPrefix(
@@ -331,7 +351,7 @@ func (t *App) setupModuleManager() error {
 		Ring:                 {Server, MemberlistKV},
 		MetricsGeneratorRing: {Server, MemberlistKV},
 		Distributor:          {Ring, Server, Overrides},
-		Ingester:             {Store, Server, Overrides, MemberlistKV},
+		Ingester:             {Store, Server, Overrides, MemberlistKV, Ring},
 		MetricsGenerator:     {Server, Overrides, MemberlistKV},
 		Querier:              {Store, Ring, Overrides},
 		Compactor:            {Store, Server, Overrides, MemberlistKV},
@@ -339,6 +359,11 @@ func (t *App) setupModuleManager() error {
 		ScalableSingleBinary: {SingleBinary},
 	}
 
+	if t.cfg.StorageConfig.Trace.BlocklistNotifications {
+		// blocklist notifications are gossiped over memberlist
+		deps[Store] = append(deps[Store], MemberlistKV)
+	}
+
 	if t.cfg.MetricsGeneratorEnabled {
 		// If metrics-generator is enabled, the distributor needs the metrics-generator ring
 		deps[Distributor] = append(deps[Distributor], MetricsGeneratorRing)
@@ -131,8 +131,13 @@ type Distributor struct {
 	generatorsPool          *ring_client.Pool
 	generatorForwarder      *forwarder
 
-	// Per-user rate limiter.
-	ingestionRateLimiter *limiter.RateLimiter
+	// spans per trace across push requests
+	traceSpanCounter *traceSpanCounter
+
+	// Per-user rate limiters.
+	ingestionRateLimiter *rateLimiter
+	spansRateLimiter     *rateLimiter
+	serviceRateLimiter   *rateLimiter
 
 	// Manager for subservices
 	subservices        *services.Manager
@@ -150,8 +155,8 @@ func New(cfg Config, clientCfg ingester_client.Config, ingestersRing ring.ReadRi
 
 	subservices := []services.Service(nil)
 
-	// Create the configured ingestion rate limit strategy (local or global).
-	var ingestionRateStrategy limiter.RateLimiterStrategy
+	// Create the configured ingestion rate limit strategies (local or global).
+	var ingestionRateStrategy, spansRateStrategy, serviceRateStrategy limiter.RateLimiterStrategy
 	var distributorRing *ring.Ring
 
 	if o.IngestionRateStrategy() == overrides.GlobalIngestionRateStrategy {
@@ -162,6 +167,8 @@ func New(cfg Config, clientCfg ingester_client.Config, ingestersRing ring.ReadRi
 		}
 		subservices = append(subservices, lifecycler)
 		ingestionRateStrategy = newGlobalIngestionRateStrategy(o, lifecycler)
+		spansRateStrategy = newGlobalSpansRateStrategy(o, lifecycler)
+		serviceRateStrategy = newGlobalServiceRateStrategy(o, lifecycler)
 
 		ring, err := ring.New(lifecyclerCfg.RingConfig, "distributor", cfg.OverrideRingKey, log.Logger, prometheus.WrapRegistererWithPrefix("cortex_", reg))
 		if err != nil {
@@ -171,6 +178,8 @@ func New(cfg Config, clientCfg ingester_client.Config, ingestersRing ring.ReadRi
 		subservices = append(subservices, distributorRing)
 	} else {
 		ingestionRateStrategy = newLocalIngestionRateStrategy(o)
+		spansRateStrategy = newLocalSpansRateStrategy(o)
+		serviceRateStrategy = newLocalServiceRateStrategy(o)
 	}
 
 	pool := ring_client.NewPool("distributor_pool",
@@ -194,7 +203,9 @@ func New(cfg Config, clientCfg ingester_client.Config, ingestersRing ring.ReadRi
 		ingestersRing:           ingestersRing,
 		pool:                    pool,
 		DistributorRing:         distributorRing,
-		ingestionRateLimiter:    limiter.NewRateLimiter(ingestionRateStrategy, 10*time.Second),
+		ingestionRateLimiter:    newRateLimiter(ingestionRateStrategy, 10*time.Second, maxTenantRateLimiters),
+		spansRateLimiter:        newRateLimiter(spansRateStrategy, 10*time.Second, maxTenantRateLimiters),
+		serviceRateLimiter:      newRateLimiter(serviceRateStrategy, 10*time.Second, maxServiceRateLimiters),
 		searchEnabled:           searchEnabled,
 		metricsGeneratorEnabled: metricsGeneratorEnabled,
 		generatorClientCfg:      generatorClientCfg,
@@ -202,6 +213,7 @@ func New(cfg Config, clientCfg ingester_client.Config, ingestersRing ring.ReadRi
 		globalTagsToDrop:        tagsToDrop,
 		overrides:               o,
 		traceEncoder:            model.MustNewSegmentDecoder(model.CurrentEncoding),
+		traceSpanCounter:        newTraceSpanCounter(traceSpanCounterSize),
 	}
 
 	if metricsGeneratorEnabled {
@@ -299,14 +311,33 @@ func (d *Distributor) PushBatches(ctx context.Context, batches []*v1.ResourceSpa
 	metricSpansIngested.WithLabelValues(userID).Add(float64(spanCount))
 
 	// check limits
-	now := time.Now()
-	if !d.ingestionRateLimiter.AllowN(now, userID, size) {
+	if err := d.checkIngestionRateLimits(time.Now(), userID, batches, size, spanCount); err != nil {
 		overrides.RecordDiscardedSpans(spanCount, reasonRateLimited, userID)
-		return nil, status.Errorf(codes.ResourceExhausted,
-			"%s ingestion rate limit (%d bytes) exceeded while adding %d bytes",
-			overrides.ErrorPrefixRateLimited,
-			int(d.ingestionRateLimiter.Limit(now, userID)),
-			size)
+		return nil, err
+	}
+
+	// apply the tenant's span rules
+	if dropped := applySpanRules(batches, d.overrides.IngestionSpanRules(userID), userID); dropped > 0 {
+		overrides.RecordDiscardedSpans(dropped, reasonSpanFiltered, userID)
+		spanCount -= dropped
+		if spanCount == 0 {
+			return &tempopb.PushResponse{}, nil
+		}
+	}
+
+	// truncate spans to the tenant's limits
+	limits := spanLimits{
+		maxAttributeValueLength: d.overrides.MaxAttributeValueLength(userID),
+		maxAttributesPerSpan:    d.overrides.MaxAttributesPerSpan(userID),
+		maxEventsPerSpan:        d.overrides.MaxEventsPerSpan(userID),
+		maxSpansPerTrace:        d.overrides.MaxSpansPerTrace(userID),
+	}
+	if dropped := applySpanLimits(batches, limits, userID, d.traceSpanCounter); dropped > 0 {
+		overrides.RecordDiscardedSpans(dropped, reasonTooManySpans, userID)
+		spanCount -= dropped
+		if spanCount == 0 {
+			return &tempopb.PushResponse{}, nil
+		}
 	}
 
 	keys, rebatchedTraces, err := requestsByTraceID(batches, userID, spanCount)
@@ -659,6 +659,25 @@ func TestDistributor(t *testing.T) {
 	}
 }
 
+func TestDistributorPushOverSpanLimit(t *testing.T) {
+	limits := &overrides.Limits{}
+	flagext.DefaultValues(limits)
+	limits.MaxSpansPerTrace = 5
+
+	d := prepare(t, limits, nil)
+
+	// the first push fills the trace up to its limit, the second only has spans over the limit
+	traceID := test.ValidTraceID(nil)
+	_, err := d.PushBatches(ctx, []*v1.ResourceSpans{test.MakeBatch(5, traceID)})
+	require.NoError(t, err)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	response, err := d.PushBatches(timeoutCtx, []*v1.ResourceSpans{test.MakeBatch(3, traceID)})
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(&tempopb.PushResponse{}, response))
+}
+
 func prepare(t *testing.T, limits *overrides.Limits, kvStore kv.Client) *Distributor {
 	var (
 		distributorConfig Config
@@ -1,8 +1,26 @@
 package distributor
 
 import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gogo/status"
 	"github.com/grafana/dskit/limiter"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+
 	"github.com/grafana/tempo/modules/overrides"
+	"github.com/grafana/tempo/pkg/model/trace"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+)
+
+const (
+	// maxTenantRateLimiters is the number of tenants the distributor keeps rate limiters for
+	maxTenantRateLimiters = 100_000
+	// maxServiceRateLimiters is the number of services of all tenants the distributor keeps rate limiters
+	// for. The service names are sent by clients, the least recently used limiters are evicted.
+	maxServiceRateLimiters = 10_000
 )
 
 // ReadLifecycler represents the read interface to the lifecycler.
@@ -11,47 +29,181 @@ type ReadLifecycler interface {
 }
 
 type localStrategy struct {
-	limits *overrides.Overrides
+	limit func(key string) float64
+	burst func(key string) int
 }
 
 func newLocalIngestionRateStrategy(limits *overrides.Overrides) limiter.RateLimiterStrategy {
 	return &localStrategy{
-		limits: limits,
+		limit: limits.IngestionRateLimitBytes,
+		burst: limits.IngestionBurstSizeBytes,
+	}
+}
+
+func newLocalSpansRateStrategy(limits *overrides.Overrides) limiter.RateLimiterStrategy {
+	return &localStrategy{
+		limit: limits.IngestionRateLimitSpans,
+		burst: limits.IngestionBurstSizeSpans,
+	}
+}
+
+func newLocalServiceRateStrategy(limits *overrides.Overrides) limiter.RateLimiterStrategy {
+	return &localStrategy{
+		limit: serviceRateLimit(limits),
+		burst: serviceBurstSize(limits),
 	}
 }
 
-func (s *localStrategy) Limit(userID string) float64 {
-	return s.limits.IngestionRateLimitBytes(userID)
+func (s *localStrategy) Limit(key string) float64 {
+	return s.limit(key)
 }
 
-func (s *localStrategy) Burst(userID string) int {
-	return s.limits.IngestionBurstSizeBytes(userID)
+func (s *localStrategy) Burst(key string) int {
+	return s.burst(key)
 }
 
 type globalStrategy struct {
-	limits *overrides.Overrides
-	ring   ReadLifecycler
+	limit func(key string) float64
+	burst func(key string) int
+	ring  ReadLifecycler
 }
 
 func newGlobalIngestionRateStrategy(limits *overrides.Overrides, ring ReadLifecycler) limiter.RateLimiterStrategy {
 	return &globalStrategy{
-		limits: limits,
-		ring:   ring,
+		limit: limits.IngestionRateLimitBytes,
+		burst: limits.IngestionBurstSizeBytes,
+		ring:  ring,
 	}
 }
 
-func (s *globalStrategy) Limit(userID string) float64 {
+func newGlobalSpansRateStrategy(limits *overrides.Overrides, ring ReadLifecycler) limiter.RateLimiterStrategy {
+	return &globalStrategy{
+		limit: limits.IngestionRateLimitSpans,
+		burst: limits.IngestionBurstSizeSpans,
+		ring:  ring,
+	}
+}
+
+func newGlobalServiceRateStrategy(limits *overrides.Overrides, ring ReadLifecycler) limiter.RateLimiterStrategy {
+	return &globalStrategy{
+		limit: serviceRateLimit(limits),
+		burst: serviceBurstSize(limits),
+		ring:  ring,
+	}
+}
+
+func (s *globalStrategy) Limit(key string) float64 {
 	numDistributors := s.ring.HealthyInstancesCount()
 
 	if numDistributors == 0 {
-		return s.limits.IngestionRateLimitBytes(userID)
+		return s.limit(key)
 	}
 
-	return s.limits.IngestionRateLimitBytes(userID) / float64(numDistributors)
+	return s.limit(key) / float64(numDistributors)
 }
 
-func (s *globalStrategy) Burst(userID string) int {
+func (s *globalStrategy) Burst(key string) int {
 	// The meaning of burst doesn't change for the global strategy, in order
 	// to keep it easier to understand for users / operators.
-	return s.limits.IngestionBurstSizeBytes(userID)
+	return s.burst(key)
+}
+
+// serviceKey is the key of the per service rate limiter. Tenant IDs can't contain a slash, so the
+// tenant can always be recovered from the key.
+func serviceKey(userID, service string) string {
+	return userID + "/" + service
+}
+
+func tenantFromServiceKey(key string) string {
+	if i := strings.IndexByte(key, '/'); i >= 0 {
+		return key[:i]
+	}
+	return key
+}
+
+func serviceRateLimit(limits *overrides.Overrides) func(string) float64 {
+	return func(key string) float64 {
+		return limits.IngestionServiceRateLimitBytes(tenantFromServiceKey(key))
+	}
+}
+
+func serviceBurstSize(limits *overrides.Overrides) func(string) int {
+	return func(key string) int {
+		return limits.IngestionServiceBurstSizeBytes(tenantFromServiceKey(key))
+	}
+}
+
+// checkIngestionRateLimits consumes the size and span count of the batches from the rate limiters of
+// the tenant and returns a RATE_LIMITED error naming the quota that was exceeded. Tokens are only consumed
+// if all limiters allow the batches, a rejected push doesn't use up any quota.
+func (d *Distributor) checkIngestionRateLimits(now time.Time, userID string, batches []*v1.ResourceSpans, size, spanCount int) error {
+	var reservations []*rate.Reservation
+	reserve := func(l *rateLimiter, key string, n int) bool {
+		r := l.ReserveN(now, key, n)
+		if !allowed(r, now) {
+			r.CancelAt(now)
+			for _, r := range reservations {
+				r.CancelAt(now)
+			}
+			return false
+		}
+		reservations = append(reservations, r)
+		return true
+	}
+
+	if d.overrides.IngestionServiceRateLimitBytes(userID) > 0 {
+		sizeByService := make(map[string]int)
+		for _, b := range batches {
+			sizeByService[serviceName(b)] += b.Size()
+		}
+
+		services := make([]string, 0, len(sizeByService))
+		for service := range sizeByService {
+			services = append(services, service)
+		}
+		sort.Strings(services)
+
+		for _, service := range services {
+			key := serviceKey(userID, service)
+			if !reserve(d.serviceRateLimiter, key, sizeByService[service]) {
+				return status.Errorf(codes.ResourceExhausted,
+					"%s ingestion rate limit (%d bytes) of service %q exceeded while adding %d bytes",
+					overrides.ErrorPrefixRateLimited,
+					int(d.serviceRateLimiter.Limit(now, key)),
+					service,
+					sizeByService[service])
+			}
+		}
+	}
+
+	if !reserve(d.ingestionRateLimiter, userID, size) {
+		return status.Errorf(codes.ResourceExhausted,
+			"%s ingestion rate limit (%d bytes) exceeded while adding %d bytes",
+			overrides.ErrorPrefixRateLimited,
+			int(d.ingestionRateLimiter.Limit(now, userID)),
+			size)
+	}
+
+	if d.overrides.IngestionRateLimitSpans(userID) > 0 && !reserve(d.spansRateLimiter, userID, spanCount) {
+		return status.Errorf(codes.ResourceExhausted,
+			"%s ingestion rate limit (%d spans) exceeded while adding %d spans",
+			overrides.ErrorPrefixRateLimited,
+			int(d.spansRateLimiter.Limit(now, userID)),
+			spanCount)
+	}
+
+	return nil
+}
+
+// serviceName returns the value of the service.name resource attribute of the batch.
+func serviceName(b *v1.ResourceSpans) string {
+	if b.Resource == nil {
+		return ""
+	}
+	for _, a := range b.Resource.Attributes {
+		if a.Key == trace.ServiceNameTag {
+			return a.Value.GetStringValue()
+		}
+	}
+	return ""
 }
@@ -2,13 +2,18 @@ package distributor
 
 import (
 	"testing"
+	"time"
 
+	"github.com/grafana/dskit/flagext"
 	"github.com/grafana/dskit/limiter"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/tempo/modules/overrides"
+	v1_common "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	v1_resource "github.com/grafana/tempo/pkg/tempopb/resource/v1"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
 )
 
 func TestIngestionRateStrategy(t *testing.T) {
@@ -70,6 +75,136 @@ func TestIngestionRateStrategy(t *testing.T) {
 	}
 }
 
+func TestSpansAndServiceRateStrategy(t *testing.T) {
+	o, err := overrides.NewOverrides(overrides.Limits{
+		IngestionRateLimitSpans:        10,
+		IngestionServiceRateLimitBytes: 100,
+		IngestionServiceBurstSizeBytes: 200,
+	})
+	require.NoError(t, err)
+
+	ring := newReadLifecyclerMock()
+	ring.On("HealthyInstancesCount").Return(2)
+
+	// the spans burst defaults to the rate limit
+	strategy := newLocalSpansRateStrategy(o)
+	assert.Equal(t, 10.0, strategy.Limit("test"))
+	assert.Equal(t, 10, strategy.Burst("test"))
+
+	strategy = newGlobalSpansRateStrategy(o, ring)
+	assert.Equal(t, 5.0, strategy.Limit("test"))
+	assert.Equal(t, 10, strategy.Burst("test"))
+
+	// service limits are looked up by the tenant of the key
+	strategy = newLocalServiceRateStrategy(o)
+	assert.Equal(t, 100.0, strategy.Limit(serviceKey("test", "my-service")))
+	assert.Equal(t, 200, strategy.Burst(serviceKey("test", "my-service")))
+
+	strategy = newGlobalServiceRateStrategy(o, ring)
+	assert.Equal(t, 50.0, strategy.Limit(serviceKey("test", "my/service")))
+	assert.Equal(t, 200, strategy.Burst(serviceKey("test", "my/service")))
+}
+
+func TestCheckIngestionRateLimits(t *testing.T) {
+	batch := func(service string, spans int) *v1.ResourceSpans {
+		b := &v1.ResourceSpans{
+			Resource: &v1_resource.Resource{
+				Attributes: []*v1_common.KeyValue{
+					{Key: "service.name", Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: service}}},
+				},
+			},
+			InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{{}},
+		}
+		for i := 0; i < spans; i++ {
+			b.InstrumentationLibrarySpans[0].Spans = append(b.InstrumentationLibrarySpans[0].Spans, &v1.Span{Name: "span"})
+		}
+		return b
+	}
+
+	now := time.Now()
+
+	t.Run("spans", func(t *testing.T) {
+		limits := &overrides.Limits{}
+		flagext.DefaultValues(limits)
+		limits.IngestionRateLimitSpans = 10
+		d := prepare(t, limits, nil)
+
+		batches := []*v1.ResourceSpans{batch("a", 6)}
+		require.NoError(t, d.checkIngestionRateLimits(now, "test", batches, batches[0].Size(), 6))
+
+		err := d.checkIngestionRateLimits(now, "test", batches, batches[0].Size(), 6)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), overrides.ErrorPrefixRateLimited)
+		assert.Contains(t, err.Error(), "(10 spans)")
+	})
+
+	t.Run("service", func(t *testing.T) {
+		noisy := batch("noisy", 10)
+		quiet := batch("quiet", 1)
+
+		limits := &overrides.Limits{}
+		flagext.DefaultValues(limits)
+		limits.IngestionServiceRateLimitBytes = noisy.Size() + 1
+		d := prepare(t, limits, nil)
+
+		require.NoError(t, d.checkIngestionRateLimits(now, "test", []*v1.ResourceSpans{noisy}, noisy.Size(), 10))
+
+		err := d.checkIngestionRateLimits(now, "test", []*v1.ResourceSpans{noisy}, noisy.Size(), 10)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), overrides.ErrorPrefixRateLimited)
+		assert.Contains(t, err.Error(), `service "noisy"`)
+
+		// other services of the tenant still have their own quota
+		require.NoError(t, d.checkIngestionRateLimits(now, "test", []*v1.ResourceSpans{quiet}, quiet.Size(), 1))
+	})
+
+	t.Run("rejected pushes consume no quota", func(t *testing.T) {
+		b := batch("a", 6)
+
+		limits := &overrides.Limits{}
+		flagext.DefaultValues(limits)
+		limits.IngestionServiceRateLimitBytes = 2*b.Size() + 1
+		limits.IngestionRateLimitSpans = 10
+		d := prepare(t, limits, nil)
+
+		require.NoError(t, d.checkIngestionRateLimits(now, "test", []*v1.ResourceSpans{b}, b.Size(), 6))
+
+		// the spans limit rejects the push after the service and bytes limits allowed it
+		err := d.checkIngestionRateLimits(now, "test", []*v1.ResourceSpans{b}, b.Size(), 6)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "(10 spans)")
+
+		// the service still has the quota of the rejected push left
+		require.NoError(t, d.checkIngestionRateLimits(now, "test", []*v1.ResourceSpans{b}, b.Size(), 4))
+	})
+}
+
+func TestRateLimiterMaxKeys(t *testing.T) {
+	o, err := overrides.NewOverrides(overrides.Limits{
+		IngestionServiceRateLimitBytes: 10,
+		IngestionServiceBurstSizeBytes: 10,
+	})
+	require.NoError(t, err)
+
+	l := newRateLimiter(newLocalServiceRateStrategy(o), time.Minute, 2)
+	now := time.Now()
+
+	for _, service := range []string{"a", "b", "c"} {
+		r := l.ReserveN(now, serviceKey("test", service), 10)
+		assert.True(t, allowed(r, now))
+	}
+	assert.Equal(t, 2, l.limiters.Len())
+
+	// a rejected reservation is canceled and doesn't take tokens
+	r := l.ReserveN(now, serviceKey("test", "c"), 1)
+	assert.False(t, allowed(r, now))
+	r.CancelAt(now)
+	assert.True(t, allowed(l.ReserveN(now.Add(100*time.Millisecond), serviceKey("test", "c"), 1), now.Add(100*time.Millisecond)))
+
+	// the limiter of the least recently used service was evicted and starts with a full burst
+	assert.True(t, allowed(l.ReserveN(now, serviceKey("test", "a"), 10), now))
+}
+
 type readLifecyclerMock struct {
 	mock.Mock
 }
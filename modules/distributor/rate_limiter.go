@@ -0,0 +1,78 @@
+package distributor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/limiter"
+	"github.com/hashicorp/golang-lru/simplelru"
+	"golang.org/x/time/rate"
+)
+
+// rateLimiter is a multi-key rate limiter like limiter.RateLimiter. Instead of consuming tokens right away it
+// reserves them, so a request checked against several limiters can cancel the reservations of the limiters
+// that allowed it if a later one rejects it. At most maxKeys limiters are kept, the least recently used are
+// evicted and start with a full burst when they are used again.
+type rateLimiter struct {
+	strategy      limiter.RateLimiterStrategy
+	recheckPeriod time.Duration
+
+	mtx      sync.Mutex
+	limiters *simplelru.LRU // of *keyLimiter
+}
+
+type keyLimiter struct {
+	limiter   *rate.Limiter
+	recheckAt time.Time
+}
+
+func newRateLimiter(strategy limiter.RateLimiterStrategy, recheckPeriod time.Duration, maxKeys int) *rateLimiter {
+	limiters, _ := simplelru.NewLRU(maxKeys, nil) // only errors on a size <= 0
+	return &rateLimiter{
+		strategy:      strategy,
+		recheckPeriod: recheckPeriod,
+		limiters:      limiters,
+	}
+}
+
+// ReserveN reserves n tokens of the key at time now. The reservation is only allowed if it is OK and doesn't
+// have to wait, see allowed. Reservations that are not used must be canceled.
+func (l *rateLimiter) ReserveN(now time.Time, key string, n int) *rate.Reservation {
+	return l.keyLimiter(now, key).ReserveN(now, n)
+}
+
+// Limit returns the currently configured rate of the key.
+func (l *rateLimiter) Limit(now time.Time, key string) float64 {
+	return float64(l.keyLimiter(now, key).Limit())
+}
+
+func (l *rateLimiter) keyLimiter(now time.Time, key string) *rate.Limiter {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if v, ok := l.limiters.Get(key); ok {
+		entry := v.(*keyLimiter)
+		if !now.Before(entry.recheckAt) {
+			if limit := rate.Limit(l.strategy.Limit(key)); entry.limiter.Limit() != limit {
+				entry.limiter.SetLimitAt(now, limit)
+			}
+			if burst := l.strategy.Burst(key); entry.limiter.Burst() != burst {
+				entry.limiter.SetBurstAt(now, burst)
+			}
+			entry.recheckAt = now.Add(l.recheckPeriod)
+		}
+		return entry.limiter
+	}
+
+	entry := &keyLimiter{
+		limiter:   rate.NewLimiter(rate.Limit(l.strategy.Limit(key)), l.strategy.Burst(key)),
+		recheckAt: now.Add(l.recheckPeriod),
+	}
+	l.limiters.Add(key, entry)
+	return entry.limiter
+}
+
+// allowed returns true if the tokens of the reservation can be consumed at time now without waiting.
+func allowed(r *rate.Reservation, now time.Time) bool {
+	return r.OK() && r.DelayFrom(now) == 0
+}
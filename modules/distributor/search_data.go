@@ -11,6 +11,11 @@ import (
 
 type extractTagFunc func(tag string) bool
 
+var (
+	intrinsicString = tempofb.TagInfo{Scope: tempofb.TagScopeIntrinsic, Type: tempofb.TagTypeString}
+	intrinsicInt    = tempofb.TagInfo{Scope: tempofb.TagScopeIntrinsic, Type: tempofb.TagTypeInt}
+)
+
 // extractSearchDataAll returns flatbuffer search data for every trace.
 func extractSearchDataAll(traces []*rebatchedTrace, extractTag extractTagFunc) [][]byte {
 	headers := make([][]byte, len(traces))
@@ -38,7 +43,7 @@ func extractSearchData(tr *tempopb.Trace, id []byte, extractTag extractTagFunc)
 					continue
 				}
 				if s, ok := extractValueAsString(a.Value); ok {
-					data.AddTag(a.Key, s)
+					data.AddTagWithInfo(a.Key, s, tempofb.TagInfo{Scope: tempofb.TagScopeResource, Type: extractValueType(a.Value)})
 				}
 			}
 		}
@@ -50,14 +55,14 @@ func extractSearchData(tr *tempopb.Trace, id []byte, extractTag extractTagFunc)
 				if len(s.ParentSpanId) == 0 {
 
 					// Collect root.name
-					data.AddTag(trace.RootSpanNameTag, s.Name)
+					data.AddTagWithInfo(trace.RootSpanNameTag, s.Name, intrinsicString)
 
 					// Collect root.service.name
 					if b.Resource != nil {
 						for _, a := range b.Resource.Attributes {
 							if a.Key == trace.ServiceNameTag {
 								if s, ok := extractValueAsString(a.Value); ok {
-									data.AddTag(trace.RootServiceNameTag, s)
+									data.AddTagWithInfo(trace.RootServiceNameTag, s, intrinsicString)
 								}
 							}
 						}
@@ -65,9 +70,9 @@ func extractSearchData(tr *tempopb.Trace, id []byte, extractTag extractTagFunc)
 				}
 
 				// Collect for any spans
-				data.AddTag(trace.SpanNameTag, s.Name)
+				data.AddTagWithInfo(trace.SpanNameTag, s.Name, intrinsicString)
 				if s.Status != nil {
-					data.AddTag(trace.StatusCodeTag, strconv.Itoa(int(s.Status.Code)))
+					data.AddTagWithInfo(trace.StatusCodeTag, strconv.Itoa(int(s.Status.Code)), intrinsicInt)
 				}
 				data.SetStartTimeUnixNano(s.StartTimeUnixNano)
 				data.SetEndTimeUnixNano(s.EndTimeUnixNano)
@@ -77,7 +82,7 @@ func extractSearchData(tr *tempopb.Trace, id []byte, extractTag extractTagFunc)
 						continue
 					}
 					if s, ok := extractValueAsString(a.Value); ok {
-						data.AddTag(a.Key, s)
+						data.AddTagWithInfo(a.Key, s, tempofb.TagInfo{Scope: tempofb.TagScopeSpan, Type: extractValueType(a.Value)})
 					}
 				}
 			}
@@ -111,3 +116,19 @@ func extractValueAsString(v *common_v1.AnyValue) (s string, ok bool) {
 
 	return "", false
 }
+
+// extractValueType returns the type of the value as recorded in the search data.
+func extractValueType(v *common_v1.AnyValue) tempofb.TagType {
+	switch v.GetValue().(type) {
+	case *common_v1.AnyValue_StringValue:
+		return tempofb.TagTypeString
+	case *common_v1.AnyValue_BoolValue:
+		return tempofb.TagTypeBool
+	case *common_v1.AnyValue_IntValue:
+		return tempofb.TagTypeInt
+	case *common_v1.AnyValue_DoubleValue:
+		return tempofb.TagTypeDouble
+	}
+
+	return 0
+}
@@ -16,6 +16,9 @@ import (
 func TestExtractSearchData(t *testing.T) {
 	traceIDA := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}
 
+	resourceString := tempofb.TagInfo{Scope: tempofb.TagScopeResource, Type: tempofb.TagTypeString}
+	spanInt := tempofb.TagInfo{Scope: tempofb.TagScopeSpan, Type: tempofb.TagTypeInt}
+
 	testCases := []struct {
 		name       string
 		trace      *tempopb.Trace
@@ -53,6 +56,14 @@ func TestExtractSearchData(t *testing.T) {
 									{
 										TraceId: traceIDA,
 										Name:    "firstSpan",
+										Attributes: []*v1_common.KeyValue{
+											{
+												Key: "http.status_code",
+												Value: &v1_common.AnyValue{
+													Value: &v1_common.AnyValue_IntValue{IntValue: 200},
+												},
+											},
+										},
 									},
 								},
 							},
@@ -63,13 +74,14 @@ func TestExtractSearchData(t *testing.T) {
 			id: traceIDA,
 			searchData: &tempofb.SearchEntryMutable{
 				TraceID: traceIDA,
-				Tags: tempofb.NewSearchDataMapWithData(map[string][]string{
-					"foo":                    {"bar"},
-					trace.RootSpanNameTag:    {"firstSpan"},
-					trace.SpanNameTag:        {"firstSpan"},
-					trace.RootServiceNameTag: {"baz"},
-					trace.ServiceNameTag:     {"baz"},
-				}),
+				Tags: tempofb.SearchDataMap{
+					"foo":                    {Info: resourceString, Values: map[string]uint32{"bar": 0}},
+					"http.status_code":       {Info: spanInt, Values: map[string]uint32{"200": 0}},
+					trace.RootSpanNameTag:    {Info: intrinsicString, Values: map[string]uint32{"firstSpan": 0}},
+					trace.SpanNameTag:        {Info: intrinsicString, Values: map[string]uint32{"firstSpan": 0}},
+					trace.RootServiceNameTag: {Info: intrinsicString, Values: map[string]uint32{"baz": 0}},
+					trace.ServiceNameTag:     {Info: resourceString, Values: map[string]uint32{"baz": 0}},
+				},
 				StartTimeUnixNano: 0,
 				EndTimeUnixNano:   0,
 			},
@@ -104,9 +116,9 @@ func TestExtractSearchData(t *testing.T) {
 			id: traceIDA,
 			searchData: &tempofb.SearchEntryMutable{
 				TraceID: traceIDA,
-				Tags: tempofb.NewSearchDataMapWithData(map[string][]string{
-					"bar": {"baz"},
-				}),
+				Tags: tempofb.SearchDataMap{
+					"bar": {Info: resourceString, Values: map[string]uint32{"baz": 0}},
+				},
 				StartTimeUnixNano: 0,
 				EndTimeUnixNano:   0,
 			},
@@ -0,0 +1,213 @@
+package distributor
+
+import (
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+
+	"github.com/grafana/tempo/modules/overrides"
+	common_v1 "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+)
+
+const (
+	// reasonTooManySpans indicates that the span exceeded the max spans per trace of the tenant
+	reasonTooManySpans = "trace_too_many_spans"
+	// reasonAttributeValueTooLong indicates that a span, event or resource attribute value was truncated
+	reasonAttributeValueTooLong = "attribute_value_too_long"
+	// reasonTooManyAttributes indicates that attributes of the span were dropped
+	reasonTooManyAttributes = "too_many_attributes"
+	// reasonTooManyEvents indicates that events of the span were dropped
+	reasonTooManyEvents = "too_many_events"
+
+	// truncatedAttribute is added to every span that was truncated by the limits of the tenant
+	truncatedAttribute = "tempo.truncated"
+
+	// traceSpanCounterSize is the number of traces the spans of which are counted across push requests
+	traceSpanCounterSize = 100_000
+	// traceSpanCounterIdle is the duration after which the span count of a trace that received no spans restarts
+	traceSpanCounterIdle = 5 * time.Minute
+)
+
+// spanLimits are the per tenant limits on the size of spans. A limit of 0 disables it.
+type spanLimits struct {
+	maxAttributeValueLength int
+	maxAttributesPerSpan    int
+	maxEventsPerSpan        int
+	maxSpansPerTrace        int
+}
+
+func (l spanLimits) enabled() bool {
+	return l.maxAttributeValueLength > 0 || l.maxAttributesPerSpan > 0 || l.maxEventsPerSpan > 0 || l.maxSpansPerTrace > 0
+}
+
+// traceSpanCounter counts the spans of traces across push requests, so the max spans per trace applies to all
+// spans of a trace this distributor receives instead of the spans of a single request. The count of a trace
+// restarts once it received no spans for traceSpanCounterIdle. The least recently updated traces are evicted
+// when more than traceSpanCounterSize traces are counted.
+type traceSpanCounter struct {
+	mtx    sync.Mutex
+	traces *simplelru.LRU
+}
+
+type traceSpanCount struct {
+	spans    int
+	lastSpan time.Time
+}
+
+func newTraceSpanCounter(size int) *traceSpanCounter {
+	traces, _ := simplelru.NewLRU(size, nil) // only errors on a size <= 0
+	return &traceSpanCounter{
+		traces: traces,
+	}
+}
+
+// allow counts a span of the trace and returns true if the trace has no more than max spans
+func (c *traceSpanCounter) allow(userID string, traceID []byte, max int, now time.Time) bool {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	key := userID + string(traceID)
+	var count *traceSpanCount
+	if v, ok := c.traces.Get(key); ok {
+		count = v.(*traceSpanCount)
+	}
+	if count == nil || now.Sub(count.lastSpan) > traceSpanCounterIdle {
+		count = &traceSpanCount{}
+		c.traces.Add(key, count)
+	}
+
+	count.lastSpan = now
+	if count.spans >= max {
+		return false
+	}
+	count.spans++
+	return true
+}
+
+// applySpanLimits truncates the spans in the batches in place to fit the limits. Instead of rejecting
+// spans that are too large, values, attributes and events over the limits are dropped, the dropped counts
+// of the span are updated and the truncatedAttribute is added. Spans over the max spans per trace, counted
+// by counter across requests, are removed. Returns the number of spans that were removed.
+func applySpanLimits(batches []*v1.ResourceSpans, limits spanLimits, userID string, counter *traceSpanCounter) int {
+	if !limits.enabled() {
+		return 0
+	}
+
+	now := time.Now()
+	dropped := 0
+	truncated := make(map[string]int)
+
+	for _, b := range batches {
+		// the resource is part of every span of the batch, so its spans count as truncated as well
+		resourceTruncated := b.Resource != nil && truncateAttributeValues(b.Resource.Attributes, limits.maxAttributeValueLength)
+
+		for _, ils := range b.InstrumentationLibrarySpans {
+			spans := ils.Spans[:0]
+			for _, s := range ils.Spans {
+				if limits.maxSpansPerTrace > 0 && !counter.allow(userID, s.TraceId, limits.maxSpansPerTrace, now) {
+					dropped++
+					continue
+				}
+
+				if truncateSpan(s, limits, resourceTruncated, truncated) {
+					addTruncatedAttribute(s, limits.maxAttributesPerSpan)
+				}
+				spans = append(spans, s)
+			}
+			ils.Spans = spans
+		}
+	}
+
+	for reason, count := range truncated {
+		overrides.RecordTruncatedSpans(count, reason, userID)
+	}
+
+	return dropped
+}
+
+// truncateSpan applies the limits to a single span and counts the reasons in truncated. Returns true if the
+// span was truncated.
+func truncateSpan(s *v1.Span, limits spanLimits, resourceTruncated bool, truncated map[string]int) bool {
+	spanTruncated := false
+
+	if limits.maxAttributesPerSpan > 0 && len(s.Attributes) > limits.maxAttributesPerSpan {
+		s.DroppedAttributesCount += uint32(len(s.Attributes) - limits.maxAttributesPerSpan)
+		s.Attributes = s.Attributes[:limits.maxAttributesPerSpan]
+		truncated[reasonTooManyAttributes]++
+		spanTruncated = true
+	}
+
+	if limits.maxEventsPerSpan > 0 && len(s.Events) > limits.maxEventsPerSpan {
+		s.DroppedEventsCount += uint32(len(s.Events) - limits.maxEventsPerSpan)
+		s.Events = s.Events[:limits.maxEventsPerSpan]
+		truncated[reasonTooManyEvents]++
+		spanTruncated = true
+	}
+
+	valueTruncated := resourceTruncated
+	if truncateAttributeValues(s.Attributes, limits.maxAttributeValueLength) {
+		valueTruncated = true
+	}
+	for _, e := range s.Events {
+		if truncateAttributeValues(e.Attributes, limits.maxAttributeValueLength) {
+			valueTruncated = true
+		}
+	}
+	if valueTruncated {
+		truncated[reasonAttributeValueTooLong]++
+		spanTruncated = true
+	}
+
+	return spanTruncated
+}
+
+// addTruncatedAttribute adds the truncatedAttribute to the span. The marker counts against the max attributes
+// per span, another attribute is dropped if the span has no room left for it.
+func addTruncatedAttribute(s *v1.Span, maxAttributes int) {
+	if maxAttributes > 0 && len(s.Attributes) >= maxAttributes {
+		drop := len(s.Attributes) - maxAttributes + 1
+		s.DroppedAttributesCount += uint32(drop)
+		s.Attributes = s.Attributes[:len(s.Attributes)-drop]
+	}
+
+	s.Attributes = append(s.Attributes, &common_v1.KeyValue{
+		Key:   truncatedAttribute,
+		Value: &common_v1.AnyValue{Value: &common_v1.AnyValue_BoolValue{BoolValue: true}},
+	})
+}
+
+// truncateAttributeValues truncates all string values longer than maxLength. Values are cut at a rune
+// boundary, so they might be a few bytes shorter than maxLength. Returns true if any value was truncated.
+func truncateAttributeValues(attrs []*common_v1.KeyValue, maxLength int) bool {
+	if maxLength <= 0 {
+		return false
+	}
+
+	truncated := false
+	for _, a := range attrs {
+		if truncateAttributeValue(a, maxLength) {
+			truncated = true
+		}
+	}
+
+	return truncated
+}
+
+// truncateAttributeValue truncates the value of the attribute at a rune boundary if it is a string longer
+// than maxLength. Returns true if the value was truncated.
+func truncateAttributeValue(a *common_v1.KeyValue, maxLength int) bool {
+	s, ok := a.Value.GetValue().(*common_v1.AnyValue_StringValue)
+	if !ok || len(s.StringValue) <= maxLength {
+		return false
+	}
+
+	n := maxLength
+	for n > 0 && !utf8.RuneStart(s.StringValue[n]) {
+		n--
+	}
+	s.StringValue = s.StringValue[:n]
+	return true
+}
@@ -0,0 +1,146 @@
+package distributor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	v1_common "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	v1_resource "github.com/grafana/tempo/pkg/tempopb/resource/v1"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+)
+
+func TestApplySpanLimits(t *testing.T) {
+	truncatedKV := &v1_common.KeyValue{Key: truncatedAttribute, Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_BoolValue{BoolValue: true}}}
+	traceA := []byte{0x01}
+	traceB := []byte{0x02}
+
+	batches := []*v1.ResourceSpans{
+		{
+			Resource: &v1_resource.Resource{
+				Attributes: []*v1_common.KeyValue{stringKV("service.name", "svc")},
+			},
+			InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{
+				{
+					Spans: []*v1.Span{
+						{TraceId: traceA, Name: "ok", Attributes: []*v1_common.KeyValue{stringKV("a", "1")}},
+						{TraceId: traceA, Name: "attrs", Attributes: []*v1_common.KeyValue{stringKV("a", "1"), stringKV("b", "2"), stringKV("c", "3")}},
+						{TraceId: traceA, Name: "dropped"},
+						{TraceId: traceB, Name: "value", Attributes: []*v1_common.KeyValue{stringKV("a", "abé")}},
+						{TraceId: traceB, Name: "events", Events: []*v1.Span_Event{{Name: "1"}, {Name: "2"}, {Name: "3", Attributes: []*v1_common.KeyValue{stringKV("a", "long")}}}},
+					},
+				},
+			},
+		},
+	}
+
+	dropped := applySpanLimits(batches, spanLimits{
+		maxAttributeValueLength: 3,
+		maxAttributesPerSpan:    2,
+		maxEventsPerSpan:        2,
+		maxSpansPerTrace:        2,
+	}, "test", newTraceSpanCounter(10))
+	assert.Equal(t, 1, dropped)
+
+	spans := batches[0].InstrumentationLibrarySpans[0].Spans
+	assert.Len(t, spans, 4)
+
+	assert.Equal(t, "ok", spans[0].Name)
+	assert.Equal(t, []*v1_common.KeyValue{stringKV("a", "1")}, spans[0].Attributes)
+
+	assert.Equal(t, "attrs", spans[1].Name)
+	// the marker counts against the max attributes per span
+	assert.Equal(t, []*v1_common.KeyValue{stringKV("a", "1"), truncatedKV}, spans[1].Attributes)
+	assert.Equal(t, uint32(2), spans[1].DroppedAttributesCount)
+
+	// é is two bytes and is not split
+	assert.Equal(t, "value", spans[2].Name)
+	assert.Equal(t, []*v1_common.KeyValue{stringKV("a", "ab"), truncatedKV}, spans[2].Attributes)
+
+	assert.Equal(t, "events", spans[3].Name)
+	assert.Equal(t, []*v1.Span_Event{{Name: "1"}, {Name: "2"}}, spans[3].Events)
+	assert.Equal(t, uint32(1), spans[3].DroppedEventsCount)
+	assert.Equal(t, []*v1_common.KeyValue{truncatedKV}, spans[3].Attributes)
+}
+
+func TestApplySpanLimitsResource(t *testing.T) {
+	batches := []*v1.ResourceSpans{
+		{
+			Resource: &v1_resource.Resource{
+				Attributes: []*v1_common.KeyValue{stringKV("service.name", "my-service")},
+			},
+			InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{
+				{
+					Spans: []*v1.Span{{Name: "span"}},
+				},
+			},
+		},
+	}
+
+	dropped := applySpanLimits(batches, spanLimits{maxAttributeValueLength: 5}, "test", newTraceSpanCounter(10))
+	assert.Equal(t, 0, dropped)
+	assert.Equal(t, []*v1_common.KeyValue{stringKV("service.name", "my-se")}, batches[0].Resource.Attributes)
+	assert.Len(t, batches[0].InstrumentationLibrarySpans[0].Spans[0].Attributes, 1)
+	assert.Equal(t, truncatedAttribute, batches[0].InstrumentationLibrarySpans[0].Spans[0].Attributes[0].Key)
+}
+
+func TestApplySpanLimitsDisabled(t *testing.T) {
+	batches := []*v1.ResourceSpans{
+		{
+			InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{
+				{
+					Spans: []*v1.Span{{Name: "span", Attributes: []*v1_common.KeyValue{stringKV("a", "long value")}}},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, 0, applySpanLimits(batches, spanLimits{}, "test", newTraceSpanCounter(10)))
+	assert.Equal(t, []*v1_common.KeyValue{stringKV("a", "long value")}, batches[0].InstrumentationLibrarySpans[0].Spans[0].Attributes)
+}
+
+func TestApplySpanLimitsAcrossRequests(t *testing.T) {
+	counter := newTraceSpanCounter(10)
+	limits := spanLimits{maxSpansPerTrace: 3}
+
+	push := func(userID string, traceID []byte, spans int) int {
+		batch := &v1.ResourceSpans{InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{{}}}
+		for j := 0; j < spans; j++ {
+			batch.InstrumentationLibrarySpans[0].Spans = append(batch.InstrumentationLibrarySpans[0].Spans, &v1.Span{TraceId: traceID})
+		}
+		return applySpanLimits([]*v1.ResourceSpans{batch}, limits, userID, counter)
+	}
+
+	assert.Equal(t, 0, push("test", []byte{0x01}, 2))
+	assert.Equal(t, 1, push("test", []byte{0x01}, 2))
+	assert.Equal(t, 2, push("test", []byte{0x01}, 2))
+
+	// other traces and tenants are counted separately
+	assert.Equal(t, 0, push("test", []byte{0x02}, 3))
+	assert.Equal(t, 0, push("test2", []byte{0x01}, 3))
+}
+
+func TestTraceSpanCounter(t *testing.T) {
+	counter := newTraceSpanCounter(1)
+	now := time.Now()
+
+	assert.True(t, counter.allow("test", []byte{0x01}, 1, now))
+	assert.False(t, counter.allow("test", []byte{0x01}, 1, now))
+
+	// the count restarts after the trace was idle
+	assert.True(t, counter.allow("test", []byte{0x01}, 1, now.Add(traceSpanCounterIdle+time.Second)))
+
+	// the least recently updated trace is evicted
+	assert.True(t, counter.allow("test", []byte{0x02}, 1, now))
+	assert.True(t, counter.allow("test", []byte{0x01}, 1, now))
+}
+
+func TestAddTruncatedAttribute(t *testing.T) {
+	s := &v1.Span{Attributes: []*v1_common.KeyValue{stringKV("a", "1"), stringKV("b", "2")}}
+	addTruncatedAttribute(s, 2)
+
+	assert.Len(t, s.Attributes, 2)
+	assert.Equal(t, truncatedAttribute, s.Attributes[1].Key)
+	assert.Equal(t, uint32(1), s.DroppedAttributesCount)
+}
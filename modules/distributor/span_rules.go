@@ -0,0 +1,161 @@
+package distributor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/tempo/modules/overrides"
+	common_v1 "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	resource_v1 "github.com/grafana/tempo/pkg/tempopb/resource/v1"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+	"github.com/grafana/tempo/pkg/util"
+)
+
+// reasonSpanFiltered indicates that the span was dropped by a span rule of the tenant
+const reasonSpanFiltered = "span_filtered"
+
+var metricSpansModified = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tempo",
+	Name:      "distributor_spans_modified_total",
+	Help:      "The total number of span and resource modifications made by span rules per tenant and action",
+}, []string{"tenant", "action"})
+
+// applySpanRules applies the rules in order to every span in the batches. Dropped spans are removed
+// and all other changes are made in place. Returns the number of spans that were dropped.
+func applySpanRules(batches []*v1.ResourceSpans, rules []overrides.SpanRule, userID string) int {
+	if len(rules) == 0 {
+		return 0
+	}
+
+	dropped := 0
+	modified := make(map[string]int)
+
+	for _, b := range batches {
+		if b.Resource != nil {
+			for i := range rules {
+				// rules with a match depend on the span, so they are not applied to the shared resource
+				if rules[i].MatchAttribute != "" {
+					continue
+				}
+				attrs, changed := applySpanRuleToAttributes(&rules[i], b.Resource.Attributes)
+				b.Resource.Attributes = attrs
+				if changed {
+					modified[rules[i].Action]++
+				}
+			}
+		}
+
+		for _, ils := range b.InstrumentationLibrarySpans {
+			spans := ils.Spans[:0]
+			for _, s := range ils.Spans {
+				if applySpanRulesToSpan(rules, b.Resource, s, modified) {
+					spans = append(spans, s)
+				} else {
+					dropped++
+				}
+			}
+			ils.Spans = spans
+		}
+	}
+
+	for action, count := range modified {
+		metricSpansModified.WithLabelValues(userID, action).Add(float64(count))
+	}
+
+	return dropped
+}
+
+// applySpanRulesToSpan applies the rules to a single span. Returns false if the span should be dropped.
+func applySpanRulesToSpan(rules []overrides.SpanRule, resource *resource_v1.Resource, s *v1.Span, modified map[string]int) bool {
+	for i := range rules {
+		r := &rules[i]
+		if !spanRuleMatches(r, resource, s) {
+			continue
+		}
+
+		if r.Action == overrides.SpanRuleDropSpan {
+			return false
+		}
+
+		attrs, changed := applySpanRuleToAttributes(r, s.Attributes)
+		s.Attributes = attrs
+		if changed {
+			modified[r.Action]++
+		}
+	}
+
+	return true
+}
+
+// spanRuleMatches returns true if the span or resource has an attribute that matches the rule.
+func spanRuleMatches(r *overrides.SpanRule, resource *resource_v1.Resource, s *v1.Span) bool {
+	if r.MatchAttribute == "" {
+		return true
+	}
+
+	if attributeMatches(r, s.Attributes) {
+		return true
+	}
+
+	return resource != nil && attributeMatches(r, resource.Attributes)
+}
+
+func attributeMatches(r *overrides.SpanRule, attrs []*common_v1.KeyValue) bool {
+	for _, a := range attrs {
+		if a.Key == r.MatchAttribute && r.MatchValue.MatchString(util.StringifyAnyValue(a.Value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// applySpanRuleToAttributes applies the attribute action of the rule and returns the updated attributes
+// and if anything was changed.
+func applySpanRuleToAttributes(r *overrides.SpanRule, attrs []*common_v1.KeyValue) ([]*common_v1.KeyValue, bool) {
+	changed := false
+
+	switch r.Action {
+	case overrides.SpanRuleDeleteAttribute:
+		kept := attrs[:0]
+		for _, a := range attrs {
+			if a.Key == r.Attribute {
+				changed = true
+				continue
+			}
+			kept = append(kept, a)
+		}
+		attrs = kept
+
+	case overrides.SpanRuleHashAttribute:
+		for _, a := range attrs {
+			if a.Key == r.Attribute {
+				hash := sha256.Sum256([]byte(util.StringifyAnyValue(a.Value)))
+				a.Value = &common_v1.AnyValue{Value: &common_v1.AnyValue_StringValue{StringValue: hex.EncodeToString(hash[:])}}
+				changed = true
+			}
+		}
+
+	case overrides.SpanRuleTruncateAttribute:
+		for _, a := range attrs {
+			if r.Attribute != "" && a.Key != r.Attribute {
+				continue
+			}
+			if truncateAttributeValue(a, r.MaxLength) {
+				changed = true
+			}
+		}
+
+	case overrides.SpanRuleRenameAttribute:
+		for _, a := range attrs {
+			if a.Key == r.Attribute {
+				a.Key = r.NewName
+				changed = true
+			}
+		}
+	}
+
+	return attrs, changed
+}
@@ -0,0 +1,106 @@
+package distributor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/grafana/tempo/modules/overrides"
+	v1_common "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	v1_resource "github.com/grafana/tempo/pkg/tempopb/resource/v1"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+)
+
+func stringKV(k, v string) *v1_common.KeyValue {
+	return &v1_common.KeyValue{Key: k, Value: &v1_common.AnyValue{Value: &v1_common.AnyValue_StringValue{StringValue: v}}}
+}
+
+func TestApplySpanRules(t *testing.T) {
+	var rules []overrides.SpanRule
+	require.NoError(t, yaml.Unmarshal([]byte(`
+- action: drop_span
+  match_attribute: http.target
+  match_value: ^/health$
+- action: delete_attribute
+  match_attribute: db.system
+  attribute: db.password
+- action: hash_attribute
+  attribute: user.email
+- action: rename_attribute
+  attribute: old
+  new_name: new
+- action: truncate_attribute
+  max_length: 3
+`), &rules))
+
+	batches := []*v1.ResourceSpans{
+		{
+			Resource: &v1_resource.Resource{
+				Attributes: []*v1_common.KeyValue{stringKV("service.name", "my-service"), stringKV("old", "a")},
+			},
+			InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{
+				{
+					Spans: []*v1.Span{
+						{Name: "health", Attributes: []*v1_common.KeyValue{stringKV("http.target", "/health")}},
+						{Name: "not-health", Attributes: []*v1_common.KeyValue{stringKV("http.target", "/healthz")}},
+						{Name: "db", Attributes: []*v1_common.KeyValue{stringKV("db.system", "sql"), stringKV("db.password", "secret")}},
+						{Name: "no-db", Attributes: []*v1_common.KeyValue{stringKV("db.password", "secret")}},
+						{Name: "user", Attributes: []*v1_common.KeyValue{stringKV("user.email", "a@b.c")}},
+					},
+				},
+			},
+		},
+	}
+
+	dropped := applySpanRules(batches, rules, "test")
+	assert.Equal(t, 1, dropped)
+
+	assert.Equal(t, []*v1_common.KeyValue{stringKV("service.name", "my-"), stringKV("new", "a")}, batches[0].Resource.Attributes)
+
+	spans := batches[0].InstrumentationLibrarySpans[0].Spans
+	require.Len(t, spans, 4)
+	assert.Equal(t, "not-health", spans[0].Name)
+	assert.Equal(t, []*v1_common.KeyValue{stringKV("http.target", "/he")}, spans[0].Attributes)
+	assert.Equal(t, []*v1_common.KeyValue{stringKV("db.system", "sql")}, spans[1].Attributes)
+	assert.Equal(t, []*v1_common.KeyValue{stringKV("db.password", "sec")}, spans[2].Attributes)
+	// sha256 of a@b.c truncated by the last rule
+	assert.Equal(t, []*v1_common.KeyValue{stringKV("user.email", "d64")}, spans[3].Attributes)
+}
+
+func TestApplySpanRulesTruncateRunes(t *testing.T) {
+	var rules []overrides.SpanRule
+	require.NoError(t, yaml.Unmarshal([]byte(`
+- action: truncate_attribute
+  attribute: name
+  max_length: 4
+`), &rules))
+
+	batches := []*v1.ResourceSpans{
+		{
+			InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{
+				{
+					Spans: []*v1.Span{
+						{Name: "span", Attributes: []*v1_common.KeyValue{stringKV("name", "aéé"), stringKV("other", "aéé")}},
+					},
+				},
+			},
+		},
+	}
+
+	applySpanRules(batches, rules, "test")
+
+	// é is 2 bytes, the value is cut before it instead of in the middle of it
+	assert.Equal(t, []*v1_common.KeyValue{stringKV("name", "aé"), stringKV("other", "aéé")}, batches[0].InstrumentationLibrarySpans[0].Spans[0].Attributes)
+}
+
+func TestSpanRulesValidation(t *testing.T) {
+	var rules []overrides.SpanRule
+
+	assert.Error(t, yaml.Unmarshal([]byte(`[{action: unknown}]`), &rules))
+	assert.Error(t, yaml.Unmarshal([]byte(`[{action: drop_span}]`), &rules))
+	assert.Error(t, yaml.Unmarshal([]byte(`[{action: rename_attribute, attribute: a}]`), &rules))
+	assert.Error(t, yaml.Unmarshal([]byte(`[{action: drop_span, match_attribute: a, match_value: "("}]`), &rules))
+	assert.NoError(t, yaml.Unmarshal([]byte(`[{action: drop_span, match_attribute: a}]`), &rules))
+}
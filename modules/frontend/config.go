@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/tempo/modules/frontend/transport"
 	v1 "github.com/grafana/tempo/modules/frontend/v1"
 	v2 "github.com/grafana/tempo/modules/frontend/v2"
+	"github.com/grafana/tempo/pkg/cache"
 	"github.com/grafana/tempo/pkg/util"
 )
 
@@ -22,16 +23,21 @@ type Config struct {
 	QueryShards          int                    `yaml:"query_shards,omitempty"`
 	TolerateFailedBlocks int                    `yaml:"tolerate_failed_blocks,omitempty"`
 	Search               SearchConfig           `yaml:"search"`
+
+	// MultiTenantQueriesEnabled allows trace by id and search requests for multiple tenants, e.g. X-Scope-OrgID: a|b
+	MultiTenantQueriesEnabled bool `yaml:"multi_tenant_queries_enabled"`
 }
 
 type SearchConfig struct {
-	Sharder SearchSharderConfig `yaml:",inline"`
+	Sharder      SearchSharderConfig      `yaml:",inline"`
+	ResultsCache SearchResultsCacheConfig `yaml:"results_cache"`
 }
 
 func (cfg *Config) RegisterFlagsAndApplyDefaults(prefix string, f *flag.FlagSet) {
 	cfg.Config.DownstreamURL = ""
 	cfg.Config.Handler.LogQueriesLongerThan = 0
 	cfg.Config.FrontendV1.MaxOutstandingPerTenant = 100
+	cfg.Config.FrontendV1.InteractiveQueueWeight = 4
 	cfg.MaxRetries = 2
 	cfg.QueryShards = 20
 	cfg.TolerateFailedBlocks = 0
@@ -44,16 +50,17 @@ func (cfg *Config) RegisterFlagsAndApplyDefaults(prefix string, f *flag.FlagSet)
 			MaxDuration:           61 * time.Minute,
 			ConcurrentRequests:    defaultConcurrentRequests,
 			TargetBytesPerRequest: defaultTargetBytesPerRequest,
+			MaxTagsBlockJobs:      defaultMaxTagsBlockJobs,
+		},
+		ResultsCache: SearchResultsCacheConfig{
+			BackgroundCache: &cache.BackgroundConfig{
+				WriteBackBuffer:     10000,
+				WriteBackGoroutines: 10,
+			},
 		},
 	}
 }
 
-type CortexNoQuerierLimits struct{}
-
-var _ v1.Limits = (*CortexNoQuerierLimits)(nil)
-
-func (CortexNoQuerierLimits) MaxQueriersPerUser(user string) int { return 0 }
-
 // This struct combines several configuration options together to preserve backwards compatibility.
 type CombinedFrontendConfig struct {
 	Handler    transport.HandlerConfig `yaml:",inline"`
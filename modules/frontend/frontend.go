@@ -22,6 +22,7 @@ import (
 	"github.com/grafana/tempo/modules/overrides"
 	"github.com/grafana/tempo/modules/storage"
 	"github.com/grafana/tempo/pkg/api"
+	"github.com/grafana/tempo/pkg/cache"
 	"github.com/grafana/tempo/pkg/tempopb"
 	"github.com/grafana/tempo/tempodb"
 )
@@ -64,11 +65,16 @@ func New(cfg Config, next http.RoundTripper, o *overrides.Overrides, store stora
 		Help:      "Total queries received per tenant.",
 	}, []string{"tenant", "op"})
 
+	searchCache, err := newSearchResultsCache(cfg.Search.ResultsCache, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create search results cache: %w", err)
+	}
+
 	retryWare := newRetryWare(cfg.MaxRetries, registerer)
 
 	// tracebyid middleware
 	traceByIDMiddleware := MergeMiddlewares(newTraceByIDMiddleware(cfg, logger), retryWare)
-	searchMiddleware := MergeMiddlewares(newSearchMiddleware(cfg, o, store, logger), retryWare)
+	searchMiddleware := MergeMiddlewares(newSearchMiddleware(cfg, o, store, searchCache, logger), retryWare)
 
 	traceByIDCounter := queriesPerTenant.MustCurryWith(prometheus.Labels{
 		"op": traceByIDOp,
@@ -93,9 +99,13 @@ func newTraceByIDMiddleware(cfg Config, logger log.Logger) Middleware {
 	return MiddlewareFunc(func(next http.RoundTripper) http.RoundTripper {
 		// We're constructing middleware in this statement, each middleware wraps the next one from left-to-right
 		// - the Deduper dedupes Span IDs for Zipkin support
+		// - the MultiTenantWare splits queries for multiple tenants into one query per tenant
 		// - the ShardingWare shards queries by splitting the block ID space
 		// - the RetryWare retries requests that have failed (error or http status 500)
-		rt := NewRoundTripper(next, newDeduper(logger), newTraceByIDSharder(cfg.QueryShards, cfg.TolerateFailedBlocks, logger))
+		rt := NewRoundTripper(next,
+			newDeduper(logger),
+			newMultiTenantMiddleware(cfg, combineMultiTenantTraceByID, logger),
+			newTraceByIDSharder(cfg.QueryShards, cfg.TolerateFailedBlocks, logger))
 
 		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
 			// validate traceID
@@ -175,14 +185,18 @@ func newTraceByIDMiddleware(cfg Config, logger log.Logger) Middleware {
 }
 
 // newSearchMiddleware creates a new frontend middleware to handle search and search tags requests.
-func newSearchMiddleware(cfg Config, o *overrides.Overrides, reader tempodb.Reader, logger log.Logger) Middleware {
+func newSearchMiddleware(cfg Config, o *overrides.Overrides, reader tempodb.Reader, c cache.Cache, logger log.Logger) Middleware {
 	return MiddlewareFunc(func(next http.RoundTripper) http.RoundTripper {
 		ingesterSearchRT := next
-		backendSearchRT := NewRoundTripper(next, newSearchSharder(reader, o, cfg.Search.Sharder, logger))
+		backendSearchRT := NewRoundTripper(next, newSearchSharder(reader, o, cfg.Search.Sharder, c, logger))
+		backendSearchTagsRT := NewRoundTripper(next, newSearchTagsSharder(reader, o, cfg.Search.Sharder, logger))
 
-		return RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		rt := RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
 			// backend search queries require sharding so we pass through a special roundtripper
 			if api.IsBackendSearch(r) {
+				if api.IsSearchTags(r) || api.IsSearchTagValues(r) {
+					return backendSearchTagsRT.RoundTrip(r)
+				}
 				return backendSearchRT.RoundTrip(r)
 			}
 
@@ -194,6 +208,9 @@ func newSearchMiddleware(cfg Config, o *overrides.Overrides, reader tempodb.Read
 
 			return ingesterSearchRT.RoundTrip(r)
 		})
+
+		// queries for multiple tenants are split into one query per tenant before they are sharded
+		return newMultiTenantMiddleware(cfg, newCombineMultiTenantSearch(cfg.Search.Sharder), logger).Wrap(rt)
 	})
 }
 
@@ -0,0 +1,302 @@
+package frontend
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/grafana/dskit/tenant"
+	"github.com/opentracing/opentracing-go"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/tempo/pkg/api"
+	"github.com/grafana/tempo/pkg/model/trace"
+	"github.com/grafana/tempo/pkg/tempofb"
+	"github.com/grafana/tempo/pkg/tempopb"
+	v1common "github.com/grafana/tempo/pkg/tempopb/common/v1"
+	v1resource "github.com/grafana/tempo/pkg/tempopb/resource/v1"
+	"github.com/grafana/tempo/tempodb/search"
+)
+
+// tenantAttribute is the resource attribute added to the batches of multi-tenant trace by id queries
+// to identify the tenant of their spans.
+const tenantAttribute = "tenant"
+
+// tenantResponse is the successful response of one tenant of a multi-tenant query
+type tenantResponse struct {
+	tenantID string
+	body     []byte
+}
+
+// multiTenantCombiner combines the successful responses of all tenants into one response
+type multiTenantCombiner func(r *http.Request, responses []tenantResponse) (*http.Response, error)
+
+type multiTenantSharder struct {
+	next     http.RoundTripper
+	combine  multiTenantCombiner
+	resolver tenant.Resolver
+	logger   log.Logger
+}
+
+// newMultiTenantMiddleware creates a middleware that splits requests for multiple tenants, i.e. with
+// X-Scope-OrgID: a|b|c, into one request per tenant and combines the responses. Every request goes
+// through the middlewares below for its own tenant, which enforces the limits of each tenant.
+// Requests for a single tenant are passed through. If multi-tenant queries are disabled the
+// middleware does nothing.
+func newMultiTenantMiddleware(cfg Config, combine multiTenantCombiner, logger log.Logger) Middleware {
+	return MiddlewareFunc(func(next http.RoundTripper) http.RoundTripper {
+		if !cfg.MultiTenantQueriesEnabled {
+			return next
+		}
+
+		return multiTenantSharder{
+			next:     next,
+			combine:  combine,
+			resolver: tenant.NewMultiResolver(),
+			logger:   logger,
+		}
+	})
+}
+
+// RoundTrip implements http.RoundTripper
+func (s multiTenantSharder) RoundTrip(r *http.Request) (*http.Response, error) {
+	tenantIDs, err := s.resolver.TenantIDs(r.Context())
+	if err != nil {
+		return &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(err.Error())),
+		}, nil
+	}
+
+	if len(tenantIDs) == 1 {
+		return s.next.RoundTrip(r)
+	}
+
+	span, ctx := opentracing.StartSpanFromContext(r.Context(), "frontend.MultiTenantQuery")
+	defer span.Finish()
+	span.SetTag("tenants", len(tenantIDs))
+
+	var (
+		wg        sync.WaitGroup
+		mtx       sync.Mutex
+		overall   error
+		failed    *http.Response
+		responses = make([]tenantResponse, 0, len(tenantIDs))
+	)
+
+	for _, tenantID := range tenantIDs {
+		wg.Add(1)
+		go func(tenantID string) {
+			defer wg.Done()
+
+			subR := r.Clone(user.InjectOrgID(ctx, tenantID))
+			subR.Header.Set(user.OrgIDHeaderName, tenantID)
+
+			resp, err := s.next.RoundTrip(subR)
+			if err != nil {
+				_ = level.Error(s.logger).Log("msg", "error executing multi-tenant query", "tenant", tenantID, "err", err)
+				mtx.Lock()
+				overall = err
+				mtx.Unlock()
+				return
+			}
+			defer resp.Body.Close()
+
+			// tenants without results are skipped
+			if resp.StatusCode == http.StatusNotFound {
+				return
+			}
+
+			body, err := io.ReadAll(resp.Body)
+
+			mtx.Lock()
+			defer mtx.Unlock()
+
+			if err != nil {
+				overall = err
+				return
+			}
+
+			// if the status code is anything but happy, pass it down the line
+			if resp.StatusCode != http.StatusOK {
+				failed = &http.Response{
+					StatusCode: resp.StatusCode,
+					Header:     resp.Header,
+					Body:       io.NopCloser(bytes.NewReader(body)),
+				}
+				return
+			}
+
+			responses = append(responses, tenantResponse{tenantID: tenantID, body: body})
+		}(tenantID)
+	}
+	wg.Wait()
+
+	if overall != nil {
+		return nil, overall
+	}
+	if failed != nil {
+		return failed, nil
+	}
+
+	if len(responses) == 0 {
+		return &http.Response{
+			StatusCode: http.StatusNotFound,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader("not found")),
+		}, nil
+	}
+
+	// order the responses by tenant to make the combined response deterministic
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].tenantID < responses[j].tenantID
+	})
+
+	return s.combine(r, responses)
+}
+
+// combineMultiTenantTraceByID combines the traces of all tenants. Every batch is tagged with the tenant
+// of its spans.
+func combineMultiTenantTraceByID(_ *http.Request, responses []tenantResponse) (*http.Response, error) {
+	combiner := trace.NewCombiner()
+	metrics := &tempopb.TraceByIDMetrics{}
+
+	for _, resp := range responses {
+		traceResp := &tempopb.TraceByIDResponse{}
+		err := proto.Unmarshal(resp.body, traceResp)
+		if err != nil {
+			return nil, err
+		}
+
+		if traceResp.Metrics != nil {
+			metrics.FailedBlocks += traceResp.Metrics.FailedBlocks
+		}
+		if traceResp.Trace == nil {
+			continue
+		}
+
+		for _, b := range traceResp.Trace.Batches {
+			if b.Resource == nil {
+				b.Resource = &v1resource.Resource{}
+			}
+			b.Resource.Attributes = append(b.Resource.Attributes, &v1common.KeyValue{
+				Key:   tenantAttribute,
+				Value: &v1common.AnyValue{Value: &v1common.AnyValue_StringValue{StringValue: resp.tenantID}},
+			})
+		}
+		combiner.Consume(traceResp.Trace)
+	}
+
+	combined, _ := combiner.Result()
+	buff, err := proto.Marshal(&tempopb.TraceByIDResponse{
+		Trace:   combined,
+		Metrics: metrics,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			api.HeaderContentType: {api.HeaderAcceptProtobuf},
+		},
+		Body:          io.NopCloser(bytes.NewReader(buff)),
+		ContentLength: int64(len(buff)),
+	}, nil
+}
+
+// newCombineMultiTenantSearch returns the combiner for search, search tags and search tag values requests.
+// Search results are tagged with their tenant and limited to the requested number of results.
+func newCombineMultiTenantSearch(cfg SearchSharderConfig) multiTenantCombiner {
+	return func(r *http.Request, responses []tenantResponse) (*http.Response, error) {
+		var result proto.Message
+
+		switch {
+		case api.IsSearchTags(r):
+			tags := map[string]tempofb.TagInfo{}
+			for _, resp := range responses {
+				tagsResp := &tempopb.SearchTagsResponse{}
+				if err := jsonpb.Unmarshal(bytes.NewReader(resp.body), tagsResp); err != nil {
+					return nil, err
+				}
+				search.CombineSearchTagsResponse(tags, tagsResp)
+			}
+			result = search.GetSearchTagsResponse(tags)
+
+		case api.IsSearchTagValues(r):
+			tagValues := map[string]uint32{}
+			for _, resp := range responses {
+				tagValuesResp := &tempopb.SearchTagValuesResponse{}
+				if err := jsonpb.Unmarshal(bytes.NewReader(resp.body), tagValuesResp); err != nil {
+					return nil, err
+				}
+				search.CombineSearchTagValuesResponse(tagValues, tagValuesResp)
+			}
+			result = search.GetSearchTagValuesResponse(tagValues)
+
+		default:
+			searchReq, err := api.ParseSearchRequest(r)
+			if err != nil {
+				return &http.Response{
+					StatusCode: http.StatusBadRequest,
+					Header:     http.Header{},
+					Body:       io.NopCloser(strings.NewReader(err.Error())),
+				}, nil
+			}
+			limit := adjustLimit(searchReq.Limit, cfg.DefaultLimit, cfg.MaxLimit)
+
+			combined := &tempopb.SearchResponse{
+				Metrics: &tempopb.SearchMetrics{},
+			}
+			for _, resp := range responses {
+				searchResp := &tempopb.SearchResponse{}
+				if err := jsonpb.Unmarshal(bytes.NewReader(resp.body), searchResp); err != nil {
+					return nil, err
+				}
+
+				for _, t := range searchResp.Traces {
+					t.TenantID = resp.tenantID
+					combined.Traces = append(combined.Traces, t)
+				}
+				if m := searchResp.Metrics; m != nil {
+					combined.Metrics.InspectedTraces += m.InspectedTraces
+					combined.Metrics.InspectedBytes += m.InspectedBytes
+					combined.Metrics.InspectedBlocks += m.InspectedBlocks
+					combined.Metrics.SkippedBlocks += m.SkippedBlocks
+					combined.Metrics.SkippedTraces += m.SkippedTraces
+				}
+			}
+
+			sort.Slice(combined.Traces, func(i, j int) bool {
+				return combined.Traces[i].StartTimeUnixNano > combined.Traces[j].StartTimeUnixNano
+			})
+			if limit > 0 && len(combined.Traces) > int(limit) {
+				combined.Traces = combined.Traces[:limit]
+			}
+			result = combined
+		}
+
+		bodyString, err := (&jsonpb.Marshaler{}).MarshalToString(result)
+		if err != nil {
+			return nil, err
+		}
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header: http.Header{
+				api.HeaderContentType: {api.HeaderAcceptJSON},
+			},
+			Body:          io.NopCloser(strings.NewReader(bodyString)),
+			ContentLength: int64(len(bodyString)),
+		}, nil
+	}
+}
@@ -0,0 +1,293 @@
+package frontend
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+	v1resource "github.com/grafana/tempo/pkg/tempopb/resource/v1"
+	v1 "github.com/grafana/tempo/pkg/tempopb/trace/v1"
+)
+
+func TestMultiTenantMiddlewarePassthrough(t *testing.T) {
+	tests := []struct {
+		name    string
+		enabled bool
+		orgID   string
+	}{
+		{
+			name:    "disabled",
+			enabled: false,
+			orgID:   "a|b",
+		},
+		{
+			name:    "single tenant",
+			enabled: true,
+			orgID:   "a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var orgIDs []string
+			next := RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				orgID, _ := user.ExtractOrgID(r.Context())
+				orgIDs = append(orgIDs, orgID)
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("foo")),
+				}, nil
+			})
+
+			rt := newMultiTenantMiddleware(Config{MultiTenantQueriesEnabled: tt.enabled}, nil, log.NewNopLogger()).Wrap(next)
+
+			req := httptest.NewRequest("GET", "/api/search", nil)
+			req = req.WithContext(user.InjectOrgID(req.Context(), tt.orgID))
+
+			resp, err := rt.RoundTrip(req)
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+			assert.Equal(t, []string{tt.orgID}, orgIDs)
+		})
+	}
+}
+
+func TestMultiTenantMiddlewareTraceByID(t *testing.T) {
+	traceForTenant := func(tenantID string) *tempopb.Trace {
+		return &tempopb.Trace{
+			Batches: []*v1.ResourceSpans{
+				{
+					Resource: &v1resource.Resource{},
+					InstrumentationLibrarySpans: []*v1.InstrumentationLibrarySpans{
+						{
+							Spans: []*v1.Span{
+								{
+									TraceId: []byte{0x01},
+									SpanId:  []byte(tenantID),
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name           string
+		statusByTenant map[string]int
+		expectedStatus int
+		expectedTenant []string
+	}{
+		{
+			name:           "found in all tenants",
+			statusByTenant: map[string]int{"a": http.StatusOK, "b": http.StatusOK},
+			expectedStatus: http.StatusOK,
+			expectedTenant: []string{"a", "b"},
+		},
+		{
+			name:           "found in one tenant",
+			statusByTenant: map[string]int{"a": http.StatusNotFound, "b": http.StatusOK},
+			expectedStatus: http.StatusOK,
+			expectedTenant: []string{"b"},
+		},
+		{
+			name:           "not found",
+			statusByTenant: map[string]int{"a": http.StatusNotFound, "b": http.StatusNotFound},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "error",
+			statusByTenant: map[string]int{"a": http.StatusOK, "b": http.StatusInternalServerError},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				orgID, err := user.ExtractOrgID(r.Context())
+				require.NoError(t, err)
+				require.Equal(t, orgID, r.Header.Get(user.OrgIDHeaderName))
+
+				status := tt.statusByTenant[orgID]
+				if status != http.StatusOK {
+					return &http.Response{
+						StatusCode: status,
+						Body:       io.NopCloser(strings.NewReader("")),
+					}, nil
+				}
+
+				buff, err := proto.Marshal(&tempopb.TraceByIDResponse{
+					Trace:   traceForTenant(orgID),
+					Metrics: &tempopb.TraceByIDMetrics{FailedBlocks: 1},
+				})
+				require.NoError(t, err)
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader(buff)),
+				}, nil
+			})
+
+			rt := newMultiTenantMiddleware(Config{MultiTenantQueriesEnabled: true}, combineMultiTenantTraceByID, log.NewNopLogger()).Wrap(next)
+
+			req := httptest.NewRequest("GET", "/api/traces/1234", nil)
+			req = req.WithContext(user.InjectOrgID(req.Context(), "b|a"))
+
+			resp, err := rt.RoundTrip(req)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedStatus, resp.StatusCode)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			actual := &tempopb.TraceByIDResponse{}
+			require.NoError(t, proto.Unmarshal(body, actual))
+
+			assert.Equal(t, uint32(len(tt.expectedTenant)), actual.Metrics.FailedBlocks)
+
+			var tenants []string
+			for _, b := range actual.Trace.Batches {
+				for _, attr := range b.Resource.Attributes {
+					if attr.Key == tenantAttribute {
+						tenants = append(tenants, attr.Value.GetStringValue())
+					}
+				}
+			}
+			assert.ElementsMatch(t, tt.expectedTenant, tenants)
+		})
+	}
+}
+
+func TestMultiTenantMiddlewareSearch(t *testing.T) {
+	responses := map[string]*tempopb.SearchResponse{
+		"a": {
+			Traces: []*tempopb.TraceSearchMetadata{
+				{TraceID: "1", StartTimeUnixNano: 100},
+				{TraceID: "2", StartTimeUnixNano: 300},
+			},
+			Metrics: &tempopb.SearchMetrics{InspectedTraces: 2, InspectedBytes: 10},
+		},
+		"b": {
+			Traces: []*tempopb.TraceSearchMetadata{
+				{TraceID: "1", StartTimeUnixNano: 200},
+			},
+			Metrics: &tempopb.SearchMetrics{InspectedTraces: 1, InspectedBytes: 5},
+		},
+	}
+
+	var mtx sync.Mutex
+	var orgIDs []string
+	next := RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		orgID, err := user.ExtractOrgID(r.Context())
+		require.NoError(t, err)
+
+		mtx.Lock()
+		orgIDs = append(orgIDs, orgID)
+		mtx.Unlock()
+
+		body, err := (&jsonpb.Marshaler{}).MarshalToString(responses[orgID])
+		require.NoError(t, err)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	})
+
+	cfg := Config{MultiTenantQueriesEnabled: true}
+	cfg.Search.Sharder.DefaultLimit = 20
+	rt := newMultiTenantMiddleware(cfg, newCombineMultiTenantSearch(cfg.Search.Sharder), log.NewNopLogger()).Wrap(next)
+
+	req := httptest.NewRequest("GET", "/api/search?limit=2", nil)
+	req = req.WithContext(user.InjectOrgID(req.Context(), "a|b"))
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.ElementsMatch(t, []string{"a", "b"}, orgIDs)
+
+	actual := &tempopb.SearchResponse{}
+	require.NoError(t, jsonpb.Unmarshal(resp.Body, actual))
+
+	assert.Equal(t, []*tempopb.TraceSearchMetadata{
+		{TraceID: "2", StartTimeUnixNano: 300, TenantID: "a"},
+		{TraceID: "1", StartTimeUnixNano: 200, TenantID: "b"},
+	}, actual.Traces)
+	assert.Equal(t, uint32(3), actual.Metrics.InspectedTraces)
+	assert.Equal(t, uint64(15), actual.Metrics.InspectedBytes)
+}
+
+func TestMultiTenantMiddlewareSearchTags(t *testing.T) {
+	responses := map[string]*tempopb.SearchTagValuesResponse{
+		"a": {
+			TagValues: []string{"x", "y"},
+			Values:    []*tempopb.SearchTagValue{{Value: "x", Count: 1}, {Value: "y", Count: 2}},
+		},
+		"b": {
+			TagValues: []string{"y", "z"},
+			Values:    []*tempopb.SearchTagValue{{Value: "y", Count: 3}, {Value: "z", Count: 4}},
+		},
+	}
+
+	next := RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		orgID, err := user.ExtractOrgID(r.Context())
+		require.NoError(t, err)
+
+		body, err := (&jsonpb.Marshaler{}).MarshalToString(responses[orgID])
+		require.NoError(t, err)
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	})
+
+	cfg := Config{MultiTenantQueriesEnabled: true}
+	rt := newMultiTenantMiddleware(cfg, newCombineMultiTenantSearch(cfg.Search.Sharder), log.NewNopLogger()).Wrap(next)
+
+	req := httptest.NewRequest("GET", "/api/search/tag/foo/values", nil)
+	req = req.WithContext(user.InjectOrgID(req.Context(), "a|b"))
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	actual := &tempopb.SearchTagValuesResponse{}
+	require.NoError(t, jsonpb.Unmarshal(resp.Body, actual))
+
+	assert.Equal(t, []string{"x", "y", "z"}, actual.TagValues)
+	assert.Equal(t, []*tempopb.SearchTagValue{
+		{Value: "x", Count: 1},
+		{Value: "y", Count: 5},
+		{Value: "z", Count: 4},
+	}, actual.Values)
+}
+
+func TestMultiTenantMiddlewareInvalidTenant(t *testing.T) {
+	next := RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		t.Fatal("request should not be forwarded")
+		return nil, nil
+	})
+
+	rt := newMultiTenantMiddleware(Config{MultiTenantQueriesEnabled: true}, nil, log.NewNopLogger()).Wrap(next)
+
+	req := httptest.NewRequest("GET", "/api/search", nil)
+	req = req.WithContext(user.InjectOrgID(req.Context(), "a|.."))
+
+	resp, err := rt.RoundTrip(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
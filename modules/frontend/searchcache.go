@@ -0,0 +1,170 @@
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/google/uuid"
+
+	"github.com/grafana/tempo/pkg/cache"
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/tempodb/backend"
+	"github.com/grafana/tempo/tempodb/backend/cache/memcached"
+	"github.com/grafana/tempo/tempodb/backend/cache/redis"
+)
+
+const searchCacheName = "frontend-search"
+
+// SearchResultsCacheConfig configures the cache used to store the results of backend search jobs.
+type SearchResultsCacheConfig struct {
+	Cache           string                  `yaml:"cache"`
+	BackgroundCache *cache.BackgroundConfig `yaml:"background_cache"`
+	Memcached       *memcached.Config       `yaml:"memcached"`
+	Redis           *redis.Config           `yaml:"redis"`
+}
+
+// newSearchResultsCache returns the cache configured by cfg or nil if caching is disabled.
+func newSearchResultsCache(cfg SearchResultsCacheConfig, logger log.Logger) (cache.Cache, error) {
+	switch cfg.Cache {
+	case "":
+		return nil, nil
+	case "redis":
+		if cfg.Redis == nil {
+			return nil, fmt.Errorf("search results cache redis config must be set")
+		}
+		return redis.NewClient(cfg.Redis, cfg.BackgroundCache, searchCacheName, logger), nil
+	case "memcached":
+		if cfg.Memcached == nil {
+			return nil, fmt.Errorf("search results cache memcached config must be set")
+		}
+		return memcached.NewClient(cfg.Memcached, cfg.BackgroundCache, searchCacheName, logger), nil
+	}
+
+	return nil, fmt.Errorf("unknown search results cache %s", cfg.Cache)
+}
+
+// searchCacheKey returns the key used to cache the results of searching the given pages of a block.
+// Backend blocks are immutable, so the results only depend on the block, the page range and the search
+// request. The request is normalized so that equivalent requests share a key:
+//   - tags are sorted
+//   - start and end are dropped if the block is fully contained in the range, in which case they don't
+//     filter anything. This allows queries with a sliding time range to reuse results of older blocks.
+//
+// Entries of compacted blocks are invalidated by searchCacheCompactedKey.
+func searchCacheKey(tenantID string, meta *backend.BlockMeta, startPage, pagesToSearch int, searchReq *tempopb.SearchRequest) string {
+	start, end := searchReq.Start, searchReq.End
+	if int64(start) <= meta.StartTime.Unix() && int64(end) >= meta.EndTime.Unix() {
+		start, end = 0, 0
+	}
+
+	keys := make([]string, 0, len(searchReq.Tags))
+	for k := range searchReq.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(searchReq.Tags[k])
+		sb.WriteByte('&')
+	}
+	fmt.Fprintf(&sb, "min=%d&max=%d&limit=%d&start=%d&end=%d", searchReq.MinDurationMs, searchReq.MaxDurationMs, searchReq.Limit, start, end)
+
+	// memcached limits keys to 250 characters so the request is hashed
+	return "search:" + tenantID + ":" + meta.BlockID.String() + ":" + strconv.Itoa(startPage) + ":" + strconv.Itoa(pagesToSearch) + ":" + strconv.FormatUint(xxhash.Sum64String(sb.String()), 16)
+}
+
+// searchCacheCompactedKey returns the key of the marker stored once a block is compacted. The cache can't
+// delete entries, so the results of a block are only served as long as the marker doesn't exist. This keeps
+// frontends with an outdated blocklist from combining cached results of a compacted block with the results
+// of the block it was compacted into.
+func searchCacheCompactedKey(tenantID string, blockID uuid.UUID) string {
+	return "search-compacted:" + tenantID + ":" + blockID.String()
+}
+
+// fetchSearchResults returns the cached results for key or nil if there are none or the block of the
+// results was compacted.
+func fetchSearchResults(ctx context.Context, c cache.Cache, key, compactedKey string, logger log.Logger) *tempopb.SearchResponse {
+	found, bufs, _ := c.Fetch(ctx, []string{key, compactedKey})
+
+	var buf []byte
+	for j, k := range found {
+		if k == compactedKey {
+			return nil
+		}
+		buf = bufs[j]
+	}
+	if buf == nil {
+		return nil
+	}
+
+	resp := &tempopb.SearchResponse{}
+	err := resp.Unmarshal(buf)
+	if err != nil {
+		_ = level.Error(logger).Log("msg", "error unmarshalling cached search results", "key", key, "err", err)
+		return nil
+	}
+
+	return resp
+}
+
+// storeSearchResults stores the results of a backend search job in the cache.
+func storeSearchResults(ctx context.Context, c cache.Cache, key string, resp *tempopb.SearchResponse, logger log.Logger) {
+	buf, err := resp.Marshal()
+	if err != nil {
+		_ = level.Error(logger).Log("msg", "error marshalling search results for cache", "key", key, "err", err)
+		return
+	}
+
+	c.Store(ctx, []string{key}, [][]byte{buf})
+}
+
+// compactedSearchBlocks invalidates the cached search results of compacted blocks. It remembers the compacted
+// blocks of every tenant so the marker of a block is only stored once per frontend.
+type compactedSearchBlocks struct {
+	mtx     sync.Mutex
+	tenants map[string]map[uuid.UUID]struct{}
+}
+
+func newCompactedSearchBlocks() *compactedSearchBlocks {
+	return &compactedSearchBlocks{
+		tenants: map[string]map[uuid.UUID]struct{}{},
+	}
+}
+
+// invalidate stores the markers of the compacted blocks of the tenant that weren't invalidated yet. Blocks
+// that dropped out of the compacted blocklist are forgotten.
+func (b *compactedSearchBlocks) invalidate(ctx context.Context, c cache.Cache, tenantID string, metas []*backend.CompactedBlockMeta) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	known := b.tenants[tenantID]
+	current := make(map[uuid.UUID]struct{}, len(metas))
+	var keys []string
+	for _, m := range metas {
+		current[m.BlockID] = struct{}{}
+		if _, ok := known[m.BlockID]; !ok {
+			keys = append(keys, searchCacheCompactedKey(tenantID, m.BlockID))
+		}
+	}
+	b.tenants[tenantID] = current
+
+	if len(keys) == 0 {
+		return
+	}
+
+	bufs := make([][]byte, len(keys))
+	for j := range bufs {
+		bufs[j] = []byte{1}
+	}
+	c.Store(ctx, keys, bufs)
+}
@@ -0,0 +1,128 @@
+package frontend
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"go.uber.org/atomic"
+
+	"github.com/grafana/tempo/modules/overrides"
+	"github.com/grafana/tempo/pkg/cache"
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/tempodb/backend"
+)
+
+func TestSearchCacheKey(t *testing.T) {
+	meta := &backend.BlockMeta{
+		BlockID:   uuid.MustParse("00000000-0000-0000-0000-000000000001"),
+		StartTime: time.Unix(1100, 0),
+		EndTime:   time.Unix(1200, 0),
+	}
+
+	key := func(req *tempopb.SearchRequest) string {
+		return searchCacheKey("test", meta, 0, 10, req)
+	}
+
+	base := key(&tempopb.SearchRequest{Tags: map[string]string{"a": "b", "c": "d"}, Start: 1000, End: 1500, Limit: 20})
+
+	// block is fully contained in both ranges
+	assert.Equal(t, base, key(&tempopb.SearchRequest{Tags: map[string]string{"c": "d", "a": "b"}, Start: 1050, End: 1600, Limit: 20}))
+
+	// block is only partially contained
+	assert.NotEqual(t, base, key(&tempopb.SearchRequest{Tags: map[string]string{"a": "b", "c": "d"}, Start: 1150, End: 1600, Limit: 20}))
+
+	// different request
+	assert.NotEqual(t, base, key(&tempopb.SearchRequest{Tags: map[string]string{"a": "b"}, Start: 1000, End: 1500, Limit: 20}))
+	assert.NotEqual(t, base, key(&tempopb.SearchRequest{Tags: map[string]string{"a": "b", "c": "d"}, Start: 1000, End: 1500, Limit: 10}))
+	assert.NotEqual(t, base, key(&tempopb.SearchRequest{Tags: map[string]string{"a": "b", "c": "d"}, Start: 1000, End: 1500, Limit: 20, MinDurationMs: 5}))
+
+	// different pages or tenant
+	assert.NotEqual(t, base, searchCacheKey("test", meta, 10, 10, &tempopb.SearchRequest{Tags: map[string]string{"a": "b", "c": "d"}, Start: 1000, End: 1500, Limit: 20}))
+	assert.NotEqual(t, base, searchCacheKey("test2", meta, 0, 10, &tempopb.SearchRequest{Tags: map[string]string{"a": "b", "c": "d"}, Start: 1000, End: 1500, Limit: 20}))
+}
+
+func TestSearchSharderRoundTripCache(t *testing.T) {
+	calls := atomic.NewInt32(0)
+	next := RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		calls.Inc()
+
+		resString, err := (&jsonpb.Marshaler{}).MarshalToString(&tempopb.SearchResponse{
+			Traces: []*tempopb.TraceSearchMetadata{
+				{
+					TraceID:           r.URL.Query().Get("startPage"),
+					StartTimeUnixNano: 1,
+				},
+			},
+			Metrics: &tempopb.SearchMetrics{},
+		})
+		require.NoError(t, err)
+
+		return &http.Response{
+			Body:       io.NopCloser(strings.NewReader(resString)),
+			StatusCode: http.StatusOK,
+		}, nil
+	})
+
+	o, err := overrides.NewOverrides(overrides.Limits{})
+	require.NoError(t, err)
+
+	reader := &mockReader{
+		metas: []*backend.BlockMeta{
+			{
+				StartTime:    time.Unix(1100, 0),
+				EndTime:      time.Unix(1200, 0),
+				Size:         defaultTargetBytesPerRequest * 2,
+				TotalRecords: 2,
+				BlockID:      uuid.MustParse("00000000-0000-0000-0000-000000000000"),
+			},
+		},
+	}
+	sharder := newSearchSharder(reader, o, SearchSharderConfig{
+		ConcurrentRequests:    1,
+		TargetBytesPerRequest: defaultTargetBytesPerRequest,
+	}, cache.NewMockCache(), log.NewNopLogger())
+	testRT := NewRoundTripper(next, sharder)
+
+	search := func(query string) *tempopb.SearchResponse {
+		req := httptest.NewRequest("GET", query, nil)
+		req = req.WithContext(user.InjectOrgID(req.Context(), "blerg"))
+
+		resp, err := testRT.RoundTrip(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		actual := &tempopb.SearchResponse{}
+		require.NoError(t, jsonpb.Unmarshal(resp.Body, actual))
+		return actual
+	}
+
+	first := search("/?start=1000&end=1500")
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Len(t, first.Traces, 2)
+
+	// the block is fully contained in the shifted range, so all jobs are served from the cache
+	second := search("/?start=1050&end=1550")
+	assert.Equal(t, int32(2), calls.Load())
+	assert.ElementsMatch(t, first.Traces, second.Traces)
+
+	// a different search has to query the block
+	search("/?start=1000&end=1500&tags=foo%3Dbar")
+	assert.Equal(t, int32(4), calls.Load())
+
+	// once the block is compacted its cached results are no longer served
+	reader.compactedMetas = []*backend.CompactedBlockMeta{{BlockMeta: *reader.metas[0]}}
+	search("/?start=1000&end=1500")
+	assert.Equal(t, int32(6), calls.Load())
+	search("/?start=1000&end=1500")
+	assert.Equal(t, int32(8), calls.Load())
+}
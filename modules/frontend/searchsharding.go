@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/tempo/modules/overrides"
 	"github.com/grafana/tempo/pkg/api"
 	"github.com/grafana/tempo/pkg/boundedwaitgroup"
+	"github.com/grafana/tempo/pkg/cache"
 	"github.com/grafana/tempo/pkg/tempopb"
 	"github.com/grafana/tempo/tempodb"
 	"github.com/grafana/tempo/tempodb/backend"
@@ -124,10 +125,19 @@ func (r *searchResponse) result() *tempopb.SearchResponse {
 	return res
 }
 
+// searchJob is a sharded search request. cacheKey is set for requests whose results can be cached.
+type searchJob struct {
+	req          *http.Request
+	cacheKey     string
+	compactedKey string
+}
+
 type searchSharder struct {
 	next      http.RoundTripper
 	reader    tempodb.Reader
 	overrides *overrides.Overrides
+	cache     cache.Cache
+	compacted *compactedSearchBlocks
 
 	cfg    SearchSharderConfig
 	logger log.Logger
@@ -141,15 +151,19 @@ type SearchSharderConfig struct {
 	MaxDuration           time.Duration `yaml:"max_duration"`
 	QueryBackendAfter     time.Duration `yaml:"query_backend_after,omitempty"`
 	QueryIngestersUntil   time.Duration `yaml:"query_ingesters_until,omitempty"`
+	MaxTagsBlockJobs      int           `yaml:"max_tags_block_jobs,omitempty"`
 }
 
-// newSearchSharder creates a sharding middleware for search
-func newSearchSharder(reader tempodb.Reader, o *overrides.Overrides, cfg SearchSharderConfig, logger log.Logger) Middleware {
+// newSearchSharder creates a sharding middleware for search. If c is not nil the results of backend
+// search jobs are cached.
+func newSearchSharder(reader tempodb.Reader, o *overrides.Overrides, cfg SearchSharderConfig, c cache.Cache, logger log.Logger) Middleware {
 	return MiddlewareFunc(func(next http.RoundTripper) http.RoundTripper {
 		return searchSharder{
 			next:      next,
 			reader:    reader,
 			overrides: o,
+			cache:     c,
+			compacted: newCompactedSearchBlocks(),
 			logger:    logger,
 			cfg:       cfg,
 		}
@@ -205,10 +219,15 @@ func (s searchSharder) RoundTrip(r *http.Request) (*http.Response, error) {
 	blocks := s.blockMetas(int64(start), int64(end), tenantID)
 	span.SetTag("block-count", len(blocks))
 
-	var reqs []*http.Request
+	// invalidate cached results of blocks compacted since the last search of the tenant
+	if s.cache != nil {
+		s.compacted.invalidate(ctx, s.cache, tenantID, s.reader.CompactedBlockMetas(tenantID))
+	}
+
+	var jobs []searchJob
 	// add backend requests if we need them
 	if start != end {
-		reqs, err = s.backendRequests(ctx, tenantID, r, blocks)
+		jobs, err = s.backendRequests(ctx, tenantID, r, searchReq, blocks)
 		if err != nil {
 			return nil, err
 		}
@@ -217,28 +236,36 @@ func (s searchSharder) RoundTrip(r *http.Request) (*http.Response, error) {
 	// the beginning of the slice so it is prioritized over the possibly enormous
 	// number of backend requests
 	if ingesterReq != nil {
-		reqs = append([]*http.Request{ingesterReq}, reqs...)
+		jobs = append([]searchJob{{req: ingesterReq}}, jobs...)
 	}
-	span.SetTag("request-count", len(reqs))
+	span.SetTag("request-count", len(jobs))
 
 	// execute requests
 	wg := boundedwaitgroup.New(uint(s.cfg.ConcurrentRequests))
 	overallResponse := newSearchResponse(ctx, int(searchReq.Limit))
 	overallResponse.resultsMetrics.InspectedBlocks = uint32(len(blocks))
 
-	for _, req := range reqs {
+	for _, job := range jobs {
 		if overallResponse.shouldQuit() {
 			break
 		}
 
 		wg.Add(1)
-		go func(innerR *http.Request) {
+		go func(job searchJob) {
 			defer wg.Done()
 
 			if overallResponse.shouldQuit() {
 				return
 			}
 
+			innerR := job.req
+			if job.cacheKey != "" {
+				if results := fetchSearchResults(ctx, s.cache, job.cacheKey, job.compactedKey, s.logger); results != nil {
+					overallResponse.addResponse(results)
+					return
+				}
+			}
+
 			resp, err := s.next.RoundTrip(innerR)
 			if err != nil {
 				_ = level.Error(s.logger).Log("msg", "error executing sharded query", "url", innerR.RequestURI, "err", err)
@@ -273,9 +300,13 @@ func (s searchSharder) RoundTrip(r *http.Request) (*http.Response, error) {
 				return
 			}
 
+			if job.cacheKey != "" {
+				storeSearchResults(ctx, s.cache, job.cacheKey, results, s.logger)
+			}
+
 			// happy path
 			overallResponse.addResponse(results)
-		}(req)
+		}(job)
 	}
 	wg.Wait()
 
@@ -326,10 +357,10 @@ func (s *searchSharder) blockMetas(start, end int64, tenantID string) []*backend
 	return metas
 }
 
-// backendRequests returns a slice of requests that cover all blocks in the store
+// backendRequests returns a slice of jobs that cover all blocks in the store
 // that are covered by start/end.
-func (s *searchSharder) backendRequests(ctx context.Context, tenantID string, parent *http.Request, metas []*backend.BlockMeta) ([]*http.Request, error) {
-	reqs := []*http.Request{}
+func (s *searchSharder) backendRequests(ctx context.Context, tenantID string, parent *http.Request, searchReq *tempopb.SearchRequest, metas []*backend.BlockMeta) ([]searchJob, error) {
+	jobs := []searchJob{}
 	for _, m := range metas {
 		if m.Size == 0 || m.TotalRecords == 0 {
 			continue
@@ -365,11 +396,17 @@ func (s *searchSharder) backendRequests(ctx context.Context, tenantID string, pa
 			}
 
 			subR.RequestURI = buildUpstreamRequestURI(parent.URL.Path, subR.URL.Query())
-			reqs = append(reqs, subR)
+
+			job := searchJob{req: subR}
+			if s.cache != nil {
+				job.cacheKey = searchCacheKey(tenantID, m, startPage, pagesPerQuery, searchReq)
+				job.compactedKey = searchCacheCompactedKey(tenantID, m.BlockID)
+			}
+			jobs = append(jobs, job)
 		}
 	}
 
-	return reqs, nil
+	return jobs, nil
 }
 
 // queryIngesterWithin returns a new start and end time range for the backend as well as an http request
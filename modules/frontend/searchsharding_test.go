@@ -18,7 +18,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/grafana/tempo/modules/overrides"
 	"github.com/grafana/tempo/pkg/api"
+	"github.com/grafana/tempo/pkg/tempofb"
 	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/tempodb"
 	"github.com/grafana/tempo/tempodb/backend"
 	"github.com/grafana/tempo/tempodb/blocklist"
 	"github.com/grafana/tempo/tempodb/encoding/common"
@@ -30,7 +32,8 @@ import (
 
 // implements tempodb.Reader interface
 type mockReader struct {
-	metas []*backend.BlockMeta
+	metas          []*backend.BlockMeta
+	compactedMetas []*backend.CompactedBlockMeta
 }
 
 func (m *mockReader) Find(ctx context.Context, tenantID string, id common.ID, blockStart string, blockEnd string, timeStart int64, timeEnd int64) ([]*tempopb.Trace, []error, error) {
@@ -40,11 +43,22 @@ func (m *mockReader) Find(ctx context.Context, tenantID string, id common.ID, bl
 func (m *mockReader) BlockMetas(tenantID string) []*backend.BlockMeta {
 	return m.metas
 }
+func (m *mockReader) CompactedBlockMetas(tenantID string) []*backend.CompactedBlockMeta {
+	return m.compactedMetas
+}
 func (m *mockReader) Search(ctx context.Context, meta *backend.BlockMeta, req *tempopb.SearchRequest, opts common.SearchOptions) (*tempopb.SearchResponse, error) {
 	return nil, nil
 }
+func (m *mockReader) SearchTags(ctx context.Context, tenantID string, blockID uuid.UUID, tags map[string]tempofb.TagInfo) error {
+	return nil
+}
+func (m *mockReader) SearchTagValues(ctx context.Context, tenantID string, blockID uuid.UUID, tagName string, tagValues map[string]uint32) error {
+	return nil
+}
 func (m *mockReader) EnablePolling(sharder blocklist.JobSharder) {}
-func (m *mockReader) Shutdown()                                  {}
+func (m *mockReader) ApplyBlocklistUpdate(ctx context.Context, tenantID string, change *tempodb.BlocklistChange) {
+}
+func (m *mockReader) Shutdown() {}
 
 func TestSearchResponseShouldQuit(t *testing.T) {
 	ctx := context.Background()
@@ -239,7 +253,7 @@ func TestBackendRequests(t *testing.T) {
 		}
 		req := httptest.NewRequest("GET", "/?k=test&v=test&start=10&end=20", nil)
 
-		reqs, err := s.backendRequests(context.Background(), "test", req, tc.metas)
+		jobs, err := s.backendRequests(context.Background(), "test", req, &tempopb.SearchRequest{}, tc.metas)
 		if tc.expectedError != nil {
 			assert.Equal(t, tc.expectedError, err)
 			continue
@@ -247,8 +261,8 @@ func TestBackendRequests(t *testing.T) {
 		assert.NoError(t, err)
 
 		actualURIs := []string{}
-		for _, r := range reqs {
-			actualURIs = append(actualURIs, r.RequestURI)
+		for _, j := range jobs {
+			actualURIs = append(actualURIs, j.req.RequestURI)
 		}
 
 		assert.Equal(t, tc.expectedURIs, actualURIs)
@@ -610,7 +624,7 @@ func TestSearchSharderRoundTrip(t *testing.T) {
 			}, o, SearchSharderConfig{
 				ConcurrentRequests:    1, // 1 concurrent request to force order
 				TargetBytesPerRequest: defaultTargetBytesPerRequest,
-			}, log.NewNopLogger())
+			}, nil, log.NewNopLogger())
 			testRT := NewRoundTripper(next, sharder)
 
 			req := httptest.NewRequest("GET", "/?start=1000&end=1500", nil)
@@ -653,7 +667,7 @@ func TestSearchSharderRoundTripBadRequest(t *testing.T) {
 		ConcurrentRequests:    defaultConcurrentRequests,
 		TargetBytesPerRequest: defaultTargetBytesPerRequest,
 		MaxDuration:           5 * time.Minute,
-	}, log.NewNopLogger())
+	}, nil, log.NewNopLogger())
 	testRT := NewRoundTripper(next, sharder)
 
 	// no org id
@@ -682,7 +696,7 @@ func TestSearchSharderRoundTripBadRequest(t *testing.T) {
 		ConcurrentRequests:    defaultConcurrentRequests,
 		TargetBytesPerRequest: defaultTargetBytesPerRequest,
 		MaxDuration:           5 * time.Minute,
-	}, log.NewNopLogger())
+	}, nil, log.NewNopLogger())
 	testRT = NewRoundTripper(next, sharder)
 
 	req = httptest.NewRequest("GET", "/?start=1000&end=1500", nil)
@@ -0,0 +1,395 @@
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"github.com/opentracing/opentracing-go"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/tempo/modules/overrides"
+	"github.com/grafana/tempo/pkg/api"
+	"github.com/grafana/tempo/pkg/boundedwaitgroup"
+	"github.com/grafana/tempo/pkg/tempofb"
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util"
+	"github.com/grafana/tempo/tempodb"
+	"github.com/grafana/tempo/tempodb/backend"
+	"github.com/grafana/tempo/tempodb/search"
+)
+
+// defaultMaxTagsBlockJobs is the default number of blocks whose tags are read for a search tags request
+const defaultMaxTagsBlockJobs = 1000
+
+// searchTagsResponse is a threadsafe struct used to aggregate the tags or tag values from all downstream
+// queriers
+type searchTagsResponse struct {
+	err        error
+	statusCode int
+	statusMsg  string
+	ctx        context.Context
+
+	tags      map[string]tempofb.TagInfo
+	tagValues map[string]uint32
+	size      int
+	limit     int
+	exhausted bool
+
+	mtx sync.Mutex
+}
+
+func newSearchTagsResponse(ctx context.Context, limit int) *searchTagsResponse {
+	return &searchTagsResponse{
+		ctx:        ctx,
+		statusCode: http.StatusOK,
+		tags:       map[string]tempofb.TagInfo{},
+		tagValues:  map[string]uint32{},
+		limit:      limit,
+	}
+}
+
+func (r *searchTagsResponse) setStatus(statusCode int, statusMsg string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.statusCode = statusCode
+	r.statusMsg = statusMsg
+}
+
+func (r *searchTagsResponse) setError(err error) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	r.err = err
+}
+
+// addTags merges the tags. Once the size of all tag names reaches the limit the response is exhausted.
+func (r *searchTagsResponse) addTags(resp *tempopb.SearchTagsResponse) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for _, k := range resp.TagNames {
+		if _, ok := r.tags[k]; !ok {
+			r.size += len(k)
+		}
+	}
+	search.CombineSearchTagsResponse(r.tags, resp)
+
+	if r.size >= r.limit {
+		r.exhausted = true
+	}
+}
+
+// addTagValues merges the tag values. Once the size of all values, which are returned in TagValues and Values,
+// reaches the limit the response is exhausted.
+func (r *searchTagsResponse) addTagValues(resp *tempopb.SearchTagValuesResponse) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	for _, v := range resp.TagValues {
+		if _, ok := r.tagValues[v]; !ok {
+			r.size += util.TagValueSize(v)
+		}
+	}
+	search.CombineSearchTagValuesResponse(r.tagValues, resp)
+
+	if r.size >= r.limit {
+		r.exhausted = true
+	}
+}
+
+func (r *searchTagsResponse) shouldQuit() bool {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if r.err != nil {
+		return true
+	}
+	if r.ctx.Err() != nil {
+		return true
+	}
+	if r.statusCode/100 != 2 {
+		return true
+	}
+	if r.exhausted {
+		return true
+	}
+
+	return false
+}
+
+// result returns the sorted tags or tag values. Like the querier, nothing is returned if they exceed the limit.
+func (r *searchTagsResponse) result(isTagValues bool) proto.Message {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	if isTagValues {
+		if r.exhausted {
+			return &tempopb.SearchTagValuesResponse{TagValues: []string{}}
+		}
+		return search.GetSearchTagValuesResponse(r.tagValues)
+	}
+
+	if r.exhausted {
+		return &tempopb.SearchTagsResponse{TagNames: []string{}}
+	}
+	return search.GetSearchTagsResponse(r.tags)
+}
+
+type searchTagsSharder struct {
+	searchSharder
+}
+
+// newSearchTagsSharder creates a sharding middleware for search tags and tag values with a time range. The
+// tags are read from the ingesters and from the search header of every block in the time range.
+func newSearchTagsSharder(reader tempodb.Reader, o *overrides.Overrides, cfg SearchSharderConfig, logger log.Logger) Middleware {
+	return MiddlewareFunc(func(next http.RoundTripper) http.RoundTripper {
+		return searchTagsSharder{
+			searchSharder: searchSharder{
+				next:      next,
+				reader:    reader,
+				overrides: o,
+				logger:    logger,
+				cfg:       cfg,
+			},
+		}
+	})
+}
+
+// Roundtrip implements http.RoundTripper
+//  execute up to concurrentRequests simultaneously where each request reads the tags of one block
+//  until the tags exceed max_bytes_per_tag_values_query. query params are:
+//    start=<unix epoch seconds>
+//    end=<unix epoch seconds>
+func (s searchTagsSharder) RoundTrip(r *http.Request) (*http.Response, error) {
+	isTagValues := api.IsSearchTagValues(r)
+
+	var (
+		start, end uint32
+		err        error
+	)
+	if isTagValues {
+		var req *tempopb.SearchTagValuesRequest
+		req, err = api.ParseSearchTagValuesRequest(r)
+		if req != nil {
+			start, end = req.Start, req.End
+		}
+	} else {
+		var req *tempopb.SearchTagsRequest
+		req, err = api.ParseSearchTagsRequest(r)
+		if req != nil {
+			start, end = req.Start, req.End
+		}
+	}
+	if err != nil {
+		return &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Body:       io.NopCloser(strings.NewReader(err.Error())),
+		}, nil
+	}
+
+	ctx := r.Context()
+	tenantID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Body:       io.NopCloser(strings.NewReader(err.Error())),
+		}, nil
+	}
+	span, ctx := opentracing.StartSpanFromContext(ctx, "frontend.ShardSearchTags")
+	defer span.Finish()
+
+	// calculate and enforce max search duration
+	maxDuration := s.maxDuration(tenantID)
+	if maxDuration != 0 && time.Duration(end-start)*time.Second > maxDuration {
+		return &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Body:       io.NopCloser(strings.NewReader(fmt.Sprintf("range specified by start and end exceeds %s. received start=%d end=%d", maxDuration, start, end))),
+		}, nil
+	}
+
+	var jobs []*http.Request
+	if ingesterReq := s.ingesterTagsRequest(ctx, tenantID, r, isTagValues, start, end); ingesterReq != nil {
+		jobs = append(jobs, ingesterReq)
+	}
+
+	backendStart, backendEnd := s.backendRange(&tempopb.SearchRequest{Start: start, End: end})
+	if backendStart != backendEnd {
+		blocks := s.blockMetas(int64(backendStart), int64(backendEnd), tenantID)
+		span.SetTag("block-count", len(blocks))
+		blocks = newestBlocks(blocks, s.cfg.MaxTagsBlockJobs)
+
+		jobs = append(jobs, s.backendTagsRequests(ctx, tenantID, r, isTagValues, start, end, blocks)...)
+	}
+	span.SetTag("request-count", len(jobs))
+
+	// execute requests
+	wg := boundedwaitgroup.New(uint(s.cfg.ConcurrentRequests))
+	overallResponse := newSearchTagsResponse(ctx, s.overrides.MaxBytesPerTagValuesQuery(tenantID))
+
+	for _, job := range jobs {
+		if overallResponse.shouldQuit() {
+			break
+		}
+
+		wg.Add(1)
+		go func(innerR *http.Request) {
+			defer wg.Done()
+
+			if overallResponse.shouldQuit() {
+				return
+			}
+
+			resp, err := s.next.RoundTrip(innerR)
+			if err != nil {
+				_ = level.Error(s.logger).Log("msg", "error executing sharded query", "url", innerR.RequestURI, "err", err)
+				overallResponse.setError(err)
+				return
+			}
+
+			if overallResponse.shouldQuit() {
+				return
+			}
+
+			// if the status code is anything but happy, save the error and pass it down the line
+			if resp.StatusCode != http.StatusOK {
+				statusCode := resp.StatusCode
+				bytesMsg, err := io.ReadAll(resp.Body)
+				if err != nil {
+					_ = level.Error(s.logger).Log("msg", "error reading response body status != ok", "url", innerR.RequestURI, "err", err)
+				}
+				statusMsg := fmt.Sprintf("upstream: (%d) %s", statusCode, string(bytesMsg))
+				overallResponse.setStatus(statusCode, statusMsg)
+				return
+			}
+
+			// successful query, read the body
+			if isTagValues {
+				results := &tempopb.SearchTagValuesResponse{}
+				err = jsonpb.Unmarshal(resp.Body, results)
+				if err != nil {
+					_ = level.Error(s.logger).Log("msg", "error reading response body status == ok", "url", innerR.RequestURI, "err", err)
+					overallResponse.setError(err)
+					return
+				}
+				overallResponse.addTagValues(results)
+				return
+			}
+
+			results := &tempopb.SearchTagsResponse{}
+			err = jsonpb.Unmarshal(resp.Body, results)
+			if err != nil {
+				_ = level.Error(s.logger).Log("msg", "error reading response body status == ok", "url", innerR.RequestURI, "err", err)
+				overallResponse.setError(err)
+				return
+			}
+			overallResponse.addTags(results)
+		}(job)
+	}
+	wg.Wait()
+
+	// all goroutines have finished, we can safely access searchTagsResponse fields directly now
+	if overallResponse.err != nil {
+		return nil, overallResponse.err
+	}
+
+	if overallResponse.statusCode != http.StatusOK {
+		// translate all non-200s into 500s. see searchSharder.RoundTrip
+		return &http.Response{
+			StatusCode: http.StatusInternalServerError,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(overallResponse.statusMsg)),
+		}, nil
+	}
+
+	m := &jsonpb.Marshaler{}
+	bodyString, err := m.MarshalToString(overallResponse.result(isTagValues))
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header: http.Header{
+			api.HeaderContentType: {api.HeaderAcceptJSON},
+		},
+		Body:          io.NopCloser(strings.NewReader(bodyString)),
+		ContentLength: int64(len([]byte(bodyString))),
+	}, nil
+}
+
+// ingesterTagsRequest returns the request for the ingesters if start/end overlaps query_ingesters_until.
+// Ingesters don't track the time range of their tags, so the request covers all tags in the ingesters.
+func (s *searchTagsSharder) ingesterTagsRequest(ctx context.Context, tenantID string, parent *http.Request, isTagValues bool, start, end uint32) *http.Request {
+	ingesterUntil := uint32(time.Now().Add(-s.cfg.QueryIngestersUntil).Unix())
+	if end < ingesterUntil {
+		return nil
+	}
+	if start < ingesterUntil {
+		start = ingesterUntil
+	}
+	if start == end {
+		return nil
+	}
+
+	subR := parent.Clone(ctx)
+	subR.Header.Set(user.OrgIDHeaderName, tenantID)
+	if isTagValues {
+		subR = api.BuildSearchTagValuesRequest(subR, &tempopb.SearchTagValuesRequest{Start: start, End: end})
+	} else {
+		subR = api.BuildSearchTagsRequest(subR, &tempopb.SearchTagsRequest{Start: start, End: end})
+	}
+	subR.RequestURI = buildUpstreamRequestURI(parent.URL.Path, subR.URL.Query())
+
+	return subR
+}
+
+// newestBlocks returns the max blocks with the latest end time. Tag lookups are best effort, so once there are
+// more blocks than jobs the tags of the oldest blocks are not read. 0 returns all blocks.
+func newestBlocks(metas []*backend.BlockMeta, max int) []*backend.BlockMeta {
+	if max <= 0 || len(metas) <= max {
+		return metas
+	}
+
+	sort.Slice(metas, func(i, j int) bool {
+		return metas[i].EndTime.After(metas[j].EndTime)
+	})
+	return metas[:max]
+}
+
+// backendTagsRequests returns a request for every block. The tag name of tag values requests is part of the path
+// of the parent request. Tags are read from the search header of the block,
+// which is small, so blocks are not split into multiple requests.
+func (s *searchTagsSharder) backendTagsRequests(ctx context.Context, tenantID string, parent *http.Request, isTagValues bool, start, end uint32, metas []*backend.BlockMeta) []*http.Request {
+	reqs := make([]*http.Request, 0, len(metas))
+	for _, m := range metas {
+		subR := parent.Clone(ctx)
+		subR.Header.Set(user.OrgIDHeaderName, tenantID)
+		if isTagValues {
+			subR = api.BuildSearchTagValuesBlockRequest(subR, &tempopb.SearchTagValuesBlockRequest{
+				SearchReq: &tempopb.SearchTagValuesRequest{Start: start, End: end},
+				BlockID:   m.BlockID.String(),
+			})
+		} else {
+			subR = api.BuildSearchTagsBlockRequest(subR, &tempopb.SearchTagsBlockRequest{
+				SearchReq: &tempopb.SearchTagsRequest{Start: start, End: end},
+				BlockID:   m.BlockID.String(),
+			})
+		}
+		subR.RequestURI = buildUpstreamRequestURI(parent.URL.Path, subR.URL.Query())
+
+		reqs = append(reqs, subR)
+	}
+
+	return reqs
+}
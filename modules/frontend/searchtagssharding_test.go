@@ -0,0 +1,235 @@
+package frontend
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/tempo/modules/overrides"
+	"github.com/grafana/tempo/pkg/tempofb"
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/tempodb/backend"
+	"github.com/grafana/tempo/tempodb/search"
+)
+
+func TestSearchTagsSharderRoundTrip(t *testing.T) {
+	blockID1 := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	blockID2 := uuid.MustParse("00000000-0000-0000-0000-000000000002")
+
+	tests := []struct {
+		name           string
+		path           string
+		vars           map[string]string
+		limit          int
+		maxBlockJobs   int
+		tagsByBlock    map[string][]string
+		status2        int
+		expectedStatus int
+		expectedValues []string
+	}{
+		{
+			name: "tags",
+			path: "/api/search/tags?start=1000&end=1500",
+			tagsByBlock: map[string][]string{
+				blockID1.String(): {"foo", "bar"},
+				blockID2.String(): {"bar", "baz"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedValues: []string{"bar", "baz", "foo"},
+		},
+		{
+			name: "tag values",
+			path: "/api/search/tag/foo/values?start=1000&end=1500",
+			vars: map[string]string{"tagName": "foo"},
+			tagsByBlock: map[string][]string{
+				blockID1.String(): {"a"},
+				blockID2.String(): {"b", "a"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedValues: []string{"a", "b"},
+		},
+		{
+			name:  "limit exceeded",
+			path:  "/api/search/tags?start=1000&end=1500",
+			limit: 5,
+			tagsByBlock: map[string][]string{
+				blockID1.String(): {"foo", "bar"},
+				blockID2.String(): {"baz"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedValues: []string{},
+		},
+		{
+			name:         "max block jobs",
+			path:         "/api/search/tags?start=1000&end=1500",
+			maxBlockJobs: 1,
+			tagsByBlock: map[string][]string{
+				blockID1.String(): {"foo", "bar"},
+				blockID2.String(): {"bar", "baz"},
+			},
+			expectedStatus: http.StatusOK,
+			// only the newest block is read
+			expectedValues: []string{"bar", "baz"},
+		},
+		{
+			name: "upstream error",
+			path: "/api/search/tags?start=1000&end=1500",
+			tagsByBlock: map[string][]string{
+				blockID1.String(): {"foo"},
+			},
+			status2:        http.StatusNotFound,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			next := RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+				blockID := r.URL.Query().Get("blockID")
+				if blockID == blockID2.String() && tc.status2 != 0 {
+					return &http.Response{
+						Body:       io.NopCloser(strings.NewReader("not found")),
+						StatusCode: tc.status2,
+					}, nil
+				}
+
+				var (
+					resString string
+					err       error
+				)
+				if tc.vars != nil {
+					values := map[string]uint32{}
+					for _, v := range tc.tagsByBlock[blockID] {
+						values[v] = 1
+					}
+					resString, err = (&jsonpb.Marshaler{}).MarshalToString(search.GetSearchTagValuesResponse(values))
+				} else {
+					tags := map[string]tempofb.TagInfo{}
+					for _, k := range tc.tagsByBlock[blockID] {
+						tags[k] = tempofb.TagInfo{Scope: tempofb.TagScopeSpan}
+					}
+					resString, err = (&jsonpb.Marshaler{}).MarshalToString(search.GetSearchTagsResponse(tags))
+				}
+				require.NoError(t, err)
+
+				return &http.Response{
+					Body:       io.NopCloser(strings.NewReader(resString)),
+					StatusCode: http.StatusOK,
+				}, nil
+			})
+
+			limit := tc.limit
+			if limit == 0 {
+				limit = 1000
+			}
+			o, err := overrides.NewOverrides(overrides.Limits{MaxBytesPerTagValuesQuery: limit})
+			require.NoError(t, err)
+
+			sharder := newSearchTagsSharder(&mockReader{
+				metas: []*backend.BlockMeta{
+					{
+						StartTime: time.Unix(1100, 0),
+						EndTime:   time.Unix(1200, 0),
+						BlockID:   blockID1,
+					},
+					{
+						StartTime: time.Unix(1200, 0),
+						EndTime:   time.Unix(1300, 0),
+						BlockID:   blockID2,
+					},
+					{ // outside of the time range
+						StartTime: time.Unix(1600, 0),
+						EndTime:   time.Unix(1700, 0),
+						BlockID:   uuid.MustParse("00000000-0000-0000-0000-000000000003"),
+					},
+				},
+			}, o, SearchSharderConfig{
+				ConcurrentRequests:    1, // 1 concurrent request to force order
+				TargetBytesPerRequest: defaultTargetBytesPerRequest,
+				MaxTagsBlockJobs:      tc.maxBlockJobs,
+			}, log.NewNopLogger())
+			testRT := NewRoundTripper(next, sharder)
+
+			req := httptest.NewRequest("GET", tc.path, nil)
+			if tc.vars != nil {
+				req = mux.SetURLVars(req, tc.vars)
+			}
+			req = req.WithContext(user.InjectOrgID(req.Context(), "blerg"))
+
+			resp, err := testRT.RoundTrip(req)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+			bytesResp, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			if tc.vars != nil {
+				actualResp := &tempopb.SearchTagValuesResponse{}
+				require.NoError(t, jsonpb.Unmarshal(bytes.NewReader(bytesResp), actualResp))
+				assert.Equal(t, tc.expectedValues, actualResp.TagValues)
+				// the number of traces per value is summed over all blocks
+				assert.Equal(t, []*tempopb.SearchTagValue{{Value: "a", Count: 2}, {Value: "b", Count: 1}}, actualResp.Values)
+			} else {
+				actualResp := &tempopb.SearchTagsResponse{}
+				require.NoError(t, jsonpb.Unmarshal(bytes.NewReader(bytesResp), actualResp))
+				if len(tc.expectedValues) == 0 {
+					assert.Empty(t, actualResp.TagNames)
+					assert.Empty(t, actualResp.Tags)
+				} else {
+					assert.Equal(t, tc.expectedValues, actualResp.TagNames)
+					for _, tag := range actualResp.Tags {
+						assert.Equal(t, []string{"span"}, tag.Scopes)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSearchTagsSharderRoundTripBadRequest(t *testing.T) {
+	next := RoundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, nil
+	})
+
+	o, err := overrides.NewOverrides(overrides.Limits{})
+	require.NoError(t, err)
+
+	sharder := newSearchTagsSharder(&mockReader{}, o, SearchSharderConfig{
+		ConcurrentRequests:    defaultConcurrentRequests,
+		TargetBytesPerRequest: defaultTargetBytesPerRequest,
+		MaxDuration:           5 * time.Minute,
+	}, log.NewNopLogger())
+	testRT := NewRoundTripper(next, sharder)
+
+	// no org id
+	req := httptest.NewRequest("GET", "/api/search/tags?start=1000&end=1100", nil)
+	resp, err := testRT.RoundTrip(req)
+	testBadRequest(t, resp, err, "no org id")
+
+	// start/end outside of max duration
+	req = httptest.NewRequest("GET", "/api/search/tags?start=1000&end=1500", nil)
+	req = req.WithContext(user.InjectOrgID(req.Context(), "blerg"))
+	resp, err = testRT.RoundTrip(req)
+	testBadRequest(t, resp, err, "range specified by start and end exceeds 5m0s. received start=1000 end=1500")
+
+	// tag values without the tag name var
+	req = httptest.NewRequest("GET", "/api/search/tag/foo/values?start=1000&end=1100", nil)
+	req = req.WithContext(user.InjectOrgID(req.Context(), "blerg"))
+	resp, err = testRT.RoundTrip(req)
+	testBadRequest(t, resp, err, "please provide a tagName")
+}
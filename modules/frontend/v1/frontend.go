@@ -27,23 +27,28 @@ import (
 
 var (
 	errTooManyRequest = httpgrpc.Errorf(http.StatusTooManyRequests, "too many outstanding requests")
+	errRequestExpired = errors.New("request expired")
 )
 
 // Config for a Frontend.
 type Config struct {
 	MaxOutstandingPerTenant int           `yaml:"max_outstanding_per_tenant"`
 	QuerierForgetDelay      time.Duration `yaml:"querier_forget_delay"`
+	InteractiveQueueWeight  int           `yaml:"interactive_queue_weight"`
 }
 
 // RegisterFlags adds the flags required to config this to the given FlagSet.
 func (cfg *Config) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&cfg.MaxOutstandingPerTenant, "querier.max-outstanding-requests-per-tenant", 100, "Maximum number of outstanding requests per tenant per frontend; requests beyond this error with HTTP 429.")
 	f.DurationVar(&cfg.QuerierForgetDelay, "query-frontend.querier-forget-delay", 0, "If a querier disconnects without sending notification about graceful shutdown, the query-frontend will keep the querier in the tenant's shard until the forget delay has passed. This feature is useful to reduce the blast radius when shuffle-sharding is enabled.")
+	f.IntVar(&cfg.InteractiveQueueWeight, "query-frontend.interactive-queue-weight", 4, "Number of interactive jobs (trace by id, ingester tags) handed out to queriers for every batch job (search, block tags) while both are queued.")
 }
 
 type Limits interface {
 	// Returns max queriers to use per tenant, or 0 if shuffle sharding is disabled.
 	MaxQueriersPerUser(user string) int
+	// Returns max jobs per tenant that can be processed by queriers at the same time, or 0 if unlimited.
+	MaxInflightJobsPerUser(user string) int
 }
 
 // Frontend queues HTTP requests, dispatches them to backends, and handles retries
@@ -70,6 +75,7 @@ type Frontend struct {
 }
 
 type request struct {
+	userID      string
 	enqueueTime time.Time
 	queueSpan   opentracing.Span
 	originalCtx context.Context
@@ -100,7 +106,7 @@ func New(cfg Config, limits Limits, log log.Logger, registerer prometheus.Regist
 		}),
 	}
 
-	f.requestQueue = queue.NewRequestQueue(cfg.MaxOutstandingPerTenant, cfg.QuerierForgetDelay, f.queueLength, f.discardedRequests)
+	f.requestQueue = queue.NewRequestQueue(cfg.MaxOutstandingPerTenant, cfg.InteractiveQueueWeight, cfg.QuerierForgetDelay, f.queueLength, f.discardedRequests)
 	f.activeUsers = util.NewActiveUsersCleanupWithDefaultValues(f.cleanupInactiveUserMetrics)
 
 	var err error
@@ -220,6 +226,9 @@ func (f *Frontend) Process(server frontendv1pb.Frontend_ProcessServer) error {
 		f.queueDuration.Observe(time.Since(req.enqueueTime).Seconds())
 		req.queueSpan.Finish()
 
+		err = f.processRequest(server, req)
+		f.requestQueue.FinishRequest(req.userID)
+
 		/*
 		  We want to dequeue the next unexpired request from the chosen tenant queue.
 		  The chance of choosing a particular tenant for dequeueing is (1/active_tenants).
@@ -231,58 +240,71 @@ func (f *Frontend) Process(server frontendv1pb.Frontend_ProcessServer) error {
 		  If this tenant meanwhile continued to queue requests,
 		  it's possible that it's own queue would perpetually contain only expired requests.
 		*/
-		if req.originalCtx.Err() != nil {
+		if errors.Is(err, errRequestExpired) {
 			lastUserIndex = lastUserIndex.ReuseLastUser()
 			continue
 		}
+		if err != nil {
+			return err
+		}
+	}
+}
 
-		// Handle the stream sending & receiving on a goroutine so we can
-		// monitoring the contexts in a select and cancel things appropriately.
-		resps := make(chan *frontendv1pb.ClientToFrontend, 1)
-		errs := make(chan error, 1)
-		go func() {
-			err = server.Send(&frontendv1pb.FrontendToClient{
-				Type:         frontendv1pb.Type_HTTP_REQUEST,
-				HttpRequest:  req.request,
-				StatsEnabled: stats.IsEnabled(req.originalCtx),
-			})
-			if err != nil {
-				errs <- err
-				return
-			}
-
-			resp, err := server.Recv()
-			if err != nil {
-				errs <- err
-				return
-			}
-
-			resps <- resp
-		}()
+// processRequest sends the request to the querier and waits for the response. It returns errRequestExpired
+// if the request was cancelled before it could be sent.
+func (f *Frontend) processRequest(server frontendv1pb.Frontend_ProcessServer, req *request) error {
+	if req.originalCtx.Err() != nil {
+		return errRequestExpired
+	}
 
-		select {
-		// If the upstream request is cancelled, we need to cancel the
-		// downstream req.  Only way we can do that is to close the stream.
-		// The worker client is expecting this semantics.
-		case <-req.originalCtx.Done():
-			return req.originalCtx.Err()
-
-		// Is there was an error handling this request due to network IO,
-		// then error out this upstream request _and_ stream.
-		case err := <-errs:
-			req.err <- err
-			return err
+	// Handle the stream sending & receiving on a goroutine so we can
+	// monitoring the contexts in a select and cancel things appropriately.
+	resps := make(chan *frontendv1pb.ClientToFrontend, 1)
+	errs := make(chan error, 1)
+	go func() {
+		err := server.Send(&frontendv1pb.FrontendToClient{
+			Type:         frontendv1pb.Type_HTTP_REQUEST,
+			HttpRequest:  req.request,
+			StatsEnabled: stats.IsEnabled(req.originalCtx),
+		})
+		if err != nil {
+			errs <- err
+			return
+		}
 
-		// Happy path: merge the stats and propagate the response.
-		case resp := <-resps:
-			if stats.ShouldTrackHTTPGRPCResponse(resp.HttpResponse) {
-				stats := stats.FromContext(req.originalCtx)
-				stats.Merge(resp.Stats) // Safe if stats is nil.
-			}
+		resp, err := server.Recv()
+		if err != nil {
+			errs <- err
+			return
+		}
+
+		resps <- resp
+	}()
 
-			req.response <- resp.HttpResponse
+	select {
+	// If the upstream request is cancelled, we need to cancel the
+	// downstream req.  Only way we can do that is to close the stream.
+	// The worker client is expecting this semantics.
+	case <-req.originalCtx.Done():
+		return req.originalCtx.Err()
+
+	// Is there was an error handling this request due to network IO,
+	// then error out this upstream request _and_ stream.
+	case err := <-errs:
+		req.err <- err
+		return err
+
+	// Happy path: merge the stats and propagate the response.
+	case resp := <-resps:
+		if stats.ShouldTrackHTTPGRPCResponse(resp.HttpResponse) {
+			stats := stats.FromContext(req.originalCtx)
+			stats.Merge(resp.Stats) // Safe if stats is nil.
 		}
+
+		req.response <- resp.HttpResponse
 	}
+
+	return nil
 }
 
 func (f *Frontend) NotifyClientShutdown(_ context.Context, req *frontendv1pb.NotifyClientShutdownRequest) (*frontendv1pb.NotifyClientShutdownResponse, error) {
@@ -325,13 +347,17 @@ func (f *Frontend) queueRequest(ctx context.Context, req *request) error {
 	req.enqueueTime = now
 	req.queueSpan, _ = opentracing.StartSpanFromContext(ctx, "queued")
 
-	// aggregate the max queriers limit in the case of a multi tenant query
-	maxQueriers := validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, f.limits.MaxQueriersPerUser)
+	// aggregate the limits in the case of a multi tenant query
+	limits := queue.UserLimits{
+		MaxQueriers: validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, f.limits.MaxQueriersPerUser),
+		MaxInflight: validation.SmallestPositiveNonZeroIntPerTenant(tenantIDs, f.limits.MaxInflightJobsPerUser),
+	}
 
 	joinedTenantID := tenant.JoinTenantIDs(tenantIDs)
 	f.activeUsers.UpdateUserTimestamp(joinedTenantID, now)
+	req.userID = joinedTenantID
 
-	err = f.requestQueue.EnqueueRequest(joinedTenantID, req, maxQueriers, nil)
+	err = f.requestQueue.EnqueueRequest(joinedTenantID, req, queue.ClassForRequest(req.request), limits, nil)
 	if err == queue.ErrTooManyRequests {
 		return errTooManyRequest
 	}
@@ -27,6 +27,7 @@ type Config struct {
 type Client struct {
 	tempopb.PusherClient
 	tempopb.QuerierClient
+	tempopb.IngesterClient
 	grpc_health_v1.HealthClient
 	io.Closer
 }
@@ -58,10 +59,11 @@ func New(addr string, cfg Config) (*Client, error) {
 		return nil, err
 	}
 	return &Client{
-		PusherClient:  tempopb.NewPusherClient(conn),
-		QuerierClient: tempopb.NewQuerierClient(conn),
-		HealthClient:  grpc_health_v1.NewHealthClient(conn),
-		Closer:        conn,
+		PusherClient:   tempopb.NewPusherClient(conn),
+		QuerierClient:  tempopb.NewQuerierClient(conn),
+		IngesterClient: tempopb.NewIngesterClient(conn),
+		HealthClient:   grpc_health_v1.NewHealthClient(conn),
+		Closer:         conn,
 	}, nil
 }
 
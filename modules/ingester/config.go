@@ -17,14 +17,16 @@ import (
 type Config struct {
 	LifecyclerConfig ring.LifecyclerConfig `yaml:"lifecycler,omitempty"`
 
-	ConcurrentFlushes    int           `yaml:"concurrent_flushes"`
-	FlushCheckPeriod     time.Duration `yaml:"flush_check_period"`
-	FlushOpTimeout       time.Duration `yaml:"flush_op_timeout"`
-	MaxTraceIdle         time.Duration `yaml:"trace_idle_period"`
-	MaxBlockDuration     time.Duration `yaml:"max_block_duration"`
-	MaxBlockBytes        uint64        `yaml:"max_block_bytes"`
-	CompleteBlockTimeout time.Duration `yaml:"complete_block_timeout"`
-	OverrideRingKey      string        `yaml:"override_ring_key"`
+	ConcurrentFlushes            int           `yaml:"concurrent_flushes"`
+	FlushCheckPeriod             time.Duration `yaml:"flush_check_period"`
+	FlushOpTimeout               time.Duration `yaml:"flush_op_timeout"`
+	MaxTraceIdle                 time.Duration `yaml:"trace_idle_period"`
+	MaxBlockDuration             time.Duration `yaml:"max_block_duration"`
+	MaxBlockBytes                uint64        `yaml:"max_block_bytes"`
+	CompleteBlockTimeout         time.Duration `yaml:"complete_block_timeout"`
+	OverrideRingKey              string        `yaml:"override_ring_key"`
+	MaxTransferRetries           int           `yaml:"max_transfer_retries"`
+	ExperimentalFlushPrimaryOnly bool          `yaml:"experimental_flush_primary_only"`
 }
 
 // RegisterFlagsAndApplyDefaults registers the flags.
@@ -43,6 +45,8 @@ func (cfg *Config) RegisterFlagsAndApplyDefaults(prefix string, f *flag.FlagSet)
 	f.DurationVar(&cfg.MaxBlockDuration, prefix+".max-block-duration", time.Hour, "Maximum duration which the head block can be appended to before cutting it.")
 	f.Uint64Var(&cfg.MaxBlockBytes, prefix+".max-block-bytes", 1024*1024*1024, "Maximum size of the head block before cutting it.")
 	f.DurationVar(&cfg.CompleteBlockTimeout, prefix+".complete-block-timeout", 3*tempodb.DefaultBlocklistPoll, "Duration to keep blocks in the ingester after they have been flushed.")
+	f.IntVar(&cfg.MaxTransferRetries, prefix+".max-transfer-retries", 0, "Number of times to try to transfer live traces and head blocks to a pending ingester on shutdown before falling back to flushing. Only used if the new ingester starts before the old one shuts down. 0 disables transfers.")
+	f.BoolVar(&cfg.ExperimentalFlushPrimaryOnly, prefix+".experimental-flush-primary-only", false, "EXPERIMENTAL: Only flush the traces this ingester is the primary owner of. Replicas flush traces while their primary owner is unhealthy. Trades away durability: replicas don't confirm that the primary owner received or flushed a trace before dropping it.")
 
 	hostname, err := os.Hostname()
 	if err != nil {
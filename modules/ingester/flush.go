@@ -150,15 +150,17 @@ func (i *Ingester) sweepAllInstances(immediate bool) {
 }
 
 func (i *Ingester) sweepInstance(instance *instance, immediate bool) {
+	cfg := i.blockConfigForTenant(instance.instanceID)
+
 	// cut traces internally
-	err := instance.CutCompleteTraces(i.cfg.MaxTraceIdle, immediate)
+	err := instance.CutCompleteTraces(cfg.maxTraceIdle, immediate)
 	if err != nil {
 		level.Error(log.WithUserID(instance.instanceID, log.Logger)).Log("msg", "failed to cut traces", "err", err)
 		return
 	}
 
 	// see if it's ready to cut a block
-	blockID, err := instance.CutBlockIfReady(i.cfg.MaxBlockDuration, i.cfg.MaxBlockBytes, immediate)
+	blockID, err := instance.CutBlockIfReady(cfg.maxBlockDuration, cfg.maxBlockBytes, immediate)
 	if err != nil {
 		level.Error(log.WithUserID(instance.instanceID, log.Logger)).Log("msg", "failed to cut block", "err", err)
 		return
@@ -176,12 +178,50 @@ func (i *Ingester) sweepInstance(instance *instance, immediate bool) {
 	}
 
 	// dump any blocks that have been flushed for awhile
-	err = instance.ClearFlushedBlocks(i.cfg.CompleteBlockTimeout)
+	err = instance.ClearFlushedBlocks(cfg.completeBlockTimeout)
 	if err != nil {
 		level.Error(log.WithUserID(instance.instanceID, log.Logger)).Log("msg", "failed to complete block", "err", err)
 	}
 }
 
+// blockConfig are the settings of the ingester config that can be overridden per tenant
+type blockConfig struct {
+	maxTraceIdle         time.Duration
+	maxBlockDuration     time.Duration
+	maxBlockBytes        uint64
+	completeBlockTimeout time.Duration
+}
+
+// blockConfigForTenant returns the block settings of the tenant. Settings the tenant doesn't override use the
+// ingester config. The overrides are read on every sweep, so changes apply without restarting the ingester.
+// The complete block timeout of the ingester config is checked against the blocklist poll on startup and is the
+// minimum for the tenant: queriers only find flushed blocks after polling the blocklist. The max block duration of
+// the ingester config is the maximum for the tenant: the query-frontend only searches the ingesters for the last
+// query_ingesters_until, traces of older head blocks can't be found.
+func (i *Ingester) blockConfigForTenant(tenantID string) blockConfig {
+	cfg := blockConfig{
+		maxTraceIdle:         i.cfg.MaxTraceIdle,
+		maxBlockDuration:     i.cfg.MaxBlockDuration,
+		maxBlockBytes:        i.cfg.MaxBlockBytes,
+		completeBlockTimeout: i.cfg.CompleteBlockTimeout,
+	}
+
+	if d := i.overrides.IngesterTraceIdlePeriod(tenantID); d > 0 {
+		cfg.maxTraceIdle = d
+	}
+	if d := i.overrides.IngesterMaxBlockDuration(tenantID); d > 0 && d < cfg.maxBlockDuration {
+		cfg.maxBlockDuration = d
+	}
+	if b := i.overrides.IngesterMaxBlockBytes(tenantID); b > 0 {
+		cfg.maxBlockBytes = b
+	}
+	if d := i.overrides.IngesterCompleteBlockTimeout(tenantID); d > cfg.completeBlockTimeout {
+		cfg.completeBlockTimeout = d
+	}
+
+	return cfg
+}
+
 func (i *Ingester) flushLoop(j int) {
 	defer func() {
 		level.Debug(log.Logger).Log("msg", "Ingester.flushLoop() exited")
@@ -269,6 +309,12 @@ func (i *Ingester) handleComplete(op *flushOp) (retry bool, err error) {
 		return false, errors.Wrap(err, "error clearing completing block")
 	}
 
+	// nothing to flush if the primary owners flush all traces of the block
+	if instance.GetBlockToBeFlushed(op.blockID) == nil {
+		level.Info(log.Logger).Log("msg", "block not flushed, all traces are flushed by their primary owners", "userid", op.userID, "blockID", op.blockID)
+		return false, nil
+	}
+
 	// add a flushOp for the block we just completed
 	// No delay
 	i.enqueue(&flushOp{
@@ -20,6 +20,7 @@ import (
 	"github.com/weaveworks/common/user"
 	"google.golang.org/grpc/codes"
 
+	"github.com/grafana/tempo/modules/ingester/client"
 	"github.com/grafana/tempo/modules/overrides"
 	"github.com/grafana/tempo/modules/storage"
 	"github.com/grafana/tempo/pkg/flushqueues"
@@ -67,19 +68,30 @@ type Ingester struct {
 	flushQueues     *flushqueues.ExclusiveQueues
 	flushQueuesDone sync.WaitGroup
 
-	limiter *Limiter
+	limiter   *Limiter
+	overrides *overrides.Overrides
+
+	// used to transfer traces to a pending ingester on shutdown. a var so tests can replace it
+	clientCfg     client.Config
+	clientFactory func(addr string, cfg client.Config) (*client.Client, error)
+
+	// set if only the primary owner of a trace flushes it
+	primaryOwner *primaryOwner
 
 	subservicesWatcher *services.FailureWatcher
 }
 
 // New makes a new Ingester.
-func New(cfg Config, store storage.Store, limits *overrides.Overrides, reg prometheus.Registerer) (*Ingester, error) {
+func New(cfg Config, clientCfg client.Config, ingesterRing ring.ReadRing, store storage.Store, limits *overrides.Overrides, reg prometheus.Registerer) (*Ingester, error) {
 	i := &Ingester{
-		cfg:          cfg,
-		instances:    map[string]*instance{},
-		store:        store,
-		flushQueues:  flushqueues.New(cfg.ConcurrentFlushes, metricFlushQueueLength),
-		replayJitter: true,
+		cfg:           cfg,
+		instances:     map[string]*instance{},
+		store:         store,
+		flushQueues:   flushqueues.New(cfg.ConcurrentFlushes, metricFlushQueueLength),
+		replayJitter:  true,
+		clientCfg:     clientCfg,
+		clientFactory: client.New,
+		overrides:     limits,
 	}
 
 	i.local = store.WAL().LocalBackend()
@@ -95,6 +107,15 @@ func New(cfg Config, store storage.Store, limits *overrides.Overrides, reg prome
 	}
 	i.lifecycler = lc
 
+	if cfg.ExperimentalFlushPrimaryOnly {
+		log.WarnExperimentalUse("Flushing primary owned traces only")
+		level.Warn(log.Logger).Log("msg", "experimental_flush_primary_only is enabled. traces are lost if their primary owner missed the push or fails before flushing them")
+		i.primaryOwner = &primaryOwner{
+			ring: ingesterRing,
+			addr: lc.Addr,
+		}
+	}
+
 	// Now that the lifecycler has been created, we can create the limiter
 	// which depends on it.
 	i.limiter = NewLimiter(limits, i.lifecycler, cfg.LifecyclerConfig.RingConfig.ReplicationFactor)
@@ -287,6 +308,7 @@ func (i *Ingester) getOrCreateInstance(instanceID string) (*instance, error) {
 		if err != nil {
 			return nil, err
 		}
+		inst.primaryOwner = i.primaryOwner
 		i.instances[instanceID] = inst
 	}
 	return inst, nil
@@ -319,11 +341,6 @@ func (i *Ingester) stopIncomingRequests() {
 	i.readonly = true
 }
 
-// TransferOut implements ring.Lifecycler.
-func (i *Ingester) TransferOut(ctx context.Context) error {
-	return ring.ErrTransferDisabled
-}
-
 func (i *Ingester) replayWal() error {
 	level.Info(log.Logger).Log("msg", "beginning wal replay")
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -12,10 +13,14 @@ import (
 	"github.com/grafana/dskit/flagext"
 	"github.com/grafana/dskit/kv/consul"
 	"github.com/grafana/dskit/ring"
+	"github.com/grafana/dskit/services"
 	"github.com/prometheus/client_golang/prometheus"
+	prom_model "github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/weaveworks/common/user"
 
+	"github.com/grafana/tempo/modules/ingester/client"
 	"github.com/grafana/tempo/modules/overrides"
 	"github.com/grafana/tempo/modules/storage"
 	"github.com/grafana/tempo/pkg/model"
@@ -309,8 +314,79 @@ func TestFlush(t *testing.T) {
 	}
 }
 
+func TestSweepInstanceTenantOverrides(t *testing.T) {
+	cfg := defaultIngesterTestConfig()
+	cfg.MaxBlockDuration = time.Hour
+	cfg.MaxBlockBytes = 1024 * 1024 * 1024
+	cfg.CompleteBlockTimeout = time.Minute
+	i := defaultIngesterModuleWithConfig(t, t.TempDir(), cfg)
+
+	// tenant "test" cuts traces and blocks right away
+	overridesFile := filepath.Join(t.TempDir(), "overrides.yaml")
+	require.NoError(t, os.WriteFile(overridesFile, []byte(`
+overrides:
+  test:
+    ingester_trace_idle_period: 1ms
+    ingester_max_block_duration: 1m
+    ingester_max_block_bytes: 1
+    ingester_complete_block_timeout: 1h
+  lower:
+    ingester_complete_block_timeout: 1ms
+  higher:
+    ingester_max_block_duration: 24h
+`), 0644))
+
+	limits := defaultLimitsTestConfig()
+	limits.PerTenantOverrideConfig = overridesFile
+	limits.PerTenantOverridePeriod = prom_model.Duration(time.Hour)
+
+	prometheus.DefaultRegisterer = prometheus.NewRegistry()
+	o, err := overrides.NewOverrides(limits)
+	require.NoError(t, err)
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), o))
+	defer func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), o))
+	}()
+	i.overrides = o
+
+	assert.Equal(t, blockConfig{
+		maxTraceIdle:         time.Millisecond,
+		maxBlockDuration:     time.Minute,
+		maxBlockBytes:        1,
+		completeBlockTimeout: time.Hour,
+	}, i.blockConfigForTenant("test"))
+	// the complete block timeout can't be lowered below the ingester config
+	assert.Equal(t, cfg.CompleteBlockTimeout, i.blockConfigForTenant("lower").completeBlockTimeout)
+	// the max block duration can't be raised above the ingester config
+	assert.Equal(t, cfg.MaxBlockDuration, i.blockConfigForTenant("higher").maxBlockDuration)
+	assert.Equal(t, blockConfig{
+		maxTraceIdle:         cfg.MaxTraceIdle,
+		maxBlockDuration:     cfg.MaxBlockDuration,
+		maxBlockBytes:        cfg.MaxBlockBytes,
+		completeBlockTimeout: cfg.CompleteBlockTimeout,
+	}, i.blockConfigForTenant("other"))
+
+	instances := map[string]*instance{}
+	for _, tenantID := range []string{"test", "other"} {
+		inst, err := i.getOrCreateInstance(tenantID)
+		require.NoError(t, err)
+		require.NoError(t, inst.PushBytesRequest(context.Background(), makeRequest(nil)))
+		require.NoError(t, inst.CutCompleteTraces(0, true))
+		instances[tenantID] = inst
+	}
+
+	i.sweepAllInstances(false)
+
+	// the head block of "test" exceeded its max block bytes and was cut, "other" uses the ingester config
+	assert.Equal(t, uint64(0), instances["test"].headBlock.DataLength())
+	assert.Greater(t, instances["other"].headBlock.DataLength(), uint64(0))
+}
+
 func defaultIngesterModule(t *testing.T, tmpDir string) *Ingester {
-	ingesterConfig := defaultIngesterTestConfig()
+	return defaultIngesterModuleWithConfig(t, tmpDir, defaultIngesterTestConfig())
+}
+
+func defaultIngesterModuleWithConfig(t *testing.T, tmpDir string, ingesterConfig Config) *Ingester {
 	limits, err := overrides.NewOverrides(defaultLimitsTestConfig())
 	require.NoError(t, err, "unexpected error creating overrides")
 
@@ -334,7 +410,7 @@ func defaultIngesterModule(t *testing.T, tmpDir string) *Ingester {
 	}, log.NewNopLogger())
 	require.NoError(t, err, "unexpected error store")
 
-	ingester, err := New(ingesterConfig, s, limits, prometheus.NewPedanticRegistry())
+	ingester, err := New(ingesterConfig, client.Config{}, nil, s, limits, prometheus.NewPedanticRegistry())
 	require.NoError(t, err, "unexpected error creating ingester")
 	ingester.replayJitter = false
 
@@ -116,6 +116,9 @@ type instance struct {
 	localReader backend.Reader
 	localWriter backend.Writer
 
+	// set if only the primary owner of a trace flushes it
+	primaryOwner *primaryOwner
+
 	hash hash.Hash32
 }
 
@@ -291,11 +294,22 @@ func (i *instance) CompleteBlock(blockID uuid.UUID) error {
 
 	ctx := context.Background()
 
-	backendBlock, err := i.writer.CompleteBlockWithBackend(ctx, completingBlock, model.StaticCombiner, i.localReader, i.localWriter)
+	var keepTrace, keepSearchData common.ObjectFilter
+	if i.primaryOwner != nil {
+		f := i.primaryOwner.newReplicaFilter(i.instanceID, completingBlock.Meta().StartTime)
+		keepTrace, keepSearchData = f.keepTrace, f.keepSearchData
+	}
+
+	backendBlock, err := i.writer.CompleteBlockWithBackend(ctx, completingBlock, model.StaticCombiner, keepTrace, i.localReader, i.localWriter)
 	if err != nil {
 		return errors.Wrap(err, "error completing wal block with local backend")
 	}
 
+	// all traces of the block are flushed by their primary owners
+	if backendBlock.BlockMeta().TotalObjects == 0 {
+		return i.local.ClearBlock(blockID, i.instanceID)
+	}
+
 	ingesterBlock, err := wal.NewLocalBlock(ctx, backendBlock, i.local)
 	if err != nil {
 		return errors.Wrap(err, "error creating ingester block")
@@ -308,7 +322,7 @@ func (i *instance) CompleteBlock(blockID uuid.UUID) error {
 
 	var newSearch *search.BackendSearchBlock
 	if oldSearch != nil {
-		newSearch, err = i.writer.CompleteSearchBlockWithBackend(oldSearch.b, backendBlock.BlockMeta().BlockID, backendBlock.BlockMeta().TenantID, i.localReader, i.localWriter)
+		newSearch, err = i.writer.CompleteSearchBlockWithBackend(oldSearch.b, backendBlock.BlockMeta().BlockID, backendBlock.BlockMeta().TenantID, keepSearchData, i.localReader, i.localWriter)
 		if err != nil {
 			return err
 		}
@@ -404,8 +418,15 @@ func (i *instance) ClearFlushedBlocks(completeBlockTimeout time.Duration) error
 }
 
 func (i *instance) FindTraceByID(ctx context.Context, id []byte) (*tempopb.Trace, error) {
+	trace, _, err := i.findTraceByID(ctx, id)
+	return trace, err
+}
+
+// findTraceByID returns the trace combined from all in-memory stages and the stages it was found in
+func (i *instance) findTraceByID(ctx context.Context, id []byte) (*tempopb.Trace, []traceStage, error) {
 	var err error
 	var completeTrace *tempopb.Trace
+	var stages []traceStage
 
 	// live traces
 	i.tracesMtx.Lock()
@@ -413,8 +434,9 @@ func (i *instance) FindTraceByID(ctx context.Context, id []byte) (*tempopb.Trace
 		completeTrace, err = model.MustNewSegmentDecoder(model.CurrentEncoding).PrepareForRead(liveTrace.batches)
 		if err != nil {
 			i.tracesMtx.Unlock()
-			return nil, fmt.Errorf("unable to unmarshal liveTrace: %w", err)
+			return nil, nil, fmt.Errorf("unable to unmarshal liveTrace: %w", err)
 		}
+		stages = append(stages, traceStage{Stage: stageLive})
 	}
 	i.tracesMtx.Unlock()
 
@@ -424,22 +446,28 @@ func (i *instance) FindTraceByID(ctx context.Context, id []byte) (*tempopb.Trace
 	// headBlock
 	foundBytes, err := i.headBlock.Find(id, model.StaticCombiner)
 	if err != nil {
-		return nil, fmt.Errorf("headBlock.Find failed: %w", err)
+		return nil, nil, fmt.Errorf("headBlock.Find failed: %w", err)
 	}
 	completeTrace, err = model.CombineForRead(foundBytes, i.headBlock.Meta().DataEncoding, completeTrace)
 	if err != nil {
-		return nil, fmt.Errorf("headblock unmarshal failed in FindTraceByID: %w", err)
+		return nil, nil, fmt.Errorf("headblock unmarshal failed in FindTraceByID: %w", err)
+	}
+	if foundBytes != nil {
+		stages = append(stages, traceStage{Stage: stageHead, BlockID: i.headBlock.BlockID().String()})
 	}
 
 	// completingBlock
 	for _, c := range i.completingBlocks {
 		foundBytes, err = c.Find(id, model.StaticCombiner)
 		if err != nil {
-			return nil, fmt.Errorf("completingBlock.Find failed: %w", err)
+			return nil, nil, fmt.Errorf("completingBlock.Find failed: %w", err)
 		}
 		completeTrace, err = model.CombineForRead(foundBytes, c.Meta().DataEncoding, completeTrace)
 		if err != nil {
-			return nil, fmt.Errorf("completingBlocks combine failed in FindTraceByID: %w", err)
+			return nil, nil, fmt.Errorf("completingBlocks combine failed in FindTraceByID: %w", err)
+		}
+		if foundBytes != nil {
+			stages = append(stages, traceStage{Stage: stageCompleting, BlockID: c.BlockID().String()})
 		}
 	}
 
@@ -449,13 +477,16 @@ func (i *instance) FindTraceByID(ctx context.Context, id []byte) (*tempopb.Trace
 	for _, c := range i.completeBlocks {
 		found, err := c.FindTraceByID(ctx, id)
 		if err != nil {
-			return nil, fmt.Errorf("completeBlock.FindTraceByID failed: %w", err)
+			return nil, nil, fmt.Errorf("completeBlock.FindTraceByID failed: %w", err)
 		}
 		combiner.Consume(found)
+		if found != nil {
+			stages = append(stages, traceStage{Stage: stageComplete, BlockID: c.BlockMeta().BlockID.String()})
+		}
 	}
 
 	result, _ := combiner.Result()
-	return result, nil
+	return result, stages, nil
 }
 
 // AddCompletingBlock adds an AppendBlock directly to the slice of completing blocks.
@@ -524,7 +555,7 @@ func (i *instance) resetHeadBlock() error {
 		return err
 	}
 
-	b, err := search.NewStreamingSearchBlockForFile(f, i.headBlock.BlockID(), enc)
+	b, err := search.NewStreamingSearchBlockForFile(f, i.headBlock.BlockID(), enc, i.writer.WAL().PageChecksums())
 	if err != nil {
 		return err
 	}
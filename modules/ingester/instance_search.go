@@ -1,6 +1,7 @@
 package ingester
 
 import (
+	"bytes"
 	"context"
 	"sort"
 
@@ -185,17 +186,13 @@ func (i *instance) searchLocalBlocks(ctx context.Context, p search.Pipeline, sr
 }
 
 func (i *instance) SearchTags(ctx context.Context) (*tempopb.SearchTagsResponse, error) {
-	tags := map[string]struct{}{}
+	tags := map[string]tempofb.TagInfo{}
 
 	kv := &tempofb.KeyValues{}
 	err := i.visitSearchEntriesLiveTraces(ctx, func(entry *tempofb.SearchEntry) {
 		for i, ii := 0, entry.TagsLength(); i < ii; i++ {
 			entry.Tags(kv, i)
-			key := string(kv.Key())
-			// check the tag is already set, this is more performant with repetitive values
-			if _, ok := tags[key]; !ok {
-				tags[key] = struct{}{}
-			}
+			search.AddTagInfo(tags, string(kv.Key()), kv.Info())
 		}
 	})
 	if err != nil {
@@ -209,13 +206,11 @@ func (i *instance) SearchTags(ctx context.Context) (*tempopb.SearchTagsResponse,
 		return nil, err
 	}
 
-	return &tempopb.SearchTagsResponse{
-		TagNames: extractKeys(tags),
-	}, nil
+	return search.GetSearchTagsResponse(tags), nil
 }
 
 func (i *instance) SearchTagValues(ctx context.Context, tagName string) (*tempopb.SearchTagValuesResponse, error) {
-	values := map[string]struct{}{}
+	values := map[string]uint32{}
 
 	userID, err := user.ExtractOrgID(ctx)
 	if err != nil {
@@ -224,17 +219,28 @@ func (i *instance) SearchTagValues(ctx context.Context, tagName string) (*tempop
 	// get limit from override
 	maxBytesPerTagValuesQuery := i.limiter.limits.MaxBytesPerTagValuesQuery(userID)
 
+	// a live trace has a search entry per push, which are visited in a row. values are counted once per trace.
+	var (
+		traceID     []byte
+		traceValues = map[string]struct{}{}
+	)
 	kv := &tempofb.KeyValues{}
 	tagNameBytes := []byte(tagName)
 	err = i.visitSearchEntriesLiveTraces(ctx, func(entry *tempofb.SearchEntry) {
+		if id := entry.Id(); !bytes.Equal(id, traceID) {
+			traceID = append(traceID[:0], id...)
+			traceValues = map[string]struct{}{}
+		}
+
 		kv := tempofb.FindTag(entry, kv, tagNameBytes)
 		if kv != nil {
 			for i, ii := 0, kv.ValueLength(); i < ii; i++ {
-				key := string(kv.Value(i))
-				// check the value is already set, this is more performant with repetitive values
-				if _, ok := values[key]; !ok {
-					values[key] = struct{}{}
+				v := string(kv.Value(i))
+				if _, ok := traceValues[v]; ok {
+					continue
 				}
+				traceValues[v] = struct{}{}
+				values[v]++
 			}
 		}
 	})
@@ -267,9 +273,7 @@ func (i *instance) SearchTagValues(ctx context.Context, tagName string) (*tempop
 		}, nil
 	}
 
-	return &tempopb.SearchTagValuesResponse{
-		TagValues: extractKeys(values),
-	}, nil
+	return search.GetSearchTagValuesResponse(values), nil
 }
 
 func (i *instance) visitSearchEntriesLiveTraces(ctx context.Context, visitFn func(entry *tempofb.SearchEntry)) error {
@@ -360,11 +364,3 @@ func (i *instance) visitSearchableBlocksLocalBlocks(ctx context.Context, visitFn
 	}
 	return nil
 }
-
-func extractKeys(set map[string]struct{}) []string {
-	keys := make([]string, 0, len(set))
-	for k := range set {
-		keys = append(keys, k)
-	}
-	return keys
-}
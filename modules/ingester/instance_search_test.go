@@ -163,6 +163,64 @@ func TestInstanceSearchNoData(t *testing.T) {
 	require.Len(t, sr.Traces, 0)
 }
 
+func TestInstanceSearchTagsInfoAndCounts(t *testing.T) {
+	limits, err := overrides.NewOverrides(overrides.Limits{MaxBytesPerTagValuesQuery: 1000})
+	require.NoError(t, err)
+	limiter := NewLimiter(limits, &ringCountMock{count: 1}, 1)
+
+	ingester, _, _ := defaultIngester(t, t.TempDir())
+	i, err := newInstance("fake", limiter, ingester.store, ingester.local)
+	require.NoError(t, err)
+
+	dec := model.MustNewSegmentDecoder(model.CurrentEncoding)
+	info := tempofb.TagInfo{Scope: tempofb.TagScopeResource, Type: tempofb.TagTypeString}
+
+	for _, v := range []string{"bar", "bar", "baz"} {
+		id := make([]byte, 16)
+		rand.Read(id)
+
+		traceBytes, err := dec.PrepareForWrite(test.MakeTrace(10, id), 0, 0)
+		require.NoError(t, err)
+
+		searchData := &tempofb.SearchEntryMutable{TraceID: id}
+		searchData.AddTagWithInfo("foo", v, info)
+
+		// every trace is pushed twice and still counted once
+		for j := 0; j < 2; j++ {
+			err = i.PushBytes(context.Background(), id, traceBytes, searchData.ToBytes())
+			require.NoError(t, err)
+		}
+	}
+
+	ctx := user.InjectOrgID(context.Background(), "fake")
+	check := func() {
+		tags, err := i.SearchTags(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{"foo"}, tags.TagNames)
+		require.Equal(t, []*tempopb.SearchTag{{Name: "foo", Scopes: []string{"resource"}, Types: []string{"string"}}}, tags.Tags)
+
+		values, err := i.SearchTagValues(ctx, "foo")
+		require.NoError(t, err)
+		require.Equal(t, []string{"bar", "baz"}, values.TagValues)
+		require.Equal(t, []*tempopb.SearchTagValue{{Value: "bar", Count: 2}, {Value: "baz", Count: 1}}, values.Values)
+	}
+
+	// live traces
+	check()
+
+	// after appending to the head block
+	err = i.CutCompleteTraces(0, true)
+	require.NoError(t, err)
+	check()
+
+	// after completing the block
+	blockID, err := i.CutBlockIfReady(0, 0, true)
+	require.NoError(t, err)
+	require.NoError(t, i.CompleteBlock(blockID))
+	require.NoError(t, i.ClearCompletingBlock(blockID))
+	check()
+}
+
 func TestInstanceSearchDoesNotRace(t *testing.T) {
 	limits, err := overrides.NewOverrides(overrides.Limits{})
 	require.NoError(t, err)
@@ -0,0 +1,98 @@
+package ingester
+
+import (
+	"time"
+
+	"github.com/grafana/dskit/ring"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/tempo/pkg/util"
+	"github.com/grafana/tempo/tempodb/encoding/common"
+)
+
+var (
+	metricReplicatedTracesSkipped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "ingester_replicated_traces_skipped_total",
+		Help:      "The total number of traces not flushed because their primary owner flushes them.",
+	}, []string{"tenant"})
+	metricReplicatedBytesSkipped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "ingester_replicated_bytes_skipped_total",
+		Help:      "The total number of bytes not flushed because the primary owner of their traces flushes them.",
+	}, []string{"tenant", "data_type"})
+)
+
+// ringOpFlush only considers ACTIVE ingesters healthy. A LEAVING primary owner may not get to flush its blocks.
+var ringOpFlush = ring.NewOp([]ring.InstanceState{ring.ACTIVE}, nil)
+
+// primaryOwner determines if this ingester is the primary owner of a trace: the first healthy ingester of the
+// replication set of the trace. Only the primary owner flushes a trace, the other replicas skip it.
+type primaryOwner struct {
+	ring ring.ReadRing
+	addr string
+}
+
+// newReplicaFilter returns a filter for a block of the tenant that started at the given time
+func (p *primaryOwner) newReplicaFilter(tenantID string, blockStart time.Time) *replicaFilter {
+	bufDescs, bufHosts, bufZones := ring.MakeBuffersForGet()
+	return &replicaFilter{
+		owner:      p,
+		tenantID:   tenantID,
+		blockStart: blockStart,
+		skipped:    map[string]struct{}{},
+		bufDescs:   bufDescs,
+		bufHosts:   bufHosts,
+		bufZones:   bufZones,
+	}
+}
+
+// replicaFilter drops the traces of a block that are flushed by their primary owner, along with their search data.
+// A trace is kept if this ingester is its primary owner or if the primary owner may not have received it: this
+// ingester isn't in the replication set of the trace anymore or the primary owner joined the ring after the block
+// started. A replica flushes all traces while the primary owner is unhealthy.
+// Nothing confirms that the primary owner received or flushed a dropped trace. A push succeeds with a quorum of
+// the replication set, so the primary owner may have missed it. This is why ExperimentalFlushPrimaryOnly is off by default.
+type replicaFilter struct {
+	owner      *primaryOwner
+	tenantID   string
+	blockStart time.Time
+	skipped    map[string]struct{}
+
+	bufDescs []ring.InstanceDesc
+	bufHosts []string
+	bufZones []string
+}
+
+// keepTrace implements common.ObjectFilter for the objects of the block
+func (f *replicaFilter) keepTrace(id common.ID, obj []byte) bool {
+	rs, err := f.owner.ring.Get(util.TokenFor(f.tenantID, id), ringOpFlush, f.bufDescs, f.bufHosts, f.bufZones)
+	if err != nil || len(rs.Instances) == 0 {
+		return true
+	}
+
+	primary := rs.Instances[0]
+	if primary.Addr == f.owner.addr || !rs.Includes(f.owner.addr) {
+		return true
+	}
+	if time.Unix(primary.RegisteredTimestamp, 0).After(f.blockStart) {
+		return true
+	}
+
+	f.skipped[string(id)] = struct{}{}
+	metricReplicatedTracesSkipped.WithLabelValues(f.tenantID).Inc()
+	metricReplicatedBytesSkipped.WithLabelValues(f.tenantID, traceDataType).Add(float64(len(obj)))
+	return false
+}
+
+// keepSearchData implements common.ObjectFilter for the search data of the block. It must be called after all
+// objects were filtered.
+func (f *replicaFilter) keepSearchData(id common.ID, data []byte) bool {
+	if _, ok := f.skipped[string(id)]; !ok {
+		return true
+	}
+
+	metricReplicatedBytesSkipped.WithLabelValues(f.tenantID, searchDataType).Add(float64(len(data)))
+	return false
+}
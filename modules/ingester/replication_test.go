@@ -0,0 +1,125 @@
+package ingester
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/grafana/dskit/ring"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/tempo/pkg/util"
+	"github.com/grafana/tempo/pkg/util/test"
+)
+
+// replicationSetMock returns the replication set of a trace by its token
+type replicationSetMock struct {
+	ring.ReadRing
+	sets map[uint32]ring.ReplicationSet
+}
+
+func (m *replicationSetMock) Get(key uint32, _ ring.Operation, _ []ring.InstanceDesc, _, _ []string) (ring.ReplicationSet, error) {
+	rs, ok := m.sets[key]
+	if !ok {
+		return ring.ReplicationSet{}, errors.New("no replication set")
+	}
+	return rs, nil
+}
+
+func TestReplicaFilter(t *testing.T) {
+	blockStart := time.Now()
+	before := blockStart.Add(-time.Hour).Unix()
+	after := blockStart.Add(time.Hour).Unix()
+
+	tests := []struct {
+		name      string
+		instances []ring.InstanceDesc
+		expected  bool
+	}{
+		{
+			name:     "ring error",
+			expected: true,
+		},
+		{
+			name:      "primary",
+			instances: []ring.InstanceDesc{{Addr: "self", RegisteredTimestamp: before}, {Addr: "other", RegisteredTimestamp: before}},
+			expected:  true,
+		},
+		{
+			name:      "replica",
+			instances: []ring.InstanceDesc{{Addr: "other", RegisteredTimestamp: before}, {Addr: "self", RegisteredTimestamp: before}},
+			expected:  false,
+		},
+		{
+			name:      "primary joined after the block started",
+			instances: []ring.InstanceDesc{{Addr: "other", RegisteredTimestamp: after}, {Addr: "self", RegisteredTimestamp: before}},
+			expected:  true,
+		},
+		{
+			name:      "not in replication set",
+			instances: []ring.InstanceDesc{{Addr: "other", RegisteredTimestamp: before}, {Addr: "another", RegisteredTimestamp: before}},
+			expected:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := test.ValidTraceID(nil)
+			mock := &replicationSetMock{sets: map[uint32]ring.ReplicationSet{}}
+			if tt.instances != nil {
+				mock.sets[util.TokenFor(testTenantID, id)] = ring.ReplicationSet{Instances: tt.instances}
+			}
+
+			owner := &primaryOwner{ring: mock, addr: "self"}
+			f := owner.newReplicaFilter(testTenantID, blockStart)
+
+			assert.Equal(t, tt.expected, f.keepTrace(id, []byte{0x01}))
+			assert.Equal(t, tt.expected, f.keepSearchData(id, []byte{0x01}))
+		})
+	}
+}
+
+func TestInstanceCompleteBlockPrimaryOnly(t *testing.T) {
+	i := defaultInstance(t, t.TempDir())
+
+	mock := &replicationSetMock{sets: map[uint32]ring.ReplicationSet{}}
+	i.primaryOwner = &primaryOwner{ring: mock, addr: "self"}
+
+	primary := ring.ReplicationSet{Instances: []ring.InstanceDesc{{Addr: "self"}, {Addr: "other"}}}
+	replica := ring.ReplicationSet{Instances: []ring.InstanceDesc{{Addr: "other"}, {Addr: "self"}}}
+
+	// a block with one trace owned by this ingester and one owned by another ingester
+	for _, rs := range []ring.ReplicationSet{primary, replica} {
+		request := makeRequest(nil)
+		mock.sets[util.TokenFor(testTenantID, request.Ids[0].Slice)] = rs
+		require.NoError(t, i.PushBytesRequest(context.Background(), request))
+	}
+	require.NoError(t, i.CutCompleteTraces(0, true))
+
+	skippedBefore := testutil.ToFloat64(metricReplicatedTracesSkipped.WithLabelValues(testTenantID))
+
+	blockID, err := i.CutBlockIfReady(0, 0, true)
+	require.NoError(t, err)
+	require.NoError(t, i.CompleteBlock(blockID))
+
+	block := i.GetBlockToBeFlushed(blockID)
+	require.NotNil(t, block)
+	assert.Equal(t, 1, block.BlockMeta().TotalObjects)
+	assert.Equal(t, 1.0, testutil.ToFloat64(metricReplicatedTracesSkipped.WithLabelValues(testTenantID))-skippedBefore)
+
+	// a block with only traces owned by another ingester isn't flushed
+	request := makeRequest(nil)
+	mock.sets[util.TokenFor(testTenantID, request.Ids[0].Slice)] = replica
+	require.NoError(t, i.PushBytesRequest(context.Background(), request))
+	require.NoError(t, i.CutCompleteTraces(0, true))
+
+	blockID, err = i.CutBlockIfReady(0, 0, true)
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, blockID)
+	require.NoError(t, i.CompleteBlock(blockID))
+	assert.Nil(t, i.GetBlockToBeFlushed(blockID))
+}
@@ -0,0 +1,209 @@
+package ingester
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gorilla/mux"
+
+	"github.com/grafana/tempo/pkg/api"
+	"github.com/grafana/tempo/tempodb/backend"
+)
+
+const (
+	muxVarTenant = "tenant"
+
+	// number of largest live traces in the tenant status
+	statusLargestTraces = 10
+)
+
+// in-memory stages of the traces and blocks of an ingester
+const (
+	stageLive       = "live"
+	stageHead       = "head"
+	stageCompleting = "completing"
+	stageComplete   = "complete"
+)
+
+// traceStage is an in-memory stage a trace was found in. Blocks stages include the id of the block.
+type traceStage struct {
+	Stage   string `json:"stage"`
+	BlockID string `json:"blockID,omitempty"`
+}
+
+type tenantStatus struct {
+	Tenant     string           `json:"tenant"`
+	LiveTraces liveTracesStatus `json:"liveTraces"`
+	Blocks     []blockStatus    `json:"blocks"`
+}
+
+type liveTracesStatus struct {
+	Count   int               `json:"count"`
+	Bytes   int               `json:"bytes"`
+	Largest []liveTraceStatus `json:"largest"`
+}
+
+type liveTraceStatus struct {
+	TraceID  string `json:"traceID"`
+	Bytes    int    `json:"bytes"`
+	Segments int    `json:"segments"`
+	Idle     string `json:"idle"`
+}
+
+// blockStatus is the status of a block. Age is the time since the start time of the block and is empty for
+// blocks without objects. SinceCreated is the time since the block was created or replayed by this process.
+type blockStatus struct {
+	BlockID      string     `json:"blockID"`
+	State        string     `json:"state"`
+	Size         uint64     `json:"size"`
+	Objects      int        `json:"objects"`
+	Age          string     `json:"age,omitempty"`
+	SinceCreated string     `json:"sinceCreated"`
+	StartTime    time.Time  `json:"startTime"`
+	EndTime      time.Time  `json:"endTime"`
+	Flushed      *time.Time `json:"flushedTime,omitempty"`
+}
+
+type tenantTrace struct {
+	Stages []traceStage     `json:"stages"`
+	Trace  *json.RawMessage `json:"trace"`
+}
+
+// TenantStatusHandler writes the live traces and the blocks the ingester holds in memory for the tenant.
+func (i *Ingester) TenantStatusHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)[muxVarTenant]
+	inst, ok := i.getInstanceByID(tenantID)
+	if !ok || inst == nil {
+		http.Error(w, fmt.Sprintf("no data for tenant %s", tenantID), http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, inst.status(time.Now()))
+}
+
+// TenantTraceHandler writes the trace with the given id the ingester holds in memory for the tenant, along with
+// the stages it was found in: live, head, completing or complete.
+func (i *Ingester) TenantTraceHandler(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)[muxVarTenant]
+	traceID, err := api.ParseTraceID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	inst, ok := i.getInstanceByID(tenantID)
+	if !ok || inst == nil {
+		http.Error(w, fmt.Sprintf("no data for tenant %s", tenantID), http.StatusNotFound)
+		return
+	}
+
+	trace, stages, err := inst.findTraceByID(r.Context(), traceID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if trace == nil || len(stages) == 0 {
+		http.Error(w, fmt.Sprintf("trace %s not found", hex.EncodeToString(traceID)), http.StatusNotFound)
+		return
+	}
+
+	traceJSON, err := (&jsonpb.Marshaler{}).MarshalToString(trace)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	raw := json.RawMessage(traceJSON)
+
+	writeJSON(w, tenantTrace{
+		Stages: stages,
+		Trace:  &raw,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(api.HeaderContentType, api.HeaderAcceptJSON)
+	_, _ = w.Write(b)
+}
+
+// status returns the live traces and blocks of the instance. Blocks are ordered by stage, from the head block
+// to the complete blocks.
+func (i *instance) status(now time.Time) tenantStatus {
+	status := tenantStatus{
+		Tenant: i.instanceID,
+		Blocks: []blockStatus{},
+	}
+
+	i.tracesMtx.Lock()
+	status.LiveTraces.Count = len(i.traces)
+	traces := make([]liveTraceStatus, 0, len(i.traces))
+	for _, t := range i.traces {
+		size := 0
+		for _, b := range t.batches {
+			size += len(b)
+		}
+		status.LiveTraces.Bytes += size
+
+		traces = append(traces, liveTraceStatus{
+			TraceID:  hex.EncodeToString(t.traceID),
+			Bytes:    size,
+			Segments: len(t.batches),
+			Idle:     now.Sub(t.lastAppend).String(),
+		})
+	}
+	i.tracesMtx.Unlock()
+
+	sort.Slice(traces, func(i, j int) bool {
+		return traces[i].Bytes > traces[j].Bytes
+	})
+	if len(traces) > statusLargestTraces {
+		traces = traces[:statusLargestTraces]
+	}
+	status.LiveTraces.Largest = traces
+
+	i.blocksMtx.RLock()
+	defer i.blocksMtx.RUnlock()
+
+	newBlockStatus := func(state string, meta *backend.BlockMeta, size uint64, created time.Time) blockStatus {
+		s := blockStatus{
+			BlockID:      meta.BlockID.String(),
+			State:        state,
+			Size:         size,
+			Objects:      meta.TotalObjects,
+			SinceCreated: now.Sub(created).String(),
+			StartTime:    meta.StartTime,
+			EndTime:      meta.EndTime,
+		}
+		if !meta.StartTime.IsZero() {
+			s.Age = now.Sub(meta.StartTime).String()
+		}
+		return s
+	}
+
+	if i.headBlock != nil {
+		status.Blocks = append(status.Blocks, newBlockStatus(stageHead, i.headBlock.Meta(), i.headBlock.DataLength(), i.headBlock.CreatedTime()))
+	}
+	for _, b := range i.completingBlocks {
+		status.Blocks = append(status.Blocks, newBlockStatus(stageCompleting, b.Meta(), b.DataLength(), b.CreatedTime()))
+	}
+	for _, b := range i.completeBlocks {
+		meta := b.BlockMeta()
+		s := newBlockStatus(stageComplete, meta, meta.Size, b.CreatedTime())
+		if flushed := b.FlushedTime(); !flushed.IsZero() {
+			s.Flushed = &flushed
+		}
+		status.Blocks = append(status.Blocks, s)
+	}
+
+	return status
+}
@@ -0,0 +1,135 @@
+package ingester
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/tempo/pkg/tempopb"
+)
+
+func TestTenantStatusHandler(t *testing.T) {
+	i, _, traceIDs := defaultIngester(t, t.TempDir())
+	inst, ok := i.getInstanceByID("test")
+	require.True(t, ok)
+
+	// all traces in a completing block
+	require.NoError(t, inst.CutCompleteTraces(0, true))
+	blockID, err := inst.CutBlockIfReady(0, 0, true)
+	require.NoError(t, err)
+
+	status := getTenantStatus(t, i, "test")
+	assert.Equal(t, "test", status.Tenant)
+	assert.Equal(t, 0, status.LiveTraces.Count)
+	require.Len(t, status.Blocks, 2)
+	assert.Equal(t, stageHead, status.Blocks[0].State)
+	assert.Equal(t, stageCompleting, status.Blocks[1].State)
+	assert.Equal(t, blockID.String(), status.Blocks[1].BlockID)
+	assert.Equal(t, len(traceIDs), status.Blocks[1].Objects)
+	assert.Greater(t, status.Blocks[1].Size, uint64(0))
+	assert.Empty(t, status.Blocks[0].Age)
+	assert.NotEmpty(t, status.Blocks[0].SinceCreated)
+
+	// the age of a block is based on its start time, which is kept on replay
+	now := time.Now()
+	inst.blocksMtx.RLock()
+	startTime := inst.completingBlocks[0].Meta().StartTime
+	inst.blocksMtx.RUnlock()
+	require.False(t, startTime.IsZero())
+	assert.Equal(t, now.Sub(startTime).String(), inst.status(now).Blocks[1].Age)
+
+	require.NoError(t, inst.CompleteBlock(blockID))
+	require.NoError(t, inst.ClearCompletingBlock(blockID))
+
+	for j := 0; j < statusLargestTraces+2; j++ {
+		require.NoError(t, inst.PushBytesRequest(context.Background(), makeRequest(nil)))
+	}
+
+	status = getTenantStatus(t, i, "test")
+	assert.Equal(t, statusLargestTraces+2, status.LiveTraces.Count)
+	assert.Greater(t, status.LiveTraces.Bytes, 0)
+	assert.Len(t, status.LiveTraces.Largest, statusLargestTraces)
+	for j := 1; j < len(status.LiveTraces.Largest); j++ {
+		assert.GreaterOrEqual(t, status.LiveTraces.Largest[j-1].Bytes, status.LiveTraces.Largest[j].Bytes)
+	}
+	require.Len(t, status.Blocks, 2)
+	assert.Equal(t, stageHead, status.Blocks[0].State)
+	assert.Equal(t, stageComplete, status.Blocks[1].State)
+	assert.Equal(t, blockID.String(), status.Blocks[1].BlockID)
+	assert.Nil(t, status.Blocks[1].Flushed)
+
+	// unknown tenant
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/ingester/tenants/unknown/status", nil), map[string]string{muxVarTenant: "unknown"})
+	rec := httptest.NewRecorder()
+	i.TenantStatusHandler(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestTenantTraceHandler(t *testing.T) {
+	i, traces, traceIDs := defaultIngester(t, t.TempDir())
+	inst, ok := i.getInstanceByID("test")
+	require.True(t, ok)
+
+	// first trace is cut to the head block, then pushed again to be live as well
+	require.NoError(t, inst.CutCompleteTraces(0, true))
+	blockID, err := inst.CutBlockIfReady(0, 0, true)
+	require.NoError(t, err)
+	for _, batch := range traces[0].Batches {
+		pushBatchV2(t, i, batch, traceIDs[0])
+	}
+
+	rec := getTenantTrace(t, i, "test", traceIDs[0])
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var res struct {
+		Stages []traceStage     `json:"stages"`
+		Trace  *json.RawMessage `json:"trace"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, []traceStage{
+		{Stage: stageLive},
+		{Stage: stageCompleting, BlockID: blockID.String()},
+	}, res.Stages)
+
+	trace := &tempopb.Trace{}
+	require.NoError(t, jsonpb.UnmarshalString(string(*res.Trace), trace))
+	assert.NotEmpty(t, trace.Batches)
+
+	// not found
+	rec = getTenantTrace(t, i, "test", []byte{0x01, 0x02})
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// invalid trace id
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/ingester/tenants/test/traces/invalid", nil), map[string]string{muxVarTenant: "test", "traceID": "invalid"})
+	rec = httptest.NewRecorder()
+	i.TenantTraceHandler(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func getTenantStatus(t *testing.T, i *Ingester, tenantID string) tenantStatus {
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/ingester/tenants/"+tenantID+"/status", nil), map[string]string{muxVarTenant: tenantID})
+	rec := httptest.NewRecorder()
+	i.TenantStatusHandler(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	status := tenantStatus{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	return status
+}
+
+func getTenantTrace(t *testing.T, i *Ingester, tenantID string, traceID []byte) *httptest.ResponseRecorder {
+	id := hex.EncodeToString(traceID)
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/ingester/tenants/"+tenantID+"/traces/"+id, nil), map[string]string{muxVarTenant: tenantID, "traceID": id})
+	rec := httptest.NewRecorder()
+	i.TenantTraceHandler(rec, req)
+	return rec
+}
@@ -0,0 +1,420 @@
+package ingester
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/google/uuid"
+	"github.com/grafana/dskit/backoff"
+	"github.com/grafana/dskit/ring"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/weaveworks/common/user"
+
+	"github.com/grafana/tempo/pkg/model"
+	"github.com/grafana/tempo/pkg/model/decoder"
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util/log"
+	"github.com/grafana/tempo/tempodb/encoding/common"
+)
+
+var (
+	metricTracesTransferredTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "ingester_transferred_traces_total",
+		Help:      "The total number of live traces and head block objects sent to or received from other ingesters on shutdown.",
+	}, []string{"direction"})
+)
+
+var transferBackoff = backoff.Config{
+	MinBackoff: 100 * time.Millisecond,
+	MaxBackoff: 5 * time.Second,
+}
+
+// TransferOut implements ring.FlushTransferer. It hands over the live traces and head blocks of all tenants to a
+// PENDING ingester, which appends them to its own head blocks and claims the tokens of this ingester. Blocks that
+// were cut before are completed and flushed as usual. If the transfer fails the lifecycler falls back to Flush.
+// A PENDING ingester only exists in surge-style rollouts. On scale-down there is none and nothing is transferred.
+func (i *Ingester) TransferOut(ctx context.Context) error {
+	if i.cfg.MaxTransferRetries <= 0 {
+		return ring.ErrTransferDisabled
+	}
+
+	// the data is about to be handed over, stop accepting writes
+	i.stopIncomingRequests()
+
+	// cut the head blocks so they can be transferred in full. they are only completed if the transfer fails
+	blocks := map[*instance]uuid.UUID{}
+	for _, inst := range i.getInstances() {
+		blockID, err := inst.CutBlockIfReady(0, 0, true)
+		if err != nil {
+			return fmt.Errorf("failed to cut head block for transfer: %w", err)
+		}
+		blocks[inst] = blockID
+	}
+
+	cfg := transferBackoff
+	cfg.MaxRetries = i.cfg.MaxTransferRetries
+	backoff := backoff.New(ctx, cfg)
+	for backoff.Ongoing() {
+		err := i.transferOut(ctx, blocks)
+		if err == nil {
+			break
+		}
+
+		level.Error(log.Logger).Log("msg", "transfer failed", "attempt", backoff.NumRetries()+1, "err", err)
+		backoff.Wait()
+	}
+	if err := backoff.Err(); err != nil {
+		// complete and flush the cut head blocks like any other block
+		for inst, blockID := range blocks {
+			if blockID == uuid.Nil {
+				continue
+			}
+			i.enqueue(&flushOp{
+				kind:    opKindComplete,
+				userID:  inst.instanceID,
+				blockID: blockID,
+			}, false)
+		}
+		return err
+	}
+
+	// the transferred head blocks are owned by the new ingester now
+	for inst, blockID := range blocks {
+		if blockID == uuid.Nil {
+			continue
+		}
+		if err := inst.ClearCompletingBlock(blockID); err != nil {
+			level.Error(log.WithUserID(inst.instanceID, log.Logger)).Log("msg", "failed to clear transferred head block", "block", blockID, "err", err)
+		}
+	}
+
+	// blocks cut before the transfer are not handed over, wait for them to be flushed
+	for !i.flushQueues.IsEmpty() {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	level.Info(log.Logger).Log("msg", "transfer successfully completed")
+	return nil
+}
+
+func (i *Ingester) transferOut(ctx context.Context, blocks map[*instance]uuid.UUID) error {
+	targetIngester, err := i.findTargetIngester(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot find ingester to transfer traces to: %w", err)
+	}
+
+	level.Info(log.Logger).Log("msg", "sending traces", "to_ingester", targetIngester.Addr)
+	c, err := i.clientFactory(targetIngester.Addr, i.clientCfg)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	// the tenant of every trace is part of the request. an org id is only required by the client interceptors
+	ctx = user.InjectOrgID(ctx, "-1")
+	stream, err := c.TransferTraces(ctx)
+	if err != nil {
+		return errors.Wrap(err, "TransferTraces")
+	}
+
+	// the first request only identifies this ingester so the tokens can be claimed even if there are no traces
+	err = stream.Send(&tempopb.TransferTracesRequest{
+		FromIngesterID: i.lifecycler.ID,
+	})
+	if err != nil {
+		return errors.Wrap(err, "Send")
+	}
+
+	sent := 0
+	send := func(req *tempopb.TransferTracesRequest) error {
+		req.FromIngesterID = i.lifecycler.ID
+		if err := stream.Send(req); err != nil {
+			return errors.Wrap(err, "Send")
+		}
+		sent++
+		return nil
+	}
+
+	for inst, blockID := range blocks {
+		if err := inst.transferOut(ctx, blockID, send); err != nil {
+			return err
+		}
+	}
+
+	_, err = stream.CloseAndRecv()
+	if err != nil {
+		return errors.Wrap(err, "CloseAndRecv")
+	}
+
+	metricTracesTransferredTotal.WithLabelValues("sent").Add(float64(sent))
+	level.Info(log.Logger).Log("msg", "successfully sent traces", "to_ingester", targetIngester.Addr, "traces", sent)
+
+	return nil
+}
+
+// findTargetIngester returns an ingester that waits in the PENDING state for a transfer
+func (i *Ingester) findTargetIngester(ctx context.Context) (*ring.InstanceDesc, error) {
+	ringDesc, err := i.lifecycler.KVStore.Get(ctx, i.lifecycler.RingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ingesters := ring.GetOrCreateRingDesc(ringDesc).FindIngestersByState(ring.PENDING)
+	if len(ingesters) == 0 {
+		return nil, fmt.Errorf("no pending ingesters")
+	}
+
+	return &ingesters[0], nil
+}
+
+// TransferTraces implements tempopb.IngesterServer. It receives the live traces and head blocks of a leaving
+// ingester, appends them to this ingester and claims the tokens of the leaving ingester. Only an ingester in
+// the PENDING state accepts transfers. If the transfer fails the received data is discarded, the leaving
+// ingester keeps and flushes it.
+func (i *Ingester) TransferTraces(stream tempopb.Ingester_TransferTracesServer) error {
+	if err := i.lifecycler.ChangeState(stream.Context(), ring.JOINING); err != nil {
+		return err
+	}
+
+	// discard the received data and return to PENDING so the leaving ingester can retry
+	instances := map[string]*instance{}
+	fail := func(err error) error {
+		level.Error(log.Logger).Log("msg", "error receiving traces", "err", err)
+		for _, inst := range instances {
+			if err := inst.discardTransferred(); err != nil {
+				level.Error(log.WithUserID(inst.instanceID, log.Logger)).Log("msg", "error discarding transferred traces", "err", err)
+			}
+		}
+		if err := i.lifecycler.ChangeState(context.Background(), ring.PENDING); err != nil {
+			level.Error(log.Logger).Log("msg", "error rolling back failed transfer", "err", err)
+		}
+		return err
+	}
+
+	fromIngesterID := ""
+	received := 0
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail(errors.Wrap(err, "TransferTraces"))
+		}
+
+		if fromIngesterID == "" {
+			fromIngesterID = req.FromIngesterID
+			level.Info(log.Logger).Log("msg", "processing TransferTraces request", "from_ingester", fromIngesterID)
+		}
+		if req.TenantID == "" {
+			continue
+		}
+
+		inst, err := i.getOrCreateInstance(req.TenantID)
+		if err != nil {
+			return fail(err)
+		}
+		instances[req.TenantID] = inst
+		if err := inst.transferIn(req); err != nil {
+			return fail(err)
+		}
+		received++
+	}
+
+	if fromIngesterID == "" {
+		return fail(fmt.Errorf("no ingester id received"))
+	}
+
+	if err := i.lifecycler.ClaimTokensFor(stream.Context(), fromIngesterID); err != nil {
+		return fail(errors.Wrap(err, "ClaimTokensFor"))
+	}
+	if err := i.lifecycler.ChangeState(stream.Context(), ring.ACTIVE); err != nil {
+		return fail(errors.Wrap(err, "ChangeState"))
+	}
+
+	metricTracesTransferredTotal.WithLabelValues("received").Add(float64(received))
+	level.Info(log.Logger).Log("msg", "successfully received traces", "from_ingester", fromIngesterID, "traces", received)
+
+	return stream.SendAndClose(&tempopb.TransferTracesResponse{})
+}
+
+// transferOut sends the live traces of the instance and the objects of the cut head block with the given id.
+func (i *instance) transferOut(ctx context.Context, blockID uuid.UUID, send func(*tempopb.TransferTracesRequest) error) error {
+	// take a snapshot of the live traces and send it outside of the lock. the segments are copied, because a
+	// sweep may cut the traces and reuse their segments while sending
+	i.tracesMtx.Lock()
+	reqs := make([]*tempopb.TransferTracesRequest, 0, len(i.traces))
+	for _, t := range i.traces {
+		segments := make([][]byte, 0, len(t.batches))
+		for _, b := range t.batches {
+			segments = append(segments, append([]byte(nil), b...))
+		}
+		reqs = append(reqs, &tempopb.TransferTracesRequest{
+			TenantID:     i.instanceID,
+			TraceID:      t.traceID,
+			Segments:     segments,
+			DataEncoding: model.CurrentEncoding,
+			SearchData:   append([][]byte(nil), t.searchData...),
+		})
+	}
+	i.tracesMtx.Unlock()
+
+	for _, req := range reqs {
+		if err := send(req); err != nil {
+			return err
+		}
+	}
+
+	if blockID == uuid.Nil {
+		return nil
+	}
+
+	i.blocksMtx.RLock()
+	var block *searchStreamingBlockEntry
+	var dataEncoding string
+	var iter, searchIter common.Iterator
+	var err error
+	for _, b := range i.completingBlocks {
+		if b.BlockID() == blockID {
+			dataEncoding = b.Meta().DataEncoding
+			iter, err = b.Iterator(model.StaticCombiner)
+			block = i.searchAppendBlocks[b]
+			break
+		}
+	}
+	i.blocksMtx.RUnlock()
+	if err != nil {
+		return err
+	}
+	if iter == nil {
+		return fmt.Errorf("error finding head block %s to transfer", blockID)
+	}
+	defer iter.Close()
+
+	if block != nil {
+		block.mtx.RLock()
+		defer block.mtx.RUnlock()
+
+		searchIter, err = block.b.Iterator()
+		if err != nil {
+			return err
+		}
+		defer searchIter.Close()
+	}
+
+	// both iterators return ids in order, join the search data to the objects
+	var searchID common.ID
+	var searchData []byte
+	for {
+		id, obj, err := iter.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		for searchIter != nil && (searchID == nil || bytes.Compare(searchID, id) < 0) {
+			searchID, searchData, err = searchIter.Next(ctx)
+			if err == io.EOF {
+				searchIter = nil
+				break
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		req := &tempopb.TransferTracesRequest{
+			TenantID:     i.instanceID,
+			TraceID:      id,
+			Object:       obj,
+			DataEncoding: dataEncoding,
+		}
+		if bytes.Equal(searchID, id) {
+			req.SearchData = [][]byte{searchData}
+		}
+		if err := send(req); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// transferIn adds a trace handed over by a leaving ingester. Live traces are added to the live traces of the
+// instance, objects are appended to the head block. The trace was accepted by the leaving ingester, so the
+// live traces limit doesn't apply.
+func (i *instance) transferIn(req *tempopb.TransferTracesRequest) error {
+	if req.DataEncoding != model.CurrentEncoding {
+		return fmt.Errorf("unsupported data encoding %s for transferred trace", req.DataEncoding)
+	}
+
+	if len(req.Object) > 0 {
+		start, end, err := model.MustNewObjectDecoder(req.DataEncoding).FastRange(req.Object)
+		if err == decoder.ErrUnsupported {
+			now := uint32(time.Now().Unix())
+			start, end = now, now
+		} else if err != nil {
+			return err
+		}
+
+		return i.writeTraceToHeadBlock(req.TraceID, req.Object, req.SearchData, start, end)
+	}
+
+	i.tracesMtx.Lock()
+	defer i.tracesMtx.Unlock()
+
+	trace := i.getOrCreateTrace(req.TraceID)
+	for _, s := range req.Segments {
+		if err := trace.Push(context.Background(), i.instanceID, s, nil); err != nil {
+			return err
+		}
+	}
+	for _, s := range req.SearchData {
+		trace.searchData = append(trace.searchData, s)
+		trace.currentSearchBytes += len(s)
+	}
+
+	return nil
+}
+
+// discardTransferred drops the live traces and the head block of the instance to roll back a failed transfer.
+// An ingester only receives transfers while PENDING. It owns no tokens and receives no writes, so all of
+// this data was transferred. Blocks replayed from the WAL are completing blocks and are kept.
+func (i *instance) discardTransferred() error {
+	i.tracesMtx.Lock()
+	i.traces = map[uint32]*liveTrace{}
+	i.traceCount.Store(0)
+	i.tracesMtx.Unlock()
+
+	i.blocksMtx.Lock()
+	defer i.blocksMtx.Unlock()
+
+	oldHeadBlock := i.headBlock
+	if oldHeadBlock == nil || oldHeadBlock.DataLength() == 0 {
+		return nil
+	}
+
+	err := i.resetHeadBlock()
+	if err != nil {
+		return fmt.Errorf("failed to resetHeadBlock: %w", err)
+	}
+
+	entry := i.searchAppendBlocks[oldHeadBlock]
+	if entry != nil {
+		entry.mtx.Lock()
+		_ = entry.b.Clear()
+		entry.mtx.Unlock()
+		delete(i.searchAppendBlocks, oldHeadBlock)
+	}
+
+	return oldHeadBlock.Clear()
+}
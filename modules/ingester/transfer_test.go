@@ -0,0 +1,229 @@
+package ingester
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/grafana/dskit/ring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/weaveworks/common/user"
+	"google.golang.org/grpc"
+
+	"github.com/grafana/tempo/modules/ingester/client"
+	"github.com/grafana/tempo/pkg/model/trace"
+	"github.com/grafana/tempo/pkg/tempopb"
+	"github.com/grafana/tempo/pkg/util/test"
+)
+
+func TestTransferOut(t *testing.T) {
+	// leaving ingester with traces in the head block and live traces
+	from, traces, traceIDs := defaultIngester(t, t.TempDir())
+	inst, ok := from.getInstanceByID("test")
+	require.True(t, ok)
+	require.NoError(t, inst.CutCompleteTraces(0, true))
+
+	for j := 0; j < 2; j++ {
+		id := test.ValidTraceID(nil)
+		testTrace := test.MakeTrace(10, id)
+		trace.SortTrace(testTrace)
+		for _, batch := range testTrace.Batches {
+			pushBatchV2(t, from, batch, id)
+		}
+
+		traces = append(traces, testTrace)
+		traceIDs = append(traceIDs, id)
+	}
+
+	// pending ingester in the same ring
+	cfg := defaultIngesterTestConfig()
+	cfg.LifecyclerConfig.RingConfig.KVStore.Mock = from.cfg.LifecyclerConfig.RingConfig.KVStore.Mock
+	cfg.LifecyclerConfig.ID = "to"
+	cfg.LifecyclerConfig.Addr = "to"
+	cfg.LifecyclerConfig.JoinAfter = time.Hour
+	to := defaultIngesterModuleWithConfig(t, t.TempDir(), cfg)
+
+	require.Eventually(t, func() bool {
+		_, err := from.findTargetIngester(context.Background())
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	from.cfg.MaxTransferRetries = 1
+	from.clientFactory = func(addr string, _ client.Config) (*client.Client, error) {
+		require.Contains(t, addr, "to")
+		return &client.Client{
+			IngesterClient: &transferClient{to: to},
+			Closer:         io.NopCloser(nil),
+		}, nil
+	}
+
+	require.NoError(t, from.TransferOut(context.Background()))
+
+	assert.Equal(t, ring.ACTIVE, to.lifecycler.GetState())
+	desc, err := to.lifecycler.KVStore.Get(context.Background(), to.lifecycler.RingKey)
+	require.NoError(t, err)
+	assert.NotEmpty(t, ring.GetOrCreateRingDesc(desc).Ingesters["to"].Tokens)
+	assert.Empty(t, ring.GetOrCreateRingDesc(desc).Ingesters["localhost"].Tokens)
+	assert.True(t, from.readonly)
+
+	// the head block was handed over
+	inst.blocksMtx.RLock()
+	assert.Len(t, inst.completingBlocks, 0)
+	inst.blocksMtx.RUnlock()
+
+	// live traces stay live, head block objects are appended to the head block
+	toInst, ok := to.getInstanceByID("test")
+	require.True(t, ok)
+	assert.Len(t, toInst.traces, 2)
+	assert.Greater(t, toInst.headBlock.DataLength(), uint64(0))
+
+	ctx := user.InjectOrgID(context.Background(), "test")
+	for j, traceID := range traceIDs {
+		foundTrace, err := to.FindTraceByID(ctx, &tempopb.TraceByIDRequest{
+			TraceID: traceID,
+		})
+		require.NoError(t, err)
+		require.True(t, proto.Equal(traces[j], foundTrace.Trace))
+	}
+}
+
+func TestTransferOutFails(t *testing.T) {
+	i, traces, traceIDs := defaultIngester(t, t.TempDir())
+
+	// disabled
+	require.Equal(t, ring.ErrTransferDisabled, i.TransferOut(context.Background()))
+
+	// no pending ingester
+	i.cfg.MaxTransferRetries = 1
+	require.Error(t, i.TransferOut(context.Background()))
+
+	// the traces are kept to be flushed
+	ctx := user.InjectOrgID(context.Background(), "test")
+	for j, traceID := range traceIDs {
+		foundTrace, err := i.FindTraceByID(ctx, &tempopb.TraceByIDRequest{
+			TraceID: traceID,
+		})
+		require.NoError(t, err)
+		require.True(t, proto.Equal(traces[j], foundTrace.Trace))
+	}
+}
+
+func TestTransferTracesFails(t *testing.T) {
+	from, _, _ := defaultIngester(t, t.TempDir())
+	inst, ok := from.getInstanceByID("test")
+	require.True(t, ok)
+
+	// half of the traces in the head block, half live
+	require.NoError(t, inst.CutCompleteTraces(0, true))
+	for j := 0; j < 2; j++ {
+		id := test.ValidTraceID(nil)
+		for _, batch := range test.MakeTrace(10, id).Batches {
+			pushBatchV2(t, from, batch, id)
+		}
+	}
+	blockID, err := inst.CutBlockIfReady(0, 0, true)
+	require.NoError(t, err)
+
+	reqs := []*tempopb.TransferTracesRequest{{FromIngesterID: "from"}}
+	require.NoError(t, inst.transferOut(context.Background(), blockID, func(req *tempopb.TransferTracesRequest) error {
+		reqs = append(reqs, req)
+		return nil
+	}))
+
+	cfg := defaultIngesterTestConfig()
+	cfg.LifecyclerConfig.ID = "to"
+	cfg.LifecyclerConfig.Addr = "to"
+	cfg.LifecyclerConfig.JoinAfter = time.Hour
+	to := defaultIngesterModuleWithConfig(t, t.TempDir(), cfg)
+
+	// the stream breaks after all traces are received
+	stream := &transferServerStream{
+		ctx:  context.Background(),
+		reqs: make(chan *tempopb.TransferTracesRequest, len(reqs)),
+		err:  errors.New("stream broken"),
+	}
+	for _, req := range reqs {
+		stream.reqs <- req
+	}
+	close(stream.reqs)
+	require.Error(t, to.TransferTraces(stream))
+
+	// the received data is discarded
+	assert.Equal(t, ring.PENDING, to.lifecycler.GetState())
+	toInst, ok := to.getInstanceByID("test")
+	require.True(t, ok)
+	assert.Len(t, toInst.traces, 0)
+	assert.Equal(t, uint64(0), toInst.headBlock.DataLength())
+}
+
+// transferClient calls TransferTraces of an ingester in process
+type transferClient struct {
+	to *Ingester
+}
+
+func (c *transferClient) TransferTraces(ctx context.Context, _ ...grpc.CallOption) (tempopb.Ingester_TransferTracesClient, error) {
+	reqs := make(chan *tempopb.TransferTracesRequest)
+	s := &transferClientStream{
+		reqs: reqs,
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		s.err = c.to.TransferTraces(&transferServerStream{ctx: ctx, reqs: reqs})
+		close(s.done)
+	}()
+
+	return s, nil
+}
+
+type transferClientStream struct {
+	grpc.ClientStream
+	reqs chan *tempopb.TransferTracesRequest
+	done chan struct{}
+	err  error
+}
+
+func (s *transferClientStream) Send(req *tempopb.TransferTracesRequest) error {
+	select {
+	case s.reqs <- req:
+		return nil
+	case <-s.done:
+		return s.err
+	}
+}
+
+func (s *transferClientStream) CloseAndRecv() (*tempopb.TransferTracesResponse, error) {
+	close(s.reqs)
+	<-s.done
+	return &tempopb.TransferTracesResponse{}, s.err
+}
+
+type transferServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	reqs chan *tempopb.TransferTracesRequest
+	err  error // returned instead of io.EOF
+}
+
+func (s *transferServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *transferServerStream) Recv() (*tempopb.TransferTracesRequest, error) {
+	req, ok := <-s.reqs
+	if !ok {
+		if s.err != nil {
+			return nil, s.err
+		}
+		return nil, io.EOF
+	}
+	return req, nil
+}
+
+func (s *transferServerStream) SendAndClose(*tempopb.TransferTracesResponse) error {
+	return nil
+}
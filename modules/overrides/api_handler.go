@@ -0,0 +1,158 @@
+package overrides
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/go-kit/log/level"
+	"github.com/gorilla/mux"
+	"github.com/grafana/dskit/tenant"
+
+	"github.com/grafana/tempo/pkg/api"
+	"github.com/grafana/tempo/pkg/util/log"
+)
+
+const (
+	muxVarTenant = "tenant"
+
+	headerETag    = "ETag"
+	headerIfMatch = "If-Match"
+
+	maxAPIOverridesBytes = 1024 * 1024
+)
+
+// APIHandler serves the overrides of the tenant that are managed via the API:
+//   GET returns them with their ETag
+//   PUT replaces them with the limits in the body
+//   PATCH sets the limits in the body and removes the limits set to null
+//   DELETE removes them
+// Modifications can be made conditional by passing the ETag of the last read in If-Match. The ETag is the
+// generation of the stored document, it is compared with the generation in the backend. A write that
+// overwrote the write of another instance in the meantime is reported with 409.
+func (o *Overrides) APIHandler(w http.ResponseWriter, r *http.Request) {
+	if o.apiOverrides == nil {
+		http.Error(w, "overrides API is not enabled", http.StatusNotFound)
+		return
+	}
+
+	tenantID := mux.Vars(r)[muxVarTenant]
+	if err := tenant.ValidTenantID(tenantID); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// the tenant is used as path in the backend
+	if tenantID == "." || tenantID == ".." {
+		http.Error(w, fmt.Sprintf("invalid tenant %s", tenantID), http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		current, err := o.apiOverrides.read(r.Context(), tenantID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if len(current.Overrides) == 0 {
+			http.Error(w, fmt.Sprintf("no overrides for tenant %s", tenantID), http.StatusNotFound)
+			return
+		}
+		writeAPIOverrides(w, current.Overrides, current.Generation)
+		return
+	}
+
+	var body apiOverridesDoc
+	if r.Method == http.MethodPut || r.Method == http.MethodPatch {
+		b, err := io.ReadAll(io.LimitReader(r.Body, maxAPIOverridesBytes))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := json.Unmarshal(b, &body); err != nil {
+			http.Error(w, fmt.Sprintf("invalid overrides: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	a := o.apiOverrides
+	a.writeMtx.Lock()
+	defer a.writeMtx.Unlock()
+
+	current, err := a.read(r.Context(), tenantID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// the generation is only compared within this instance, see apiOverrides.write
+	exists := len(current.Overrides) > 0
+	if ifMatch := r.Header.Get(headerIfMatch); ifMatch != "" && !(ifMatch == "*" && exists) && !(exists && ifMatch == etag(current.Generation)) {
+		http.Error(w, "overrides were modified", http.StatusPreconditionFailed)
+		return
+	}
+
+	var doc apiOverridesDoc
+	switch r.Method {
+	case http.MethodPut:
+		doc = apiOverridesDoc{}
+		for k, v := range body {
+			if !isJSONNull(v) {
+				doc[k] = v
+			}
+		}
+	case http.MethodPatch:
+		doc = current.Overrides
+		for k, v := range body {
+			if isJSONNull(v) {
+				delete(doc, k)
+			} else {
+				doc[k] = v
+			}
+		}
+	case http.MethodDelete:
+		doc = apiOverridesDoc{}
+	default:
+		http.Error(w, fmt.Sprintf("method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if err := doc.validate(); err != nil {
+		http.Error(w, fmt.Sprintf("invalid overrides: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	generation, err := a.write(r.Context(), tenantID, doc, current.Generation)
+	if errors.Is(err, errAPIOverridesConflict) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	level.Info(log.Logger).Log("msg", "overrides updated via the overrides API", "tenant", tenantID, "method", r.Method)
+
+	if len(doc) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeAPIOverrides(w, doc, generation)
+}
+
+func writeAPIOverrides(w http.ResponseWriter, doc apiOverridesDoc, generation uint64) {
+	b, err := doc.bytes()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(api.HeaderContentType, api.HeaderAcceptJSON)
+	w.Header().Set(headerETag, etag(generation))
+	_, _ = w.Write(b)
+}
+
+func isJSONNull(v json.RawMessage) bool {
+	return string(v) == "null"
+}
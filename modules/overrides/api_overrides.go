@@ -0,0 +1,438 @@
+package overrides
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/go-kit/log/level"
+
+	"github.com/grafana/tempo/pkg/util"
+	"github.com/grafana/tempo/pkg/util/log"
+	"github.com/grafana/tempo/tempodb/backend"
+	"github.com/grafana/tempo/tempodb/backend/azure"
+	"github.com/grafana/tempo/tempodb/backend/gcs"
+	"github.com/grafana/tempo/tempodb/backend/local"
+	"github.com/grafana/tempo/tempodb/backend/s3"
+)
+
+const (
+	// apiOverridesName is the name of the object holding the API managed overrides of a tenant
+	apiOverridesName = "overrides.json"
+	// apiOverridesIndexName is the name of the object holding the API managed overrides of all tenants. It is
+	// stored at the root of the bucket, next to the folders of the tenants.
+	apiOverridesIndexName = "index.json"
+)
+
+// errAPIOverridesConflict is returned if a concurrent write of the overrides of a tenant by another instance is
+// detected. Not every concurrent write is detected.
+var errAPIOverridesConflict = errors.New("overrides were modified concurrently")
+
+// apiOverridesGlobalFields are settings that can't be set per tenant
+var apiOverridesGlobalFields = []string{"ingestion_rate_strategy", "per_tenant_override_config", "per_tenant_override_period"}
+
+// APIConfig configures the overrides that are managed via the overrides HTTP API. They are stored in their
+// own bucket, separate from the trace storage.
+type APIConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Backend string `yaml:"backend"`
+
+	// Serve is set for the targets that serve the API. They read the overrides of every tenant and share them
+	// with all other targets in an index.
+	Serve bool `yaml:"-"`
+
+	Local *local.Config `yaml:"local"`
+	GCS   *gcs.Config   `yaml:"gcs"`
+	S3    *s3.Config    `yaml:"s3"`
+	Azure *azure.Config `yaml:"azure"`
+}
+
+// RegisterFlagsAndApplyDefaults registers the flags.
+func (cfg *APIConfig) RegisterFlagsAndApplyDefaults(prefix string, f *flag.FlagSet) {
+	f.BoolVar(&cfg.Enabled, util.PrefixConfig(prefix, "enabled"), false, "Enable the overrides HTTP API.")
+	f.StringVar(&cfg.Backend, util.PrefixConfig(prefix, "backend"), "", "Overrides API backend (s3, azure, gcs, local)")
+
+	cfg.Local = &local.Config{}
+	f.StringVar(&cfg.Local.Path, util.PrefixConfig(prefix, "local.path"), "", "path to store overrides at.")
+
+	cfg.GCS = &gcs.Config{}
+	f.StringVar(&cfg.GCS.BucketName, util.PrefixConfig(prefix, "gcs.bucket"), "", "gcs bucket to store overrides in.")
+	cfg.GCS.ChunkBufferSize = 10 * 1024 * 1024
+
+	cfg.S3 = &s3.Config{}
+	f.StringVar(&cfg.S3.Bucket, util.PrefixConfig(prefix, "s3.bucket"), "", "s3 bucket to store overrides in.")
+	f.StringVar(&cfg.S3.Endpoint, util.PrefixConfig(prefix, "s3.endpoint"), "", "s3 endpoint to push overrides to.")
+
+	cfg.Azure = &azure.Config{}
+	f.StringVar(&cfg.Azure.ContainerName, util.PrefixConfig(prefix, "azure.container-name"), "", "Azure container name to store overrides in.")
+	cfg.Azure.Endpoint = "blob.core.windows.net"
+	cfg.Azure.MaxBuffers = 4
+	cfg.Azure.BufferSize = 3 * 1024 * 1024
+}
+
+func newAPIBackend(cfg APIConfig) (backend.RawReader, backend.RawWriter, error) {
+	var (
+		r   backend.RawReader
+		w   backend.RawWriter
+		err error
+	)
+
+	switch cfg.Backend {
+	case "local":
+		r, w, _, err = local.New(cfg.Local)
+	case "gcs":
+		r, w, _, err = gcs.New(cfg.GCS)
+	case "s3":
+		r, w, _, err = s3.New(cfg.S3)
+	case "azure":
+		r, w, _, err = azure.New(cfg.Azure)
+	default:
+		err = fmt.Errorf("unknown backend %s", cfg.Backend)
+	}
+
+	return r, w, err
+}
+
+// apiOverridesDoc are the limits of a tenant set via the API. Only the limits present in the document
+// replace the limits from the runtime config.
+type apiOverridesDoc map[string]json.RawMessage
+
+// validate returns an error if the document contains unknown limits, limits that can't be set per tenant
+// or values of the wrong type.
+func (d apiOverridesDoc) validate() error {
+	for _, f := range apiOverridesGlobalFields {
+		if _, ok := d[f]; ok {
+			return fmt.Errorf("%s can't be set per tenant", f)
+		}
+	}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(&Limits{})
+}
+
+// apply returns a copy of the base limits overlaid with the limits of the document.
+func (d apiOverridesDoc) apply(base *Limits) (*Limits, error) {
+	baseBytes, err := json.Marshal(base)
+	if err != nil {
+		return nil, err
+	}
+	docBytes, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Limits{}
+	if err := json.Unmarshal(baseBytes, l); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(docBytes, l); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// bytes returns the canonical encoding of the document. Keys are sorted.
+func (d apiOverridesDoc) bytes() ([]byte, error) {
+	return json.Marshal(d)
+}
+
+// apiOverridesObject is the stored document of a tenant. The generation is incremented on every write, also
+// when the overrides are removed, so it identifies the version of the document.
+type apiOverridesObject struct {
+	Generation uint64          `json:"generation"`
+	Overrides  apiOverridesDoc `json:"overrides"`
+}
+
+// apiOverridesIndex holds the documents of all tenants with overrides
+type apiOverridesIndex struct {
+	Tenants map[string]apiOverridesObject `json:"tenants"`
+}
+
+// etag returns the entity tag of the generation of a document
+func etag(generation uint64) string {
+	return `"` + strconv.FormatUint(generation, 10) + `"`
+}
+
+// mergedOverrides caches the runtime config merged with the API managed overrides
+type mergedOverrides struct {
+	file    *perTenantOverrides
+	version uint64
+	result  *perTenantOverrides
+}
+
+// apiOverrides loads the overrides managed via the API from the backend and merges them with the runtime
+// config.
+type apiOverrides struct {
+	r backend.RawReader
+	w backend.RawWriter
+
+	// serve is set if this instance serves the API and builds the index
+	serve bool
+
+	mtx     sync.RWMutex
+	docs    map[string]apiOverridesObject
+	version uint64
+	merged  atomic.Value
+
+	// serializes read-compare-write cycles of this instance
+	writeMtx sync.Mutex
+}
+
+func newAPIOverrides(cfg APIConfig) (*apiOverrides, error) {
+	r, w, err := newAPIBackend(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiOverrides{
+		r:     r,
+		w:     w,
+		serve: cfg.Serve,
+		docs:  map[string]apiOverridesObject{},
+	}, nil
+}
+
+func (a *apiOverrides) starting(ctx context.Context) error {
+	return a.reload(ctx)
+}
+
+func (a *apiOverrides) iteration(ctx context.Context) error {
+	if err := a.reload(ctx); err != nil {
+		level.Error(log.Logger).Log("msg", "failed to reload overrides from the overrides API backend", "err", err)
+	}
+	return nil
+}
+
+func (a *apiOverrides) stopping(_ error) error {
+	a.r.Shutdown()
+	return nil
+}
+
+// reload loads the overrides of all tenants. Instances that serve the API read the document of every tenant
+// and write them to the index, all other instances only read the index.
+func (a *apiOverrides) reload(ctx context.Context) error {
+	if !a.serve {
+		return a.readIndex(ctx)
+	}
+
+	tenants, err := a.r.List(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	docs := make(map[string]apiOverridesObject, len(tenants))
+	for _, tenant := range tenants {
+		obj, err := a.read(ctx, tenant)
+		if err != nil {
+			// skip the tenant instead of failing the reload, e.g. if a limit was removed
+			level.Error(log.Logger).Log("msg", "failed to read overrides from the overrides API backend", "tenant", tenant, "err", err)
+			continue
+		}
+		if len(obj.Overrides) > 0 {
+			docs[tenant] = obj
+		}
+	}
+
+	a.mtx.Lock()
+	a.docs = docs
+	a.version++
+	a.mtx.Unlock()
+
+	return a.writeIndex(ctx)
+}
+
+// readIndex loads the overrides of all tenants from the index. Without an index no tenant has overrides.
+func (a *apiOverrides) readIndex(ctx context.Context) error {
+	index := apiOverridesIndex{}
+
+	rc, _, err := a.r.Read(ctx, apiOverridesIndexName, nil, false)
+	if err != nil && !errors.Is(err, backend.ErrDoesNotExist) {
+		return err
+	}
+	if err == nil {
+		defer rc.Close()
+		if err := json.NewDecoder(rc).Decode(&index); err != nil {
+			return err
+		}
+	}
+
+	docs := make(map[string]apiOverridesObject, len(index.Tenants))
+	for tenant, obj := range index.Tenants {
+		if err := obj.Overrides.validate(); err != nil {
+			level.Error(log.Logger).Log("msg", "failed to read overrides from the overrides API index", "tenant", tenant, "err", err)
+			continue
+		}
+		if len(obj.Overrides) > 0 {
+			docs[tenant] = obj
+		}
+	}
+
+	a.mtx.Lock()
+	defer a.mtx.Unlock()
+
+	a.docs = docs
+	a.version++
+
+	return nil
+}
+
+// writeIndex writes the overrides of all tenants known to this instance to the index. Instances serving the
+// API can overwrite each other's index, every reload rebuilds it from the documents of the tenants.
+func (a *apiOverrides) writeIndex(ctx context.Context) error {
+	a.mtx.RLock()
+	index := apiOverridesIndex{Tenants: make(map[string]apiOverridesObject, len(a.docs))}
+	for tenant, obj := range a.docs {
+		index.Tenants[tenant] = obj
+	}
+	a.mtx.RUnlock()
+
+	b, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+
+	return a.w.Write(ctx, apiOverridesIndexName, nil, bytes.NewReader(b), int64(len(b)), false)
+}
+
+// read returns the document of the tenant stored in the backend. A tenant that never had overrides has an
+// empty document with generation 0.
+func (a *apiOverrides) read(ctx context.Context, tenant string) (apiOverridesObject, error) {
+	b, err := a.readBytes(ctx, tenant)
+	if err != nil {
+		return apiOverridesObject{}, err
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return apiOverridesObject{Overrides: apiOverridesDoc{}}, nil
+	}
+
+	obj := apiOverridesObject{}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return apiOverridesObject{}, err
+	}
+	if obj.Overrides == nil {
+		obj.Overrides = apiOverridesDoc{}
+	}
+	if err := obj.Overrides.validate(); err != nil {
+		return apiOverridesObject{}, err
+	}
+
+	return obj, nil
+}
+
+func (a *apiOverrides) readBytes(ctx context.Context, tenant string) ([]byte, error) {
+	rc, _, err := a.r.Read(ctx, apiOverridesName, backend.KeyPath{tenant}, false)
+	if errors.Is(err, backend.ErrDoesNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
+
+// write stores the overrides of the tenant in the backend with the next generation and returns it. RawWriter
+// has no delete, so an empty document removes the overrides of the tenant. The backend has no conditional
+// writes, so the generation only guards against concurrent writes of this instance, which are serialized by
+// writeMtx. Another instance can write the same generation at the same time and one of the writes is lost. The
+// document is read back as a best effort to detect this, which returns errAPIOverridesConflict.
+func (a *apiOverrides) write(ctx context.Context, tenant string, doc apiOverridesDoc, generation uint64) (uint64, error) {
+	obj := apiOverridesObject{
+		Generation: generation + 1,
+		Overrides:  doc,
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return 0, err
+	}
+
+	err = a.w.Write(ctx, apiOverridesName, backend.KeyPath{tenant}, bytes.NewReader(b), int64(len(b)), false)
+	if err != nil {
+		return 0, err
+	}
+
+	stored, err := a.readBytes(ctx, tenant)
+	if err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(stored, b) {
+		return 0, errAPIOverridesConflict
+	}
+
+	// apply the change to this instance right away, other instances pick it up on their next reload
+	a.mtx.Lock()
+	if len(doc) > 0 {
+		a.docs[tenant] = obj
+	} else {
+		delete(a.docs, tenant)
+	}
+	a.version++
+	a.mtx.Unlock()
+
+	if a.serve {
+		if err := a.writeIndex(ctx); err != nil {
+			level.Error(log.Logger).Log("msg", "failed to write the overrides API index", "err", err)
+		}
+	}
+
+	return obj.Generation, nil
+}
+
+// merge returns the runtime config with the API managed overrides of each tenant applied on top of the
+// limits the tenant would get otherwise: its own runtime config, the wildcard runtime config or the defaults.
+func (a *apiOverrides) merge(file *perTenantOverrides, defaults *Limits) *perTenantOverrides {
+	a.mtx.RLock()
+	defer a.mtx.RUnlock()
+
+	if m, ok := a.merged.Load().(*mergedOverrides); ok && m.file == file && m.version == a.version {
+		return m.result
+	}
+
+	result := &perTenantOverrides{TenantLimits: map[string]*Limits{}}
+	if file != nil {
+		for tenant, l := range file.TenantLimits {
+			result.TenantLimits[tenant] = l
+		}
+	}
+
+	for tenant, obj := range a.docs {
+		base := defaults
+		if file != nil {
+			if l := file.forUser(tenant); l != nil {
+				base = l
+			} else if l := file.forUser(wildcardTenant); l != nil {
+				base = l
+			}
+		}
+
+		l, err := obj.Overrides.apply(base)
+		if err != nil {
+			level.Error(log.Logger).Log("msg", "failed to apply overrides from the overrides API", "tenant", tenant, "err", err)
+			continue
+		}
+		result.TenantLimits[tenant] = l
+	}
+
+	a.merged.Store(&mergedOverrides{
+		file:    file,
+		version: a.version,
+		result:  result,
+	})
+
+	return result
+}
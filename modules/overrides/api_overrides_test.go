@@ -0,0 +1,244 @@
+package overrides
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/grafana/tempo/tempodb/backend"
+	"github.com/grafana/tempo/tempodb/backend/local"
+)
+
+// newTestAPIOverrides returns overrides that serve the API
+func newTestAPIOverrides(t *testing.T, limits Limits, fileOverrides *perTenantOverrides, path string) *Overrides {
+	return newTestAPIOverridesWithServe(t, limits, fileOverrides, path, true)
+}
+
+func newTestAPIOverridesWithServe(t *testing.T, limits Limits, fileOverrides *perTenantOverrides, path string, serve bool) *Overrides {
+	if fileOverrides != nil {
+		overridesFile := filepath.Join(t.TempDir(), "overrides.yaml")
+
+		buff, err := yaml.Marshal(fileOverrides)
+		require.NoError(t, err)
+
+		err = os.WriteFile(overridesFile, buff, os.ModePerm)
+		require.NoError(t, err)
+
+		limits.PerTenantOverrideConfig = overridesFile
+	}
+	limits.PerTenantOverridePeriod = model.Duration(time.Hour)
+
+	prometheus.DefaultRegisterer = prometheus.NewRegistry() // have to overwrite the registry or test panics with multiple metric reg
+	o, err := NewOverridesWithAPI(limits, APIConfig{
+		Enabled: true,
+		Backend: "local",
+		Local:   &local.Config{Path: path},
+		Serve:   serve,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, services.StartAndAwaitRunning(context.Background(), o))
+	t.Cleanup(func() {
+		require.NoError(t, services.StopAndAwaitTerminated(context.Background(), o))
+	})
+
+	return o
+}
+
+func doAPIRequest(t *testing.T, o *Overrides, method, tenant, body, ifMatch string) *http.Response {
+	req := httptest.NewRequest(method, "/api/overrides/"+tenant, strings.NewReader(body))
+	req = mux.SetURLVars(req, map[string]string{muxVarTenant: tenant})
+	if ifMatch != "" {
+		req.Header.Set(headerIfMatch, ifMatch)
+	}
+
+	rec := httptest.NewRecorder()
+	o.APIHandler(rec, req)
+	return rec.Result()
+}
+
+func TestAPIOverrides(t *testing.T) {
+	path := t.TempDir()
+	o := newTestAPIOverrides(t, Limits{
+		MaxLocalTracesPerUser: 1,
+		MaxBytesPerTrace:      2,
+	}, &perTenantOverrides{
+		TenantLimits: map[string]*Limits{
+			"file": {
+				MaxLocalTracesPerUser: 3,
+				MaxBytesPerTrace:      4,
+			},
+		},
+	}, path)
+
+	// no overrides yet
+	resp := doAPIRequest(t, o, http.MethodGet, "file", "", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// put overlays the runtime config of the tenant
+	resp = doAPIRequest(t, o, http.MethodPut, "file", `{"max_traces_per_user": 10}`, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get(headerETag)
+	require.NotEmpty(t, etag)
+
+	assert.Equal(t, 10, o.MaxLocalTracesPerUser("file"))
+	assert.Equal(t, 4, o.MaxBytesPerTrace("file"))
+
+	// tenants without runtime config are based on the defaults
+	resp = doAPIRequest(t, o, http.MethodPut, "api", `{"max_bytes_per_trace": 20, "block_retention": "1h"}`, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	assert.Equal(t, 1, o.MaxLocalTracesPerUser("api"))
+	assert.Equal(t, 20, o.MaxBytesPerTrace("api"))
+	assert.Equal(t, time.Hour, o.BlockRetention("api"))
+	assert.Equal(t, 2, o.MaxBytesPerTrace("other"))
+
+	// get returns the stored overrides
+	resp = doAPIRequest(t, o, http.MethodGet, "file", "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, etag, resp.Header.Get(headerETag))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"max_traces_per_user": 10}`, string(body))
+
+	// patch with a stale etag fails
+	resp = doAPIRequest(t, o, http.MethodPatch, "file", `{"max_bytes_per_trace": 11}`, `"stale"`)
+	assert.Equal(t, http.StatusPreconditionFailed, resp.StatusCode)
+
+	// patch sets and removes limits
+	resp = doAPIRequest(t, o, http.MethodPatch, "file", `{"max_bytes_per_trace": 11, "max_traces_per_user": null}`, etag)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEqual(t, etag, resp.Header.Get(headerETag))
+
+	assert.Equal(t, 3, o.MaxLocalTracesPerUser("file"))
+	assert.Equal(t, 11, o.MaxBytesPerTrace("file"))
+
+	// other instances pick up the changes from the index when they load the overrides
+	other := newTestAPIOverridesWithServe(t, Limits{MaxBytesPerTrace: 2}, nil, path, false)
+	assert.Equal(t, 11, other.MaxBytesPerTrace("file"))
+	assert.Equal(t, 20, other.MaxBytesPerTrace("api"))
+
+	// the etag is the generation, writing the same overrides again changes it
+	resp = doAPIRequest(t, o, http.MethodPut, "api", `{"max_bytes_per_trace": 20, "block_retention": "1h"}`, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"2"`, resp.Header.Get(headerETag))
+
+	// delete falls back to the runtime config
+	resp = doAPIRequest(t, o, http.MethodDelete, "file", "", "")
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	assert.Equal(t, 4, o.MaxBytesPerTrace("file"))
+	resp = doAPIRequest(t, o, http.MethodGet, "file", "", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestAPIOverridesInvalid(t *testing.T) {
+	o := newTestAPIOverrides(t, Limits{}, nil, t.TempDir())
+
+	tests := []struct {
+		name   string
+		method string
+		tenant string
+		body   string
+	}{
+		{
+			name:   "unknown limit",
+			method: http.MethodPut,
+			tenant: "test",
+			body:   `{"foo": 1}`,
+		},
+		{
+			name:   "wrong type",
+			method: http.MethodPatch,
+			tenant: "test",
+			body:   `{"max_bytes_per_trace": "foo"}`,
+		},
+		{
+			name:   "global limit",
+			method: http.MethodPut,
+			tenant: "test",
+			body:   `{"ingestion_rate_strategy": "global"}`,
+		},
+		{
+			name:   "invalid span rule",
+			method: http.MethodPut,
+			tenant: "test",
+			body:   `{"ingestion_span_rules": [{"action": "foo"}]}`,
+		},
+		{
+			name:   "not json",
+			method: http.MethodPut,
+			tenant: "test",
+			body:   `max_bytes_per_trace: 1`,
+		},
+		{
+			name:   "invalid tenant",
+			method: http.MethodPut,
+			tenant: "..",
+			body:   `{"max_bytes_per_trace": 1}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := doAPIRequest(t, o, tt.method, tt.tenant, tt.body, "")
+			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		})
+	}
+
+	resp := doAPIRequest(t, o, http.MethodGet, "test", "", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestAPIOverridesConcurrentWrite(t *testing.T) {
+	path := t.TempDir()
+	o := newTestAPIOverrides(t, Limits{}, nil, path)
+
+	resp := doAPIRequest(t, o, http.MethodPut, "test", `{"max_bytes_per_trace": 1}`, "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get(headerETag)
+
+	// another instance writes the same generation while this one writes
+	o.apiOverrides.w = &concurrentWriter{RawWriter: o.apiOverrides.w, other: `{"generation":2,"overrides":{"max_bytes_per_trace":3}}`}
+
+	resp = doAPIRequest(t, o, http.MethodPut, "test", `{"max_bytes_per_trace": 2}`, etag)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	// the write of the other instance is kept
+	resp = doAPIRequest(t, o, http.MethodGet, "test", "", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `"2"`, resp.Header.Get(headerETag))
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"max_bytes_per_trace": 3}`, string(body))
+}
+
+// concurrentWriter writes the document of another instance after every write of a tenant's overrides
+type concurrentWriter struct {
+	backend.RawWriter
+	other string
+}
+
+func (w *concurrentWriter) Write(ctx context.Context, name string, keypath backend.KeyPath, data io.Reader, size int64, shouldCache bool) error {
+	if err := w.RawWriter.Write(ctx, name, keypath, data, size, shouldCache); err != nil {
+		return err
+	}
+	if name != apiOverridesName {
+		return nil
+	}
+	return w.RawWriter.Write(ctx, name, keypath, strings.NewReader(w.other), int64(len(w.other)), shouldCache)
+}
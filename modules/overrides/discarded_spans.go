@@ -16,3 +16,13 @@ var metricDiscardedSpans = promauto.NewCounterVec(prometheus.CounterOpts{
 func RecordDiscardedSpans(spansDiscarded int, reason string, tenant string) {
 	metricDiscardedSpans.WithLabelValues(reason, tenant).Add(float64(spansDiscarded))
 }
+
+var metricTruncatedSpans = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "tempo",
+	Name:      "truncated_spans_total",
+	Help:      "The total number of spans that were truncated to fit the limits of the tenant.",
+}, []string{discardReasonLabel, "tenant"})
+
+func RecordTruncatedSpans(spansTruncated int, reason string, tenant string) {
+	metricTruncatedSpans.WithLabelValues(reason, tenant).Add(float64(spansTruncated))
+}
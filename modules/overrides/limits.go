@@ -2,6 +2,8 @@ package overrides
 
 import (
 	"flag"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -32,6 +34,11 @@ const (
 	MetricBlockRetention            = "block_retention"
 )
 
+// metricLimitNames are the metric names of the limits that differ from their yaml names
+var metricLimitNames = map[string]string{
+	"max_traces_per_user": MetricMaxLocalTracesPerUser,
+}
+
 var (
 	metricLimitsDesc = prometheus.NewDesc(
 		"tempo_limits_defaults",
@@ -45,16 +52,32 @@ var (
 // limits via flags, or per-user limits via yaml config.
 type Limits struct {
 	// Distributor enforced limits.
-	IngestionRateStrategy   string    `yaml:"ingestion_rate_strategy" json:"ingestion_rate_strategy"`
-	IngestionRateLimitBytes int       `yaml:"ingestion_rate_limit_bytes" json:"ingestion_rate_limit_bytes"`
-	IngestionBurstSizeBytes int       `yaml:"ingestion_burst_size_bytes" json:"ingestion_burst_size_bytes"`
-	SearchTagsAllowList     ListToMap `yaml:"search_tags_allow_list" json:"search_tags_allow_list"`
+	IngestionRateStrategy   string     `yaml:"ingestion_rate_strategy" json:"ingestion_rate_strategy"`
+	IngestionRateLimitBytes int        `yaml:"ingestion_rate_limit_bytes" json:"ingestion_rate_limit_bytes"`
+	IngestionBurstSizeBytes int        `yaml:"ingestion_burst_size_bytes" json:"ingestion_burst_size_bytes"`
+	IngestionRateLimitSpans int        `yaml:"ingestion_rate_limit_spans" json:"ingestion_rate_limit_spans"`
+	IngestionBurstSizeSpans int        `yaml:"ingestion_burst_size_spans" json:"ingestion_burst_size_spans"`
+	SearchTagsAllowList     ListToMap  `yaml:"search_tags_allow_list" json:"search_tags_allow_list"`
+	IngestionSpanRules      []SpanRule `yaml:"ingestion_span_rules" json:"ingestion_span_rules"`
+	MaxAttributeValueLength int        `yaml:"max_attribute_value_length" json:"max_attribute_value_length"`
+	MaxAttributesPerSpan    int        `yaml:"max_attributes_per_span" json:"max_attributes_per_span"`
+	MaxEventsPerSpan        int        `yaml:"max_events_per_span" json:"max_events_per_span"`
+	MaxSpansPerTrace        int        `yaml:"max_spans_per_trace" json:"max_spans_per_trace"`
+
+	IngestionServiceRateLimitBytes int `yaml:"ingestion_service_rate_limit_bytes" json:"ingestion_service_rate_limit_bytes"`
+	IngestionServiceBurstSizeBytes int `yaml:"ingestion_service_burst_size_bytes" json:"ingestion_service_burst_size_bytes"`
 
 	// Ingester enforced limits.
 	MaxLocalTracesPerUser  int `yaml:"max_traces_per_user" json:"max_traces_per_user"`
 	MaxGlobalTracesPerUser int `yaml:"max_global_traces_per_user" json:"max_global_traces_per_user"`
 	MaxSearchBytesPerTrace int `yaml:"max_search_bytes_per_trace" json:"max_search_bytes_per_trace"`
 
+	// Ingester block cutting. Zero values use the ingester config.
+	IngesterTraceIdlePeriod      model.Duration `yaml:"ingester_trace_idle_period" json:"ingester_trace_idle_period"`
+	IngesterMaxBlockDuration     model.Duration `yaml:"ingester_max_block_duration" json:"ingester_max_block_duration"`
+	IngesterMaxBlockBytes        uint64         `yaml:"ingester_max_block_bytes" json:"ingester_max_block_bytes"`
+	IngesterCompleteBlockTimeout model.Duration `yaml:"ingester_complete_block_timeout" json:"ingester_complete_block_timeout"`
+
 	// Metrics-generator config
 	MetricsGeneratorRingSize                               int           `yaml:"metrics_generator_ring_size" json:"metrics_generator_ring_size"`
 	MetricsGeneratorProcessors                             ListToMap     `yaml:"metrics_generator_processors" json:"metrics_generator_processors"`
@@ -75,7 +98,9 @@ type Limits struct {
 	MaxBytesPerTagValuesQuery int `yaml:"max_bytes_per_tag_values_query" json:"max_bytes_per_tag_values_query"`
 
 	// QueryFrontend enforced limits
-	MaxSearchDuration model.Duration `yaml:"max_search_duration" json:"max_search_duration"`
+	MaxSearchDuration        model.Duration `yaml:"max_search_duration" json:"max_search_duration"`
+	MaxQueriersPerTenant     int            `yaml:"max_queriers_per_tenant" json:"max_queriers_per_tenant"`
+	MaxInflightJobsPerTenant int            `yaml:"max_inflight_jobs_per_tenant" json:"max_inflight_jobs_per_tenant"`
 
 	// MaxBytesPerTrace is enforced in the Ingester, Compactor, Querier (Search) and Serverless (Search). It
 	//  it not enforce currently when doing a trace by id lookup.
@@ -92,6 +117,14 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.StringVar(&l.IngestionRateStrategy, "distributor.rate-limit-strategy", "local", "Whether the various ingestion rate limits should be applied individually to each distributor instance (local), or evenly shared across the cluster (global).")
 	f.IntVar(&l.IngestionRateLimitBytes, "distributor.ingestion-rate-limit-bytes", 15e6, "Per-user ingestion rate limit in bytes per second.")
 	f.IntVar(&l.IngestionBurstSizeBytes, "distributor.ingestion-burst-size-bytes", 20e6, "Per-user ingestion burst size in bytes. Should be set to the expected size (in bytes) of a single push request.")
+	f.IntVar(&l.IngestionRateLimitSpans, "distributor.ingestion-rate-limit-spans", 0, "Per-user ingestion rate limit in spans per second. 0 to disable.")
+	f.IntVar(&l.IngestionBurstSizeSpans, "distributor.ingestion-burst-size-spans", 0, "Per-user ingestion burst size in spans. Defaults to the spans rate limit if 0.")
+	f.IntVar(&l.IngestionServiceRateLimitBytes, "distributor.ingestion-service-rate-limit-bytes", 0, "Per-service ingestion rate limit in bytes per second within a tenant. Services are identified by the service.name resource attribute. 0 to disable.")
+	f.IntVar(&l.IngestionServiceBurstSizeBytes, "distributor.ingestion-service-burst-size-bytes", 0, "Per-service ingestion burst size in bytes within a tenant. Defaults to the per-service rate limit if 0.")
+	f.IntVar(&l.MaxAttributeValueLength, "distributor.max-attribute-value-length", 0, "Maximum length of string attribute values. Longer values are truncated. 0 to disable.")
+	f.IntVar(&l.MaxAttributesPerSpan, "distributor.max-attributes-per-span", 0, "Maximum number of attributes per span. Additional attributes are dropped. 0 to disable.")
+	f.IntVar(&l.MaxEventsPerSpan, "distributor.max-events-per-span", 0, "Maximum number of events per span. Additional events are dropped. 0 to disable.")
+	f.IntVar(&l.MaxSpansPerTrace, "distributor.max-spans-per-trace", 0, "Maximum number of spans per trace in a single push request. Additional spans are discarded. 0 to disable.")
 
 	// Ingester limits
 	f.IntVar(&l.MaxLocalTracesPerUser, "ingester.max-traces-per-user", 10e3, "Maximum number of active traces per user, per ingester. 0 to disable.")
@@ -99,6 +132,10 @@ func (l *Limits) RegisterFlags(f *flag.FlagSet) {
 	f.IntVar(&l.MaxBytesPerTrace, "ingester.max-bytes-per-trace", 50e5, "Maximum size of a trace in bytes.  0 to disable.")
 	f.IntVar(&l.MaxSearchBytesPerTrace, "ingester.max-search-bytes-per-trace", 5e3, "Maximum size of search data per trace in bytes.  0 to disable.")
 
+	// Query frontend limits
+	f.IntVar(&l.MaxQueriersPerTenant, "frontend.max-queriers-per-tenant", 0, "Maximum number of queriers that can handle requests for a single tenant. 0 to disable shuffle sharding of queriers.")
+	f.IntVar(&l.MaxInflightJobsPerTenant, "frontend.max-inflight-jobs-per-tenant", 0, "Maximum number of jobs of a single tenant that can be processed by queriers at the same time. 0 to disable.")
+
 	// Querier limits
 	f.IntVar(&l.MaxBytesPerTagValuesQuery, "querier.max-bytes-per-tag-values-query", 50e5, "Maximum size of response for a tag-values query. Used mainly to limit large the number of values associated with a particular tag")
 
@@ -112,12 +149,34 @@ func (l *Limits) Describe(ch chan<- *prometheus.Desc) {
 }
 
 func (l *Limits) Collect(ch chan<- prometheus.Metric) {
-	ch <- prometheus.MustNewConstMetric(metricLimitsDesc, prometheus.GaugeValue, float64(l.MaxLocalTracesPerUser), MetricMaxLocalTracesPerUser)
-	ch <- prometheus.MustNewConstMetric(metricLimitsDesc, prometheus.GaugeValue, float64(l.MaxGlobalTracesPerUser), MetricMaxGlobalTracesPerUser)
-	ch <- prometheus.MustNewConstMetric(metricLimitsDesc, prometheus.GaugeValue, float64(l.MaxBytesPerTrace), MetricMaxBytesPerTrace)
-	ch <- prometheus.MustNewConstMetric(metricLimitsDesc, prometheus.GaugeValue, float64(l.MaxSearchBytesPerTrace), MetricMaxSearchBytesPerTrace)
-	ch <- prometheus.MustNewConstMetric(metricLimitsDesc, prometheus.GaugeValue, float64(l.MaxBytesPerTagValuesQuery), MetricMaxBytesPerTagValuesQuery)
-	ch <- prometheus.MustNewConstMetric(metricLimitsDesc, prometheus.GaugeValue, float64(l.IngestionRateLimitBytes), MetricIngestionRateLimitBytes)
-	ch <- prometheus.MustNewConstMetric(metricLimitsDesc, prometheus.GaugeValue, float64(l.IngestionBurstSizeBytes), MetricIngestionBurstSizeBytes)
-	ch <- prometheus.MustNewConstMetric(metricLimitsDesc, prometheus.GaugeValue, float64(l.BlockRetention), MetricBlockRetention)
+	l.forEachNumericLimit(func(name string, value float64) {
+		ch <- prometheus.MustNewConstMetric(metricLimitsDesc, prometheus.GaugeValue, value, name)
+	})
+}
+
+// forEachNumericLimit calls fn with the metric name and the value of every numeric limit. The metric name
+// is the yaml name of the limit. Durations are exported in nanoseconds.
+func (l *Limits) forEachNumericLimit(fn func(name string, value float64)) {
+	v := reflect.ValueOf(l).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" || name == "per_tenant_override_period" {
+			continue
+		}
+		if metricName, ok := metricLimitNames[name]; ok {
+			name = metricName
+		}
+
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fn(name, float64(f.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			fn(name, float64(f.Uint()))
+		case reflect.Float32, reflect.Float64:
+			fn(name, f.Float())
+		}
+	}
 }
@@ -41,6 +41,10 @@ ingestion_burst_size_bytes: 100_000
 search_tags_allow_list:
 - a
 - b
+ingestion_span_rules:
+- action: drop_span
+  match_attribute: http.target
+  match_value: ^/health$
 
 max_traces_per_user: 1000
 max_global_traces_per_user: 1000
@@ -64,6 +68,9 @@ max_search_duration: 5m
 	"search_tags_allow_list" : [
 	  "a", "b"
 	],
+	"ingestion_span_rules": [
+	  {"action": "drop_span", "match_attribute": "http.target", "match_value": "^/health$"}
+	],
 
 	"max_traces_per_user": 1000,
 	"max_global_traces_per_user": 1000,
@@ -67,6 +67,7 @@ type Overrides struct {
 
 	defaultLimits    *Limits
 	runtimeConfigMgr *runtimeconfig.Manager
+	apiOverrides     *apiOverrides
 
 	// Manager for subservices
 	subservices        *services.Manager
@@ -78,7 +79,14 @@ type Overrides struct {
 // are defaulted to those values.  As such, the last call to NewOverrides will
 // become the new global defaults.
 func NewOverrides(defaults Limits) (*Overrides, error) {
+	return NewOverridesWithAPI(defaults, APIConfig{})
+}
+
+// NewOverridesWithAPI makes a new Overrides that also loads the overrides managed via the overrides API
+// if it is enabled. They are reloaded every PerTenantOverridePeriod.
+func NewOverridesWithAPI(defaults Limits, apiCfg APIConfig) (*Overrides, error) {
 	var manager *runtimeconfig.Manager
+	var api *apiOverrides
 	subservices := []services.Service(nil)
 
 	if defaults.PerTenantOverrideConfig != "" {
@@ -95,8 +103,18 @@ func NewOverrides(defaults Limits) (*Overrides, error) {
 		subservices = append(subservices, runtimeCfgMgr)
 	}
 
+	if apiCfg.Enabled {
+		var err error
+		api, err = newAPIOverrides(apiCfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create overrides API backend %w", err)
+		}
+		subservices = append(subservices, services.NewTimerService(time.Duration(defaults.PerTenantOverridePeriod), api.starting, api.iteration, api.stopping))
+	}
+
 	o := &Overrides{
 		runtimeConfigMgr: manager,
+		apiOverrides:     api,
 		defaultLimits:    &defaults,
 	}
 
@@ -146,7 +164,16 @@ func (o *Overrides) stopping(_ error) error {
 	return nil
 }
 
+// tenantOverrides returns the runtime config merged with the overrides managed via the API
 func (o *Overrides) tenantOverrides() *perTenantOverrides {
+	file := o.fileOverrides()
+	if o.apiOverrides == nil {
+		return file
+	}
+	return o.apiOverrides.merge(file, o.defaultLimits)
+}
+
+func (o *Overrides) fileOverrides() *perTenantOverrides {
 	if o.runtimeConfigMgr == nil {
 		return nil
 	}
@@ -213,6 +240,63 @@ func (o *Overrides) WriteStatusRuntimeConfig(w io.Writer, r *http.Request) error
 	return nil
 }
 
+// WriteStatusOverrides writes the effective limits of the tenant, or of all tenants with overrides if tenantID is
+// empty. With mode=diff only the limits that differ from the defaults are written.
+func (o *Overrides) WriteStatusOverrides(w io.Writer, r *http.Request, tenantID string) error {
+	limits := map[string]*Limits{}
+	if tenantID != "" {
+		limits[tenantID] = o.getOverridesForUser(tenantID)
+	} else if tenantOverrides := o.tenantOverrides(); tenantOverrides != nil {
+		for tenant, l := range tenantOverrides.TenantLimits {
+			if l != nil {
+				limits[tenant] = l
+			}
+		}
+	}
+
+	// settings that can't be set per tenant are left out
+	limitsYaml := func(l *Limits) (map[interface{}]interface{}, error) {
+		m, err := util.YAMLMarshalUnmarshal(l)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range apiOverridesGlobalFields {
+			delete(m, f)
+		}
+		return m, nil
+	}
+
+	defaultsYaml, err := limitsYaml(o.defaultLimits)
+	if err != nil {
+		return err
+	}
+
+	output := map[string]interface{}{}
+	for tenant, l := range limits {
+		tenantYaml, err := limitsYaml(l)
+		if err != nil {
+			return err
+		}
+
+		if r.URL.Query().Get("mode") == "diff" {
+			output[tenant], err = util.DiffConfig(defaultsYaml, tenantYaml)
+			if err != nil {
+				return err
+			}
+		} else {
+			output[tenant] = tenantYaml
+		}
+	}
+
+	out, err := yaml.Marshal(output)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(out)
+	return err
+}
+
 // IngestionRateStrategy returns whether the ingestion rate limit should be individually applied
 // to each distributor instance (local) or evenly shared across the cluster (global).
 func (o *Overrides) IngestionRateStrategy() string {
@@ -243,6 +327,30 @@ func (o *Overrides) MaxSearchBytesPerTrace(userID string) int {
 	return o.getOverridesForUser(userID).MaxSearchBytesPerTrace
 }
 
+// IngesterTraceIdlePeriod is the duration after which a live trace of this tenant is cut to the head block.
+// Zero uses the ingester config.
+func (o *Overrides) IngesterTraceIdlePeriod(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).IngesterTraceIdlePeriod)
+}
+
+// IngesterMaxBlockDuration is the maximum duration the head block of this tenant is appended to before it is cut.
+// Zero uses the ingester config, which is also the upper bound.
+func (o *Overrides) IngesterMaxBlockDuration(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).IngesterMaxBlockDuration)
+}
+
+// IngesterMaxBlockBytes is the maximum size of the head block of this tenant before it is cut. Zero uses the
+// ingester config.
+func (o *Overrides) IngesterMaxBlockBytes(userID string) uint64 {
+	return o.getOverridesForUser(userID).IngesterMaxBlockBytes
+}
+
+// IngesterCompleteBlockTimeout is the duration the ingester keeps the blocks of this tenant after they were flushed.
+// Zero uses the ingester config. Values below the ingester config are raised to it by the ingester.
+func (o *Overrides) IngesterCompleteBlockTimeout(userID string) time.Duration {
+	return time.Duration(o.getOverridesForUser(userID).IngesterCompleteBlockTimeout)
+}
+
 // MaxBytesPerTagValuesQuery returns the maximum size of a response to a tag-values query allowed for a user.
 func (o *Overrides) MaxBytesPerTagValuesQuery(userID string) int {
 	return o.getOverridesForUser(userID).MaxBytesPerTagValuesQuery
@@ -258,11 +366,65 @@ func (o *Overrides) IngestionBurstSizeBytes(userID string) int {
 	return o.getOverridesForUser(userID).IngestionBurstSizeBytes
 }
 
+// IngestionRateLimitSpans is the number of spans per second allowed for this tenant.
+func (o *Overrides) IngestionRateLimitSpans(userID string) float64 {
+	return float64(o.getOverridesForUser(userID).IngestionRateLimitSpans)
+}
+
+// IngestionBurstSizeSpans is the burst size in spans allowed for this tenant. Defaults to the rate limit.
+func (o *Overrides) IngestionBurstSizeSpans(userID string) int {
+	limits := o.getOverridesForUser(userID)
+	if limits.IngestionBurstSizeSpans <= 0 {
+		return limits.IngestionRateLimitSpans
+	}
+	return limits.IngestionBurstSizeSpans
+}
+
+// IngestionServiceRateLimitBytes is the number of bytes per second allowed for each service of this tenant.
+func (o *Overrides) IngestionServiceRateLimitBytes(userID string) float64 {
+	return float64(o.getOverridesForUser(userID).IngestionServiceRateLimitBytes)
+}
+
+// IngestionServiceBurstSizeBytes is the burst size in bytes allowed for each service of this tenant.
+// Defaults to the rate limit.
+func (o *Overrides) IngestionServiceBurstSizeBytes(userID string) int {
+	limits := o.getOverridesForUser(userID)
+	if limits.IngestionServiceBurstSizeBytes <= 0 {
+		return limits.IngestionServiceRateLimitBytes
+	}
+	return limits.IngestionServiceBurstSizeBytes
+}
+
 // SearchTagsAllowList is the list of tags to be extracted for search, for this tenant.
 func (o *Overrides) SearchTagsAllowList(userID string) map[string]struct{} {
 	return o.getOverridesForUser(userID).SearchTagsAllowList.GetMap()
 }
 
+// IngestionSpanRules are the rules applied by the distributor to every incoming span of this tenant.
+func (o *Overrides) IngestionSpanRules(userID string) []SpanRule {
+	return o.getOverridesForUser(userID).IngestionSpanRules
+}
+
+// MaxAttributeValueLength is the maximum length of string attribute values of this tenant.
+func (o *Overrides) MaxAttributeValueLength(userID string) int {
+	return o.getOverridesForUser(userID).MaxAttributeValueLength
+}
+
+// MaxAttributesPerSpan is the maximum number of attributes per span of this tenant.
+func (o *Overrides) MaxAttributesPerSpan(userID string) int {
+	return o.getOverridesForUser(userID).MaxAttributesPerSpan
+}
+
+// MaxEventsPerSpan is the maximum number of events per span of this tenant.
+func (o *Overrides) MaxEventsPerSpan(userID string) int {
+	return o.getOverridesForUser(userID).MaxEventsPerSpan
+}
+
+// MaxSpansPerTrace is the maximum number of spans per trace in a single push of this tenant.
+func (o *Overrides) MaxSpansPerTrace(userID string) int {
+	return o.getOverridesForUser(userID).MaxSpansPerTrace
+}
+
 // MetricsGeneratorRingSize is the desired size of the metrics-generator ring for this tenant.
 // Using shuffle sharding, a tenant can use a smaller ring than the entire ring.
 func (o *Overrides) MetricsGeneratorRingSize(userID string) int {
@@ -336,6 +498,17 @@ func (o *Overrides) MaxSearchDuration(userID string) time.Duration {
 	return time.Duration(o.getOverridesForUser(userID).MaxSearchDuration)
 }
 
+// MaxQueriersPerUser is the number of queriers that can handle requests for this tenant. Zero disables
+// shuffle sharding and allows the tenant to use all queriers.
+func (o *Overrides) MaxQueriersPerUser(userID string) int {
+	return o.getOverridesForUser(userID).MaxQueriersPerTenant
+}
+
+// MaxInflightJobsPerUser is the number of jobs of this tenant that can be processed by queriers at the same time.
+func (o *Overrides) MaxInflightJobsPerUser(userID string) int {
+	return o.getOverridesForUser(userID).MaxInflightJobsPerTenant
+}
+
 func (o *Overrides) getOverridesForUser(userID string) *Limits {
 	if tenantOverrides := o.tenantOverrides(); tenantOverrides != nil {
 		l := tenantOverrides.forUser(userID)
@@ -363,12 +536,12 @@ func (o *Overrides) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	for tenant, limits := range overrides.TenantLimits {
-		ch <- prometheus.MustNewConstMetric(metricOverridesLimitsDesc, prometheus.GaugeValue, float64(limits.MaxLocalTracesPerUser), MetricMaxLocalTracesPerUser, tenant)
-		ch <- prometheus.MustNewConstMetric(metricOverridesLimitsDesc, prometheus.GaugeValue, float64(limits.MaxGlobalTracesPerUser), MetricMaxGlobalTracesPerUser, tenant)
-		ch <- prometheus.MustNewConstMetric(metricOverridesLimitsDesc, prometheus.GaugeValue, float64(limits.MaxBytesPerTrace), MetricMaxBytesPerTrace, tenant)
-		ch <- prometheus.MustNewConstMetric(metricOverridesLimitsDesc, prometheus.GaugeValue, float64(limits.MaxSearchBytesPerTrace), MetricMaxSearchBytesPerTrace, tenant)
-		ch <- prometheus.MustNewConstMetric(metricOverridesLimitsDesc, prometheus.GaugeValue, float64(limits.IngestionRateLimitBytes), MetricIngestionRateLimitBytes, tenant)
-		ch <- prometheus.MustNewConstMetric(metricOverridesLimitsDesc, prometheus.GaugeValue, float64(limits.IngestionBurstSizeBytes), MetricIngestionBurstSizeBytes, tenant)
-		ch <- prometheus.MustNewConstMetric(metricOverridesLimitsDesc, prometheus.GaugeValue, float64(limits.BlockRetention), MetricBlockRetention, tenant)
+		if limits == nil {
+			continue
+		}
+		tenant := tenant
+		limits.forEachNumericLimit(func(name string, value float64) {
+			ch <- prometheus.MustNewConstMetric(metricOverridesLimitsDesc, prometheus.GaugeValue, value, name, tenant)
+		})
 	}
 }
@@ -1,7 +1,9 @@
 package overrides
 
 import (
+	"bytes"
 	"context"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -157,3 +159,122 @@ func TestOverrides(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteStatusOverrides(t *testing.T) {
+	o := newTestAPIOverrides(t, Limits{
+		MaxLocalTracesPerUser: 1,
+		MaxBytesPerTrace:      2,
+	}, &perTenantOverrides{
+		TenantLimits: map[string]*Limits{
+			"user1": {
+				MaxLocalTracesPerUser: 3,
+				MaxBytesPerTrace:      2,
+			},
+			"*": {
+				MaxLocalTracesPerUser: 4,
+				MaxBytesPerTrace:      2,
+			},
+		},
+	}, t.TempDir())
+
+	tests := []struct {
+		name     string
+		tenant   string
+		mode     string
+		expected map[string]map[string]interface{}
+	}{
+		{
+			name: "all tenants",
+			mode: "diff",
+			expected: map[string]map[string]interface{}{
+				"user1": {"max_traces_per_user": 3},
+				"*":     {"max_traces_per_user": 4},
+			},
+		},
+		{
+			name:   "tenant",
+			tenant: "user1",
+			mode:   "diff",
+			expected: map[string]map[string]interface{}{
+				"user1": {"max_traces_per_user": 3},
+			},
+		},
+		{
+			name:   "tenant without overrides gets the wildcard overrides",
+			tenant: "user2",
+			mode:   "diff",
+			expected: map[string]map[string]interface{}{
+				"user2": {"max_traces_per_user": 4},
+			},
+		},
+		{
+			name:   "effective limits",
+			tenant: "user1",
+			expected: map[string]map[string]interface{}{
+				"user1": {"max_traces_per_user": 3, "max_bytes_per_trace": 2},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/status/overrides?mode="+tt.mode, nil)
+			buff := &bytes.Buffer{}
+			require.NoError(t, o.WriteStatusOverrides(buff, req, tt.tenant))
+
+			actual := map[string]map[string]interface{}{}
+			require.NoError(t, yaml.Unmarshal(buff.Bytes(), &actual))
+
+			require.Len(t, actual, len(tt.expected))
+			for tenant, expected := range tt.expected {
+				if tt.mode == "diff" {
+					assert.Equal(t, expected, actual[tenant])
+					continue
+				}
+				for k, v := range expected {
+					assert.Equal(t, v, actual[tenant][k])
+				}
+			}
+		})
+	}
+}
+
+func TestOverridesCollect(t *testing.T) {
+	o := newTestAPIOverrides(t, Limits{}, &perTenantOverrides{
+		TenantLimits: map[string]*Limits{
+			"user1": {
+				MaxLocalTracesPerUser:                                  1,
+				MaxSearchDuration:                                      model.Duration(time.Second),
+				MetricsGeneratorMaxActiveSeries:                        2,
+				MetricsGeneratorProcessorServiceGraphsHistogramBuckets: []float64{1},
+			},
+		},
+	}, t.TempDir())
+
+	reg := prometheus.NewPedanticRegistry()
+	require.NoError(t, reg.Register(o))
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+
+	values := map[string]float64{}
+	for _, m := range families[0].GetMetric() {
+		labels := map[string]string{}
+		for _, l := range m.GetLabel() {
+			labels[l.GetName()] = l.GetValue()
+		}
+		require.Equal(t, "user1", labels["user"])
+		values[labels["limit_name"]] = m.GetGauge().GetValue()
+	}
+
+	assert.Equal(t, 1.0, values[MetricMaxLocalTracesPerUser])
+	assert.Equal(t, float64(time.Second), values["max_search_duration"])
+	assert.Equal(t, 2.0, values["metrics_generator_max_active_series"])
+	assert.Equal(t, 0.0, values[MetricMaxBytesPerTrace])
+
+	// only numeric limits are exported
+	assert.NotContains(t, values, "ingestion_rate_strategy")
+	assert.NotContains(t, values, "metrics_generator_processor_service_graphs_histogram_buckets")
+	assert.NotContains(t, values, "per_tenant_override_period")
+}
@@ -0,0 +1,150 @@
+package overrides
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// SpanRuleDropSpan drops matching spans.
+	SpanRuleDropSpan = "drop_span"
+	// SpanRuleDeleteAttribute removes the attribute.
+	SpanRuleDeleteAttribute = "delete_attribute"
+	// SpanRuleHashAttribute replaces the value of the attribute with its SHA-256 hash.
+	SpanRuleHashAttribute = "hash_attribute"
+	// SpanRuleTruncateAttribute truncates the value of the attribute, or all attributes if none is given, to MaxLength.
+	SpanRuleTruncateAttribute = "truncate_attribute"
+	// SpanRuleRenameAttribute renames the attribute to NewName.
+	SpanRuleRenameAttribute = "rename_attribute"
+)
+
+// SpanRule is a rule the distributor applies to every incoming span of a tenant before the span is
+// forwarded to the ingesters. Rules are applied in order.
+type SpanRule struct {
+	Action string `yaml:"action" json:"action"`
+
+	// The rule only applies to spans that have a span or resource attribute MatchAttribute with a value
+	// matching MatchValue. If MatchValue is empty, any value matches. If MatchAttribute is empty, the rule
+	// applies to all spans.
+	MatchAttribute string `yaml:"match_attribute,omitempty" json:"match_attribute,omitempty"`
+	MatchValue     Regexp `yaml:"match_value,omitempty" json:"match_value,omitempty"`
+
+	// Attribute the rule acts on. Rules without a match are also applied to resource attributes.
+	Attribute string `yaml:"attribute,omitempty" json:"attribute,omitempty"`
+	NewName   string `yaml:"new_name,omitempty" json:"new_name,omitempty"`
+	MaxLength int    `yaml:"max_length,omitempty" json:"max_length,omitempty"`
+}
+
+// Validate returns an error if the rule is not valid.
+func (r *SpanRule) Validate() error {
+	switch r.Action {
+	case SpanRuleDropSpan:
+		if r.MatchAttribute == "" {
+			return fmt.Errorf("span rule %s requires match_attribute", r.Action)
+		}
+	case SpanRuleDeleteAttribute, SpanRuleHashAttribute:
+		if r.Attribute == "" {
+			return fmt.Errorf("span rule %s requires attribute", r.Action)
+		}
+	case SpanRuleTruncateAttribute:
+		if r.MaxLength <= 0 {
+			return fmt.Errorf("span rule %s requires max_length > 0", r.Action)
+		}
+	case SpanRuleRenameAttribute:
+		if r.Attribute == "" || r.NewName == "" {
+			return fmt.Errorf("span rule %s requires attribute and new_name", r.Action)
+		}
+	default:
+		return fmt.Errorf("unknown span rule action %q", r.Action)
+	}
+
+	return nil
+}
+
+// spanRule is used to unmarshal a SpanRule without recursing into its Unmarshal methods.
+type spanRule SpanRule
+
+// UnmarshalYAML implements the Unmarshaler interface of the yaml pkg.
+func (r *SpanRule) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := unmarshal((*spanRule)(r)); err != nil {
+		return err
+	}
+	return r.Validate()
+}
+
+// UnmarshalJSON implements the Unmarshal interface of the json pkg.
+func (r *SpanRule) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, (*spanRule)(r)); err != nil {
+		return err
+	}
+	return r.Validate()
+}
+
+// Regexp is a regular expression that is compiled when unmarshalled. The zero value matches everything.
+type Regexp struct {
+	*regexp.Regexp
+}
+
+var _ yaml.Marshaler = (*Regexp)(nil)
+var _ yaml.Unmarshaler = (*Regexp)(nil)
+var _ json.Marshaler = (*Regexp)(nil)
+var _ json.Unmarshaler = (*Regexp)(nil)
+
+// MatchString reports whether s matches the regular expression.
+func (r Regexp) MatchString(s string) bool {
+	if r.Regexp == nil {
+		return true
+	}
+	return r.Regexp.MatchString(s)
+}
+
+// MarshalYAML implements the Marshal interface of the yaml pkg.
+func (r Regexp) MarshalYAML() (interface{}, error) {
+	if r.Regexp == nil {
+		return nil, nil
+	}
+	return r.String(), nil
+}
+
+// UnmarshalYAML implements the Unmarshaler interface of the yaml pkg.
+func (r *Regexp) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return err
+	}
+	return r.compile(s)
+}
+
+// MarshalJSON implements the Marshal interface of the json pkg.
+func (r Regexp) MarshalJSON() ([]byte, error) {
+	if r.Regexp == nil {
+		return json.Marshal(nil)
+	}
+	return json.Marshal(r.String())
+}
+
+// UnmarshalJSON implements the Unmarshal interface of the json pkg.
+func (r *Regexp) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return r.compile(s)
+}
+
+func (r *Regexp) compile(s string) error {
+	if s == "" {
+		r.Regexp = nil
+		return nil
+	}
+
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return err
+	}
+	r.Regexp = re
+	return nil
+}
@@ -25,6 +25,11 @@ type SearchConfig struct {
 	ExternalEndpoints []string      `yaml:"external_endpoints"`
 	HedgeRequestsAt   time.Duration `yaml:"external_hedge_requests_at"`
 	HedgeRequestsUpTo int           `yaml:"external_hedge_requests_up_to"`
+
+	// adaptive routing to the external endpoints
+	ExternalCPUThreshold           float64       `yaml:"external_cpu_threshold"`
+	ExternalCircuitBreakerFailures uint32        `yaml:"external_circuit_breaker_failures"`
+	ExternalCircuitBreakerTimeout  time.Duration `yaml:"external_circuit_breaker_timeout"`
 }
 
 // RegisterFlagsAndApplyDefaults register flags.
@@ -36,6 +41,9 @@ func (cfg *Config) RegisterFlagsAndApplyDefaults(prefix string, f *flag.FlagSet)
 	cfg.Search.HedgeRequestsAt = 4 * time.Second
 	cfg.Search.HedgeRequestsUpTo = 3
 	cfg.Search.QueryTimeout = 30 * time.Second
+	cfg.Search.ExternalCPUThreshold = 0.8
+	cfg.Search.ExternalCircuitBreakerFailures = 5
+	cfg.Search.ExternalCircuitBreakerTimeout = 30 * time.Second
 	cfg.Worker = worker.Config{
 		MatchMaxConcurrency:   true,
 		MaxConcurrentRequests: cfg.MaxConcurrentQueries,
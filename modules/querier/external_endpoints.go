@@ -0,0 +1,314 @@
+package querier
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/procfs"
+	"github.com/sony/gobreaker"
+
+	"github.com/grafana/tempo/pkg/util/log"
+)
+
+const (
+	// endpointEWMAWeight is the weight of a new sample in the moving averages of the endpoint latency and error rate
+	endpointEWMAWeight = 0.2
+	// minEndpointWeight keeps endpoints with a high error rate in rotation until their circuit breaker opens
+	minEndpointWeight = 0.01
+	// cpuSampleInterval is the minimum time between two samples of the cpu time of the querier
+	cpuSampleInterval = time.Second
+)
+
+var (
+	metricEndpointRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "querier_external_endpoint_requests_total",
+		Help:      "Total number of requests to the external endpoints by result.",
+	}, []string{"endpoint", "result"})
+	metricEndpointCircuitBreakerOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "tempo",
+		Name:      "querier_external_endpoint_circuit_breaker_open",
+		Help:      "A value of 1 indicates the circuit breaker of the external endpoint is open and the endpoint is out of rotation.",
+	}, []string{"endpoint"})
+	metricSearchBlockDispatch = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "querier_search_block_dispatch_total",
+		Help:      "Total number of block searches by where they were executed. fallback is a local search after no external endpoint was available or it failed.",
+	}, []string{"target"})
+)
+
+// externalEndpoint is an external endpoint with its circuit breaker, moving averages of its latency and error rate
+// and the number of requests in flight
+type externalEndpoint struct {
+	url string
+	cb  *gobreaker.TwoStepCircuitBreaker
+
+	// protected by the mutex of externalEndpoints
+	latency   float64
+	errorRate float64
+	samples   int
+	inflight  int
+}
+
+// weight is the relative share of requests the endpoint receives. Fast endpoints without errors receive the
+// most requests. The weight is 0 if the endpoint has no samples yet.
+func (e *externalEndpoint) weight() float64 {
+	if e.samples == 0 {
+		return 0
+	}
+
+	w := (1 - e.errorRate) / e.latency
+	if w < minEndpointWeight {
+		w = minEndpointWeight
+	}
+	return w
+}
+
+// queued divides the weight by the number of requests queued at the endpoint, including the new one. The
+// moving averages only change once requests complete, so without it a burst of requests all goes to the
+// endpoint that was fastest before the burst.
+func (e *externalEndpoint) queued(weight float64) float64 {
+	return weight / float64(1+e.inflight)
+}
+
+// externalEndpoints selects the external endpoint for a block search. Endpoints are weighted by their latency
+// and error rate, and endpoints that keep failing are taken out of rotation by their circuit breaker.
+type externalEndpoints struct {
+	mtx       sync.Mutex
+	endpoints []*externalEndpoint
+	rand      *rand.Rand
+
+	cpu          *cpuMonitor
+	cpuThreshold float64
+}
+
+func newExternalEndpoints(cfg SearchConfig) *externalEndpoints {
+	e := &externalEndpoints{
+		endpoints:    make([]*externalEndpoint, 0, len(cfg.ExternalEndpoints)),
+		rand:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		cpu:          newCPUMonitor(),
+		cpuThreshold: cfg.ExternalCPUThreshold,
+	}
+
+	for _, url := range cfg.ExternalEndpoints {
+		endpoint := &externalEndpoint{url: url}
+
+		if cfg.ExternalCircuitBreakerFailures > 0 {
+			endpoint.cb = gobreaker.NewTwoStepCircuitBreaker(gobreaker.Settings{
+				Name:          url,
+				Timeout:       cfg.ExternalCircuitBreakerTimeout,
+				OnStateChange: circuitBreakerStateChange,
+				ReadyToTrip: func(counts gobreaker.Counts) bool {
+					return counts.ConsecutiveFailures >= cfg.ExternalCircuitBreakerFailures
+				},
+			})
+		}
+		metricEndpointCircuitBreakerOpen.WithLabelValues(url).Set(0)
+
+		e.endpoints = append(e.endpoints, endpoint)
+	}
+
+	return e
+}
+
+func circuitBreakerStateChange(name string, from gobreaker.State, to gobreaker.State) {
+	level.Info(log.Logger).Log("msg", "external endpoint circuit breaker state change", "endpoint", name, "from", from, "to", to)
+
+	open := 0.0
+	if to == gobreaker.StateOpen {
+		open = 1
+	}
+	metricEndpointCircuitBreakerOpen.WithLabelValues(name).Set(open)
+}
+
+// cpuSaturated returns true if the cpu utilization of the querier is above the threshold
+func (e *externalEndpoints) cpuSaturated() bool {
+	if e.cpuThreshold <= 0 {
+		return false
+	}
+	return e.cpu.utilization() >= e.cpuThreshold
+}
+
+// pick selects an endpoint by weighted random selection among the endpoints that are not taken out of rotation
+// by their circuit breaker. Endpoints without samples get the highest weight so they are tried. Weights are
+// reduced by the requests in flight at the endpoint. It returns nil if no endpoint is available.
+func (e *externalEndpoints) pick() *externalEndpoint {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	available := make([]*externalEndpoint, 0, len(e.endpoints))
+	maxWeight := 0.0
+	for _, endpoint := range e.endpoints {
+		if endpoint.cb != nil && endpoint.cb.State() == gobreaker.StateOpen {
+			continue
+		}
+		available = append(available, endpoint)
+
+		if w := endpoint.weight(); w > maxWeight {
+			maxWeight = w
+		}
+	}
+	if len(available) == 0 {
+		return nil
+	}
+	if maxWeight == 0 {
+		maxWeight = 1
+	}
+
+	weights := make([]float64, len(available))
+	total := 0.0
+	for i, endpoint := range available {
+		w := endpoint.weight()
+		if w == 0 {
+			w = maxWeight
+		}
+		w = endpoint.queued(w)
+		weights[i] = w
+		total += w
+	}
+
+	r := e.rand.Float64() * total
+	for i, w := range weights {
+		r -= w
+		if r < 0 {
+			return available[i]
+		}
+	}
+	return available[len(available)-1]
+}
+
+// do executes the request to the endpoint through its circuit breaker and records the latency and result.
+// Requests that are canceled by the caller, or fail with context.Canceled, do not count against the endpoint.
+func (e *externalEndpoints) do(endpoint *externalEndpoint, canceled func() bool, fn func() (interface{}, error)) (interface{}, error) {
+	var (
+		done     func(success bool)
+		halfOpen bool
+	)
+	if endpoint.cb != nil {
+		halfOpen = endpoint.cb.State() == gobreaker.StateHalfOpen
+
+		var err error
+		done, err = endpoint.cb.Allow()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	e.mtx.Lock()
+	endpoint.inflight++
+	e.mtx.Unlock()
+
+	start := time.Now()
+	res, err := fn()
+	duration := time.Since(start)
+
+	e.mtx.Lock()
+	endpoint.inflight--
+	e.mtx.Unlock()
+
+	if err != nil && (errors.Is(err, context.Canceled) || canceled()) {
+		// a canceled request says nothing about the endpoint. Only a canceled probe of a half open breaker is
+		// reported as failed, otherwise the breaker would wait for its result forever.
+		if done != nil && halfOpen {
+			done(false)
+		}
+		return nil, err
+	}
+
+	e.record(endpoint, duration, err)
+	if done != nil {
+		done(err == nil)
+	}
+	return res, err
+}
+
+func (e *externalEndpoints) record(endpoint *externalEndpoint, duration time.Duration, err error) {
+	result := "success"
+	failed := 0.0
+	if err != nil {
+		result = "failure"
+		failed = 1
+	}
+	metricEndpointRequests.WithLabelValues(endpoint.url, result).Inc()
+
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	latency := duration.Seconds()
+	if endpoint.samples == 0 {
+		endpoint.latency = latency
+		endpoint.errorRate = failed
+	} else {
+		endpoint.latency = endpointEWMAWeight*latency + (1-endpointEWMAWeight)*endpoint.latency
+		endpoint.errorRate = endpointEWMAWeight*failed + (1-endpointEWMAWeight)*endpoint.errorRate
+	}
+	if endpoint.latency <= 0 {
+		endpoint.latency = time.Millisecond.Seconds()
+	}
+	endpoint.samples++
+}
+
+// cpuMonitor measures the cpu utilization of the process as the share of GOMAXPROCS cores used since the last
+// sample. It is only available on linux, everywhere else the utilization is 0.
+type cpuMonitor struct {
+	mtx sync.Mutex
+
+	cpuTime func() (float64, error)
+	now     func() time.Time
+
+	lastSample  time.Time
+	lastCPUTime float64
+	current     float64
+}
+
+func newCPUMonitor() *cpuMonitor {
+	return &cpuMonitor{
+		cpuTime: processCPUTime,
+		now:     time.Now,
+	}
+}
+
+func (m *cpuMonitor) utilization() float64 {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	now := m.now()
+	if now.Sub(m.lastSample) < cpuSampleInterval {
+		return m.current
+	}
+
+	cpuTime, err := m.cpuTime()
+	if err != nil {
+		return 0
+	}
+
+	if !m.lastSample.IsZero() {
+		elapsed := now.Sub(m.lastSample).Seconds() * float64(runtime.GOMAXPROCS(0))
+		m.current = (cpuTime - m.lastCPUTime) / elapsed
+	}
+	m.lastSample = now
+	m.lastCPUTime = cpuTime
+
+	return m.current
+}
+
+func processCPUTime() (float64, error) {
+	p, err := procfs.Self()
+	if err != nil {
+		return 0, err
+	}
+
+	stat, err := p.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	return stat.CPUTime(), nil
+}
@@ -0,0 +1,173 @@
+package querier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/sony/gobreaker"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalEndpointsCircuitBreaker(t *testing.T) {
+	e := newExternalEndpoints(SearchConfig{
+		ExternalEndpoints:              []string{"a"},
+		ExternalCircuitBreakerFailures: 2,
+		ExternalCircuitBreakerTimeout:  time.Hour,
+	})
+
+	fail := func() (interface{}, error) { return nil, errors.New("failed") }
+	notCanceled := func() bool { return false }
+
+	endpoint := e.pick()
+	require.NotNil(t, endpoint)
+
+	_, err := e.do(endpoint, notCanceled, fail)
+	require.Error(t, err)
+	require.NotNil(t, e.pick())
+
+	_, err = e.do(endpoint, notCanceled, fail)
+	require.Error(t, err)
+
+	// the endpoint is out of rotation
+	assert.Equal(t, gobreaker.StateOpen, endpoint.cb.State())
+	assert.Nil(t, e.pick())
+
+	_, err = e.do(endpoint, notCanceled, func() (interface{}, error) { return "ok", nil })
+	assert.Equal(t, gobreaker.ErrOpenState, err)
+}
+
+func TestExternalEndpointsCanceledRequestsDontCount(t *testing.T) {
+	e := newExternalEndpoints(SearchConfig{
+		ExternalEndpoints:              []string{"a"},
+		ExternalCircuitBreakerFailures: 1,
+		ExternalCircuitBreakerTimeout:  time.Hour,
+	})
+
+	endpoint := e.pick()
+	_, err := e.do(endpoint, func() bool { return true }, func() (interface{}, error) { return nil, errors.New("canceled") })
+	require.Error(t, err)
+
+	assert.Equal(t, gobreaker.StateClosed, endpoint.cb.State())
+	assert.Equal(t, 0, endpoint.samples)
+
+	// context.Canceled from the request doesn't count either
+	_, err = e.do(endpoint, func() bool { return false }, func() (interface{}, error) {
+		return nil, fmt.Errorf("request failed: %w", context.Canceled)
+	})
+	require.ErrorIs(t, err, context.Canceled)
+
+	assert.Equal(t, gobreaker.StateClosed, endpoint.cb.State())
+	assert.Equal(t, 0, endpoint.samples)
+}
+
+func TestExternalEndpointsCanceledRequestsDontCloseBreaker(t *testing.T) {
+	e := newExternalEndpoints(SearchConfig{
+		ExternalEndpoints:              []string{"a"},
+		ExternalCircuitBreakerFailures: 1,
+		ExternalCircuitBreakerTimeout:  10 * time.Millisecond,
+	})
+	notCanceled := func() bool { return false }
+
+	endpoint := e.pick()
+	_, err := e.do(endpoint, notCanceled, func() (interface{}, error) { return nil, errors.New("failed") })
+	require.Error(t, err)
+	require.Equal(t, gobreaker.StateOpen, endpoint.cb.State())
+
+	// a canceled probe of the half open breaker doesn't close it
+	time.Sleep(20 * time.Millisecond)
+	require.Equal(t, gobreaker.StateHalfOpen, endpoint.cb.State())
+	_, err = e.do(endpoint, notCanceled, func() (interface{}, error) { return nil, context.Canceled })
+	require.Error(t, err)
+	assert.Equal(t, gobreaker.StateOpen, endpoint.cb.State())
+
+	// a successful probe does
+	time.Sleep(20 * time.Millisecond)
+	_, err = e.do(endpoint, notCanceled, func() (interface{}, error) { return "ok", nil })
+	require.NoError(t, err)
+	assert.Equal(t, gobreaker.StateClosed, endpoint.cb.State())
+}
+
+func TestExternalEndpointsPickWeighted(t *testing.T) {
+	e := newExternalEndpoints(SearchConfig{
+		ExternalEndpoints: []string{"fast", "slow", "failing", "new"},
+	})
+
+	e.record(e.endpoints[0], 100*time.Millisecond, nil)
+	e.record(e.endpoints[1], time.Second, nil)
+	for i := 0; i < 20; i++ {
+		e.record(e.endpoints[2], 100*time.Millisecond, errors.New("failed"))
+	}
+
+	picked := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		picked[e.pick().url]++
+	}
+
+	assert.Greater(t, picked["fast"], picked["slow"])
+	assert.Greater(t, picked["slow"], picked["failing"])
+	// endpoints without samples get the highest weight
+	assert.InDelta(t, picked["fast"], picked["new"], 1000)
+}
+
+func TestExternalEndpointsPickQueueDepth(t *testing.T) {
+	e := newExternalEndpoints(SearchConfig{
+		ExternalEndpoints: []string{"busy", "idle"},
+	})
+
+	e.record(e.endpoints[0], 100*time.Millisecond, nil)
+	e.record(e.endpoints[1], 100*time.Millisecond, nil)
+	e.endpoints[0].inflight = 9
+
+	picked := map[string]int{}
+	for i := 0; i < 10000; i++ {
+		picked[e.pick().url]++
+	}
+
+	// the busy endpoint has a tenth of the weight of the idle one
+	assert.InDelta(t, 10000/11, picked["busy"], 300)
+}
+
+func TestCPUMonitor(t *testing.T) {
+	now := time.Unix(0, 0)
+	cpuTime := 0.0
+
+	m := newCPUMonitor()
+	m.now = func() time.Time { return now }
+	m.cpuTime = func() (float64, error) { return cpuTime, nil }
+
+	assert.Equal(t, 0.0, m.utilization())
+
+	// used all cores for half of the time
+	now = now.Add(10 * time.Second)
+	cpuTime = 5 * float64(runtime.GOMAXPROCS(0))
+	assert.InDelta(t, 0.5, m.utilization(), 0.001)
+
+	// samples are cached for a second
+	cpuTime = 100 * float64(runtime.GOMAXPROCS(0))
+	assert.InDelta(t, 0.5, m.utilization(), 0.001)
+
+	m.cpuTime = func() (float64, error) { return 0, errors.New("unavailable") }
+	now = now.Add(10 * time.Second)
+	assert.Equal(t, 0.0, m.utilization())
+}
+
+func TestExternalEndpointsCPUSaturated(t *testing.T) {
+	e := newExternalEndpoints(SearchConfig{ExternalCPUThreshold: 0.8})
+	e.cpu.now = func() time.Time { return time.Unix(0, 0) }
+	e.cpu.current = 0.9
+	e.cpu.lastSample = time.Unix(0, 0)
+	assert.True(t, e.cpuSaturated())
+
+	e.cpu.current = 0.5
+	assert.False(t, e.cpuSaturated())
+
+	// disabled
+	e.cpuThreshold = 0
+	e.cpu.current = 1
+	assert.False(t, e.cpuSaturated())
+}
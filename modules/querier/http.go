@@ -8,7 +8,6 @@ import (
 
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
-	"github.com/gorilla/mux"
 	"github.com/grafana/tempo/pkg/api"
 	"github.com/grafana/tempo/pkg/tempopb"
 	"github.com/opentracing/opentracing-go"
@@ -151,6 +150,8 @@ func (q *Querier) SearchHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (q *Querier) SearchTagsHandler(w http.ResponseWriter, r *http.Request) {
+	isSearchBlock := api.IsSearchBlock(r)
+
 	// Enforce the query timeout while querying backends
 	ctx, cancel := context.WithDeadline(r.Context(), time.Now().Add(q.cfg.Search.QueryTimeout))
 	defer cancel()
@@ -158,16 +159,39 @@ func (q *Querier) SearchTagsHandler(w http.ResponseWriter, r *http.Request) {
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Querier.SearchTagsHandler")
 	defer span.Finish()
 
-	req := &tempopb.SearchTagsRequest{}
+	span.SetTag("isSearchBlock", isSearchBlock)
 
-	resp, err := q.SearchTags(ctx, req)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	var resp *tempopb.SearchTagsResponse
+	if !isSearchBlock {
+		req, err := api.ParseSearchTagsRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp, err = q.SearchTags(ctx, req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		req, err := api.ParseSearchTagsBlockRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		span.SetTag("SearchTagsBlockRequest", req.String())
+
+		resp, err = q.SearchTagsBlock(ctx, req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
 	}
 
 	marshaller := &jsonpb.Marshaler{}
-	err = marshaller.Marshal(w, resp)
+	err := marshaller.Marshal(w, resp)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -176,6 +200,8 @@ func (q *Querier) SearchTagsHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (q *Querier) SearchTagValuesHandler(w http.ResponseWriter, r *http.Request) {
+	isSearchBlock := api.IsSearchBlock(r)
+
 	// Enforce the query timeout while querying backends
 	ctx, cancel := context.WithDeadline(r.Context(), time.Now().Add(q.cfg.Search.QueryTimeout))
 	defer cancel()
@@ -183,24 +209,39 @@ func (q *Querier) SearchTagValuesHandler(w http.ResponseWriter, r *http.Request)
 	span, ctx := opentracing.StartSpanFromContext(ctx, "Querier.SearchTagValuesHandler")
 	defer span.Finish()
 
-	vars := mux.Vars(r)
-	tagName, ok := vars["tagName"]
-	if !ok {
-		http.Error(w, "please provide a tagName", http.StatusBadRequest)
-		return
-	}
-	req := &tempopb.SearchTagValuesRequest{
-		TagName: tagName,
-	}
+	span.SetTag("isSearchBlock", isSearchBlock)
 
-	resp, err := q.SearchTagValues(ctx, req)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	var resp *tempopb.SearchTagValuesResponse
+	if !isSearchBlock {
+		req, err := api.ParseSearchTagValuesRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		resp, err = q.SearchTagValues(ctx, req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		req, err := api.ParseSearchTagValuesBlockRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		span.SetTag("SearchTagValuesBlockRequest", req.String())
+
+		resp, err = q.SearchTagValuesBlock(ctx, req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
 	}
 
 	marshaller := &jsonpb.Marshaler{}
-	err = marshaller.Marshal(w, resp)
+	err := marshaller.Marshal(w, resp)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -5,7 +5,6 @@ import (
 	"context"
 	"fmt"
 	"io/ioutil"
-	"math/rand"
 	"net/http"
 	"sort"
 	"time"
@@ -33,6 +32,7 @@ import (
 	"github.com/grafana/tempo/modules/storage"
 	"github.com/grafana/tempo/pkg/api"
 	"github.com/grafana/tempo/pkg/model/trace"
+	"github.com/grafana/tempo/pkg/tempofb"
 	"github.com/grafana/tempo/pkg/tempopb"
 	"github.com/grafana/tempo/pkg/util"
 	"github.com/grafana/tempo/pkg/util/log"
@@ -69,6 +69,8 @@ type Querier struct {
 	searchClient     *http.Client
 	searchPreferSelf *semaphore.Weighted
 
+	searchExternalEndpoints *externalEndpoints
+
 	subservices        *services.Manager
 	subservicesWatcher *services.FailureWatcher
 }
@@ -97,6 +99,8 @@ func New(cfg Config, clientCfg ingester_client.Config, ring ring.ReadRing, store
 		limits:           limits,
 		searchPreferSelf: semaphore.NewWeighted(int64(cfg.Search.PreferSelf)),
 		searchClient:     http.DefaultClient,
+
+		searchExternalEndpoints: newExternalEndpoints(cfg.Search),
 	}
 
 	//
@@ -316,28 +320,18 @@ func (q *Querier) SearchTags(ctx context.Context, req *tempopb.SearchTagsRequest
 	}
 
 	// Collect only unique values
-	uniqueMap := map[string]struct{}{}
+	uniqueMap := map[string]tempofb.TagInfo{}
 	for _, resp := range lookupResults {
-		for _, res := range resp.response.(*tempopb.SearchTagsResponse).TagNames {
-			uniqueMap[res] = struct{}{}
-		}
+		search.CombineSearchTagsResponse(uniqueMap, resp.response.(*tempopb.SearchTagsResponse))
 	}
 
 	// Extra tags
 	for _, k := range search.GetVirtualTags() {
-		uniqueMap[k] = struct{}{}
+		search.AddTagInfo(uniqueMap, k, search.GetVirtualTagInfo(k))
 	}
 
 	// Final response (sorted)
-	resp := &tempopb.SearchTagsResponse{
-		TagNames: make([]string, 0, len(uniqueMap)),
-	}
-	for k := range uniqueMap {
-		resp.TagNames = append(resp.TagNames, k)
-	}
-	sort.Strings(resp.TagNames)
-
-	return resp, nil
+	return search.GetSearchTagsResponse(uniqueMap), nil
 }
 
 func (q *Querier) SearchTagValues(ctx context.Context, req *tempopb.SearchTagValuesRequest) (*tempopb.SearchTagValuesResponse, error) {
@@ -363,16 +357,16 @@ func (q *Querier) SearchTagValues(ctx context.Context, req *tempopb.SearchTagVal
 	}
 
 	// Collect only unique values
-	uniqueMap := map[string]struct{}{}
+	uniqueMap := map[string]uint32{}
 	for _, resp := range lookupResults {
-		for _, res := range resp.response.(*tempopb.SearchTagValuesResponse).TagValues {
-			uniqueMap[res] = struct{}{}
-		}
+		search.CombineSearchTagValuesResponse(uniqueMap, resp.response.(*tempopb.SearchTagValuesResponse))
 	}
 
 	// Extra values
 	for _, v := range search.GetVirtualTagValues(req.TagName) {
-		uniqueMap[v] = struct{}{}
+		if _, ok := uniqueMap[v]; !ok {
+			uniqueMap[v] = 0
+		}
 	}
 
 	if !util.MapSizeWithinLimit(uniqueMap, tagValuesLimitBytes) {
@@ -382,27 +376,70 @@ func (q *Querier) SearchTagValues(ctx context.Context, req *tempopb.SearchTagVal
 	}
 
 	// Final response (sorted)
-	resp := &tempopb.SearchTagValuesResponse{
-		TagValues: make([]string, 0, len(uniqueMap)),
+	return search.GetSearchTagValuesResponse(uniqueMap), nil
+}
+
+// SearchTagsBlock returns the tags in the search header of the block.
+func (q *Querier) SearchTagsBlock(ctx context.Context, req *tempopb.SearchTagsBlockRequest) (*tempopb.SearchTagsResponse, error) {
+	tenantID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error extracting org id in Querier.SearchTagsBlock")
+	}
+
+	blockID, err := uuid.Parse(req.BlockID)
+	if err != nil {
+		return nil, err
+	}
+
+	uniqueMap := map[string]tempofb.TagInfo{}
+	err = q.store.SearchTags(ctx, tenantID, blockID, uniqueMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading tags in Querier.SearchTagsBlock")
+	}
+
+	return search.GetSearchTagsResponse(uniqueMap), nil
+}
+
+// SearchTagValuesBlock returns the values of the tag in the search header of the block.
+func (q *Querier) SearchTagValuesBlock(ctx context.Context, req *tempopb.SearchTagValuesBlockRequest) (*tempopb.SearchTagValuesResponse, error) {
+	tenantID, err := user.ExtractOrgID(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error extracting org id in Querier.SearchTagValuesBlock")
+	}
+
+	blockID, err := uuid.Parse(req.BlockID)
+	if err != nil {
+		return nil, err
 	}
-	for k := range uniqueMap {
-		resp.TagValues = append(resp.TagValues, k)
+
+	uniqueMap := map[string]uint32{}
+	err = q.store.SearchTagValues(ctx, tenantID, blockID, req.GetSearchReq().GetTagName(), uniqueMap)
+	if err != nil {
+		return nil, errors.Wrap(err, "error reading tag values in Querier.SearchTagValuesBlock")
 	}
-	sort.Strings(resp.TagValues)
 
-	return resp, nil
+	if !util.MapSizeWithinLimit(uniqueMap, q.limits.MaxBytesPerTagValuesQuery(tenantID)) {
+		return &tempopb.SearchTagValuesResponse{
+			TagValues: []string{},
+		}, nil
+	}
+
+	return search.GetSearchTagValuesResponse(uniqueMap), nil
 }
 
 // SearchBlock searches the specified subset of the block for the passed tags.
 func (q *Querier) SearchBlock(ctx context.Context, req *tempopb.SearchBlockRequest) (*tempopb.SearchResponse, error) {
 	// if we have no external configuration always search in the querier
 	if len(q.cfg.Search.ExternalEndpoints) == 0 {
+		metricSearchBlockDispatch.WithLabelValues("local").Inc()
 		return q.internalSearchBlock(ctx, req)
 	}
 
-	// if we have external configuration but there's an open slot locally then search in the querier
-	if q.searchPreferSelf.TryAcquire(1) {
+	// if we have external configuration but there's an open slot locally and the querier is not saturated
+	// then search in the querier
+	if !q.searchExternalEndpoints.cpuSaturated() && q.searchPreferSelf.TryAcquire(1) {
 		defer q.searchPreferSelf.Release(1)
+		metricSearchBlockDispatch.WithLabelValues("local").Inc()
 		return q.internalSearchBlock(ctx, req)
 	}
 
@@ -413,8 +450,28 @@ func (q *Querier) SearchBlock(ctx context.Context, req *tempopb.SearchBlockReque
 	}
 	maxBytes := q.limits.MaxBytesPerTrace(tenantID)
 
-	endpoint := q.cfg.Search.ExternalEndpoints[rand.Intn(len(q.cfg.Search.ExternalEndpoints))]
-	return q.searchExternalEndpoint(ctx, endpoint, maxBytes, req)
+	// all endpoints are out of rotation, search in the querier
+	endpoint := q.searchExternalEndpoints.pick()
+	if endpoint == nil {
+		metricSearchBlockDispatch.WithLabelValues("fallback").Inc()
+		return q.internalSearchBlock(ctx, req)
+	}
+
+	canceled := func() bool { return ctx.Err() != nil }
+	resp, err := q.searchExternalEndpoints.do(endpoint, canceled, func() (interface{}, error) {
+		return q.searchExternalEndpoint(ctx, endpoint.url, maxBytes, req)
+	})
+	if err == nil {
+		metricSearchBlockDispatch.WithLabelValues("external").Inc()
+		return resp.(*tempopb.SearchResponse), nil
+	}
+	if canceled() {
+		return nil, err
+	}
+
+	level.Warn(log.Logger).Log("msg", "external endpoint failed, searching locally", "endpoint", endpoint.url, "err", err)
+	metricSearchBlockDispatch.WithLabelValues("fallback").Inc()
+	return q.internalSearchBlock(ctx, req)
 }
 
 func (q *Querier) internalSearchBlock(ctx context.Context, req *tempopb.SearchBlockRequest) (*tempopb.SearchResponse, error) {
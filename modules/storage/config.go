@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/tempo/tempodb"
 	"github.com/grafana/tempo/tempodb/backend"
 	"github.com/grafana/tempo/tempodb/backend/azure"
+	backend_cache "github.com/grafana/tempo/tempodb/backend/cache"
 	"github.com/grafana/tempo/tempodb/backend/gcs"
 	"github.com/grafana/tempo/tempodb/backend/local"
 	"github.com/grafana/tempo/tempodb/backend/s3"
@@ -80,6 +81,19 @@ func (cfg *Config) RegisterFlagsAndApplyDefaults(prefix string, f *flag.FlagSet)
 	cfg.Trace.BackgroundCache.WriteBackBuffer = 10000
 	cfg.Trace.BackgroundCache.WriteBackGoroutines = 10
 
+	cfg.Trace.DiskCache = &cache.DiskCacheConfig{}
+	f.StringVar(&cfg.Trace.DiskCache.Path, util.PrefixConfig(prefix, "trace.disk_cache.path"), "", "path of the disk cache for blocks. Empty disables the cache.")
+	f.Int64Var(&cfg.Trace.DiskCache.MaxSizeBytes, util.PrefixConfig(prefix, "trace.disk_cache.max_size_bytes"), 10<<30, "maximum size of the disk cache.")
+
+	cfg.Trace.InProcessCache = &cache.LRUConfig{}
+	f.Int64Var(&cfg.Trace.InProcessCache.MaxSizeBytes, util.PrefixConfig(prefix, "trace.in_process_cache.max_size_bytes"), 0, "maximum size of the in-process cache stacked over memcached or redis. 0 disables the cache.")
+
+	cfg.Trace.CacheTTL = &backend_cache.TTLConfig{}
+	f.DurationVar(&cfg.Trace.CacheTTL.Bloom, util.PrefixConfig(prefix, "trace.cache_ttl.bloom"), 0, "time to live of cached bloom filters. 0 never expires them.")
+	f.DurationVar(&cfg.Trace.CacheTTL.Index, util.PrefixConfig(prefix, "trace.cache_ttl.index"), 0, "time to live of cached block indexes. 0 never expires them.")
+	f.DurationVar(&cfg.Trace.CacheTTL.TenantIndex, util.PrefixConfig(prefix, "trace.cache_ttl.tenant_index"), 0, "time to live of cached tenant indexes. 0 does not cache them.")
+	f.DurationVar(&cfg.Trace.CacheTTL.Default, util.PrefixConfig(prefix, "trace.cache_ttl.default"), 0, "time to live of other cached objects. 0 never expires them.")
+
 	cfg.Trace.Pool = &pool.Config{}
 	f.IntVar(&cfg.Trace.Pool.MaxWorkers, util.PrefixConfig(prefix, "trace.pool.max-workers"), 50, "Workers in the worker pool.")
 	f.IntVar(&cfg.Trace.Pool.QueueDepth, util.PrefixConfig(prefix, "trace.pool.queue-depth"), 10000, "Work item queue depth.")
@@ -0,0 +1,378 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/google/uuid"
+	"github.com/grafana/dskit/kv/memberlist"
+	"github.com/grafana/dskit/services"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/tempo/tempodb"
+)
+
+const (
+	// blocklistNotificationsKeyPrefix is followed by the tenant, every tenant has its own key
+	blocklistNotificationsKeyPrefix = "blocklist-notifications/"
+	blocklistNotificationsTimeout   = 5 * time.Second
+
+	// blocklistNotificationsMaxEvents is the maximum number of events per tenant, the ones that expire first
+	// are dropped
+	blocklistNotificationsMaxEvents = 1000
+)
+
+var (
+	metricBlocklistNotificationsSent = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "blocklist_notifications_sent_total",
+		Help:      "Total number of blocklist changes announced to other components.",
+	})
+	metricBlocklistNotificationsReceived = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "blocklist_notifications_received_total",
+		Help:      "Total number of blocklist changes announced by other components and applied to the blocklist.",
+	})
+	metricBlocklistNotificationsFailed = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "blocklist_notifications_failed_total",
+		Help:      "Total number of blocklist changes that failed to be announced.",
+	})
+)
+
+// BlocklistNotificationsCodec is the codec of the blocklist notifications gossiped over memberlist. It must
+// be registered with the memberlist KV.
+var BlocklistNotificationsCodec = blocklistNotificationsCodec{}
+
+// blocklistEvent is a change of the blocklist of a tenant. Events are gossiped until they expire, by then the
+// change is known to all components through polling.
+type blocklistEvent struct {
+	ExpiresAt int64                    `json:"expires_at"`
+	Change    *tempodb.BlocklistChange `json:"change"`
+}
+
+func (e *blocklistEvent) expired(now time.Time) bool {
+	return now.UnixNano() > e.ExpiresAt
+}
+
+// blocklistNotifications is the set of blocklist events of a tenant by their id. It implements
+// memberlist.Mergeable, merging is the union of both sets without the expired events and limited to the
+// blocklistNotificationsMaxEvents events that expire last.
+type blocklistNotifications struct {
+	Events map[string]*blocklistEvent `json:"events"`
+}
+
+func newBlocklistNotifications() *blocklistNotifications {
+	return &blocklistNotifications{Events: map[string]*blocklistEvent{}}
+}
+
+// Merge implements memberlist.Mergeable. A local CAS removes the events that are missing in other, which is
+// how they are pruned. Removed events are no change, they are not gossiped but expire on all components.
+func (n *blocklistNotifications) Merge(other memberlist.Mergeable, localCAS bool) (memberlist.Mergeable, error) {
+	if other == nil {
+		return nil, nil
+	}
+
+	o, ok := other.(*blocklistNotifications)
+	if !ok {
+		return nil, fmt.Errorf("expected *blocklistNotifications, got %T", other)
+	}
+	if o == nil {
+		return nil, nil
+	}
+
+	now := time.Now()
+	n.removeExpired(now)
+
+	if localCAS {
+		for id := range n.Events {
+			if _, ok := o.Events[id]; !ok {
+				delete(n.Events, id)
+			}
+		}
+	}
+
+	change := newBlocklistNotifications()
+	for id, e := range o.Events {
+		if e.expired(now) {
+			continue
+		}
+		if _, ok := n.Events[id]; ok {
+			continue
+		}
+
+		n.Events[id] = e
+		change.Events[id] = e
+	}
+
+	for _, id := range n.limit() {
+		delete(change.Events, id)
+	}
+
+	if len(change.Events) == 0 {
+		return nil, nil
+	}
+	return change, nil
+}
+
+// MergeContent implements memberlist.Mergeable
+func (n *blocklistNotifications) MergeContent() []string {
+	ids := make([]string, 0, len(n.Events))
+	for id := range n.Events {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// RemoveTombstones implements memberlist.Mergeable. Events expire instead of being deleted, so there are no
+// tombstones.
+func (n *blocklistNotifications) RemoveTombstones(_ time.Time) (total, removed int) {
+	return 0, 0
+}
+
+// Clone implements memberlist.Mergeable. Events are never modified, so they are shared.
+func (n *blocklistNotifications) Clone() memberlist.Mergeable {
+	clone := newBlocklistNotifications()
+	for id, e := range n.Events {
+		clone.Events[id] = e
+	}
+	return clone
+}
+
+// removeExpired removes the expired events and returns true if there were any.
+func (n *blocklistNotifications) removeExpired(now time.Time) bool {
+	removed := false
+	for id, e := range n.Events {
+		if e.expired(now) {
+			delete(n.Events, id)
+			removed = true
+		}
+	}
+	return removed
+}
+
+// limit removes the events that expire first beyond blocklistNotificationsMaxEvents and returns their ids.
+// Events are ordered by expiry and id, so all components remove the same events.
+func (n *blocklistNotifications) limit() []string {
+	if len(n.Events) <= blocklistNotificationsMaxEvents {
+		return nil
+	}
+
+	ids := make([]string, 0, len(n.Events))
+	for id := range n.Events {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, b := n.Events[ids[i]], n.Events[ids[j]]
+		if a.ExpiresAt != b.ExpiresAt {
+			return a.ExpiresAt < b.ExpiresAt
+		}
+		return ids[i] < ids[j]
+	})
+
+	removed := ids[:len(ids)-blocklistNotificationsMaxEvents]
+	for _, id := range removed {
+		delete(n.Events, id)
+	}
+	return removed
+}
+
+type blocklistNotificationsCodec struct{}
+
+// CodecID implements codec.Codec
+func (blocklistNotificationsCodec) CodecID() string {
+	return "blocklistNotifications"
+}
+
+// Decode implements codec.Codec
+func (blocklistNotificationsCodec) Decode(b []byte) (interface{}, error) {
+	n := newBlocklistNotifications()
+	err := json.Unmarshal(b, n)
+	if err != nil {
+		return nil, err
+	}
+	if n.Events == nil {
+		n.Events = map[string]*blocklistEvent{}
+	}
+	return n, nil
+}
+
+// Encode implements codec.Codec
+func (blocklistNotificationsCodec) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// blocklistNotifier announces the blocklist changes of this component over memberlist and applies the ones
+// announced by other components to the blocklist of the reader.
+type blocklistNotifier struct {
+	services.Service
+
+	getKV  func() (*memberlist.KV, error)
+	reader tempodb.Reader
+	ttl    time.Duration
+	logger log.Logger
+
+	client *memberlist.Client
+
+	// seen holds the ids of events that have been applied or were sent by this component, until they expire
+	seenMtx sync.Mutex
+	seen    map[string]int64
+}
+
+func newBlocklistNotifier(getKV func() (*memberlist.KV, error), reader tempodb.Reader, ttl time.Duration, logger log.Logger) *blocklistNotifier {
+	n := &blocklistNotifier{
+		getKV:  getKV,
+		reader: reader,
+		ttl:    ttl,
+		logger: logger,
+		seen:   map[string]int64{},
+	}
+
+	n.Service = services.NewBasicService(n.starting, n.running, nil)
+	return n
+}
+
+func (n *blocklistNotifier) starting(_ context.Context) error {
+	kv, err := n.getKV()
+	if err != nil {
+		return fmt.Errorf("failed to initialize memberlist for blocklist notifications: %w", err)
+	}
+
+	n.client, err = memberlist.NewClient(kv, BlocklistNotificationsCodec)
+	if err != nil {
+		return fmt.Errorf("failed to create memberlist client for blocklist notifications: %w", err)
+	}
+
+	return nil
+}
+
+func (n *blocklistNotifier) running(ctx context.Context) error {
+	go n.pruneLoop(ctx)
+
+	n.client.WatchPrefix(ctx, blocklistNotificationsKeyPrefix, func(key string, v interface{}) bool {
+		if notifications, ok := v.(*blocklistNotifications); ok {
+			n.apply(ctx, strings.TrimPrefix(key, blocklistNotificationsKeyPrefix), notifications)
+		}
+		return true
+	})
+
+	return nil
+}
+
+// pruneLoop removes the expired events of all tenants. Events are only removed while merging otherwise,
+// which doesn't happen for tenants without new events.
+func (n *blocklistNotifier) pruneLoop(ctx context.Context) {
+	ticker := time.NewTicker(n.ttl)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			n.prune(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (n *blocklistNotifier) prune(ctx context.Context) {
+	keys, err := n.client.List(ctx, blocklistNotificationsKeyPrefix)
+	if err != nil {
+		level.Error(n.logger).Log("msg", "failed to list blocklist notifications", "err", err)
+		return
+	}
+
+	for _, key := range keys {
+		err = n.client.CAS(ctx, key, func(in interface{}) (out interface{}, retry bool, err error) {
+			notifications, ok := in.(*blocklistNotifications)
+			if !ok || notifications == nil || !notifications.removeExpired(time.Now()) {
+				return nil, false, nil
+			}
+			return notifications, true, nil
+		})
+		if err != nil {
+			level.Error(n.logger).Log("msg", "failed to prune blocklist notifications", "key", key, "err", err)
+		}
+	}
+}
+
+// NotifyBlocklistUpdate implements tempodb.BlocklistNotifier
+func (n *blocklistNotifier) NotifyBlocklistUpdate(tenantID string, change *tempodb.BlocklistChange) {
+	if n.client == nil {
+		return
+	}
+
+	id := uuid.New().String()
+	e := &blocklistEvent{
+		ExpiresAt: time.Now().Add(n.ttl).UnixNano(),
+		Change:    change,
+	}
+
+	// this component already applied the change
+	n.seenMtx.Lock()
+	n.seen[id] = e.ExpiresAt
+	n.seenMtx.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), blocklistNotificationsTimeout)
+	defer cancel()
+
+	err := n.client.CAS(ctx, blocklistNotificationsKeyPrefix+tenantID, func(in interface{}) (out interface{}, retry bool, err error) {
+		notifications, ok := in.(*blocklistNotifications)
+		if !ok || notifications == nil {
+			notifications = newBlocklistNotifications()
+		}
+
+		notifications.removeExpired(time.Now())
+		notifications.Events[id] = e
+		notifications.limit()
+		return notifications, true, nil
+	})
+	if err != nil {
+		metricBlocklistNotificationsFailed.Inc()
+		level.Error(n.logger).Log("msg", "failed to announce blocklist change", "tenant", tenantID, "err", err)
+		return
+	}
+
+	metricBlocklistNotificationsSent.Inc()
+}
+
+// apply applies all events of the tenant that have not been seen yet. Applying reads the metas of new blocks
+// from the backend, so it is done outside of the lock.
+func (n *blocklistNotifier) apply(ctx context.Context, tenantID string, notifications *blocklistNotifications) {
+	now := time.Now()
+
+	var changes []*tempodb.BlocklistChange
+
+	n.seenMtx.Lock()
+	for id, e := range notifications.Events {
+		if e.expired(now) || e.Change == nil {
+			continue
+		}
+		if _, ok := n.seen[id]; ok {
+			continue
+		}
+
+		n.seen[id] = e.ExpiresAt
+		changes = append(changes, e.Change)
+	}
+
+	for id, expiresAt := range n.seen {
+		if now.UnixNano() > expiresAt {
+			delete(n.seen, id)
+		}
+	}
+	n.seenMtx.Unlock()
+
+	for _, change := range changes {
+		n.reader.ApplyBlocklistUpdate(ctx, tenantID, change)
+		metricBlocklistNotificationsReceived.Inc()
+	}
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/tempo/tempodb"
+)
+
+func newTestEvent(expiresAt time.Time) *blocklistEvent {
+	return &blocklistEvent{
+		ExpiresAt: expiresAt.UnixNano(),
+		Change:    &tempodb.BlocklistChange{Add: []uuid.UUID{uuid.New()}},
+	}
+}
+
+func TestBlocklistNotificationsMerge(t *testing.T) {
+	now := time.Now()
+	a, b := newTestEvent(now.Add(time.Hour)), newTestEvent(now.Add(time.Hour))
+	expired := newTestEvent(now.Add(-time.Hour))
+
+	n := newBlocklistNotifications()
+	n.Events["a"] = a
+	n.Events["expired"] = expired
+
+	other := newBlocklistNotifications()
+	other.Events["a"] = a
+	other.Events["b"] = b
+	other.Events["expired2"] = expired
+
+	// only new events are a change, expired events are dropped
+	change, err := n.Merge(other, false)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]*blocklistEvent{"b": b}, change.(*blocklistNotifications).Events)
+	assert.Equal(t, map[string]*blocklistEvent{"a": a, "b": b}, n.Events)
+
+	// merging again is no change
+	change, err = n.Merge(other.Clone(), false)
+	require.NoError(t, err)
+	assert.Nil(t, change)
+	assert.ElementsMatch(t, []string{"a", "b"}, n.MergeContent())
+
+	// a local CAS removes the events missing in it
+	pruned := newBlocklistNotifications()
+	pruned.Events["b"] = b
+	change, err = n.Merge(pruned, true)
+	require.NoError(t, err)
+	assert.Nil(t, change)
+	assert.Equal(t, map[string]*blocklistEvent{"b": b}, n.Events)
+}
+
+func TestBlocklistNotificationsLimit(t *testing.T) {
+	now := time.Now()
+
+	n := newBlocklistNotifications()
+	other := newBlocklistNotifications()
+	for i := 0; i < blocklistNotificationsMaxEvents+10; i++ {
+		other.Events[strconv.Itoa(i)] = newTestEvent(now.Add(time.Hour + time.Duration(i)*time.Second))
+	}
+
+	// the events that expire first are dropped and are no change
+	change, err := n.Merge(other, false)
+	require.NoError(t, err)
+	assert.Len(t, n.Events, blocklistNotificationsMaxEvents)
+	assert.Len(t, change.(*blocklistNotifications).Events, blocklistNotificationsMaxEvents)
+	for i := 0; i < 10; i++ {
+		assert.NotContains(t, n.Events, strconv.Itoa(i))
+	}
+}
+
+func TestBlocklistNotificationsCodec(t *testing.T) {
+	n := newBlocklistNotifications()
+	n.Events["a"] = newTestEvent(time.Now())
+
+	b, err := BlocklistNotificationsCodec.Encode(n)
+	require.NoError(t, err)
+
+	actual, err := BlocklistNotificationsCodec.Decode(b)
+	require.NoError(t, err)
+	assert.Equal(t, n.Events["a"].Change.Add, actual.(*blocklistNotifications).Events["a"].Change.Add)
+}
+
+type mockBlocklistReader struct {
+	tempodb.Reader
+	tenants []string
+	added   []uuid.UUID
+}
+
+func (m *mockBlocklistReader) ApplyBlocklistUpdate(_ context.Context, tenantID string, change *tempodb.BlocklistChange) {
+	m.tenants = append(m.tenants, tenantID)
+	m.added = append(m.added, change.Add...)
+}
+
+func TestBlocklistNotifierApply(t *testing.T) {
+	r := &mockBlocklistReader{}
+	n := newBlocklistNotifier(nil, r, time.Hour, log.NewNopLogger())
+
+	now := time.Now()
+	a, expired := newTestEvent(now.Add(time.Hour)), newTestEvent(now.Add(-time.Hour))
+
+	notifications := newBlocklistNotifications()
+	notifications.Events["a"] = a
+	notifications.Events["expired"] = expired
+	notifications.Events["own"] = newTestEvent(now.Add(time.Hour))
+	n.seen["own"] = now.Add(time.Hour).UnixNano()
+
+	// events are applied once
+	n.apply(context.Background(), "test", notifications)
+	n.apply(context.Background(), "test", notifications)
+	assert.Equal(t, a.Change.Add, r.added)
+	assert.Equal(t, []string{"test"}, r.tenants)
+}
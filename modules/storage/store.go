@@ -2,8 +2,10 @@ package storage
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/go-kit/log"
+	"github.com/grafana/dskit/kv/memberlist"
 	"github.com/grafana/dskit/services"
 
 	"github.com/grafana/tempo/tempodb"
@@ -23,6 +25,8 @@ type store struct {
 
 	cfg Config
 
+	notifier *blocklistNotifier
+
 	tempodb.Reader
 	tempodb.Writer
 	tempodb.Compactor
@@ -46,11 +50,39 @@ func NewStore(cfg Config, logger log.Logger) (Store, error) {
 	return s, nil
 }
 
-func (s *store) starting(_ context.Context) error {
+// NewStoreWithBlocklistNotifications creates a new Tempo Store that announces the blocks it writes, compacts
+// and deletes over memberlist, and applies the ones announced by others to its blocklist.
+func NewStoreWithBlocklistNotifications(cfg Config, getKV func() (*memberlist.KV, error), logger log.Logger) (Store, error) {
+	st, err := NewStore(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	// announced changes are known to everyone after the next poll of the tenant index builders and the
+	// following poll of all other components
+	s := st.(*store)
+	s.notifier = newBlocklistNotifier(getKV, s.Reader, 2*cfg.Trace.BlocklistPoll, logger)
+	s.Writer.EnableBlocklistNotifications(s.notifier)
+
+	return s, nil
+}
+
+func (s *store) starting(ctx context.Context) error {
+	if s.notifier != nil {
+		err := services.StartAndAwaitRunning(ctx, s.notifier)
+		if err != nil {
+			return fmt.Errorf("failed to start blocklist notifications: %w", err)
+		}
+	}
+
 	return nil
 }
 
 func (s *store) stopping(_ error) error {
+	if s.notifier != nil {
+		_ = services.StopAndAwaitTerminated(context.Background(), s.notifier)
+	}
+
 	s.Reader.Shutdown()
 
 	return nil
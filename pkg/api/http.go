@@ -38,6 +38,9 @@ const (
 	urlParamDataEncoding  = "dataEncoding"
 	urlParamVersion       = "version"
 
+	// search tags and tag values
+	muxVarTagName = "tagName"
+
 	// maxBytes (serverless only)
 	urlParamMaxBytes = "maxBytes"
 
@@ -53,6 +56,10 @@ const (
 	PathSearchTags      = "/api/search/tags"
 	PathSearchTagValues = "/api/search/tag/{tagName}/values"
 	PathEcho            = "/api/echo"
+	PathOverrides       = "/api/overrides/{tenant}"
+
+	PathIngesterTenantStatus = "/ingester/tenants/{tenant}/status"
+	PathIngesterTenantTrace  = "/ingester/tenants/{tenant}/traces/{traceID}"
 
 	QueryModeKey       = "mode"
 	QueryModeIngesters = "ingesters"
@@ -270,6 +277,126 @@ func ParseSearchBlockRequest(r *http.Request) (*tempopb.SearchBlockRequest, erro
 	return req, nil
 }
 
+// ParseSearchTagsRequest takes an http.Request and decodes query params to create a tempopb.SearchTagsRequest
+func ParseSearchTagsRequest(r *http.Request) (*tempopb.SearchTagsRequest, error) {
+	start, end, err := parseStartEnd(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tempopb.SearchTagsRequest{
+		Start: start,
+		End:   end,
+	}, nil
+}
+
+// ParseSearchTagValuesRequest takes an http.Request and decodes the tag name and query params to create a
+// tempopb.SearchTagValuesRequest
+func ParseSearchTagValuesRequest(r *http.Request) (*tempopb.SearchTagValuesRequest, error) {
+	vars := mux.Vars(r)
+	tagName, ok := vars[muxVarTagName]
+	if !ok {
+		return nil, errors.New("please provide a tagName")
+	}
+
+	start, end, err := parseStartEnd(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tempopb.SearchTagValuesRequest{
+		TagName: tagName,
+		Start:   start,
+		End:     end,
+	}, nil
+}
+
+// ParseSearchTagsBlockRequest parses all http parameters necessary to read the tags of a block.
+func ParseSearchTagsBlockRequest(r *http.Request) (*tempopb.SearchTagsBlockRequest, error) {
+	searchReq, err := ParseSearchTagsRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// start and end = 0 is NOT fine for a block request
+	if searchReq.End == 0 {
+		return nil, errors.New("start and end required")
+	}
+
+	blockID, err := parseBlockID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tempopb.SearchTagsBlockRequest{
+		SearchReq: searchReq,
+		BlockID:   blockID,
+	}, nil
+}
+
+// ParseSearchTagValuesBlockRequest parses all http parameters necessary to read the values of a tag of a block.
+func ParseSearchTagValuesBlockRequest(r *http.Request) (*tempopb.SearchTagValuesBlockRequest, error) {
+	searchReq, err := ParseSearchTagValuesRequest(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// start and end = 0 is NOT fine for a block request
+	if searchReq.End == 0 {
+		return nil, errors.New("start and end required")
+	}
+
+	blockID, err := parseBlockID(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tempopb.SearchTagValuesBlockRequest{
+		SearchReq: searchReq,
+		BlockID:   blockID,
+	}, nil
+}
+
+// parseStartEnd parses the optional start and end params. If one of them is set, start must be before end.
+func parseStartEnd(r *http.Request) (uint32, uint32, error) {
+	var start, end uint32
+
+	if s, ok := extractQueryParam(r, urlParamStart); ok {
+		v, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid start: %w", err)
+		}
+		start = uint32(v)
+	}
+
+	if s, ok := extractQueryParam(r, urlParamEnd); ok {
+		v, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid end: %w", err)
+		}
+		end = uint32(v)
+	}
+
+	// start and end == 0 is fine
+	if start == 0 && end == 0 {
+		return 0, 0, nil
+	}
+
+	if end <= start {
+		return 0, 0, fmt.Errorf("http parameter start must be before end. received start=%d end=%d", start, end)
+	}
+	return start, end, nil
+}
+
+func parseBlockID(r *http.Request) (string, error) {
+	s := r.URL.Query().Get(urlParamBlockID)
+	blockID, err := uuid.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid blockID: %w", err)
+	}
+	return blockID.String(), nil
+}
+
 // BuildSearchRequest takes a tempopb.SearchRequest and populates the passed http.Request
 // with the appropriate params. If no http.Request is provided a new one is created.
 func BuildSearchRequest(req *http.Request, searchReq *tempopb.SearchRequest) (*http.Request, error) {
@@ -345,6 +472,70 @@ func BuildSearchBlockRequest(req *http.Request, searchReq *tempopb.SearchBlockRe
 	return req, nil
 }
 
+// BuildSearchTagsRequest takes a tempopb.SearchTagsRequest and populates the passed http.Request
+// with the appropriate params. If no http.Request is provided a new one is created.
+func BuildSearchTagsRequest(req *http.Request, searchReq *tempopb.SearchTagsRequest) *http.Request {
+	if req == nil {
+		req = &http.Request{
+			URL: &url.URL{},
+		}
+	}
+
+	if searchReq == nil {
+		return req
+	}
+
+	return setStartEnd(req, searchReq.Start, searchReq.End)
+}
+
+// BuildSearchTagValuesRequest takes a tempopb.SearchTagValuesRequest and populates the passed http.Request
+// with the appropriate params. The tag name is part of the path and is expected to be set on the passed
+// http.Request already. If no http.Request is provided a new one is created.
+func BuildSearchTagValuesRequest(req *http.Request, searchReq *tempopb.SearchTagValuesRequest) *http.Request {
+	if req == nil {
+		req = &http.Request{
+			URL: &url.URL{},
+		}
+	}
+
+	if searchReq == nil {
+		return req
+	}
+
+	return setStartEnd(req, searchReq.Start, searchReq.End)
+}
+
+// BuildSearchTagsBlockRequest takes a tempopb.SearchTagsBlockRequest and populates the passed http.Request
+// with the appropriate params. If no http.Request is provided a new one is created.
+func BuildSearchTagsBlockRequest(req *http.Request, searchReq *tempopb.SearchTagsBlockRequest) *http.Request {
+	req = BuildSearchTagsRequest(req, searchReq.SearchReq)
+	return setBlockID(req, searchReq.BlockID)
+}
+
+// BuildSearchTagValuesBlockRequest takes a tempopb.SearchTagValuesBlockRequest and populates the passed
+// http.Request with the appropriate params. If no http.Request is provided a new one is created.
+func BuildSearchTagValuesBlockRequest(req *http.Request, searchReq *tempopb.SearchTagValuesBlockRequest) *http.Request {
+	req = BuildSearchTagValuesRequest(req, searchReq.SearchReq)
+	return setBlockID(req, searchReq.BlockID)
+}
+
+func setStartEnd(req *http.Request, start, end uint32) *http.Request {
+	q := req.URL.Query()
+	q.Set(urlParamStart, strconv.FormatUint(uint64(start), 10))
+	q.Set(urlParamEnd, strconv.FormatUint(uint64(end), 10))
+	req.URL.RawQuery = q.Encode()
+
+	return req
+}
+
+func setBlockID(req *http.Request, blockID string) *http.Request {
+	q := req.URL.Query()
+	q.Set(urlParamBlockID, blockID)
+	req.URL.RawQuery = q.Encode()
+
+	return req
+}
+
 // AddServerlessParams takes an already existing http.Request and adds maxBytes
 //  to it
 func AddServerlessParams(req *http.Request, maxBytes int) *http.Request {
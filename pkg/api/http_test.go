@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/gorilla/mux"
 	"github.com/grafana/tempo/cmd/tempo-query/tempo"
 	"github.com/grafana/tempo/pkg/tempopb"
 	"github.com/stretchr/testify/assert"
@@ -374,6 +375,95 @@ func TestBuildSearchBlockRequest(t *testing.T) {
 	}
 }
 
+func TestParseSearchTagsBlockRequest(t *testing.T) {
+	tests := []struct {
+		url           string
+		expected      *tempopb.SearchTagsBlockRequest
+		expectedError string
+	}{
+		{
+			url:           "/",
+			expectedError: "start and end required",
+		},
+		{
+			url:           "/?start=20&end=10",
+			expectedError: "http parameter start must be before end. received start=20 end=10",
+		},
+		{
+			url:           "/?start=10&end=20",
+			expectedError: "invalid blockID: invalid UUID length: 0",
+		},
+		{
+			url: "/?start=10&end=20&blockID=b92ec614-3fd7-4299-b6db-f657e7025a9b",
+			expected: &tempopb.SearchTagsBlockRequest{
+				SearchReq: &tempopb.SearchTagsRequest{
+					Start: 10,
+					End:   20,
+				},
+				BlockID: "b92ec614-3fd7-4299-b6db-f657e7025a9b",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		r := httptest.NewRequest("GET", tc.url, nil)
+		actualReq, actualErr := ParseSearchTagsBlockRequest(r)
+
+		if len(tc.expectedError) != 0 {
+			assert.EqualError(t, actualErr, tc.expectedError)
+			assert.Nil(t, actualReq)
+			continue
+		}
+		assert.NoError(t, actualErr)
+		assert.Equal(t, tc.expected, actualReq)
+	}
+}
+
+func TestParseSearchTagValuesRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/?start=10&end=20", nil)
+	_, err := ParseSearchTagValuesRequest(r)
+	assert.EqualError(t, err, "please provide a tagName")
+
+	r = mux.SetURLVars(r, map[string]string{muxVarTagName: "foo"})
+	actual, err := ParseSearchTagValuesRequest(r)
+	require.NoError(t, err)
+	assert.Equal(t, &tempopb.SearchTagValuesRequest{TagName: "foo", Start: 10, End: 20}, actual)
+
+	r = mux.SetURLVars(httptest.NewRequest("GET", "/", nil), map[string]string{muxVarTagName: "foo"})
+	actual, err = ParseSearchTagValuesRequest(r)
+	require.NoError(t, err)
+	assert.Equal(t, &tempopb.SearchTagValuesRequest{TagName: "foo"}, actual)
+
+	r = mux.SetURLVars(httptest.NewRequest("GET", "/?start=10&end=20&blockID=b92ec614-3fd7-4299-b6db-f657e7025a9b", nil), map[string]string{muxVarTagName: "foo"})
+	actualBlock, err := ParseSearchTagValuesBlockRequest(r)
+	require.NoError(t, err)
+	assert.Equal(t, &tempopb.SearchTagValuesBlockRequest{
+		SearchReq: &tempopb.SearchTagValuesRequest{TagName: "foo", Start: 10, End: 20},
+		BlockID:   "b92ec614-3fd7-4299-b6db-f657e7025a9b",
+	}, actualBlock)
+}
+
+func TestBuildSearchTagsBlockRequest(t *testing.T) {
+	req := BuildSearchTagsBlockRequest(httptest.NewRequest("GET", "/api/search/tags", nil), &tempopb.SearchTagsBlockRequest{
+		SearchReq: &tempopb.SearchTagsRequest{
+			Start: 10,
+			End:   20,
+		},
+		BlockID: "b92ec614-3fd7-4299-b6db-f657e7025a9b",
+	})
+	assert.Equal(t, "/api/search/tags?blockID=b92ec614-3fd7-4299-b6db-f657e7025a9b&end=20&start=10", req.URL.String())
+
+	req = BuildSearchTagValuesBlockRequest(httptest.NewRequest("GET", "/api/search/tag/foo/values", nil), &tempopb.SearchTagValuesBlockRequest{
+		SearchReq: &tempopb.SearchTagValuesRequest{
+			TagName: "foo",
+			Start:   10,
+			End:     20,
+		},
+		BlockID: "b92ec614-3fd7-4299-b6db-f657e7025a9b",
+	})
+	assert.Equal(t, "/api/search/tag/foo/values?blockID=b92ec614-3fd7-4299-b6db-f657e7025a9b&end=20&start=10", req.URL.String())
+}
+
 func TestValidateAndSanitizeRequest(t *testing.T) {
 	tests := []struct {
 		httpReq       *http.Request
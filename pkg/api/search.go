@@ -2,8 +2,13 @@ package api
 
 import (
 	"net/http"
+	"regexp"
+	"strings"
 )
 
+// searchTagValuesPathRegexp matches PathSearchTagValues with any tag name and http api prefix
+var searchTagValuesPathRegexp = regexp.MustCompile(`/api/search/tag/[^/]+/values/?$`)
+
 // IsBackendSearch returns true if the request has a start, end and tags parameter and is the /api/search path
 func IsBackendSearch(r *http.Request) bool {
 	q := r.URL.Query()
@@ -17,3 +22,13 @@ func IsSearchBlock(r *http.Request) bool {
 
 	return q.Get(urlParamBlockID) != ""
 }
+
+// IsSearchTags returns true if the request is for the tags endpoint
+func IsSearchTags(r *http.Request) bool {
+	return strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), PathSearchTags)
+}
+
+// IsSearchTagValues returns true if the request is for the tag values endpoint
+func IsSearchTagValues(r *http.Request) bool {
+	return searchTagValuesPathRegexp.MatchString(r.URL.Path)
+}
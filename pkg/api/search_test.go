@@ -27,3 +27,23 @@ func TestIsSearchBlock(t *testing.T) {
 	assert.True(t, IsSearchBlock(httptest.NewRequest("GET", "/querier/api/search?blockID=blerg", nil)))
 	assert.True(t, IsSearchBlock(httptest.NewRequest("GET", "/querier/api/search/?blockID=blerg", nil)))
 }
+
+func TestIsSearchTags(t *testing.T) {
+	assert.False(t, IsSearchTags(httptest.NewRequest("GET", "/api/search", nil)))
+	assert.False(t, IsSearchTags(httptest.NewRequest("GET", "/api/search/tag/foo/values", nil)))
+
+	assert.True(t, IsSearchTags(httptest.NewRequest("GET", "/api/search/tags", nil)))
+	assert.True(t, IsSearchTags(httptest.NewRequest("GET", "/api/search/tags/?start=1&end=2", nil)))
+	assert.True(t, IsSearchTags(httptest.NewRequest("GET", "/querier/api/search/tags?blockID=blerg", nil)))
+	assert.True(t, IsSearchTags(httptest.NewRequest("GET", "/tempo/api/search/tags", nil)))
+}
+
+func TestIsSearchTagValues(t *testing.T) {
+	assert.False(t, IsSearchTagValues(httptest.NewRequest("GET", "/api/search", nil)))
+	assert.False(t, IsSearchTagValues(httptest.NewRequest("GET", "/api/search/tags", nil)))
+
+	assert.True(t, IsSearchTagValues(httptest.NewRequest("GET", "/api/search/tag/foo/values", nil)))
+	assert.True(t, IsSearchTagValues(httptest.NewRequest("GET", "/api/search/tag/foo/values/?start=1&end=2", nil)))
+	assert.True(t, IsSearchTagValues(httptest.NewRequest("GET", "/querier/api/search/tag/service.name/values?blockID=blerg", nil)))
+	assert.True(t, IsSearchTagValues(httptest.NewRequest("GET", "/tempo/api/search/tag/foo/values", nil)))
+}
@@ -0,0 +1,288 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/go-kit/log/level"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const diskCacheTmpSuffix = ".tmp"
+
+// DiskCacheConfig is config to make a DiskCache.
+type DiskCacheConfig struct {
+	Path         string `yaml:"path"`
+	MaxSizeBytes int64  `yaml:"max_size_bytes"`
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet
+func (cfg *DiskCacheConfig) RegisterFlagsWithPrefix(prefix, description string, f *flag.FlagSet) {
+	f.StringVar(&cfg.Path, prefix+"disk-cache.path", "", description+"Directory of the disk cache. Empty disables the cache.")
+	f.Int64Var(&cfg.MaxSizeBytes, prefix+"disk-cache.max-size-bytes", 10<<30, description+"Maximum size of all items in the disk cache.")
+}
+
+type diskCacheEntry struct {
+	file string
+	size int64
+}
+
+// DiskCache is a Cache that stores items as files in a local directory. When the size of all items
+// exceeds the maximum size, the least recently used items are removed. Items are named by the hash of
+// their key, so the cache survives restarts: existing files are added to the cache on startup in the
+// order they were last modified.
+type DiskCache struct {
+	path    string
+	maxSize int64
+	logger  log.Logger
+
+	mtx     sync.Mutex
+	size    int64
+	lru     *list.List // of *diskCacheEntry, most recently used first
+	entries map[string]*list.Element
+
+	requests  prometheus.Counter
+	hits      prometheus.Counter
+	evictions prometheus.Counter
+	sizeBytes prometheus.Gauge
+	items     prometheus.Gauge
+}
+
+// NewDiskCache makes a new DiskCache.
+func NewDiskCache(cfg DiskCacheConfig, name string, reg prometheus.Registerer, logger log.Logger) (*DiskCache, error) {
+	if cfg.MaxSizeBytes <= 0 {
+		return nil, errors.New("disk cache max size must be greater than 0")
+	}
+
+	err := os.MkdirAll(cfg.Path, 0o700)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &DiskCache{
+		path:    cfg.Path,
+		maxSize: cfg.MaxSizeBytes,
+		logger:  logger,
+		lru:     list.New(),
+		entries: map[string]*list.Element{},
+
+		requests: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   "tempo",
+			Name:        "disk_cache_requests_total",
+			Help:        "Total count of keys requested from the disk cache.",
+			ConstLabels: prometheus.Labels{"name": name},
+		}),
+		hits: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   "tempo",
+			Name:        "disk_cache_hits_total",
+			Help:        "Total count of keys found in the disk cache.",
+			ConstLabels: prometheus.Labels{"name": name},
+		}),
+		evictions: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   "tempo",
+			Name:        "disk_cache_evictions_total",
+			Help:        "Total count of items removed from the disk cache to stay below the max size.",
+			ConstLabels: prometheus.Labels{"name": name},
+		}),
+		sizeBytes: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace:   "tempo",
+			Name:        "disk_cache_size_bytes",
+			Help:        "Size of all items in the disk cache.",
+			ConstLabels: prometheus.Labels{"name": name},
+		}),
+		items: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace:   "tempo",
+			Name:        "disk_cache_items",
+			Help:        "Number of items in the disk cache.",
+			ConstLabels: prometheus.Labels{"name": name},
+		}),
+	}
+
+	err = c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Store implements Cache
+func (c *DiskCache) Store(_ context.Context, keys []string, bufs [][]byte) {
+	for i := range keys {
+		size := int64(len(bufs[i]))
+		if size > c.maxSize {
+			continue
+		}
+
+		name := diskCacheName(keys[i])
+		file := c.file(name)
+
+		err := writeFileAtomic(file, bufs[i])
+		if err != nil {
+			_ = level.Error(c.logger).Log("msg", "failed to write disk cache item", "file", file, "err", err)
+			continue
+		}
+
+		c.mtx.Lock()
+		c.add(name, &diskCacheEntry{file: file, size: size})
+		c.mtx.Unlock()
+	}
+}
+
+// Fetch implements Cache
+func (c *DiskCache) Fetch(_ context.Context, keys []string) (found []string, bufs [][]byte, missing []string) {
+	c.requests.Add(float64(len(keys)))
+
+	for _, key := range keys {
+		name := diskCacheName(key)
+
+		c.mtx.Lock()
+		elem, ok := c.entries[name]
+		if ok {
+			c.lru.MoveToFront(elem)
+		}
+		c.mtx.Unlock()
+
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+
+		// the item might have been evicted in the meantime, which is a miss as well
+		buf, err := os.ReadFile(elem.Value.(*diskCacheEntry).file)
+		if err != nil {
+			missing = append(missing, key)
+			continue
+		}
+
+		found = append(found, key)
+		bufs = append(bufs, buf)
+	}
+
+	c.hits.Add(float64(len(found)))
+	return
+}
+
+// Stop implements Cache
+func (c *DiskCache) Stop() {
+}
+
+// add inserts or replaces the entry and evicts the least recently used entries until the cache is
+// below its max size. Must be called with the lock held.
+func (c *DiskCache) add(name string, entry *diskCacheEntry) {
+	if elem, ok := c.entries[name]; ok {
+		c.size -= elem.Value.(*diskCacheEntry).size
+		elem.Value = entry
+		c.lru.MoveToFront(elem)
+	} else {
+		c.entries[name] = c.lru.PushFront(entry)
+	}
+	c.size += entry.size
+
+	for c.size > c.maxSize {
+		elem := c.lru.Back()
+		evicted := elem.Value.(*diskCacheEntry)
+
+		c.lru.Remove(elem)
+		delete(c.entries, filepath.Base(evicted.file))
+		c.size -= evicted.size
+		c.evictions.Inc()
+
+		err := os.Remove(evicted.file)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			_ = level.Error(c.logger).Log("msg", "failed to remove disk cache item", "file", evicted.file, "err", err)
+		}
+	}
+
+	c.sizeBytes.Set(float64(c.size))
+	c.items.Set(float64(len(c.entries)))
+}
+
+// load adds the items already on disk to the cache, oldest first, and removes leftover temporary files.
+func (c *DiskCache) load() error {
+	type item struct {
+		file    string
+		size    int64
+		modTime time.Time
+	}
+	var items []item
+
+	err := filepath.WalkDir(c.path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		if strings.HasSuffix(path, diskCacheTmpSuffix) {
+			return os.Remove(path)
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		items = append(items, item{file: path, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].modTime.Before(items[j].modTime)
+	})
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	for _, it := range items {
+		c.add(filepath.Base(it.file), &diskCacheEntry{file: it.file, size: it.size})
+	}
+
+	return nil
+}
+
+// file returns the path of the item. Items are spread over subdirectories to keep directories small.
+func (c *DiskCache) file(name string) string {
+	return filepath.Join(c.path, name[:2], name)
+}
+
+func diskCacheName(key string) string {
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+// writeFileAtomic writes the file through a temporary file, so readers never see a partial item.
+func writeFileAtomic(file string, buf []byte) error {
+	err := os.MkdirAll(filepath.Dir(file), 0o700)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*"+diskCacheTmpSuffix)
+	if err != nil {
+		return err
+	}
+
+	_, err = tmp.Write(buf)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-kit/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDiskCache(t *testing.T, path string, maxSize int64) *DiskCache {
+	c, err := NewDiskCache(DiskCacheConfig{Path: path, MaxSizeBytes: maxSize}, "test", prometheus.NewRegistry(), log.NewNopLogger())
+	require.NoError(t, err)
+	return c
+}
+
+func TestDiskCache(t *testing.T) {
+	c := newTestDiskCache(t, t.TempDir(), 100)
+	ctx := context.Background()
+
+	c.Store(ctx, []string{"key1", "key2"}, [][]byte{[]byte("data1"), []byte("data2")})
+
+	found, bufs, missing := c.Fetch(ctx, []string{"key1", "miss", "key2"})
+	assert.Equal(t, []string{"key1", "key2"}, found)
+	assert.Equal(t, [][]byte{[]byte("data1"), []byte("data2")}, bufs)
+	assert.Equal(t, []string{"miss"}, missing)
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(c.requests))
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.hits))
+	assert.Equal(t, 10.0, testutil.ToFloat64(c.sizeBytes))
+
+	// replacing an item updates the size
+	c.Store(ctx, []string{"key1"}, [][]byte{[]byte("new")})
+	_, bufs, _ = c.Fetch(ctx, []string{"key1"})
+	assert.Equal(t, [][]byte{[]byte("new")}, bufs)
+	assert.Equal(t, 8.0, testutil.ToFloat64(c.sizeBytes))
+}
+
+func TestDiskCacheEviction(t *testing.T) {
+	c := newTestDiskCache(t, t.TempDir(), 30)
+	ctx := context.Background()
+	buf := make([]byte, 10)
+
+	c.Store(ctx, []string{"a", "b", "c"}, [][]byte{buf, buf, buf})
+
+	// a is used, so b is the least recently used item
+	found, _, _ := c.Fetch(ctx, []string{"a"})
+	require.Len(t, found, 1)
+
+	c.Store(ctx, []string{"d"}, [][]byte{buf})
+
+	found, _, missing := c.Fetch(ctx, []string{"a", "b", "c", "d"})
+	assert.Equal(t, []string{"a", "c", "d"}, found)
+	assert.Equal(t, []string{"b"}, missing)
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.evictions))
+	assert.Equal(t, 30.0, testutil.ToFloat64(c.sizeBytes))
+
+	_, err := os.Stat(c.file(diskCacheName("b")))
+	assert.True(t, os.IsNotExist(err))
+
+	// items larger than the cache are not stored
+	c.Store(ctx, []string{"large"}, [][]byte{make([]byte, 31)})
+	found, _, _ = c.Fetch(ctx, []string{"large"})
+	assert.Empty(t, found)
+}
+
+func TestDiskCacheLoad(t *testing.T) {
+	path := t.TempDir()
+	ctx := context.Background()
+
+	c := newTestDiskCache(t, path, 100)
+	c.Store(ctx, []string{"key"}, [][]byte{[]byte("data")})
+
+	// leftover temporary files are removed
+	tmp := filepath.Join(path, "ab", "ab.1"+diskCacheTmpSuffix)
+	require.NoError(t, os.MkdirAll(filepath.Dir(tmp), 0o700))
+	require.NoError(t, os.WriteFile(tmp, []byte("partial"), 0o600))
+
+	c = newTestDiskCache(t, path, 100)
+	found, bufs, _ := c.Fetch(ctx, []string{"key"})
+	assert.Equal(t, []string{"key"}, found)
+	assert.Equal(t, [][]byte{[]byte("data")}, bufs)
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.items))
+
+	_, err := os.Stat(tmp)
+	assert.True(t, os.IsNotExist(err))
+}
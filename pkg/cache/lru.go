@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"flag"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// LRUConfig is config to make a LRU.
+type LRUConfig struct {
+	MaxSizeBytes int64 `yaml:"max_size_bytes"`
+}
+
+// RegisterFlagsWithPrefix adds the flags required to config this to the given FlagSet
+func (cfg *LRUConfig) RegisterFlagsWithPrefix(prefix, description string, f *flag.FlagSet) {
+	f.Int64Var(&cfg.MaxSizeBytes, prefix+"lru.max-size-bytes", 0, description+"Maximum size of all items in the in-process cache. 0 disables the cache.")
+}
+
+type lruEntry struct {
+	key string
+	buf []byte
+}
+
+// LRU is an in-process Cache bounded by the size of its items. When the size of all items exceeds the
+// maximum size, the least recently used items are removed.
+type LRU struct {
+	maxSize int64
+
+	mtx     sync.Mutex
+	size    int64
+	lru     *list.List // of *lruEntry, most recently used first
+	entries map[string]*list.Element
+
+	requests  prometheus.Counter
+	hits      prometheus.Counter
+	evictions prometheus.Counter
+	sizeBytes prometheus.Gauge
+}
+
+// NewLRU makes a new LRU.
+func NewLRU(cfg LRUConfig, name string, reg prometheus.Registerer) *LRU {
+	return &LRU{
+		maxSize: cfg.MaxSizeBytes,
+		lru:     list.New(),
+		entries: map[string]*list.Element{},
+
+		requests: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   "tempo",
+			Name:        "lru_cache_requests_total",
+			Help:        "Total count of keys requested from the in-process cache.",
+			ConstLabels: prometheus.Labels{"name": name},
+		}),
+		hits: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   "tempo",
+			Name:        "lru_cache_hits_total",
+			Help:        "Total count of keys found in the in-process cache.",
+			ConstLabels: prometheus.Labels{"name": name},
+		}),
+		evictions: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   "tempo",
+			Name:        "lru_cache_evictions_total",
+			Help:        "Total count of items removed from the in-process cache to stay below the max size.",
+			ConstLabels: prometheus.Labels{"name": name},
+		}),
+		sizeBytes: promauto.With(reg).NewGauge(prometheus.GaugeOpts{
+			Namespace:   "tempo",
+			Name:        "lru_cache_size_bytes",
+			Help:        "Size of all items in the in-process cache.",
+			ConstLabels: prometheus.Labels{"name": name},
+		}),
+	}
+}
+
+// Store implements Cache
+func (c *LRU) Store(_ context.Context, keys []string, bufs [][]byte) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for i := range keys {
+		size := int64(len(bufs[i]))
+		if size > c.maxSize {
+			continue
+		}
+
+		if elem, ok := c.entries[keys[i]]; ok {
+			entry := elem.Value.(*lruEntry)
+			c.size += size - int64(len(entry.buf))
+			entry.buf = bufs[i]
+			c.lru.MoveToFront(elem)
+		} else {
+			c.entries[keys[i]] = c.lru.PushFront(&lruEntry{key: keys[i], buf: bufs[i]})
+			c.size += size
+		}
+
+		for c.size > c.maxSize {
+			elem := c.lru.Back()
+			entry := elem.Value.(*lruEntry)
+
+			c.lru.Remove(elem)
+			delete(c.entries, entry.key)
+			c.size -= int64(len(entry.buf))
+			c.evictions.Inc()
+		}
+	}
+
+	c.sizeBytes.Set(float64(c.size))
+}
+
+// Fetch implements Cache
+func (c *LRU) Fetch(_ context.Context, keys []string) (found []string, bufs [][]byte, missing []string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for _, key := range keys {
+		elem, ok := c.entries[key]
+		if !ok {
+			missing = append(missing, key)
+			continue
+		}
+
+		c.lru.MoveToFront(elem)
+		found = append(found, key)
+		bufs = append(bufs, elem.Value.(*lruEntry).buf)
+	}
+
+	c.requests.Add(float64(len(keys)))
+	c.hits.Add(float64(len(found)))
+	return
+}
+
+// Stop implements Cache
+func (c *LRU) Stop() {
+}
@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRU(t *testing.T) {
+	c := NewLRU(LRUConfig{MaxSizeBytes: 30}, "test", prometheus.NewRegistry())
+	ctx := context.Background()
+	buf := make([]byte, 10)
+
+	c.Store(ctx, []string{"a", "b", "c"}, [][]byte{buf, buf, buf})
+
+	// a is used, so b is the least recently used item
+	found, _, missing := c.Fetch(ctx, []string{"a", "miss"})
+	assert.Equal(t, []string{"a"}, found)
+	assert.Equal(t, []string{"miss"}, missing)
+
+	c.Store(ctx, []string{"d"}, [][]byte{buf})
+
+	found, _, missing = c.Fetch(ctx, []string{"a", "b", "c", "d"})
+	assert.Equal(t, []string{"a", "c", "d"}, found)
+	assert.Equal(t, []string{"b"}, missing)
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.evictions))
+	assert.Equal(t, 30.0, testutil.ToFloat64(c.sizeBytes))
+	assert.Equal(t, 6.0, testutil.ToFloat64(c.requests))
+	assert.Equal(t, 4.0, testutil.ToFloat64(c.hits))
+
+	// replacing an item updates the size
+	c.Store(ctx, []string{"a"}, [][]byte{[]byte("new")})
+	_, bufs, _ := c.Fetch(ctx, []string{"a"})
+	assert.Equal(t, [][]byte{[]byte("new")}, bufs)
+	assert.Equal(t, 23.0, testutil.ToFloat64(c.sizeBytes))
+
+	// items larger than the cache are not stored
+	c.Store(ctx, []string{"large"}, [][]byte{make([]byte, 31)})
+	found, _, _ = c.Fetch(ctx, []string{"large"})
+	assert.Empty(t, found)
+}
@@ -0,0 +1,180 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// tieredMagic is stored in front of every value in l1, followed by the expiry. Values without it were not
+// written by a tiered cache and are misses.
+var tieredMagic = []byte("tc01")
+
+const tieredHeaderSize = 4 + 8
+
+// TTLFunc returns the time to live of the item with the given key. 0 means it never expires.
+type TTLFunc func(key string) time.Duration
+
+type tieredCache struct {
+	l1  Cache
+	l2  Cache
+	ttl TTLFunc
+	now func() time.Time
+
+	hits    *prometheus.CounterVec
+	expired prometheus.Counter
+}
+
+// NewTiered returns a Cache that stacks the fast cache l1, usually an in-process LRU, over the larger cache
+// l2, usually memcached or redis. Items are written through to both levels and items only found in l2 are
+// added to l1. l2 may be nil.
+//
+// Every item expires after the TTL returned by ttl for its key. l1 stores the expiry with the value. Values
+// in l2 are stored unchanged, so l2 can be shared with other readers. Items with a TTL are stored in l2 under
+// a key suffixed with the current TTL window instead, which moves to a new key once the window ends. Items
+// in past windows are no longer read and left to the expiration of l2.
+func NewTiered(name string, l1 Cache, l2 Cache, ttl TTLFunc, reg prometheus.Registerer) Cache {
+	return &tieredCache{
+		l1:  l1,
+		l2:  l2,
+		ttl: ttl,
+		now: time.Now,
+
+		hits: promauto.With(reg).NewCounterVec(prometheus.CounterOpts{
+			Namespace:   "tempo",
+			Name:        "tiered_cache_hits_total",
+			Help:        "Total count of keys found per level of the tiered cache.",
+			ConstLabels: prometheus.Labels{"name": name},
+		}, []string{"level"}),
+		expired: promauto.With(reg).NewCounter(prometheus.CounterOpts{
+			Namespace:   "tempo",
+			Name:        "tiered_cache_expired_total",
+			Help:        "Total count of keys found in the tiered cache that were expired.",
+			ConstLabels: prometheus.Labels{"name": name},
+		}),
+	}
+}
+
+// Store implements Cache
+func (c *tieredCache) Store(ctx context.Context, keys []string, bufs [][]byte) {
+	now := c.now()
+
+	values := make([][]byte, len(bufs))
+	l2Keys := make([]string, len(keys))
+	for i := range bufs {
+		var expiry int64
+		l2Keys[i], expiry = c.l2Key(now, keys[i])
+
+		values[i] = wrap(bufs[i], expiry)
+	}
+
+	c.l1.Store(ctx, keys, values)
+	if c.l2 != nil {
+		c.l2.Store(ctx, l2Keys, bufs)
+	}
+}
+
+// Fetch implements Cache
+func (c *tieredCache) Fetch(ctx context.Context, keys []string) (found []string, bufs [][]byte, missing []string) {
+	now := c.now()
+
+	l1Found, l1Values, l1Missing := c.l1.Fetch(ctx, keys)
+	found, bufs, l1Missing = c.unwrap(now, l1Found, l1Values, l1Missing)
+	c.hits.WithLabelValues("l1").Add(float64(len(found)))
+
+	if c.l2 == nil || len(l1Missing) == 0 {
+		return found, bufs, l1Missing
+	}
+
+	l2Keys := make([]string, len(l1Missing))
+	keysByL2Key := make(map[string]string, len(l1Missing))
+	expiries := make(map[string]int64, len(l1Missing))
+	for i, key := range l1Missing {
+		l2Key, expiry := c.l2Key(now, key)
+		l2Keys[i] = l2Key
+		keysByL2Key[l2Key] = key
+		expiries[key] = expiry
+	}
+
+	l2Found, l2Bufs, l2Missing := c.l2.Fetch(ctx, l2Keys)
+	for i := range l2Found {
+		l2Found[i] = keysByL2Key[l2Found[i]]
+	}
+	for i := range l2Missing {
+		l2Missing[i] = keysByL2Key[l2Missing[i]]
+	}
+	c.hits.WithLabelValues("l2").Add(float64(len(l2Found)))
+
+	// promote the values of l2 to l1, they expire at the end of their window
+	if len(l2Found) > 0 {
+		promoteValues := make([][]byte, len(l2Found))
+		for i := range l2Found {
+			promoteValues[i] = wrap(l2Bufs[i], expiries[l2Found[i]])
+		}
+		c.l1.Store(ctx, l2Found, promoteValues)
+	}
+
+	return append(found, l2Found...), append(bufs, l2Bufs...), l2Missing
+}
+
+// Stop implements Cache
+func (c *tieredCache) Stop() {
+	c.l1.Stop()
+	if c.l2 != nil {
+		c.l2.Stop()
+	}
+}
+
+// unwrap removes the header from the values. Expired and invalid values are moved to missing.
+func (c *tieredCache) unwrap(now time.Time, keys []string, values [][]byte, missing []string) ([]string, [][]byte, []string) {
+	found := make([]string, 0, len(keys))
+	bufs := make([][]byte, 0, len(keys))
+
+	for i := range keys {
+		if c.expiredAt(now, values[i]) {
+			c.expired.Inc()
+			missing = append(missing, keys[i])
+			continue
+		}
+
+		found = append(found, keys[i])
+		bufs = append(bufs, values[i][tieredHeaderSize:])
+	}
+
+	return found, bufs, missing
+}
+
+// l2Key returns the key of the item in l2 and its expiry at time now. Items without a TTL never expire and
+// keep their key.
+func (c *tieredCache) l2Key(now time.Time, key string) (string, int64) {
+	ttl := c.ttl(key)
+	if ttl <= 0 {
+		return key, 0
+	}
+
+	window := now.UnixNano() / int64(ttl)
+	return key + ":ttl-" + strconv.FormatInt(window, 10), (window + 1) * int64(ttl)
+}
+
+// wrap returns the value stored in l1 for buf.
+func wrap(buf []byte, expiry int64) []byte {
+	value := make([]byte, tieredHeaderSize+len(buf))
+	copy(value, tieredMagic)
+	binary.BigEndian.PutUint64(value[len(tieredMagic):], uint64(expiry))
+	copy(value[tieredHeaderSize:], buf)
+	return value
+}
+
+func (c *tieredCache) expiredAt(now time.Time, value []byte) bool {
+	if len(value) < tieredHeaderSize || !bytes.Equal(value[:len(tieredMagic)], tieredMagic) {
+		return true
+	}
+
+	expiry := int64(binary.BigEndian.Uint64(value[len(tieredMagic):]))
+	return expiry != 0 && now.UnixNano() > expiry
+}
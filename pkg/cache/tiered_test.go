@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLRU() *LRU {
+	return NewLRU(LRUConfig{MaxSizeBytes: 1000}, "test", prometheus.NewRegistry())
+}
+
+func TestTiered(t *testing.T) {
+	l1, l2 := newTestLRU(), newTestLRU()
+	c := NewTiered("test", l1, l2, func(string) time.Duration { return 0 }, prometheus.NewRegistry()).(*tieredCache)
+	ctx := context.Background()
+
+	// items are written through to both levels
+	c.Store(ctx, []string{"a", "b"}, [][]byte{[]byte("data-a"), []byte("data-b")})
+	found, _, _ := l1.Fetch(ctx, []string{"a", "b"})
+	assert.Len(t, found, 2)
+	// values are stored unchanged in l2
+	found, bufs, _ := l2.Fetch(ctx, []string{"a", "b"})
+	assert.Len(t, found, 2)
+	assert.Equal(t, [][]byte{[]byte("data-a"), []byte("data-b")}, bufs)
+
+	// items only in l2 are found and added to l1
+	l1 = newTestLRU()
+	c.l1 = l1
+	found, bufs, missing := c.Fetch(ctx, []string{"a", "b", "miss"})
+	assert.Equal(t, []string{"a", "b"}, found)
+	assert.Equal(t, [][]byte{[]byte("data-a"), []byte("data-b")}, bufs)
+	assert.Equal(t, []string{"miss"}, missing)
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.hits.WithLabelValues("l2")))
+
+	found, _, _ = c.Fetch(ctx, []string{"a", "b"})
+	assert.Len(t, found, 2)
+	assert.Equal(t, 2.0, testutil.ToFloat64(c.hits.WithLabelValues("l1")))
+
+	// values in l1 not written by a tiered cache are misses
+	l1.Store(ctx, []string{"raw"}, [][]byte{[]byte("not a tiered value")})
+	_, _, missing = c.Fetch(ctx, []string{"raw"})
+	assert.Equal(t, []string{"raw"}, missing)
+
+	// values in l2 written by others are found
+	l2.Store(ctx, []string{"shared"}, [][]byte{[]byte("data-shared")})
+	found, bufs, _ = c.Fetch(ctx, []string{"shared"})
+	assert.Equal(t, []string{"shared"}, found)
+	assert.Equal(t, [][]byte{[]byte("data-shared")}, bufs)
+}
+
+func TestTieredTTL(t *testing.T) {
+	now := time.Unix(1000, 0)
+	ttl := func(key string) time.Duration {
+		if key == "short" {
+			return time.Minute
+		}
+		return 0
+	}
+
+	l2 := newTestLRU()
+	c := NewTiered("test", newTestLRU(), l2, ttl, prometheus.NewRegistry()).(*tieredCache)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	c.Store(ctx, []string{"short", "forever"}, [][]byte{[]byte("1"), []byte("2")})
+
+	found, _, _ := c.Fetch(ctx, []string{"short", "forever"})
+	assert.Equal(t, []string{"short", "forever"}, found)
+
+	// items with a ttl are stored in l2 under the key of their window
+	found, _, missing := l2.Fetch(ctx, []string{"short", "short:ttl-16", "forever"})
+	assert.Equal(t, []string{"short:ttl-16", "forever"}, found)
+	assert.Equal(t, []string{"short"}, missing)
+
+	// expired in l1 and in the next window of l2
+	now = now.Add(time.Hour)
+	found, _, missing = c.Fetch(ctx, []string{"short", "forever"})
+	assert.Equal(t, []string{"forever"}, found)
+	assert.Equal(t, []string{"short"}, missing)
+	assert.Equal(t, 1.0, testutil.ToFloat64(c.expired))
+
+	// items promoted from l2 expire at the end of their window in l1
+	c.l1 = newTestLRU()
+	c.Store(ctx, []string{"short"}, [][]byte{[]byte("1")})
+	c.l1 = newTestLRU()
+	found, _, _ = c.Fetch(ctx, []string{"short"})
+	assert.Equal(t, []string{"short"}, found)
+
+	now = now.Add(time.Minute)
+	c.l2 = nil
+	_, _, missing = c.Fetch(ctx, []string{"short"})
+	assert.Equal(t, []string{"short"}, missing)
+}
@@ -0,0 +1,25 @@
+package queue
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/weaveworks/common/httpgrpc"
+
+	"github.com/grafana/tempo/pkg/api"
+)
+
+// ClassForRequest returns the queue class of the request. Search requests and the per block jobs of the tags
+// and tag values endpoints fan out into many jobs and are scheduled as batch, everything else is interactive.
+func ClassForRequest(req *httpgrpc.HTTPRequest) RequestClass {
+	u, err := url.Parse(req.GetUrl())
+	if err != nil {
+		return ClassInteractive
+	}
+
+	if strings.HasSuffix(u.Path, api.PathSearch) || api.IsSearchBlock(&http.Request{URL: u}) {
+		return ClassBatch
+	}
+	return ClassInteractive
+}
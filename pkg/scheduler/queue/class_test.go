@@ -0,0 +1,31 @@
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/weaveworks/common/httpgrpc"
+)
+
+func TestClassForRequest(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected RequestClass
+	}{
+		{url: "/querier/api/traces/1234", expected: ClassInteractive},
+		{url: "/querier/api/search?start=10&end=20&blockID=b2d1a3c5-4f0e-4f5a-9d1c-0a6e7c2b8f31", expected: ClassBatch},
+		{url: "/querier/api/search?tags=foo%3Dbar", expected: ClassBatch},
+		{url: "/querier/api/search/tags", expected: ClassInteractive},
+		{url: "/querier/api/search/tags?blockID=b2d1a3c5-4f0e-4f5a-9d1c-0a6e7c2b8f31", expected: ClassBatch},
+		{url: "/querier/api/search/tag/service.name/values", expected: ClassInteractive},
+		{url: "/querier/api/search/tag/service.name/values?blockID=b2d1a3c5-4f0e-4f5a-9d1c-0a6e7c2b8f31", expected: ClassBatch},
+		{url: "/querier/api/echo", expected: ClassInteractive},
+		{url: "%zz", expected: ClassInteractive},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.url, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ClassForRequest(&httpgrpc.HTTPRequest{Url: tc.url}))
+		})
+	}
+}
@@ -43,6 +43,26 @@ func FirstUser() UserIndex {
 // Request stored into the queue.
 type Request interface{}
 
+// RequestClass determines which of the user's queues a request is stored in.
+type RequestClass int
+
+const (
+	// ClassInteractive is used for latency sensitive requests, like trace by id lookups.
+	ClassInteractive RequestClass = iota
+	// ClassBatch is used for requests that are part of a large fan out, like backend search jobs.
+	ClassBatch
+)
+
+// UserLimits are user-specific values that control how requests of a user are scheduled. They are passed
+// to each EnqueueRequest, because they can change between calls.
+type UserLimits struct {
+	// MaxQueriers is the number of queriers this user can use (zero or negative = all queriers).
+	MaxQueriers int
+	// MaxInflight is the number of requests of this user that can be handled by queriers at the same
+	// time (zero or negative = unlimited).
+	MaxInflight int
+}
+
 // RequestQueue holds incoming requests in per-user queues. It also assigns each user specified number of queriers,
 // and when querier asks for next request to handle (using GetNextRequestForQuerier), it returns requests
 // in a fair fashion.
@@ -60,9 +80,11 @@ type RequestQueue struct {
 	discardedRequests *prometheus.CounterVec // Per user.
 }
 
-func NewRequestQueue(maxOutstandingPerTenant int, forgetDelay time.Duration, queueLength *prometheus.GaugeVec, discardedRequests *prometheus.CounterVec) *RequestQueue {
+// NewRequestQueue creates a new RequestQueue. interactiveWeight is the number of interactive requests handed
+// out for every batch request while both classes have pending requests.
+func NewRequestQueue(maxOutstandingPerTenant int, interactiveWeight int, forgetDelay time.Duration, queueLength *prometheus.GaugeVec, discardedRequests *prometheus.CounterVec) *RequestQueue {
 	q := &RequestQueue{
-		queues:                  newUserQueues(maxOutstandingPerTenant, forgetDelay),
+		queues:                  newUserQueues(maxOutstandingPerTenant, interactiveWeight, forgetDelay),
 		connectedQuerierWorkers: atomic.NewInt32(0),
 		queueLength:             queueLength,
 		discardedRequests:       discardedRequests,
@@ -74,12 +96,11 @@ func NewRequestQueue(maxOutstandingPerTenant int, forgetDelay time.Duration, que
 	return q
 }
 
-// EnqueueRequest puts the request into the queue. MaxQueries is user-specific value that specifies how many queriers can
-// this user use (zero or negative = all queriers). It is passed to each EnqueueRequest, because it can change
-// between calls.
+// EnqueueRequest puts the request into the user's queue for the given class. Limits are user-specific values
+// that control how many queriers and in-flight requests this user can use.
 //
 // If request is successfully enqueued, successFn is called with the lock held, before any querier can receive the request.
-func (q *RequestQueue) EnqueueRequest(userID string, req Request, maxQueriers int, successFn func()) error {
+func (q *RequestQueue) EnqueueRequest(userID string, req Request, class RequestClass, limits UserLimits, successFn func()) error {
 	q.mtx.Lock()
 	defer q.mtx.Unlock()
 
@@ -87,14 +108,19 @@ func (q *RequestQueue) EnqueueRequest(userID string, req Request, maxQueriers in
 		return ErrStopped
 	}
 
-	queue := q.queues.getOrAddQueue(userID, maxQueriers)
-	if queue == nil {
+	uq := q.queues.getOrAddQueue(userID, limits)
+	if uq == nil {
 		// This can only happen if userID is "".
 		return errors.New("no queue found")
 	}
 
+	if uq.len() >= q.queues.maxUserQueueSize {
+		q.discardedRequests.WithLabelValues(userID).Inc()
+		return ErrTooManyRequests
+	}
+
 	select {
-	case queue <- req:
+	case uq.chanFor(class) <- req:
 		q.queueLength.WithLabelValues(userID).Inc()
 		q.cond.Broadcast()
 		// Call this function while holding a lock. This guarantees that no querier can fetch the request before function returns.
@@ -111,6 +137,8 @@ func (q *RequestQueue) EnqueueRequest(userID string, req Request, maxQueriers in
 // GetNextRequestForQuerier find next user queue and takes the next request off of it. Will block if there are no requests.
 // By passing user index from previous call of this method, querier guarantees that it iterates over all users fairly.
 // If querier finds that request from the user is already expired, it can get a request for the same user by using UserIndex.ReuseLastUser.
+// Interactive requests are preferred over batch requests according to the configured interactive weight.
+// Every request returned by this method counts as in-flight for its user until FinishRequest is called.
 func (q *RequestQueue) GetNextRequestForQuerier(ctx context.Context, last UserIndex, querierID string) (Request, UserIndex, error) {
 	q.mtx.Lock()
 	defer q.mtx.Unlock()
@@ -132,27 +160,23 @@ FindQueue:
 		return nil, last, err
 	}
 
-	for {
-		queue, userID, idx := q.queues.getNextQueueForQuerier(last.last, querierID)
-		last.last = idx
+	for _, class := range q.queues.classesInPriorityOrder() {
+		queue, userID, idx := q.queues.getNextQueueForQuerier(last.last, querierID, class)
 		if queue == nil {
-			break
+			continue
 		}
+		last.last = idx
 
 		// Pick next request from the queue.
-		for {
-			request := <-queue
-			if len(queue) == 0 {
-				q.queues.deleteQueue(userID)
-			}
+		request := <-queue
+		q.queues.requestDequeued(userID, class)
 
-			q.queueLength.WithLabelValues(userID).Dec()
+		q.queueLength.WithLabelValues(userID).Dec()
 
-			// Tell close() we've processed a request.
-			q.cond.Broadcast()
+		// Tell close() we've processed a request.
+		q.cond.Broadcast()
 
-			return request, last, nil
-		}
+		return request, last, nil
 	}
 
 	// There are no unexpired requests, so we can get back
@@ -161,6 +185,18 @@ FindQueue:
 	goto FindQueue
 }
 
+// FinishRequest marks a request of the user returned by GetNextRequestForQuerier as completed, which
+// frees up an in-flight slot for the user.
+func (q *RequestQueue) FinishRequest(userID string) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	if q.queues.requestFinished(userID) {
+		// Queriers may be waiting for this user to drop below its in-flight limit.
+		q.cond.Broadcast()
+	}
+}
+
 func (q *RequestQueue) forgetDisconnectedQueriers(_ context.Context) error {
 	q.mtx.Lock()
 	defer q.mtx.Unlock()
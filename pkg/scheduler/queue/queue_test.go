@@ -0,0 +1,78 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQueue(maxOutstanding int, interactiveWeight int) *RequestQueue {
+	return NewRequestQueue(maxOutstanding, interactiveWeight, 0,
+		prometheus.NewGaugeVec(prometheus.GaugeOpts{}, []string{"user"}),
+		prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"user"}))
+}
+
+func TestQueueInteractiveWeight(t *testing.T) {
+	q := newTestQueue(100, 2)
+	q.RegisterQuerierConnection("querier")
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, q.EnqueueRequest("batch-tenant", "batch", ClassBatch, UserLimits{}, nil))
+	}
+	for i := 0; i < 4; i++ {
+		require.NoError(t, q.EnqueueRequest("interactive-tenant", "interactive", ClassInteractive, UserLimits{}, nil))
+	}
+
+	var actual []Request
+	last := FirstUser()
+	for i := 0; i < 8; i++ {
+		req, idx, err := q.GetNextRequestForQuerier(context.Background(), last, "querier")
+		require.NoError(t, err)
+		last = idx
+		actual = append(actual, req)
+	}
+
+	expected := []Request{"interactive", "interactive", "batch", "interactive", "interactive", "batch", "batch", "batch"}
+	assert.Equal(t, expected, actual)
+}
+
+func TestQueueMaxInflight(t *testing.T) {
+	q := newTestQueue(100, 1)
+	q.RegisterQuerierConnection("querier")
+
+	limits := UserLimits{MaxInflight: 1}
+	require.NoError(t, q.EnqueueRequest("tenant", "first", ClassInteractive, limits, nil))
+	require.NoError(t, q.EnqueueRequest("tenant", "second", ClassInteractive, limits, nil))
+
+	req, last, err := q.GetNextRequestForQuerier(context.Background(), FirstUser(), "querier")
+	require.NoError(t, err)
+	assert.Equal(t, "first", req)
+
+	// the tenant is at its in-flight limit, so the querier has to wait
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		q.QuerierDisconnecting()
+	}()
+	_, _, err = q.GetNextRequestForQuerier(ctx, last, "querier")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	q.FinishRequest("tenant")
+
+	req, _, err = q.GetNextRequestForQuerier(context.Background(), last, "querier")
+	require.NoError(t, err)
+	assert.Equal(t, "second", req)
+}
+
+func TestQueueMaxOutstandingAcrossClasses(t *testing.T) {
+	q := newTestQueue(2, 1)
+
+	require.NoError(t, q.EnqueueRequest("tenant", "a", ClassInteractive, UserLimits{}, nil))
+	require.NoError(t, q.EnqueueRequest("tenant", "b", ClassBatch, UserLimits{}, nil))
+	assert.Equal(t, ErrTooManyRequests, q.EnqueueRequest("tenant", "c", ClassBatch, UserLimits{}, nil))
+}
@@ -32,6 +32,16 @@ type queues struct {
 
 	maxUserQueueSize int
 
+	// Number of interactive requests handed out for every batch request while both classes have pending requests.
+	interactiveWeight int
+
+	// Number of interactive requests handed out since the last batch request.
+	interactiveStreak int
+
+	// Number of requests per user that have been handed out to queriers and are not finished yet. This is
+	// tracked outside of userQueues, because the user queue is deleted once it becomes empty.
+	inflight map[string]int
+
 	// How long to wait before removing a querier which has got disconnected
 	// but hasn't notified about a graceful shutdown.
 	forgetDelay time.Duration
@@ -44,13 +54,17 @@ type queues struct {
 }
 
 type userQueue struct {
-	ch chan Request
+	interactive chan Request
+	batch       chan Request
 
 	// If not nil, only these queriers can handle user requests. If nil, all queriers can.
 	// We set this to nil if number of available queriers <= maxQueriers.
 	queriers    map[string]struct{}
 	maxQueriers int
 
+	// Max number of requests of this user that can be in-flight. Zero means unlimited.
+	maxInflight int
+
 	// Seed for shuffle sharding of queriers. This seed is based on userID only and is therefore consistent
 	// between different frontends.
 	seed int64
@@ -59,17 +73,35 @@ type userQueue struct {
 	index int
 }
 
-func newUserQueues(maxUserQueueSize int, forgetDelay time.Duration) *queues {
+func newUserQueues(maxUserQueueSize int, interactiveWeight int, forgetDelay time.Duration) *queues {
+	if interactiveWeight < 1 {
+		interactiveWeight = 1
+	}
+
 	return &queues{
-		userQueues:       map[string]*userQueue{},
-		users:            nil,
-		maxUserQueueSize: maxUserQueueSize,
-		forgetDelay:      forgetDelay,
-		queriers:         map[string]*querier{},
-		sortedQueriers:   nil,
+		userQueues:        map[string]*userQueue{},
+		users:             nil,
+		maxUserQueueSize:  maxUserQueueSize,
+		interactiveWeight: interactiveWeight,
+		inflight:          map[string]int{},
+		forgetDelay:       forgetDelay,
+		queriers:          map[string]*querier{},
+		sortedQueriers:    nil,
 	}
 }
 
+// len returns the number of pending requests in the user queue, regardless of their class.
+func (uq *userQueue) len() int {
+	return len(uq.interactive) + len(uq.batch)
+}
+
+func (uq *userQueue) chanFor(class RequestClass) chan Request {
+	if class == ClassBatch {
+		return uq.batch
+	}
+	return uq.interactive
+}
+
 func (q *queues) len() int {
 	return len(q.userQueues)
 }
@@ -90,15 +122,16 @@ func (q *queues) deleteQueue(userID string) {
 }
 
 // Returns existing or new queue for user.
-// MaxQueriers is used to compute which queriers should handle requests for this user.
-// If maxQueriers is <= 0, all queriers can handle this user's requests.
-// If maxQueriers has changed since the last call, queriers for this are recomputed.
-func (q *queues) getOrAddQueue(userID string, maxQueriers int) chan Request {
+// limits.MaxQueriers is used to compute which queriers should handle requests for this user.
+// If it is <= 0, all queriers can handle this user's requests.
+// If it has changed since the last call, queriers for this are recomputed.
+func (q *queues) getOrAddQueue(userID string, limits UserLimits) *userQueue {
 	// Empty user is not allowed, as that would break our users list ("" is used for free spot).
 	if userID == "" {
 		return nil
 	}
 
+	maxQueriers := limits.MaxQueriers
 	if maxQueriers < 0 {
 		maxQueriers = 0
 	}
@@ -107,9 +140,10 @@ func (q *queues) getOrAddQueue(userID string, maxQueriers int) chan Request {
 
 	if uq == nil {
 		uq = &userQueue{
-			ch:    make(chan Request, q.maxUserQueueSize),
-			seed:  shard.ShuffleShardSeed(userID, ""),
-			index: -1,
+			interactive: make(chan Request, q.maxUserQueueSize),
+			batch:       make(chan Request, q.maxUserQueueSize),
+			seed:        shard.ShuffleShardSeed(userID, ""),
+			index:       -1,
 		}
 		q.userQueues[userID] = uq
 
@@ -134,13 +168,57 @@ func (q *queues) getOrAddQueue(userID string, maxQueriers int) chan Request {
 		uq.queriers = shuffleQueriersForUser(uq.seed, maxQueriers, q.sortedQueriers, nil)
 	}
 
-	return uq.ch
+	uq.maxInflight = limits.MaxInflight
+	if uq.maxInflight < 0 {
+		uq.maxInflight = 0
+	}
+
+	return uq
 }
 
-// Finds next queue for the querier. To support fair scheduling between users, client is expected
+// classesInPriorityOrder returns the request classes in the order they should be checked for the next request.
+// Interactive requests are preferred until interactiveWeight of them have been handed out in a row.
+func (q *queues) classesInPriorityOrder() []RequestClass {
+	if q.interactiveStreak >= q.interactiveWeight {
+		return []RequestClass{ClassBatch, ClassInteractive}
+	}
+	return []RequestClass{ClassInteractive, ClassBatch}
+}
+
+// requestDequeued updates the bookkeeping after a request of the given class has been taken off the user's queue.
+func (q *queues) requestDequeued(userID string, class RequestClass) {
+	if class == ClassBatch {
+		q.interactiveStreak = 0
+	} else {
+		q.interactiveStreak++
+	}
+
+	q.inflight[userID]++
+
+	if uq := q.userQueues[userID]; uq != nil && uq.len() == 0 {
+		q.deleteQueue(userID)
+	}
+}
+
+// requestFinished releases an in-flight slot of the user. Returns true if the user had any requests in-flight.
+func (q *queues) requestFinished(userID string) bool {
+	n, ok := q.inflight[userID]
+	if !ok {
+		return false
+	}
+
+	if n <= 1 {
+		delete(q.inflight, userID)
+	} else {
+		q.inflight[userID] = n - 1
+	}
+	return true
+}
+
+// Finds next queue of the given class for the querier. To support fair scheduling between users, client is expected
 // to pass last user index returned by this function as argument. Is there was no previous
-// last user index, use -1.
-func (q *queues) getNextQueueForQuerier(lastUserIndex int, querierID string) (chan Request, string, int) {
+// last user index, use -1. Users that have reached their in-flight limit are skipped.
+func (q *queues) getNextQueueForQuerier(lastUserIndex int, querierID string, class RequestClass) (chan Request, string, int) {
 	uid := lastUserIndex
 
 	for iters := 0; iters < len(q.users); iters++ {
@@ -157,18 +235,28 @@ func (q *queues) getNextQueueForQuerier(lastUserIndex int, querierID string) (ch
 			continue
 		}
 
-		q := q.userQueues[u]
+		uq := q.userQueues[u]
+
+		ch := uq.chanFor(class)
+		if len(ch) == 0 {
+			continue
+		}
 
-		if q.queriers != nil {
-			if _, ok := q.queriers[querierID]; !ok {
+		if uq.queriers != nil {
+			if _, ok := uq.queriers[querierID]; !ok {
 				// This querier is not handling the user.
 				continue
 			}
 		}
 
-		return q.ch, u, uid
+		if uq.maxInflight > 0 && q.inflight[u] >= uq.maxInflight {
+			// This user is already using all of its in-flight slots.
+			continue
+		}
+
+		return ch, u, uid
 	}
-	return nil, "", uid
+	return nil, "", lastUserIndex
 }
 
 func (q *queues) addQuerierConnection(querierID string) {
@@ -24,12 +24,14 @@ func (s *SearchBlockHeaderMutable) AddEntry(e *SearchEntry) {
 
 	kv := &KeyValues{} //buffer
 
-	// Record all unique keyvalues
+	// Record all unique keyvalues and count the traces per value. Entries are expected to be combined per trace, a
+	// trace that is added again is counted again.
 	for i, ii := 0, e.TagsLength(); i < ii; i++ {
 		e.Tags(kv, i)
 		key := string(kv.Key())
+		info := kv.Info()
 		for j, jj := 0, kv.ValueLength(); j < jj; j++ {
-			s.AddTag(key, string(kv.Value(j)))
+			s.Tags.AddWithInfo(key, string(kv.Value(j)), info, 1)
 		}
 	}
 
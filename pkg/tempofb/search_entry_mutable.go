@@ -21,6 +21,15 @@ func (s *SearchEntryMutable) AddTag(k string, v string) {
 	s.Tags.Add(k, v)
 }
 
+// AddTagWithInfo adds the unique tag name and value to the search data and records the scope and
+// value type of the tag.
+func (s *SearchEntryMutable) AddTagWithInfo(k string, v string, info TagInfo) {
+	if s.Tags == nil {
+		s.Tags = NewSearchDataMap()
+	}
+	s.Tags.AddWithInfo(k, v, info, 0)
+}
+
 // SetStartTimeUnixNano records the earliest of all timestamps passed to this function.
 func (s *SearchEntryMutable) SetStartTimeUnixNano(t uint64) {
 	if t > 0 && (s.StartTimeUnixNano == 0 || s.StartTimeUnixNano > t) {
@@ -122,3 +122,43 @@ func TestContainsTag(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchBlockHeaderMutableTagInfoAndCounts(t *testing.T) {
+	resource := TagInfo{Scope: TagScopeResource, Type: TagTypeString}
+	span := TagInfo{Scope: TagScopeSpan, Type: TagTypeInt}
+
+	entry := func(values ...string) *SearchEntry {
+		e := &SearchEntryMutable{}
+		for _, v := range values {
+			e.AddTagWithInfo("foo", v, resource)
+		}
+		e.AddTagWithInfo("foo", values[0], span)
+		return NewSearchEntryFromBytes(e.ToBytes())
+	}
+
+	h := NewSearchBlockHeaderMutable()
+	h.AddEntry(entry("a", "b"))
+	h.AddEntry(entry("b"))
+
+	header := GetRootAsSearchBlockHeader(h.ToBytes(), 0)
+	kv := FindTag(header, &KeyValues{}, []byte("foo"))
+	require.NotNil(t, kv)
+	require.Equal(t, resource.Merge(span), kv.Info())
+
+	counts := map[string]uint32{}
+	for i := 0; i < kv.ValueLength(); i++ {
+		counts[string(kv.Value(i))] = kv.ValueCount(i)
+	}
+	require.Equal(t, map[string]uint32{"a": 1, "b": 2}, counts)
+}
+
+func TestTagInfoStrings(t *testing.T) {
+	scope := TagScopeResource | TagScopeIntrinsic
+	require.Equal(t, []string{"resource", "intrinsic"}, scope.Strings())
+	require.Equal(t, scope, ParseTagScope(scope.Strings()))
+	require.Nil(t, TagScope(0).Strings())
+
+	typ := TagTypeInt | TagTypeBool
+	require.Equal(t, []string{"int", "bool"}, typ.Strings())
+	require.Equal(t, typ, ParseTagType(append(typ.Strings(), "unknown")))
+}
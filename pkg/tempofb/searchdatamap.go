@@ -9,7 +9,14 @@ import (
 	flatbuffers "github.com/google/flatbuffers/go"
 )
 
-type SearchDataMap map[string]map[string]struct{}
+// SearchDataValues are the values of a tag with the number of traces per value, and the scope and
+// value type of the tag.
+type SearchDataValues struct {
+	Info   TagInfo
+	Values map[string]uint32
+}
+
+type SearchDataMap map[string]*SearchDataValues
 
 func NewSearchDataMap() SearchDataMap {
 	return make(SearchDataMap, 10) // 10 for luck
@@ -27,22 +34,35 @@ func NewSearchDataMapWithData(m map[string][]string) SearchDataMap {
 }
 
 func (s SearchDataMap) Add(k, v string) {
+	s.AddWithInfo(k, v, TagInfo{}, 0)
+}
+
+// AddWithInfo adds the value, increases the number of traces with the value by count and merges
+// the scope and value type of the tag.
+func (s SearchDataMap) AddWithInfo(k, v string, info TagInfo, count uint32) {
 	values, ok := s[k]
 	if !ok {
 		// first entry
-		s[k] = map[string]struct{}{v: {}}
+		s[k] = &SearchDataValues{
+			Info:   info,
+			Values: map[string]uint32{v: count},
+		}
 		return
 	}
 
+	values.Info = values.Info.Merge(info)
+
 	// For repeats it is more performant to avoid the map assigns.
-	if _, ok = values[v]; !ok {
-		values[v] = struct{}{}
+	if count > 0 {
+		values.Values[v] += count
+	} else if _, ok = values.Values[v]; !ok {
+		values.Values[v] = 0
 	}
 }
 
 func (s SearchDataMap) Contains(k, v string) bool {
 	if values, ok := s[k]; ok {
-		_, ok := values[v]
+		_, ok := values.Values[v]
 		return ok
 	}
 	return false
@@ -50,7 +70,7 @@ func (s SearchDataMap) Contains(k, v string) bool {
 
 func (s SearchDataMap) Range(f func(k, v string)) {
 	for k, values := range s {
-		for v := range values {
+		for v := range values.Values {
 			f(k, v)
 		}
 	}
@@ -62,9 +82,27 @@ func (s SearchDataMap) RangeKeys(f func(k string)) {
 	}
 }
 
+// RangeKeysWithInfo calls f for every tag with its scope and value type.
+func (s SearchDataMap) RangeKeysWithInfo(f func(k string, info TagInfo)) {
+	for k, values := range s {
+		f(k, values.Info)
+	}
+}
+
 func (s SearchDataMap) RangeKeyValues(k string, f func(v string)) {
-	for v := range s[k] {
-		f(v)
+	if values, ok := s[k]; ok {
+		for v := range values.Values {
+			f(v)
+		}
+	}
+}
+
+// RangeKeyValuesWithCount calls f for every value of the tag with the number of traces with the value.
+func (s SearchDataMap) RangeKeyValuesWithCount(k string, f func(v string, count uint32)) {
+	if values, ok := s[k]; ok {
+		for v, count := range values.Values {
+			f(v, count)
+		}
 	}
 }
 
@@ -79,14 +117,27 @@ func WriteSearchDataMap(b *flatbuffers.Builder, d SearchDataMap, cache map[uint6
 
 	offsets := make([]flatbuffers.UOffsetT, 0, len(keys))
 	var values []string
+	var counts []uint32
 	for _, k := range keys {
 
 		values = values[:0]
-		d.RangeKeyValues(k, func(v string) {
+		counts = counts[:0]
+		hasCounts := false
+		d.RangeKeyValuesWithCount(k, func(v string, count uint32) {
 			values = append(values, v)
+			counts = append(counts, count)
+			if count > 0 {
+				hasCounts = true
+			}
 		})
 
-		offsets = append(offsets, writeKeyValues(b, k, values, h, cache))
+		// counts are only written if they were recorded
+		var valueCounts []uint32
+		if hasCounts {
+			valueCounts = counts
+		}
+
+		offsets = append(offsets, writeKeyValues(b, k, values, valueCounts, d[k].Info, h, cache))
 	}
 
 	SearchEntryStartTagsVector(b, len(offsets))
@@ -98,8 +149,8 @@ func WriteSearchDataMap(b *flatbuffers.Builder, d SearchDataMap, cache map[uint6
 }
 
 // writeKeyValues saves the key->values entry to the builder.  Results are optionally cached and
-// existing identical key->values entries reused.
-func writeKeyValues(b *flatbuffers.Builder, key string, values []string, h hash.Hash64, cache map[uint64]flatbuffers.UOffsetT) flatbuffers.UOffsetT {
+// existing identical key->values entries reused. Counts are optional and in the same order as values.
+func writeKeyValues(b *flatbuffers.Builder, key string, values []string, counts []uint32, info TagInfo, h hash.Hash64, cache map[uint64]flatbuffers.UOffsetT) flatbuffers.UOffsetT {
 	// Skip empty keys
 	if len(values) <= 0 {
 		return 0
@@ -110,7 +161,11 @@ func writeKeyValues(b *flatbuffers.Builder, key string, values []string, h hash.
 	for i := range values {
 		values[i] = strings.ToLower(values[i])
 	}
-	sort.Strings(values)
+	if counts != nil {
+		sort.Sort(valuesWithCounts{values: values, counts: counts})
+	} else {
+		sort.Strings(values)
+	}
 
 	// Hash, cache (optional)
 	var ce uint64
@@ -121,6 +176,10 @@ func writeKeyValues(b *flatbuffers.Builder, key string, values []string, h hash.
 			h.Write([]byte{0}) // separator
 			h.Write([]byte(v))
 		}
+		h.Write([]byte{0, byte(info.Scope), byte(info.Type)})
+		for _, c := range counts {
+			h.Write([]byte{byte(c), byte(c >> 8), byte(c >> 16), byte(c >> 24)})
+		}
 		ce = h.Sum64()
 		if offset, ok := cache[ce]; ok {
 			return offset
@@ -139,9 +198,23 @@ func writeKeyValues(b *flatbuffers.Builder, key string, values []string, h hash.
 	}
 	valueVector := b.EndVector(len(valueStrings))
 
+	var countVector flatbuffers.UOffsetT
+	if counts != nil {
+		KeyValuesStartValueCountVector(b, len(counts))
+		for _, c := range counts {
+			b.PrependUint32(c)
+		}
+		countVector = b.EndVector(len(counts))
+	}
+
 	KeyValuesStart(b)
 	KeyValuesAddKey(b, ko)
 	KeyValuesAddValue(b, valueVector)
+	if counts != nil {
+		KeyValuesAddValueCount(b, countVector)
+	}
+	KeyValuesAddScope(b, byte(info.Scope))
+	KeyValuesAddValueType(b, byte(info.Type))
 	offset := KeyValuesEnd(b)
 
 	if cache != nil {
@@ -150,3 +223,16 @@ func writeKeyValues(b *flatbuffers.Builder, key string, values []string, h hash.
 
 	return offset
 }
+
+// valuesWithCounts sorts the values and keeps the counts in the same order.
+type valuesWithCounts struct {
+	values []string
+	counts []uint32
+}
+
+func (v valuesWithCounts) Len() int           { return len(v.values) }
+func (v valuesWithCounts) Less(i, j int) bool { return v.values[i] < v.values[j] }
+func (v valuesWithCounts) Swap(i, j int) {
+	v.values[i], v.values[j] = v.values[j], v.values[i]
+	v.counts[i], v.counts[j] = v.counts[j], v.counts[i]
+}
@@ -0,0 +1,110 @@
+package tempofb
+
+// TagScope is a bitmask of the scopes a tag was found in.
+type TagScope uint8
+
+const (
+	TagScopeResource TagScope = 1 << iota
+	TagScopeSpan
+	TagScopeIntrinsic
+)
+
+var tagScopeNames = []struct {
+	scope TagScope
+	name  string
+}{
+	{TagScopeResource, "resource"},
+	{TagScopeSpan, "span"},
+	{TagScopeIntrinsic, "intrinsic"},
+}
+
+// Strings returns the names of all scopes in the bitmask.
+func (s TagScope) Strings() []string {
+	var names []string
+	for _, n := range tagScopeNames {
+		if s&n.scope != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return names
+}
+
+// ParseTagScope returns the bitmask of the scope names. Unknown names are ignored.
+func ParseTagScope(names []string) TagScope {
+	var s TagScope
+	for _, name := range names {
+		for _, n := range tagScopeNames {
+			if n.name == name {
+				s |= n.scope
+			}
+		}
+	}
+	return s
+}
+
+// TagType is a bitmask of the types of the values of a tag.
+type TagType uint8
+
+const (
+	TagTypeString TagType = 1 << iota
+	TagTypeInt
+	TagTypeDouble
+	TagTypeBool
+)
+
+var tagTypeNames = []struct {
+	typ  TagType
+	name string
+}{
+	{TagTypeString, "string"},
+	{TagTypeInt, "int"},
+	{TagTypeDouble, "double"},
+	{TagTypeBool, "bool"},
+}
+
+// Strings returns the names of all types in the bitmask.
+func (t TagType) Strings() []string {
+	var names []string
+	for _, n := range tagTypeNames {
+		if t&n.typ != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return names
+}
+
+// ParseTagType returns the bitmask of the type names. Unknown names are ignored.
+func ParseTagType(names []string) TagType {
+	var t TagType
+	for _, name := range names {
+		for _, n := range tagTypeNames {
+			if n.name == name {
+				t |= n.typ
+			}
+		}
+	}
+	return t
+}
+
+// TagInfo is the scope and value type of a tag. Search data written before they were recorded
+// has neither.
+type TagInfo struct {
+	Scope TagScope
+	Type  TagType
+}
+
+// Merge returns the union of both scopes and value types.
+func (i TagInfo) Merge(o TagInfo) TagInfo {
+	return TagInfo{
+		Scope: i.Scope | o.Scope,
+		Type:  i.Type | o.Type,
+	}
+}
+
+// Info returns the scope and value type recorded for the tag.
+func (rcv *KeyValues) Info() TagInfo {
+	return TagInfo{
+		Scope: TagScope(rcv.Scope()),
+		Type:  TagType(rcv.ValueType()),
+	}
+}
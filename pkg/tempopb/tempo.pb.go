@@ -447,6 +447,8 @@ type TraceSearchMetadata struct {
 	RootTraceName     string `protobuf:"bytes,3,opt,name=rootTraceName,proto3" json:"rootTraceName,omitempty"`
 	StartTimeUnixNano uint64 `protobuf:"varint,4,opt,name=startTimeUnixNano,proto3" json:"startTimeUnixNano,omitempty"`
 	DurationMs        uint32 `protobuf:"varint,5,opt,name=durationMs,proto3" json:"durationMs,omitempty"`
+	// only set for multi-tenant queries
+	TenantID string `protobuf:"bytes,6,opt,name=tenantID,proto3" json:"tenantID,omitempty"`
 }
 
 func (m *TraceSearchMetadata) Reset()         { *m = TraceSearchMetadata{} }
@@ -517,6 +519,13 @@ func (m *TraceSearchMetadata) GetDurationMs() uint32 {
 	return 0
 }
 
+func (m *TraceSearchMetadata) GetTenantID() string {
+	if m != nil {
+		return m.TenantID
+	}
+	return ""
+}
+
 type SearchMetrics struct {
 	InspectedTraces uint32 `protobuf:"varint,1,opt,name=inspectedTraces,proto3" json:"inspectedTraces,omitempty"`
 	InspectedBytes  uint64 `protobuf:"varint,2,opt,name=inspectedBytes,proto3" json:"inspectedBytes,omitempty"`
@@ -594,6 +603,8 @@ func (m *SearchMetrics) GetSkippedTraces() uint32 {
 }
 
 type SearchTagsRequest struct {
+	Start uint32 `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	End   uint32 `protobuf:"varint,2,opt,name=end,proto3" json:"end,omitempty"`
 }
 
 func (m *SearchTagsRequest) Reset()         { *m = SearchTagsRequest{} }
@@ -629,8 +640,23 @@ func (m *SearchTagsRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_SearchTagsRequest proto.InternalMessageInfo
 
+func (m *SearchTagsRequest) GetStart() uint32 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *SearchTagsRequest) GetEnd() uint32 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
 type SearchTagsResponse struct {
-	TagNames []string `protobuf:"bytes,1,rep,name=tagNames,proto3" json:"tagNames,omitempty"`
+	TagNames []string     `protobuf:"bytes,1,rep,name=tagNames,proto3" json:"tagNames,omitempty"`
+	Tags     []*SearchTag `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
 }
 
 func (m *SearchTagsResponse) Reset()         { *m = SearchTagsResponse{} }
@@ -673,15 +699,86 @@ func (m *SearchTagsResponse) GetTagNames() []string {
 	return nil
 }
 
+func (m *SearchTagsResponse) GetTags() []*SearchTag {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+// SearchTag is a tag name with the scopes it was found in (resource, span or intrinsic) and
+// the types of its values (string, int, double or bool).
+type SearchTag struct {
+	Name   string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Scopes []string `protobuf:"bytes,2,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	Types  []string `protobuf:"bytes,3,rep,name=types,proto3" json:"types,omitempty"`
+}
+
+func (m *SearchTag) Reset()         { *m = SearchTag{} }
+func (m *SearchTag) String() string { return proto.CompactTextString(m) }
+func (*SearchTag) ProtoMessage()    {}
+func (*SearchTag) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{10}
+}
+func (m *SearchTag) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SearchTag) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SearchTag.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SearchTag) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchTag.Merge(m, src)
+}
+func (m *SearchTag) XXX_Size() int {
+	return m.Size()
+}
+func (m *SearchTag) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchTag.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchTag proto.InternalMessageInfo
+
+func (m *SearchTag) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SearchTag) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *SearchTag) GetTypes() []string {
+	if m != nil {
+		return m.Types
+	}
+	return nil
+}
+
 type SearchTagValuesRequest struct {
 	TagName string `protobuf:"bytes,1,opt,name=tagName,proto3" json:"tagName,omitempty"`
+	Start   uint32 `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"`
+	End     uint32 `protobuf:"varint,3,opt,name=end,proto3" json:"end,omitempty"`
 }
 
 func (m *SearchTagValuesRequest) Reset()         { *m = SearchTagValuesRequest{} }
 func (m *SearchTagValuesRequest) String() string { return proto.CompactTextString(m) }
 func (*SearchTagValuesRequest) ProtoMessage()    {}
 func (*SearchTagValuesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{10}
+	return fileDescriptor_f22805646f4f62b6, []int{11}
 }
 func (m *SearchTagValuesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -717,15 +814,30 @@ func (m *SearchTagValuesRequest) GetTagName() string {
 	return ""
 }
 
+func (m *SearchTagValuesRequest) GetStart() uint32 {
+	if m != nil {
+		return m.Start
+	}
+	return 0
+}
+
+func (m *SearchTagValuesRequest) GetEnd() uint32 {
+	if m != nil {
+		return m.End
+	}
+	return 0
+}
+
 type SearchTagValuesResponse struct {
-	TagValues []string `protobuf:"bytes,1,rep,name=tagValues,proto3" json:"tagValues,omitempty"`
+	TagValues []string          `protobuf:"bytes,1,rep,name=tagValues,proto3" json:"tagValues,omitempty"`
+	Values    []*SearchTagValue `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty"`
 }
 
 func (m *SearchTagValuesResponse) Reset()         { *m = SearchTagValuesResponse{} }
 func (m *SearchTagValuesResponse) String() string { return proto.CompactTextString(m) }
 func (*SearchTagValuesResponse) ProtoMessage()    {}
 func (*SearchTagValuesResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{11}
+	return fileDescriptor_f22805646f4f62b6, []int{12}
 }
 func (m *SearchTagValuesResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -761,6 +873,174 @@ func (m *SearchTagValuesResponse) GetTagValues() []string {
 	return nil
 }
 
+func (m *SearchTagValuesResponse) GetValues() []*SearchTagValue {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+// SearchTagValue is a tag value with the approximate number of traces it was found in.
+type SearchTagValue struct {
+	Value string `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Count uint32 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (m *SearchTagValue) Reset()         { *m = SearchTagValue{} }
+func (m *SearchTagValue) String() string { return proto.CompactTextString(m) }
+func (*SearchTagValue) ProtoMessage()    {}
+func (*SearchTagValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{13}
+}
+func (m *SearchTagValue) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SearchTagValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SearchTagValue.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SearchTagValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchTagValue.Merge(m, src)
+}
+func (m *SearchTagValue) XXX_Size() int {
+	return m.Size()
+}
+func (m *SearchTagValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchTagValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchTagValue proto.InternalMessageInfo
+
+func (m *SearchTagValue) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+func (m *SearchTagValue) GetCount() uint32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+// SearchTagsBlockRequest takes SearchTagsRequest parameters as well as the block in the backend
+// to read the tags from.
+type SearchTagsBlockRequest struct {
+	SearchReq *SearchTagsRequest `protobuf:"bytes,1,opt,name=searchReq,proto3" json:"searchReq,omitempty"`
+	BlockID   string             `protobuf:"bytes,2,opt,name=blockID,proto3" json:"blockID,omitempty"`
+}
+
+func (m *SearchTagsBlockRequest) Reset()         { *m = SearchTagsBlockRequest{} }
+func (m *SearchTagsBlockRequest) String() string { return proto.CompactTextString(m) }
+func (*SearchTagsBlockRequest) ProtoMessage()    {}
+func (*SearchTagsBlockRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{14}
+}
+func (m *SearchTagsBlockRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SearchTagsBlockRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SearchTagsBlockRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SearchTagsBlockRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchTagsBlockRequest.Merge(m, src)
+}
+func (m *SearchTagsBlockRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *SearchTagsBlockRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchTagsBlockRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchTagsBlockRequest proto.InternalMessageInfo
+
+func (m *SearchTagsBlockRequest) GetSearchReq() *SearchTagsRequest {
+	if m != nil {
+		return m.SearchReq
+	}
+	return nil
+}
+
+func (m *SearchTagsBlockRequest) GetBlockID() string {
+	if m != nil {
+		return m.BlockID
+	}
+	return ""
+}
+
+// SearchTagValuesBlockRequest takes SearchTagValuesRequest parameters as well as the block in the backend
+// to read the tag values from.
+type SearchTagValuesBlockRequest struct {
+	SearchReq *SearchTagValuesRequest `protobuf:"bytes,1,opt,name=searchReq,proto3" json:"searchReq,omitempty"`
+	BlockID   string                  `protobuf:"bytes,2,opt,name=blockID,proto3" json:"blockID,omitempty"`
+}
+
+func (m *SearchTagValuesBlockRequest) Reset()         { *m = SearchTagValuesBlockRequest{} }
+func (m *SearchTagValuesBlockRequest) String() string { return proto.CompactTextString(m) }
+func (*SearchTagValuesBlockRequest) ProtoMessage()    {}
+func (*SearchTagValuesBlockRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{15}
+}
+func (m *SearchTagValuesBlockRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SearchTagValuesBlockRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SearchTagValuesBlockRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SearchTagValuesBlockRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SearchTagValuesBlockRequest.Merge(m, src)
+}
+func (m *SearchTagValuesBlockRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *SearchTagValuesBlockRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SearchTagValuesBlockRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SearchTagValuesBlockRequest proto.InternalMessageInfo
+
+func (m *SearchTagValuesBlockRequest) GetSearchReq() *SearchTagValuesRequest {
+	if m != nil {
+		return m.SearchReq
+	}
+	return nil
+}
+
+func (m *SearchTagValuesBlockRequest) GetBlockID() string {
+	if m != nil {
+		return m.BlockID
+	}
+	return ""
+}
+
 type Trace struct {
 	Batches []*v1.ResourceSpans `protobuf:"bytes,1,rep,name=batches,proto3" json:"batches,omitempty"`
 }
@@ -769,7 +1049,7 @@ func (m *Trace) Reset()         { *m = Trace{} }
 func (m *Trace) String() string { return proto.CompactTextString(m) }
 func (*Trace) ProtoMessage()    {}
 func (*Trace) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{12}
+	return fileDescriptor_f22805646f4f62b6, []int{16}
 }
 func (m *Trace) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -813,7 +1093,7 @@ func (m *PushResponse) Reset()         { *m = PushResponse{} }
 func (m *PushResponse) String() string { return proto.CompactTextString(m) }
 func (*PushResponse) ProtoMessage()    {}
 func (*PushResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{13}
+	return fileDescriptor_f22805646f4f62b6, []int{17}
 }
 func (m *PushResponse) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -857,7 +1137,7 @@ func (m *PushBytesRequest) Reset()         { *m = PushBytesRequest{} }
 func (m *PushBytesRequest) String() string { return proto.CompactTextString(m) }
 func (*PushBytesRequest) ProtoMessage()    {}
 func (*PushBytesRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{14}
+	return fileDescriptor_f22805646f4f62b6, []int{18}
 }
 func (m *PushBytesRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -895,7 +1175,7 @@ func (m *PushSpansRequest) Reset()         { *m = PushSpansRequest{} }
 func (m *PushSpansRequest) String() string { return proto.CompactTextString(m) }
 func (*PushSpansRequest) ProtoMessage()    {}
 func (*PushSpansRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{15}
+	return fileDescriptor_f22805646f4f62b6, []int{19}
 }
 func (m *PushSpansRequest) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -940,7 +1220,7 @@ func (m *TraceBytes) Reset()         { *m = TraceBytes{} }
 func (m *TraceBytes) String() string { return proto.CompactTextString(m) }
 func (*TraceBytes) ProtoMessage()    {}
 func (*TraceBytes) Descriptor() ([]byte, []int) {
-	return fileDescriptor_f22805646f4f62b6, []int{16}
+	return fileDescriptor_f22805646f4f62b6, []int{20}
 }
 func (m *TraceBytes) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -976,127 +1256,283 @@ func (m *TraceBytes) GetTraces() [][]byte {
 	return nil
 }
 
-func init() {
-	proto.RegisterType((*TraceByIDRequest)(nil), "tempopb.TraceByIDRequest")
-	proto.RegisterType((*TraceByIDResponse)(nil), "tempopb.TraceByIDResponse")
-	proto.RegisterType((*TraceByIDMetrics)(nil), "tempopb.TraceByIDMetrics")
-	proto.RegisterType((*SearchRequest)(nil), "tempopb.SearchRequest")
-	proto.RegisterMapType((map[string]string)(nil), "tempopb.SearchRequest.TagsEntry")
-	proto.RegisterType((*SearchBlockRequest)(nil), "tempopb.SearchBlockRequest")
-	proto.RegisterType((*SearchResponse)(nil), "tempopb.SearchResponse")
-	proto.RegisterType((*TraceSearchMetadata)(nil), "tempopb.TraceSearchMetadata")
-	proto.RegisterType((*SearchMetrics)(nil), "tempopb.SearchMetrics")
-	proto.RegisterType((*SearchTagsRequest)(nil), "tempopb.SearchTagsRequest")
-	proto.RegisterType((*SearchTagsResponse)(nil), "tempopb.SearchTagsResponse")
-	proto.RegisterType((*SearchTagValuesRequest)(nil), "tempopb.SearchTagValuesRequest")
-	proto.RegisterType((*SearchTagValuesResponse)(nil), "tempopb.SearchTagValuesResponse")
-	proto.RegisterType((*Trace)(nil), "tempopb.Trace")
-	proto.RegisterType((*PushResponse)(nil), "tempopb.PushResponse")
-	proto.RegisterType((*PushBytesRequest)(nil), "tempopb.PushBytesRequest")
-	proto.RegisterType((*PushSpansRequest)(nil), "tempopb.PushSpansRequest")
-	proto.RegisterType((*TraceBytes)(nil), "tempopb.TraceBytes")
+// Transfer
+// TransferTracesRequest is a trace handed over by a leaving ingester. It is either a live trace with its segments
+//  or an object of the head block.
+type TransferTracesRequest struct {
+	FromIngesterID string `protobuf:"bytes,1,opt,name=fromIngesterID,proto3" json:"fromIngesterID,omitempty"`
+	TenantID       string `protobuf:"bytes,2,opt,name=tenantID,proto3" json:"tenantID,omitempty"`
+	TraceID        []byte `protobuf:"bytes,3,opt,name=traceID,proto3" json:"traceID,omitempty"`
+	// segments of a live trace. encoded using the SegmentDecoder for dataEncoding
+	Segments [][]byte `protobuf:"bytes,4,rep,name=segments,proto3" json:"segments,omitempty"`
+	// object of the head block. encoded using the ObjectDecoder for dataEncoding
+	Object       []byte `protobuf:"bytes,5,opt,name=object,proto3" json:"object,omitempty"`
+	DataEncoding string `protobuf:"bytes,6,opt,name=dataEncoding,proto3" json:"dataEncoding,omitempty"`
+	// search data of the trace
+	SearchData [][]byte `protobuf:"bytes,7,rep,name=searchData,proto3" json:"searchData,omitempty"`
+}
+
+func (m *TransferTracesRequest) Reset()         { *m = TransferTracesRequest{} }
+func (m *TransferTracesRequest) String() string { return proto.CompactTextString(m) }
+func (*TransferTracesRequest) ProtoMessage()    {}
+func (*TransferTracesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{21}
+}
+func (m *TransferTracesRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
 }
-
-func init() { proto.RegisterFile("pkg/tempopb/tempo.proto", fileDescriptor_f22805646f4f62b6) }
-
-var fileDescriptor_f22805646f4f62b6 = []byte{
-	// 1083 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x56, 0xcd, 0x6e, 0xdb, 0x46,
-	0x10, 0x36, 0xf5, 0x63, 0x59, 0x63, 0xc9, 0x3f, 0x9b, 0xc4, 0x66, 0x19, 0x43, 0x36, 0x08, 0xa3,
-	0xd5, 0xa1, 0x91, 0x13, 0x25, 0x6d, 0xda, 0x5c, 0x8a, 0x0a, 0x76, 0x53, 0x03, 0x55, 0xe0, 0x52,
-	0xae, 0xef, 0x2b, 0x72, 0x23, 0x13, 0x96, 0xb8, 0x0c, 0xb9, 0x32, 0xec, 0x3e, 0x40, 0x4f, 0x45,
-	0xd1, 0x57, 0xe8, 0xdb, 0xe4, 0xd0, 0x02, 0xb9, 0xb5, 0xe8, 0x21, 0x28, 0xec, 0xf7, 0x28, 0x8a,
-	0xfd, 0x15, 0x49, 0xcb, 0x3e, 0x34, 0x27, 0x71, 0xbe, 0xf9, 0x76, 0x76, 0xe6, 0xdb, 0x99, 0x5d,
-	0xc1, 0x66, 0x7c, 0x36, 0xda, 0x63, 0x64, 0x12, 0xd3, 0x78, 0x28, 0x7f, 0x3b, 0x71, 0x42, 0x19,
-	0x45, 0x35, 0x05, 0x3a, 0xf7, 0x59, 0x82, 0x7d, 0xb2, 0x77, 0xfe, 0x64, 0x4f, 0x7c, 0x48, 0xb7,
-	0xf3, 0x68, 0x14, 0xb2, 0xd3, 0xe9, 0xb0, 0xe3, 0xd3, 0xc9, 0xde, 0x88, 0x8e, 0xe8, 0x9e, 0x80,
-	0x87, 0xd3, 0xd7, 0xc2, 0x12, 0x86, 0xf8, 0x92, 0x74, 0xf7, 0x27, 0x0b, 0xd6, 0x8e, 0xf9, 0xf2,
-	0xde, 0xe5, 0xe1, 0xbe, 0x47, 0xde, 0x4c, 0x49, 0xca, 0x90, 0x0d, 0x35, 0x11, 0xf2, 0x70, 0xdf,
-	0xb6, 0x76, 0xac, 0x76, 0xc3, 0xd3, 0x26, 0x6a, 0x01, 0x0c, 0xc7, 0xd4, 0x3f, 0x1b, 0x30, 0x9c,
-	0x30, 0xbb, 0xb4, 0x63, 0xb5, 0xeb, 0x5e, 0x06, 0x41, 0x0e, 0x2c, 0x09, 0xeb, 0x20, 0x0a, 0xec,
-	0xb2, 0xf0, 0x1a, 0x1b, 0x6d, 0x41, 0xfd, 0xcd, 0x94, 0x24, 0x97, 0x7d, 0x1a, 0x10, 0xbb, 0x2a,
-	0x9c, 0x33, 0xc0, 0x8d, 0x60, 0x3d, 0x93, 0x47, 0x1a, 0xd3, 0x28, 0x25, 0x68, 0x17, 0xaa, 0x62,
-	0x67, 0x91, 0xc6, 0x72, 0x77, 0xa5, 0xa3, 0x6a, 0xef, 0x08, 0xaa, 0x27, 0x9d, 0xe8, 0x29, 0xd4,
-	0x26, 0x84, 0x25, 0xa1, 0x9f, 0x8a, 0x8c, 0x96, 0xbb, 0x1f, 0xe5, 0x79, 0x3c, 0x64, 0x5f, 0x12,
-	0x3c, 0xcd, 0x74, 0x3f, 0xcf, 0xd4, 0xad, 0x9c, 0xc8, 0x85, 0xc6, 0x6b, 0x1c, 0x8e, 0x49, 0xd0,
-	0xe3, 0x39, 0xa7, 0x62, 0xd7, 0xa6, 0x97, 0xc3, 0xdc, 0x5f, 0x4a, 0xd0, 0x1c, 0x10, 0x9c, 0xf8,
-	0xa7, 0x5a, 0xad, 0x17, 0x50, 0x39, 0xc6, 0x23, 0xce, 0x2e, 0xb7, 0x97, 0xbb, 0x3b, 0x66, 0xef,
-	0x1c, 0xab, 0xc3, 0x29, 0x07, 0x11, 0x4b, 0x2e, 0x7b, 0x95, 0xb7, 0xef, 0xb7, 0x17, 0x3c, 0xb1,
-	0x06, 0xed, 0x42, 0xb3, 0x1f, 0x46, 0xfb, 0xd3, 0x04, 0xb3, 0x90, 0x46, 0x7d, 0x59, 0x40, 0xd3,
-	0xcb, 0x83, 0x82, 0x85, 0x2f, 0x32, 0xac, 0xb2, 0x62, 0x65, 0x41, 0x74, 0x1f, 0xaa, 0xdf, 0x85,
-	0x93, 0x90, 0xd9, 0x15, 0xe1, 0x95, 0x06, 0x47, 0x53, 0x71, 0x58, 0x55, 0x89, 0x0a, 0x03, 0xad,
-	0x41, 0x99, 0x44, 0x81, 0xbd, 0x28, 0x30, 0xfe, 0xe9, 0x3c, 0x87, 0xba, 0x49, 0x91, 0xbb, 0xcf,
-	0xc8, 0xa5, 0xa8, 0xbf, 0xee, 0xf1, 0x4f, 0x1e, 0xe6, 0x1c, 0x8f, 0xa7, 0x44, 0x9d, 0xb9, 0x34,
-	0x5e, 0x94, 0xbe, 0xb0, 0xdc, 0xdf, 0x4b, 0x80, 0x64, 0xa9, 0x42, 0x21, 0xad, 0xca, 0x33, 0xa8,
-	0xa7, 0x5a, 0x00, 0x75, 0x7c, 0x1b, 0xf3, 0xa5, 0xf1, 0x66, 0x44, 0xde, 0x79, 0xa2, 0x5f, 0x0e,
-	0xf7, 0xd5, 0x46, 0xda, 0xe4, 0xdd, 0x23, 0x52, 0x3f, 0xc2, 0x23, 0xa2, 0xea, 0x9f, 0x01, 0x5c,
-	0xa1, 0x18, 0x8f, 0x48, 0x7a, 0x4c, 0x65, 0x68, 0xa5, 0x41, 0x1e, 0xe4, 0xdd, 0x49, 0x22, 0x9f,
-	0x06, 0x61, 0x34, 0x52, 0x0d, 0x68, 0x6c, 0x1e, 0x21, 0x8c, 0x02, 0x72, 0xc1, 0xc3, 0x0d, 0xc2,
-	0x1f, 0x89, 0xd2, 0x26, 0x0f, 0xf2, 0x0e, 0x61, 0x94, 0xe1, 0xb1, 0x47, 0x7c, 0x9a, 0x04, 0xa9,
-	0x5d, 0x93, 0x1d, 0x92, 0xc5, 0x38, 0x27, 0xc0, 0x0c, 0x1f, 0xe8, 0x9d, 0x96, 0xc4, 0x4e, 0x39,
-	0x8c, 0xd7, 0x79, 0x4e, 0x92, 0x34, 0xa4, 0x91, 0x5d, 0x97, 0x75, 0x2a, 0xd3, 0xbd, 0x80, 0x15,
-	0xad, 0x8e, 0x1a, 0x82, 0x67, 0xb0, 0x28, 0xfa, 0x5c, 0x77, 0xd8, 0x56, 0xbe, 0xbb, 0x25, 0xbb,
-	0x4f, 0x18, 0xe6, 0x3b, 0x78, 0x8a, 0x8b, 0x1e, 0x17, 0x87, 0xa2, 0xa8, 0xfe, 0x8d, 0x89, 0xf8,
-	0xc3, 0x82, 0x7b, 0x73, 0x22, 0x16, 0x6f, 0x83, 0xfa, 0xec, 0x36, 0x68, 0xc3, 0x6a, 0x42, 0x29,
-	0x1b, 0x90, 0xe4, 0x3c, 0xf4, 0xc9, 0x2b, 0x3c, 0xd1, 0xed, 0x51, 0x84, 0xb9, 0xba, 0x1c, 0x12,
-	0xe1, 0x05, 0x4f, 0x5e, 0x0e, 0x79, 0x10, 0x7d, 0x0a, 0xeb, 0xe2, 0x48, 0x8f, 0xc3, 0x09, 0xf9,
-	0x21, 0x0a, 0x2f, 0x5e, 0xe1, 0x88, 0x8a, 0x93, 0xac, 0x78, 0x37, 0x1d, 0xfc, 0x2e, 0x0a, 0x66,
-	0x23, 0x21, 0xdb, 0x3b, 0x83, 0xb8, 0x7f, 0x5a, 0x7a, 0x52, 0xf5, 0x7c, 0xb7, 0x61, 0x35, 0x8c,
-	0xd2, 0x98, 0xf8, 0x8c, 0x04, 0xc7, 0x5a, 0x52, 0xbe, 0xac, 0x08, 0xa3, 0x8f, 0x61, 0xc5, 0x40,
-	0xbd, 0x4b, 0x46, 0xa4, 0x88, 0x15, 0xaf, 0x80, 0xe6, 0x22, 0xaa, 0x4b, 0xa3, 0x5c, 0x88, 0x28,
-	0x61, 0xae, 0x40, 0x7a, 0x16, 0xc6, 0xb1, 0xe1, 0xa9, 0x0e, 0xcd, 0x81, 0x19, 0x96, 0xca, 0xaf,
-	0x9a, 0x63, 0x49, 0xd0, 0xbd, 0x07, 0xeb, 0xb2, 0x30, 0x3e, 0xb1, 0x6a, 0x8a, 0xdc, 0xc7, 0x7a,
-	0x0c, 0x25, 0xa8, 0x9a, 0xc7, 0x81, 0x25, 0x86, 0x47, 0x5c, 0x5d, 0xd9, 0x3e, 0x75, 0xcf, 0xd8,
-	0x6e, 0x17, 0x36, 0xcc, 0x8a, 0x13, 0x3e, 0xcf, 0x69, 0xf6, 0x01, 0x90, 0x2c, 0x73, 0xe4, 0xd2,
-	0x74, 0x9f, 0xc3, 0xe6, 0x8d, 0x35, 0x6a, 0xab, 0x2d, 0xa8, 0x33, 0x0d, 0xaa, 0xbd, 0x66, 0x80,
-	0xdb, 0x83, 0xaa, 0xc8, 0x1e, 0x7d, 0x09, 0xb5, 0x21, 0x66, 0xfe, 0xa9, 0xe9, 0xe7, 0x6d, 0xd3,
-	0x98, 0xf2, 0x1d, 0x3b, 0x7f, 0xd2, 0xf1, 0x48, 0x4a, 0xa7, 0x89, 0x4f, 0x06, 0x31, 0x8e, 0x52,
-	0x4f, 0xf3, 0xdd, 0x15, 0x68, 0x1c, 0x4d, 0x53, 0x33, 0x19, 0xee, 0x6f, 0x16, 0xac, 0x71, 0x40,
-	0x9c, 0x85, 0xce, 0xfd, 0x91, 0x19, 0x97, 0xd2, 0x4e, 0xb9, 0xdd, 0xe8, 0x3d, 0xe0, 0xd7, 0xed,
-	0xdf, 0xef, 0xb7, 0x9b, 0x47, 0x09, 0xc1, 0xe3, 0x31, 0xf5, 0x25, 0x5b, 0xcf, 0xc9, 0x27, 0x50,
-	0x0e, 0x03, 0x7e, 0x6a, 0x77, 0x70, 0x39, 0x03, 0x7d, 0x06, 0x20, 0xef, 0xa9, 0x7d, 0xcc, 0xb0,
-	0x5d, 0xb9, 0x8b, 0x9f, 0x21, 0xba, 0x7d, 0x99, 0xa2, 0xac, 0x44, 0xa5, 0xf8, 0x01, 0x12, 0xec,
-	0x02, 0xa8, 0x67, 0x8b, 0xb7, 0xdf, 0x46, 0xee, 0x6a, 0x68, 0xe8, 0xa2, 0xba, 0x3f, 0x5b, 0xb0,
-	0xc8, 0x77, 0x25, 0x09, 0xfa, 0x0a, 0xea, 0x46, 0x22, 0x34, 0x7b, 0x18, 0x8b, 0xb2, 0x39, 0x0f,
-	0x72, 0x2e, 0x23, 0xf1, 0x02, 0xfa, 0x1a, 0x96, 0x0d, 0xf9, 0xa4, 0xfb, 0x7f, 0x42, 0x74, 0x07,
-	0xb0, 0xa6, 0x46, 0xf0, 0x25, 0x89, 0x48, 0x82, 0x19, 0x35, 0x79, 0x89, 0xf2, 0x0a, 0x41, 0xb3,
-	0x5a, 0xdd, 0x1e, 0xf4, 0xdf, 0x12, 0xd4, 0xbe, 0x9f, 0x92, 0x24, 0x24, 0x09, 0xfa, 0x16, 0x9a,
-	0xdf, 0x84, 0x51, 0x60, 0x1e, 0x74, 0x34, 0xe7, 0x1f, 0x80, 0x0e, 0xe8, 0xcc, 0x73, 0x65, 0xaa,
-	0x6d, 0xe8, 0xeb, 0xd7, 0x27, 0x11, 0x43, 0xb7, 0xbc, 0x59, 0xce, 0xe6, 0x0d, 0xdc, 0x84, 0x38,
-	0x80, 0xe5, 0xcc, 0x7b, 0x88, 0x1e, 0x16, 0x98, 0xd9, 0x57, 0xf2, 0xae, 0x30, 0x2f, 0x01, 0x66,
-	0xf3, 0x8c, 0x9c, 0x02, 0x31, 0x33, 0xf9, 0xce, 0xc3, 0xb9, 0x3e, 0x13, 0xe8, 0x04, 0x56, 0x0b,
-	0x23, 0x8b, 0xb6, 0x6f, 0xae, 0xc8, 0x5d, 0x00, 0xce, 0xce, 0xed, 0x04, 0x1d, 0xb7, 0x67, 0xbf,
-	0xbd, 0x6a, 0x59, 0xef, 0xae, 0x5a, 0xd6, 0x3f, 0x57, 0x2d, 0xeb, 0xd7, 0xeb, 0xd6, 0xc2, 0xbb,
-	0xeb, 0xd6, 0xc2, 0x5f, 0xd7, 0xad, 0x85, 0xe1, 0xa2, 0xf8, 0x6f, 0xf9, 0xf4, 0xbf, 0x00, 0x00,
-	0x00, 0xff, 0xff, 0x9e, 0x0d, 0x2e, 0x59, 0xc4, 0x0a, 0x00, 0x00,
+func (m *TransferTracesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TransferTracesRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TransferTracesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransferTracesRequest.Merge(m, src)
+}
+func (m *TransferTracesRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *TransferTracesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransferTracesRequest.DiscardUnknown(m)
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+var xxx_messageInfo_TransferTracesRequest proto.InternalMessageInfo
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *TransferTracesRequest) GetFromIngesterID() string {
+	if m != nil {
+		return m.FromIngesterID
+	}
+	return ""
+}
 
-// PusherClient is the client API for Pusher service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type PusherClient interface {
-	// different versions of PushBytes expect the trace data to be pushed in different formats
-	PushBytes(ctx context.Context, in *PushBytesRequest, opts ...grpc.CallOption) (*PushResponse, error)
-	PushBytesV2(ctx context.Context, in *PushBytesRequest, opts ...grpc.CallOption) (*PushResponse, error)
+func (m *TransferTracesRequest) GetTenantID() string {
+	if m != nil {
+		return m.TenantID
+	}
+	return ""
 }
 
-type pusherClient struct {
-	cc *grpc.ClientConn
+func (m *TransferTracesRequest) GetTraceID() []byte {
+	if m != nil {
+		return m.TraceID
+	}
+	return nil
 }
 
-func NewPusherClient(cc *grpc.ClientConn) PusherClient {
-	return &pusherClient{cc}
+func (m *TransferTracesRequest) GetSegments() [][]byte {
+	if m != nil {
+		return m.Segments
+	}
+	return nil
 }
 
-func (c *pusherClient) PushBytes(ctx context.Context, in *PushBytesRequest, opts ...grpc.CallOption) (*PushResponse, error) {
+func (m *TransferTracesRequest) GetObject() []byte {
+	if m != nil {
+		return m.Object
+	}
+	return nil
+}
+
+func (m *TransferTracesRequest) GetDataEncoding() string {
+	if m != nil {
+		return m.DataEncoding
+	}
+	return ""
+}
+
+func (m *TransferTracesRequest) GetSearchData() [][]byte {
+	if m != nil {
+		return m.SearchData
+	}
+	return nil
+}
+
+type TransferTracesResponse struct {
+}
+
+func (m *TransferTracesResponse) Reset()         { *m = TransferTracesResponse{} }
+func (m *TransferTracesResponse) String() string { return proto.CompactTextString(m) }
+func (*TransferTracesResponse) ProtoMessage()    {}
+func (*TransferTracesResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f22805646f4f62b6, []int{22}
+}
+func (m *TransferTracesResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TransferTracesResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_TransferTracesResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *TransferTracesResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TransferTracesResponse.Merge(m, src)
+}
+func (m *TransferTracesResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *TransferTracesResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_TransferTracesResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TransferTracesResponse proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterType((*TraceByIDRequest)(nil), "tempopb.TraceByIDRequest")
+	proto.RegisterType((*TraceByIDResponse)(nil), "tempopb.TraceByIDResponse")
+	proto.RegisterType((*TraceByIDMetrics)(nil), "tempopb.TraceByIDMetrics")
+	proto.RegisterType((*SearchRequest)(nil), "tempopb.SearchRequest")
+	proto.RegisterMapType((map[string]string)(nil), "tempopb.SearchRequest.TagsEntry")
+	proto.RegisterType((*SearchBlockRequest)(nil), "tempopb.SearchBlockRequest")
+	proto.RegisterType((*SearchResponse)(nil), "tempopb.SearchResponse")
+	proto.RegisterType((*TraceSearchMetadata)(nil), "tempopb.TraceSearchMetadata")
+	proto.RegisterType((*SearchMetrics)(nil), "tempopb.SearchMetrics")
+	proto.RegisterType((*SearchTagsRequest)(nil), "tempopb.SearchTagsRequest")
+	proto.RegisterType((*SearchTagsResponse)(nil), "tempopb.SearchTagsResponse")
+	proto.RegisterType((*SearchTag)(nil), "tempopb.SearchTag")
+	proto.RegisterType((*SearchTagValuesRequest)(nil), "tempopb.SearchTagValuesRequest")
+	proto.RegisterType((*SearchTagValuesResponse)(nil), "tempopb.SearchTagValuesResponse")
+	proto.RegisterType((*SearchTagValue)(nil), "tempopb.SearchTagValue")
+	proto.RegisterType((*SearchTagsBlockRequest)(nil), "tempopb.SearchTagsBlockRequest")
+	proto.RegisterType((*SearchTagValuesBlockRequest)(nil), "tempopb.SearchTagValuesBlockRequest")
+	proto.RegisterType((*Trace)(nil), "tempopb.Trace")
+	proto.RegisterType((*PushResponse)(nil), "tempopb.PushResponse")
+	proto.RegisterType((*PushBytesRequest)(nil), "tempopb.PushBytesRequest")
+	proto.RegisterType((*PushSpansRequest)(nil), "tempopb.PushSpansRequest")
+	proto.RegisterType((*TraceBytes)(nil), "tempopb.TraceBytes")
+	proto.RegisterType((*TransferTracesRequest)(nil), "tempopb.TransferTracesRequest")
+	proto.RegisterType((*TransferTracesResponse)(nil), "tempopb.TransferTracesResponse")
+}
+
+func init() { proto.RegisterFile("pkg/tempopb/tempo.proto", fileDescriptor_f22805646f4f62b6) }
+
+var fileDescriptor_f22805646f4f62b6 = []byte{
+	// 1340 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x57, 0xdf, 0x6e, 0x1b, 0xc5,
+	0x17, 0xce, 0xda, 0x8e, 0x9d, 0x3d, 0xb1, 0xdd, 0x74, 0x7e, 0x6d, 0xe2, 0x9f, 0x5b, 0x39, 0xd1,
+	0xaa, 0x2a, 0xb9, 0xa0, 0x09, 0x4d, 0x0b, 0x94, 0x02, 0x42, 0x58, 0x09, 0x25, 0x12, 0xae, 0xca,
+	0xc6, 0xad, 0x10, 0x77, 0xe3, 0xf5, 0x64, 0xb3, 0xc4, 0x9e, 0x71, 0x77, 0xc6, 0x56, 0xc2, 0x03,
+	0x70, 0x85, 0x10, 0xaf, 0xc0, 0xdb, 0xf4, 0x82, 0x8b, 0xde, 0x81, 0xb8, 0xa8, 0x50, 0x7b, 0xc5,
+	0x05, 0xaf, 0x80, 0xd0, 0xfc, 0xd9, 0xd9, 0x3f, 0x76, 0x8a, 0x0a, 0x57, 0xd9, 0xf3, 0x9d, 0xcf,
+	0x67, 0xce, 0xf9, 0xe6, 0xcc, 0x99, 0x09, 0x6c, 0x4c, 0x4e, 0xc3, 0x5d, 0x41, 0xc6, 0x13, 0x36,
+	0x19, 0xe8, 0xbf, 0x3b, 0x93, 0x98, 0x09, 0x86, 0x6a, 0x06, 0x6c, 0x5f, 0x11, 0x31, 0x0e, 0xc8,
+	0xee, 0xec, 0xf6, 0xae, 0xfa, 0xd0, 0xee, 0xf6, 0xad, 0x30, 0x12, 0x27, 0xd3, 0xc1, 0x4e, 0xc0,
+	0xc6, 0xbb, 0x21, 0x0b, 0xd9, 0xae, 0x82, 0x07, 0xd3, 0x63, 0x65, 0x29, 0x43, 0x7d, 0x69, 0xba,
+	0xf7, 0x9d, 0x03, 0x6b, 0x7d, 0xf9, 0xf3, 0xee, 0xf9, 0xe1, 0xbe, 0x4f, 0x9e, 0x4e, 0x09, 0x17,
+	0xa8, 0x05, 0x35, 0x15, 0xf2, 0x70, 0xbf, 0xe5, 0x6c, 0x39, 0xdb, 0x75, 0x3f, 0x31, 0x51, 0x07,
+	0x60, 0x30, 0x62, 0xc1, 0xe9, 0x91, 0xc0, 0xb1, 0x68, 0x95, 0xb6, 0x9c, 0x6d, 0xd7, 0xcf, 0x20,
+	0xa8, 0x0d, 0x2b, 0xca, 0x3a, 0xa0, 0xc3, 0x56, 0x59, 0x79, 0xad, 0x8d, 0xae, 0x83, 0xfb, 0x74,
+	0x4a, 0xe2, 0xf3, 0x1e, 0x1b, 0x92, 0xd6, 0xb2, 0x72, 0xa6, 0x80, 0x47, 0xe1, 0x72, 0x26, 0x0f,
+	0x3e, 0x61, 0x94, 0x13, 0x74, 0x03, 0x96, 0xd5, 0xca, 0x2a, 0x8d, 0xd5, 0xbd, 0xe6, 0x8e, 0xa9,
+	0x7d, 0x47, 0x51, 0x7d, 0xed, 0x44, 0x77, 0xa0, 0x36, 0x26, 0x22, 0x8e, 0x02, 0xae, 0x32, 0x5a,
+	0xdd, 0xfb, 0x7f, 0x9e, 0x27, 0x43, 0xf6, 0x34, 0xc1, 0x4f, 0x98, 0xde, 0x7b, 0xb0, 0x56, 0x74,
+	0x22, 0x0f, 0xea, 0xc7, 0x38, 0x1a, 0x91, 0x61, 0x57, 0xe6, 0xcc, 0xd5, 0xaa, 0x0d, 0x3f, 0x87,
+	0x79, 0x3f, 0x94, 0xa0, 0x71, 0x44, 0x70, 0x1c, 0x9c, 0x24, 0x6a, 0xdd, 0x87, 0x4a, 0x1f, 0x87,
+	0x92, 0x5d, 0xde, 0x5e, 0xdd, 0xdb, 0xb2, 0x6b, 0xe7, 0x58, 0x3b, 0x92, 0x72, 0x40, 0x45, 0x7c,
+	0xde, 0xad, 0x3c, 0x7b, 0xb1, 0xb9, 0xe4, 0xab, 0xdf, 0xa0, 0x1b, 0xd0, 0xe8, 0x45, 0x74, 0x7f,
+	0x1a, 0x63, 0x11, 0x31, 0xda, 0xd3, 0x05, 0x34, 0xfc, 0x3c, 0xa8, 0x58, 0xf8, 0x2c, 0xc3, 0x2a,
+	0x1b, 0x56, 0x16, 0x44, 0x57, 0x60, 0xf9, 0x8b, 0x68, 0x1c, 0x89, 0x56, 0x45, 0x79, 0xb5, 0x21,
+	0x51, 0xae, 0x36, 0x6b, 0x59, 0xa3, 0xca, 0x40, 0x6b, 0x50, 0x26, 0x74, 0xd8, 0xaa, 0x2a, 0x4c,
+	0x7e, 0xb6, 0xdf, 0x07, 0xd7, 0xa6, 0x28, 0xdd, 0xa7, 0xe4, 0x5c, 0xd5, 0xef, 0xfa, 0xf2, 0x53,
+	0x86, 0x99, 0xe1, 0xd1, 0x94, 0x98, 0x3d, 0xd7, 0xc6, 0xfd, 0xd2, 0x3d, 0xc7, 0xfb, 0xb9, 0x04,
+	0x48, 0x97, 0xaa, 0x14, 0x4a, 0x54, 0xb9, 0x0b, 0x2e, 0x4f, 0x04, 0x30, 0xdb, 0xb7, 0xbe, 0x58,
+	0x1a, 0x3f, 0x25, 0xca, 0xce, 0x53, 0xfd, 0x72, 0xb8, 0x6f, 0x16, 0x4a, 0x4c, 0xd9, 0x3d, 0x2a,
+	0xf5, 0x47, 0x38, 0x24, 0xa6, 0xfe, 0x14, 0x90, 0x0a, 0x4d, 0x70, 0x48, 0x78, 0x9f, 0xe9, 0xd0,
+	0x46, 0x83, 0x3c, 0x28, 0xbb, 0x93, 0xd0, 0x80, 0x0d, 0x23, 0x1a, 0x9a, 0x06, 0xb4, 0xb6, 0x8c,
+	0x10, 0xd1, 0x21, 0x39, 0x93, 0xe1, 0x8e, 0xa2, 0x6f, 0x89, 0xd1, 0x26, 0x0f, 0xca, 0x0e, 0x11,
+	0x4c, 0xe0, 0x91, 0x4f, 0x02, 0x16, 0x0f, 0x79, 0xab, 0xa6, 0x3b, 0x24, 0x8b, 0x49, 0xce, 0x10,
+	0x0b, 0x7c, 0x90, 0xac, 0xb4, 0xa2, 0x56, 0xca, 0x61, 0xb2, 0xce, 0x19, 0x89, 0x79, 0xc4, 0x68,
+	0xcb, 0xd5, 0x75, 0x1a, 0xd3, 0x3b, 0x83, 0x66, 0xa2, 0x8e, 0x39, 0x04, 0x77, 0xa1, 0xaa, 0xfa,
+	0x3c, 0xe9, 0xb0, 0xeb, 0xf9, 0xee, 0xd6, 0xec, 0x1e, 0x11, 0x58, 0xae, 0xe0, 0x1b, 0x2e, 0x7a,
+	0xa7, 0x78, 0x28, 0x8a, 0xea, 0xcf, 0x9d, 0x88, 0x3f, 0x1c, 0xf8, 0xdf, 0x82, 0x88, 0xc5, 0x69,
+	0xe0, 0xa6, 0xd3, 0x60, 0x1b, 0x2e, 0xc5, 0x8c, 0x89, 0x23, 0x12, 0xcf, 0xa2, 0x80, 0x3c, 0xc4,
+	0xe3, 0xa4, 0x3d, 0x8a, 0xb0, 0x54, 0x57, 0x42, 0x2a, 0xbc, 0xe2, 0xe9, 0xe1, 0x90, 0x07, 0xd1,
+	0xdb, 0x70, 0x59, 0x6d, 0x69, 0x3f, 0x1a, 0x93, 0xc7, 0x34, 0x3a, 0x7b, 0x88, 0x29, 0x53, 0x3b,
+	0x59, 0xf1, 0xe7, 0x1d, 0x72, 0x16, 0x0d, 0xd3, 0x23, 0xa1, 0xdb, 0x3b, 0x83, 0xc8, 0xdd, 0x16,
+	0x84, 0x62, 0x2a, 0x0e, 0xf7, 0xd5, 0x66, 0xba, 0xbe, 0xb5, 0xbd, 0x5f, 0x1c, 0x68, 0xe4, 0x64,
+	0x90, 0xb5, 0x44, 0x94, 0x4f, 0x48, 0x20, 0xc8, 0xb0, 0x9f, 0xc8, 0x2d, 0x43, 0x16, 0x61, 0x74,
+	0x13, 0x9a, 0x16, 0xea, 0x9e, 0x0b, 0xa2, 0x05, 0xae, 0xf8, 0x05, 0x34, 0x17, 0xd1, 0x0c, 0x94,
+	0x72, 0x21, 0xa2, 0x86, 0xa5, 0x3a, 0xfc, 0x34, 0x9a, 0x4c, 0x2c, 0xcf, 0x74, 0x6f, 0x0e, 0xcc,
+	0xb0, 0x4c, 0x7e, 0xcb, 0x39, 0x96, 0x06, 0xbd, 0x0f, 0xe1, 0xb2, 0x2e, 0x4c, 0x9e, 0xe6, 0xe4,
+	0x30, 0xda, 0x21, 0xe0, 0x2c, 0x18, 0x02, 0x25, 0x3b, 0x04, 0xbc, 0xaf, 0x00, 0x65, 0x7f, 0x6c,
+	0x1a, 0x50, 0x0a, 0x89, 0x43, 0xb9, 0x43, 0xba, 0x05, 0x5d, 0xdf, 0xda, 0xe8, 0x26, 0x54, 0x84,
+	0x1c, 0x7e, 0x25, 0xd5, 0x9a, 0xa8, 0xd0, 0x63, 0x7d, 0x1c, 0xfa, 0xca, 0xef, 0xf5, 0xc0, 0xb5,
+	0x10, 0x42, 0x50, 0xa1, 0xb2, 0x09, 0x74, 0x3b, 0xa9, 0x6f, 0xb4, 0x0e, 0x55, 0x1e, 0xb0, 0x09,
+	0xd1, 0xa1, 0x5c, 0xdf, 0x58, 0x32, 0x75, 0x71, 0x2e, 0xe1, 0xb2, 0x82, 0xb5, 0xe1, 0x7d, 0x0d,
+	0xeb, 0x36, 0xdc, 0x13, 0x39, 0x8a, 0x78, 0xf6, 0xee, 0xd2, 0xc9, 0xd9, 0x6e, 0xd5, 0x66, 0x2a,
+	0x42, 0x69, 0x81, 0x08, 0xe5, 0x54, 0x84, 0x13, 0xd8, 0x98, 0x8b, 0x6d, 0x94, 0xb8, 0x0e, 0xae,
+	0x48, 0x40, 0x23, 0x45, 0x0a, 0xa0, 0x5d, 0xa8, 0xce, 0xb4, 0x4b, 0xab, 0xb1, 0x31, 0xaf, 0x86,
+	0x62, 0xfa, 0x86, 0xe6, 0x7d, 0x04, 0xcd, 0xbc, 0x27, 0x1d, 0xb3, 0x4e, 0x66, 0xcc, 0x4a, 0x34,
+	0x60, 0x53, 0x6a, 0x33, 0x57, 0x86, 0x37, 0xca, 0x68, 0xc0, 0x73, 0xb3, 0xf7, 0xde, 0xfc, 0xec,
+	0x6d, 0xcf, 0xe7, 0xc2, 0xdf, 0x64, 0xfe, 0x7a, 0x33, 0xb8, 0x56, 0x50, 0x25, 0xb7, 0xe4, 0xc7,
+	0xf3, 0x4b, 0x6e, 0x5e, 0x50, 0xfe, 0x9b, 0xad, 0xdb, 0x85, 0x65, 0xd5, 0xd9, 0xe8, 0x03, 0xa8,
+	0x0d, 0xb0, 0x08, 0x4e, 0xec, 0x1c, 0x4c, 0xe3, 0xeb, 0xf7, 0xcf, 0xec, 0xf6, 0x8e, 0x4f, 0x38,
+	0x9b, 0xc6, 0x01, 0x39, 0x9a, 0x60, 0xca, 0xfd, 0x84, 0xef, 0x35, 0xa1, 0xfe, 0x68, 0xca, 0xed,
+	0x44, 0xf5, 0x7e, 0x72, 0x60, 0x4d, 0x02, 0xea, 0x9c, 0x26, 0x15, 0xdc, 0xb2, 0x63, 0x56, 0xee,
+	0x5e, 0xbd, 0x7b, 0x55, 0x5e, 0xd3, 0xbf, 0xbd, 0xd8, 0x6c, 0x3c, 0x8a, 0x09, 0x1e, 0x8d, 0x58,
+	0xa0, 0xd9, 0x86, 0x84, 0xde, 0x82, 0x72, 0x34, 0xd4, 0x5d, 0x79, 0x21, 0x57, 0x32, 0xd0, 0xbb,
+	0x00, 0xba, 0xce, 0x7d, 0x2c, 0x70, 0xab, 0xf2, 0x3a, 0x7e, 0x86, 0xe8, 0xf5, 0x74, 0x8a, 0xba,
+	0x12, 0x93, 0xe2, 0x7f, 0x90, 0xe0, 0x06, 0x80, 0x79, 0xee, 0x08, 0xc2, 0xe5, 0x61, 0xcb, 0x5c,
+	0x29, 0xf5, 0xa4, 0x28, 0xef, 0x4f, 0x07, 0xae, 0xf6, 0x63, 0x4c, 0xf9, 0x31, 0x89, 0x15, 0xdd,
+	0x2e, 0x7d, 0x13, 0x9a, 0xc7, 0x31, 0x1b, 0x1f, 0xd2, 0x90, 0x70, 0x41, 0x62, 0x7b, 0x17, 0x14,
+	0xd0, 0xdc, 0xd0, 0x2d, 0xe5, 0x87, 0x6e, 0xf6, 0x22, 0x29, 0xe7, 0x9f, 0x95, 0x6d, 0x58, 0xe1,
+	0x24, 0x1c, 0x13, 0x2a, 0xb8, 0x56, 0xc8, 0xb7, 0xb6, 0xcc, 0x95, 0x0d, 0xbe, 0x21, 0x81, 0x7e,
+	0xc1, 0xd4, 0x7d, 0x63, 0xcd, 0x5d, 0xb3, 0xd5, 0x05, 0xd7, 0x6c, 0x27, 0xa7, 0x7d, 0x4d, 0x45,
+	0xce, 0x8a, 0xdc, 0x82, 0xf5, 0x62, 0xb9, 0xba, 0x45, 0xf6, 0xbe, 0x77, 0xa0, 0x2a, 0xf5, 0x27,
+	0x31, 0xfa, 0x04, 0x5c, 0xdb, 0x2c, 0x28, 0x7d, 0x5a, 0x16, 0x1b, 0xa8, 0x7d, 0x35, 0xe7, 0xb2,
+	0xcd, 0xb6, 0x84, 0x3e, 0x85, 0x55, 0x4b, 0x7e, 0xb2, 0xf7, 0x6f, 0x42, 0xec, 0x1d, 0xc1, 0x9a,
+	0xb9, 0xa8, 0x1e, 0x10, 0x4a, 0x62, 0x2c, 0x98, 0xcd, 0x4b, 0x6d, 0x74, 0x21, 0x68, 0xb6, 0x6b,
+	0x2e, 0x0e, 0xfa, 0x57, 0x09, 0x6a, 0x5f, 0x4e, 0x49, 0x1c, 0x91, 0x18, 0x7d, 0x0e, 0x8d, 0xcf,
+	0x22, 0x3a, 0xb4, 0x4f, 0x62, 0xb4, 0xe0, 0x0d, 0x9d, 0x04, 0x6c, 0x2f, 0x72, 0x65, 0xaa, 0xad,
+	0x27, 0x0f, 0x98, 0x80, 0x50, 0x81, 0x2e, 0x78, 0xf5, 0xb5, 0x37, 0xe6, 0x70, 0x1b, 0xe2, 0x00,
+	0x56, 0x33, 0x2f, 0x4a, 0x74, 0xad, 0xc0, 0xcc, 0x0e, 0x9e, 0xd7, 0x85, 0x79, 0x00, 0x90, 0x0e,
+	0x3b, 0xf4, 0x9a, 0x09, 0xd8, 0xbe, 0xb6, 0xd0, 0x67, 0x03, 0x3d, 0x81, 0x4b, 0x85, 0x11, 0x86,
+	0xfe, 0x69, 0xb8, 0xb5, 0xb7, 0x2e, 0x26, 0xd8, 0x0d, 0xc0, 0xb0, 0x92, 0x1c, 0x1d, 0xf4, 0x18,
+	0x9a, 0xf9, 0x56, 0x44, 0x9d, 0xac, 0xcc, 0xf3, 0x47, 0xb2, 0xbd, 0x79, 0xa1, 0x3f, 0x59, 0x60,
+	0xdb, 0xe9, 0xb6, 0x9e, 0xbd, 0xec, 0x38, 0xcf, 0x5f, 0x76, 0x9c, 0xdf, 0x5f, 0x76, 0x9c, 0x1f,
+	0x5f, 0x75, 0x96, 0x9e, 0xbf, 0xea, 0x2c, 0xfd, 0xfa, 0xaa, 0xb3, 0x34, 0xa8, 0xaa, 0x7f, 0x00,
+	0xef, 0xfc, 0x3d, 0x00, 0xf5, 0xe7, 0xc6, 0x4d, 0x69, 0x0e, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// PusherClient is the client API for Pusher service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type PusherClient interface {
+	// different versions of PushBytes expect the trace data to be pushed in different formats
+	PushBytes(ctx context.Context, in *PushBytesRequest, opts ...grpc.CallOption) (*PushResponse, error)
+	PushBytesV2(ctx context.Context, in *PushBytesRequest, opts ...grpc.CallOption) (*PushResponse, error)
+}
+
+type pusherClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewPusherClient(cc *grpc.ClientConn) PusherClient {
+	return &pusherClient{cc}
+}
+
+func (c *pusherClient) PushBytes(ctx context.Context, in *PushBytesRequest, opts ...grpc.CallOption) (*PushResponse, error) {
 	out := new(PushResponse)
 	err := c.cc.Invoke(ctx, "/tempopb.Pusher/PushBytes", in, out, opts...)
 	if err != nil {
@@ -1477,6 +1913,114 @@ var _Querier_serviceDesc = grpc.ServiceDesc{
 	Metadata: "pkg/tempopb/tempo.proto",
 }
 
+// IngesterClient is the client API for Ingester service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type IngesterClient interface {
+	// TransferTraces hands over the live traces and head block of a leaving ingester to the ingester claiming its tokens
+	TransferTraces(ctx context.Context, opts ...grpc.CallOption) (Ingester_TransferTracesClient, error)
+}
+
+type ingesterClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewIngesterClient(cc *grpc.ClientConn) IngesterClient {
+	return &ingesterClient{cc}
+}
+
+func (c *ingesterClient) TransferTraces(ctx context.Context, opts ...grpc.CallOption) (Ingester_TransferTracesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Ingester_serviceDesc.Streams[0], "/tempopb.Ingester/TransferTraces", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &ingesterTransferTracesClient{stream}
+	return x, nil
+}
+
+type Ingester_TransferTracesClient interface {
+	Send(*TransferTracesRequest) error
+	CloseAndRecv() (*TransferTracesResponse, error)
+	grpc.ClientStream
+}
+
+type ingesterTransferTracesClient struct {
+	grpc.ClientStream
+}
+
+func (x *ingesterTransferTracesClient) Send(m *TransferTracesRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *ingesterTransferTracesClient) CloseAndRecv() (*TransferTracesResponse, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(TransferTracesResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// IngesterServer is the server API for Ingester service.
+type IngesterServer interface {
+	// TransferTraces hands over the live traces and head block of a leaving ingester to the ingester claiming its tokens
+	TransferTraces(Ingester_TransferTracesServer) error
+}
+
+// UnimplementedIngesterServer can be embedded to have forward compatible implementations.
+type UnimplementedIngesterServer struct {
+}
+
+func (*UnimplementedIngesterServer) TransferTraces(srv Ingester_TransferTracesServer) error {
+	return status.Errorf(codes.Unimplemented, "method TransferTraces not implemented")
+}
+
+func RegisterIngesterServer(s *grpc.Server, srv IngesterServer) {
+	s.RegisterService(&_Ingester_serviceDesc, srv)
+}
+
+func _Ingester_TransferTraces_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IngesterServer).TransferTraces(&ingesterTransferTracesServer{stream})
+}
+
+type Ingester_TransferTracesServer interface {
+	SendAndClose(*TransferTracesResponse) error
+	Recv() (*TransferTracesRequest, error)
+	grpc.ServerStream
+}
+
+type ingesterTransferTracesServer struct {
+	grpc.ServerStream
+}
+
+func (x *ingesterTransferTracesServer) SendAndClose(m *TransferTracesResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *ingesterTransferTracesServer) Recv() (*TransferTracesRequest, error) {
+	m := new(TransferTracesRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _Ingester_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "tempopb.Ingester",
+	HandlerType: (*IngesterServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "TransferTraces",
+			Handler:       _Ingester_TransferTraces_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "pkg/tempopb/tempo.proto",
+}
+
 func (m *TraceByIDRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -1822,6 +2366,13 @@ func (m *TraceSearchMetadata) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.TenantID) > 0 {
+		i -= len(m.TenantID)
+		copy(dAtA[i:], m.TenantID)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.TenantID)))
+		i--
+		dAtA[i] = 0x32
+	}
 	if m.DurationMs != 0 {
 		i = encodeVarintTempo(dAtA, i, uint64(m.DurationMs))
 		i--
@@ -1924,6 +2475,16 @@ func (m *SearchTagsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.End != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.End))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Start != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.Start))
+		i--
+		dAtA[i] = 0x8
+	}
 	return len(dAtA) - i, nil
 }
 
@@ -1947,6 +2508,20 @@ func (m *SearchTagsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Tags) > 0 {
+		for iNdEx := len(m.Tags) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Tags[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintTempo(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
 	if len(m.TagNames) > 0 {
 		for iNdEx := len(m.TagNames) - 1; iNdEx >= 0; iNdEx-- {
 			i -= len(m.TagNames[iNdEx])
@@ -1959,7 +2534,7 @@ func (m *SearchTagsResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
-func (m *SearchTagValuesRequest) Marshal() (dAtA []byte, err error) {
+func (m *SearchTag) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -1969,27 +2544,45 @@ func (m *SearchTagValuesRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *SearchTagValuesRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *SearchTag) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *SearchTagValuesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *SearchTag) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if len(m.TagName) > 0 {
-		i -= len(m.TagName)
-		copy(dAtA[i:], m.TagName)
-		i = encodeVarintTempo(dAtA, i, uint64(len(m.TagName)))
+	if len(m.Types) > 0 {
+		for iNdEx := len(m.Types) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Types[iNdEx])
+			copy(dAtA[i:], m.Types[iNdEx])
+			i = encodeVarintTempo(dAtA, i, uint64(len(m.Types[iNdEx])))
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.Scopes) > 0 {
+		for iNdEx := len(m.Scopes) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Scopes[iNdEx])
+			copy(dAtA[i:], m.Scopes[iNdEx])
+			i = encodeVarintTempo(dAtA, i, uint64(len(m.Scopes[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.Name)))
 		i--
 		dAtA[i] = 0xa
 	}
 	return len(dAtA) - i, nil
 }
 
-func (m *SearchTagValuesResponse) Marshal() (dAtA []byte, err error) {
+func (m *SearchTagValuesRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -1999,29 +2592,37 @@ func (m *SearchTagValuesResponse) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *SearchTagValuesResponse) MarshalTo(dAtA []byte) (int, error) {
+func (m *SearchTagValuesRequest) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *SearchTagValuesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *SearchTagValuesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if len(m.TagValues) > 0 {
-		for iNdEx := len(m.TagValues) - 1; iNdEx >= 0; iNdEx-- {
-			i -= len(m.TagValues[iNdEx])
-			copy(dAtA[i:], m.TagValues[iNdEx])
-			i = encodeVarintTempo(dAtA, i, uint64(len(m.TagValues[iNdEx])))
-			i--
-			dAtA[i] = 0xa
-		}
+	if m.End != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.End))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.Start != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.Start))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.TagName) > 0 {
+		i -= len(m.TagName)
+		copy(dAtA[i:], m.TagName)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.TagName)))
+		i--
+		dAtA[i] = 0xa
 	}
 	return len(dAtA) - i, nil
 }
 
-func (m *Trace) Marshal() (dAtA []byte, err error) {
+func (m *SearchTagValuesResponse) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -2031,20 +2632,20 @@ func (m *Trace) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *Trace) MarshalTo(dAtA []byte) (int, error) {
+func (m *SearchTagValuesResponse) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *Trace) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *SearchTagValuesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if len(m.Batches) > 0 {
-		for iNdEx := len(m.Batches) - 1; iNdEx >= 0; iNdEx-- {
+	if len(m.Values) > 0 {
+		for iNdEx := len(m.Values) - 1; iNdEx >= 0; iNdEx-- {
 			{
-				size, err := m.Batches[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				size, err := m.Values[iNdEx].MarshalToSizedBuffer(dAtA[:i])
 				if err != nil {
 					return 0, err
 				}
@@ -2052,13 +2653,22 @@ func (m *Trace) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 				i = encodeVarintTempo(dAtA, i, uint64(size))
 			}
 			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.TagValues) > 0 {
+		for iNdEx := len(m.TagValues) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.TagValues[iNdEx])
+			copy(dAtA[i:], m.TagValues[iNdEx])
+			i = encodeVarintTempo(dAtA, i, uint64(len(m.TagValues[iNdEx])))
+			i--
 			dAtA[i] = 0xa
 		}
 	}
 	return len(dAtA) - i, nil
 }
 
-func (m *PushResponse) Marshal() (dAtA []byte, err error) {
+func (m *SearchTagValue) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -2068,20 +2678,32 @@ func (m *PushResponse) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *PushResponse) MarshalTo(dAtA []byte) (int, error) {
+func (m *SearchTagValue) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *PushResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *SearchTagValue) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
+	if m.Count != 0 {
+		i = encodeVarintTempo(dAtA, i, uint64(m.Count))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Value) > 0 {
+		i -= len(m.Value)
+		copy(dAtA[i:], m.Value)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.Value)))
+		i--
+		dAtA[i] = 0xa
+	}
 	return len(dAtA) - i, nil
 }
 
-func (m *PushBytesRequest) Marshal() (dAtA []byte, err error) {
+func (m *SearchTagsBlockRequest) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
 	n, err := m.MarshalToSizedBuffer(dAtA[:size])
@@ -2091,32 +2713,176 @@ func (m *PushBytesRequest) Marshal() (dAtA []byte, err error) {
 	return dAtA[:n], nil
 }
 
-func (m *PushBytesRequest) MarshalTo(dAtA []byte) (int, error) {
+func (m *SearchTagsBlockRequest) MarshalTo(dAtA []byte) (int, error) {
 	size := m.Size()
 	return m.MarshalToSizedBuffer(dAtA[:size])
 }
 
-func (m *PushBytesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+func (m *SearchTagsBlockRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	i := len(dAtA)
 	_ = i
 	var l int
 	_ = l
-	if len(m.SearchData) > 0 {
-		for iNdEx := len(m.SearchData) - 1; iNdEx >= 0; iNdEx-- {
-			{
-				size := m.SearchData[iNdEx].Size()
-				i -= size
-				if _, err := m.SearchData[iNdEx].MarshalTo(dAtA[i:]); err != nil {
-					return 0, err
-				}
-				i = encodeVarintTempo(dAtA, i, uint64(size))
-			}
-			i--
-			dAtA[i] = 0x22
-		}
-	}
-	if len(m.Ids) > 0 {
-		for iNdEx := len(m.Ids) - 1; iNdEx >= 0; iNdEx-- {
+	if len(m.BlockID) > 0 {
+		i -= len(m.BlockID)
+		copy(dAtA[i:], m.BlockID)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.BlockID)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.SearchReq != nil {
+		{
+			size, err := m.SearchReq.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTempo(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *SearchTagValuesBlockRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SearchTagValuesBlockRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SearchTagValuesBlockRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.BlockID) > 0 {
+		i -= len(m.BlockID)
+		copy(dAtA[i:], m.BlockID)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.BlockID)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.SearchReq != nil {
+		{
+			size, err := m.SearchReq.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintTempo(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Trace) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Trace) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Trace) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Batches) > 0 {
+		for iNdEx := len(m.Batches) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Batches[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintTempo(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *PushResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PushResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PushResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	return len(dAtA) - i, nil
+}
+
+func (m *PushBytesRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *PushBytesRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *PushBytesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.SearchData) > 0 {
+		for iNdEx := len(m.SearchData) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size := m.SearchData[iNdEx].Size()
+				i -= size
+				if _, err := m.SearchData[iNdEx].MarshalTo(dAtA[i:]); err != nil {
+					return 0, err
+				}
+				i = encodeVarintTempo(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if len(m.Ids) > 0 {
+		for iNdEx := len(m.Ids) - 1; iNdEx >= 0; iNdEx-- {
 			{
 				size := m.Ids[iNdEx].Size()
 				i -= size
@@ -2215,6 +2981,105 @@ func (m *TraceBytes) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *TransferTracesRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TransferTracesRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TransferTracesRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.SearchData) > 0 {
+		for iNdEx := len(m.SearchData) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.SearchData[iNdEx])
+			copy(dAtA[i:], m.SearchData[iNdEx])
+			i = encodeVarintTempo(dAtA, i, uint64(len(m.SearchData[iNdEx])))
+			i--
+			dAtA[i] = 0x3a
+		}
+	}
+	if len(m.DataEncoding) > 0 {
+		i -= len(m.DataEncoding)
+		copy(dAtA[i:], m.DataEncoding)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.DataEncoding)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.Object) > 0 {
+		i -= len(m.Object)
+		copy(dAtA[i:], m.Object)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.Object)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.Segments) > 0 {
+		for iNdEx := len(m.Segments) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Segments[iNdEx])
+			copy(dAtA[i:], m.Segments[iNdEx])
+			i = encodeVarintTempo(dAtA, i, uint64(len(m.Segments[iNdEx])))
+			i--
+			dAtA[i] = 0x22
+		}
+	}
+	if len(m.TraceID) > 0 {
+		i -= len(m.TraceID)
+		copy(dAtA[i:], m.TraceID)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.TraceID)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.TenantID) > 0 {
+		i -= len(m.TenantID)
+		copy(dAtA[i:], m.TenantID)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.TenantID)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.FromIngesterID) > 0 {
+		i -= len(m.FromIngesterID)
+		copy(dAtA[i:], m.FromIngesterID)
+		i = encodeVarintTempo(dAtA, i, uint64(len(m.FromIngesterID)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *TransferTracesResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TransferTracesResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TransferTracesResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintTempo(dAtA []byte, offset int, v uint64) int {
 	offset -= sovTempo(v)
 	base := offset
@@ -2396,6 +3261,10 @@ func (m *TraceSearchMetadata) Size() (n int) {
 	if m.DurationMs != 0 {
 		n += 1 + sovTempo(uint64(m.DurationMs))
 	}
+	l = len(m.TenantID)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
 	return n
 }
 
@@ -2429,6 +3298,12 @@ func (m *SearchTagsRequest) Size() (n int) {
 	}
 	var l int
 	_ = l
+	if m.Start != 0 {
+		n += 1 + sovTempo(uint64(m.Start))
+	}
+	if m.End != 0 {
+		n += 1 + sovTempo(uint64(m.End))
+	}
 	return n
 }
 
@@ -2444,34 +3319,127 @@ func (m *SearchTagsResponse) Size() (n int) {
 			n += 1 + l + sovTempo(uint64(l))
 		}
 	}
+	if len(m.Tags) > 0 {
+		for _, e := range m.Tags {
+			l = e.Size()
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
 	return n
 }
 
-func (m *SearchTagValuesRequest) Size() (n int) {
+func (m *SearchTag) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	l = len(m.TagName)
+	l = len(m.Name)
 	if l > 0 {
 		n += 1 + l + sovTempo(uint64(l))
 	}
+	if len(m.Scopes) > 0 {
+		for _, s := range m.Scopes {
+			l = len(s)
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	if len(m.Types) > 0 {
+		for _, s := range m.Types {
+			l = len(s)
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
 	return n
 }
 
-func (m *SearchTagValuesResponse) Size() (n int) {
+func (m *SearchTagValuesRequest) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	if len(m.TagValues) > 0 {
-		for _, s := range m.TagValues {
+	l = len(m.TagName)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if m.Start != 0 {
+		n += 1 + sovTempo(uint64(m.Start))
+	}
+	if m.End != 0 {
+		n += 1 + sovTempo(uint64(m.End))
+	}
+	return n
+}
+
+func (m *SearchTagValuesResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.TagValues) > 0 {
+		for _, s := range m.TagValues {
 			l = len(s)
 			n += 1 + l + sovTempo(uint64(l))
 		}
 	}
+	if len(m.Values) > 0 {
+		for _, e := range m.Values {
+			l = e.Size()
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *SearchTagValue) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Value)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if m.Count != 0 {
+		n += 1 + sovTempo(uint64(m.Count))
+	}
+	return n
+}
+
+func (m *SearchTagsBlockRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.SearchReq != nil {
+		l = m.SearchReq.Size()
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	l = len(m.BlockID)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	return n
+}
+
+func (m *SearchTagValuesBlockRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.SearchReq != nil {
+		l = m.SearchReq.Size()
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	l = len(m.BlockID)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
 	return n
 }
 
@@ -2556,6 +3524,56 @@ func (m *TraceBytes) Size() (n int) {
 	return n
 }
 
+func (m *TransferTracesRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.FromIngesterID)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	l = len(m.TenantID)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	l = len(m.TraceID)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if len(m.Segments) > 0 {
+		for _, b := range m.Segments {
+			l = len(b)
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	l = len(m.Object)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	l = len(m.DataEncoding)
+	if l > 0 {
+		n += 1 + l + sovTempo(uint64(l))
+	}
+	if len(m.SearchData) > 0 {
+		for _, b := range m.SearchData {
+			l = len(b)
+			n += 1 + l + sovTempo(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *TransferTracesResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
 func sovTempo(x uint64) (n int) {
 	return (math_bits.Len64(x|1) + 6) / 7
 }
@@ -3778,6 +4796,38 @@ func (m *TraceSearchMetadata) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TenantID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TenantID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTempo(dAtA[iNdEx:])
@@ -3973,6 +5023,44 @@ func (m *SearchTagsRequest) Unmarshal(dAtA []byte) error {
 			return fmt.Errorf("proto: SearchTagsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+			}
+			m.Start = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Start |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+			}
+			m.End = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.End |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTempo(dAtA[iNdEx:])
@@ -4055,61 +5143,11 @@ func (m *SearchTagsResponse) Unmarshal(dAtA []byte) error {
 			}
 			m.TagNames = append(m.TagNames, string(dAtA[iNdEx:postIndex]))
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipTempo(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
-				return ErrInvalidLengthTempo
-			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
-			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *SearchTagValuesRequest) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowTempo
-			}
-			if iNdEx >= l {
-				return io.ErrUnexpectedEOF
-			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: SearchTagValuesRequest: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SearchTagValuesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TagName", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Tags", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -4119,23 +5157,25 @@ func (m *SearchTagValuesRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthTempo
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthTempo
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.TagName = string(dAtA[iNdEx:postIndex])
+			m.Tags = append(m.Tags, &SearchTag{})
+			if err := m.Tags[len(m.Tags)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -4158,7 +5198,7 @@ func (m *SearchTagValuesRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *SearchTagValuesResponse) Unmarshal(dAtA []byte) error {
+func (m *SearchTag) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4181,15 +5221,15 @@ func (m *SearchTagValuesResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: SearchTagValuesResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: SearchTag: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SearchTagValuesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SearchTag: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TagValues", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -4217,14 +5257,940 @@ func (m *SearchTagValuesResponse) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.TagValues = append(m.TagValues, string(dAtA[iNdEx:postIndex]))
+			m.Name = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipTempo(dAtA[iNdEx:])
-			if err != nil {
-				return err
-			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Scopes", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Scopes = append(m.Scopes, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Types", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Types = append(m.Types, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SearchTagValuesRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SearchTagValuesRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SearchTagValuesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TagName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TagName = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Start", wireType)
+			}
+			m.Start = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Start |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field End", wireType)
+			}
+			m.End = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.End |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SearchTagValuesResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SearchTagValuesResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SearchTagValuesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TagValues", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TagValues = append(m.TagValues, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Values = append(m.Values, &SearchTagValue{})
+			if err := m.Values[len(m.Values)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SearchTagValue) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SearchTagValue: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SearchTagValue: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Value", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Value = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Count", wireType)
+			}
+			m.Count = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Count |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SearchTagsBlockRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SearchTagsBlockRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SearchTagsBlockRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SearchReq", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.SearchReq == nil {
+				m.SearchReq = &SearchTagsRequest{}
+			}
+			if err := m.SearchReq.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BlockID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BlockID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SearchTagValuesBlockRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SearchTagValuesBlockRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SearchTagValuesBlockRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SearchReq", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.SearchReq == nil {
+				m.SearchReq = &SearchTagValuesRequest{}
+			}
+			if err := m.SearchReq.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BlockID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BlockID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Trace) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Trace: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Trace: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Batches", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Batches = append(m.Batches, &v1.ResourceSpans{})
+			if err := m.Batches[len(m.Batches)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PushResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PushResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PushResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *PushBytesRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTempo
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: PushBytesRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: PushBytesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Traces", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v PreallocBytes
+			m.Traces = append(m.Traces, v)
+			if err := m.Traces[len(m.Traces)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ids", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v PreallocBytes
+			m.Ids = append(m.Ids, v)
+			if err := m.Ids[len(m.Ids)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SearchData", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			var v PreallocBytes
+			m.SearchData = append(m.SearchData, v)
+			if err := m.SearchData[len(m.SearchData)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTempo(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
 			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthTempo
 			}
@@ -4240,7 +6206,7 @@ func (m *SearchTagValuesResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Trace) Unmarshal(dAtA []byte) error {
+func (m *PushSpansRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4263,10 +6229,10 @@ func (m *Trace) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Trace: wiretype end group for non-group")
+			return fmt.Errorf("proto: PushSpansRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Trace: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: PushSpansRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
@@ -4324,7 +6290,7 @@ func (m *Trace) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *PushResponse) Unmarshal(dAtA []byte) error {
+func (m *TraceBytes) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4347,12 +6313,44 @@ func (m *PushResponse) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: PushResponse: wiretype end group for non-group")
+			return fmt.Errorf("proto: TraceBytes: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PushResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: TraceBytes: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Traces", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Traces = append(m.Traces, make([]byte, postIndex-iNdEx))
+			copy(m.Traces[len(m.Traces)-1], dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTempo(dAtA[iNdEx:])
@@ -4374,7 +6372,7 @@ func (m *PushResponse) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *PushBytesRequest) Unmarshal(dAtA []byte) error {
+func (m *TransferTracesRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4397,15 +6395,79 @@ func (m *PushBytesRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: PushBytesRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: TransferTracesRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PushBytesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: TransferTracesRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FromIngesterID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.FromIngesterID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Traces", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TenantID", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.TenantID = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TraceID", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -4432,15 +6494,14 @@ func (m *PushBytesRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var v PreallocBytes
-			m.Traces = append(m.Traces, v)
-			if err := m.Traces[len(m.Traces)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.TraceID = append(m.TraceID[:0], dAtA[iNdEx:postIndex]...)
+			if m.TraceID == nil {
+				m.TraceID = []byte{}
 			}
 			iNdEx = postIndex
-		case 3:
+		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Ids", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Segments", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -4467,15 +6528,12 @@ func (m *PushBytesRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var v PreallocBytes
-			m.Ids = append(m.Ids, v)
-			if err := m.Ids[len(m.Ids)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Segments = append(m.Segments, make([]byte, postIndex-iNdEx))
+			copy(m.Segments[len(m.Segments)-1], dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 4:
+		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field SearchData", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Object", wireType)
 			}
 			var byteLen int
 			for shift := uint(0); ; shift += 7 {
@@ -4502,67 +6560,48 @@ func (m *PushBytesRequest) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			var v PreallocBytes
-			m.SearchData = append(m.SearchData, v)
-			if err := m.SearchData[len(m.SearchData)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.Object = append(m.Object[:0], dAtA[iNdEx:postIndex]...)
+			if m.Object == nil {
+				m.Object = []byte{}
 			}
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipTempo(dAtA[iNdEx:])
-			if err != nil {
-				return err
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DataEncoding", wireType)
 			}
-			if (skippy < 0) || (iNdEx+skippy) < 0 {
-				return ErrInvalidLengthTempo
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTempo
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTempo
 			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *PushSpansRequest) Unmarshal(dAtA []byte) error {
-	l := len(dAtA)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowTempo
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTempo
 			}
-			if iNdEx >= l {
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			b := dAtA[iNdEx]
-			iNdEx++
-			wire |= uint64(b&0x7F) << shift
-			if b < 0x80 {
-				break
-			}
-		}
-		fieldNum := int32(wire >> 3)
-		wireType := int(wire & 0x7)
-		if wireType == 4 {
-			return fmt.Errorf("proto: PushSpansRequest: wiretype end group for non-group")
-		}
-		if fieldNum <= 0 {
-			return fmt.Errorf("proto: PushSpansRequest: illegal tag %d (wire type %d)", fieldNum, wire)
-		}
-		switch fieldNum {
-		case 1:
+			m.DataEncoding = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Batches", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field SearchData", wireType)
 			}
-			var msglen int
+			var byteLen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowTempo
@@ -4572,25 +6611,23 @@ func (m *PushSpansRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				byteLen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			if byteLen < 0 {
 				return ErrInvalidLengthTempo
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + byteLen
 			if postIndex < 0 {
 				return ErrInvalidLengthTempo
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Batches = append(m.Batches, &v1.ResourceSpans{})
-			if err := m.Batches[len(m.Batches)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.SearchData = append(m.SearchData, make([]byte, postIndex-iNdEx))
+			copy(m.SearchData[len(m.SearchData)-1], dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -4613,7 +6650,7 @@ func (m *PushSpansRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *TraceBytes) Unmarshal(dAtA []byte) error {
+func (m *TransferTracesResponse) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -4636,44 +6673,12 @@ func (m *TraceBytes) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: TraceBytes: wiretype end group for non-group")
+			return fmt.Errorf("proto: TransferTracesResponse: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: TraceBytes: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: TransferTracesResponse: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
-		case 1:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Traces", wireType)
-			}
-			var byteLen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowTempo
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				byteLen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if byteLen < 0 {
-				return ErrInvalidLengthTempo
-			}
-			postIndex := iNdEx + byteLen
-			if postIndex < 0 {
-				return ErrInvalidLengthTempo
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.Traces = append(m.Traces, make([]byte, postIndex-iNdEx))
-			copy(m.Traces[len(m.Traces)-1], dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTempo(dAtA[iNdEx:])
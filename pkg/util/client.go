@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/golang/protobuf/jsonpb"
 	"github.com/golang/protobuf/proto"
@@ -124,6 +125,26 @@ func (c *Client) SearchWithRange(tags string, start int64, end int64) (*tempopb.
 	return m, nil
 }
 
+// SearchWithDuration calls the /api/search endpoint like SearchWithRange and additionally filters by trace duration.
+// A zero minDuration or maxDuration is not sent.
+func (c *Client) SearchWithDuration(tags string, start int64, end int64, minDuration time.Duration, maxDuration time.Duration) (*tempopb.SearchResponse, error) {
+	query := "/api/search?tags=" + url.QueryEscape(tags) + "&start=" + strconv.FormatInt(start, 10) + "&end=" + strconv.FormatInt(end, 10)
+	if minDuration > 0 {
+		query += "&minDuration=" + minDuration.String()
+	}
+	if maxDuration > 0 {
+		query += "&maxDuration=" + maxDuration.String()
+	}
+
+	m := &tempopb.SearchResponse{}
+	_, err := c.getFor(c.BaseURL+query, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
 func (c *Client) QueryTrace(id string) (*tempopb.Trace, error) {
 	m := &tempopb.Trace{}
 	resp, err := c.getFor(c.BaseURL+QueryTraceEndpoint+"/"+id, m)
@@ -1,10 +1,16 @@
 package util
 
-// MapSizeWithinLimit evaluates the total size of all keys in the map against the limit
-func MapSizeWithinLimit(uniqueMap map[string]struct{}, limit int) bool {
+// TagValueSize is the size of a value in a tag values response. Every value is returned twice, in TagValues and
+// in Values along with its count.
+func TagValueSize(v string) int {
+	return 2*len(v) + 4
+}
+
+// MapSizeWithinLimit evaluates the size of the tag values response of the map against the limit
+func MapSizeWithinLimit(uniqueMap map[string]uint32, limit int) bool {
 	var mapSize int
 	for key := range uniqueMap {
-		mapSize += len(key)
+		mapSize += TagValueSize(key)
 	}
 
 	return mapSize < limit
@@ -6,7 +6,6 @@ import (
 	"math/rand"
 	"time"
 
-	jaeger_grpc "github.com/jaegertracing/jaeger/cmd/agent/app/reporter/grpc"
 	thrift "github.com/jaegertracing/jaeger/thrift-gen/jaeger"
 	jaegerTrans "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/jaeger"
 	"github.com/weaveworks/common/user"
@@ -27,6 +26,11 @@ var (
 	maxLongWritesPerTrace int64 = 3
 )
 
+// JaegerClient pushes jaeger batches to Tempo. It is implemented by the jaeger grpc reporter.
+type JaegerClient interface {
+	EmitBatch(ctx context.Context, b *thrift.Batch) error
+}
+
 // TraceInfo is used to construct synthetic traces and manage the expectations.
 type TraceInfo struct {
 	timestamp           time.Time
@@ -86,7 +90,7 @@ func (t *TraceInfo) Done() {
 	t.longWritesRemaining--
 }
 
-func (t *TraceInfo) EmitBatches(c *jaeger_grpc.Reporter) error {
+func (t *TraceInfo) EmitBatches(c JaegerClient) error {
 	for i := int64(0); i < t.generateRandomInt(1, maxBatchesPerWrite); i++ {
 		ctx := user.InjectOrgID(context.Background(), t.tempoOrgID)
 		ctx, err := user.InjectIntoGRPCRequest(ctx)
@@ -105,7 +109,7 @@ func (t *TraceInfo) EmitBatches(c *jaeger_grpc.Reporter) error {
 
 // EmitAllBatches sends all the batches that would normally be sent at some
 // interval when using EmitBatches.
-func (t *TraceInfo) EmitAllBatches(c *jaeger_grpc.Reporter) error {
+func (t *TraceInfo) EmitAllBatches(c JaegerClient) error {
 	err := t.EmitBatches(c)
 	if err != nil {
 		return err
@@ -31,6 +31,12 @@ type Writer interface {
 	CloseAppend(ctx context.Context, tracker AppendTracker) error
 	// WriteTenantIndex writes the two meta slices as a tenant index
 	WriteTenantIndex(ctx context.Context, tenantID string, meta []*BlockMeta, compactedMeta []*CompactedBlockMeta) error
+	// WriteTenantIndexSnapshot writes the two meta slices as the tenant index of the builder that is the base of the given generation and version of deltas
+	WriteTenantIndexSnapshot(ctx context.Context, tenantID string, builder int, generation string, version uint64, meta []*BlockMeta, compactedMeta []*CompactedBlockMeta) error
+	// WriteTenantIndexDelta writes a tenant index delta of the builder to the given slot
+	WriteTenantIndexDelta(ctx context.Context, tenantID string, builder int, slot int, delta *TenantIndexDelta) error
+	// WriteTenantIndexHead writes the head that points at the current version of the tenant index deltas
+	WriteTenantIndexHead(ctx context.Context, tenantID string, head *TenantIndexHead) error
 }
 
 // Reader is a collection of methods to read data from tempodb backends
@@ -49,6 +55,12 @@ type Reader interface {
 	BlockMeta(ctx context.Context, blockID uuid.UUID, tenantID string) (*BlockMeta, error)
 	// TenantIndex returns lists of all metas given a tenant
 	TenantIndex(ctx context.Context, tenantID string) (*TenantIndex, error)
+	// TenantIndexSnapshot returns the base snapshot of the tenant index deltas of the builder
+	TenantIndexSnapshot(ctx context.Context, tenantID string, builder int) (*TenantIndex, error)
+	// TenantIndexDelta returns the tenant index delta of the builder in the given slot
+	TenantIndexDelta(ctx context.Context, tenantID string, builder int, slot int) (*TenantIndexDelta, error)
+	// TenantIndexHead returns the head of the tenant index deltas
+	TenantIndexHead(ctx context.Context, tenantID string) (*TenantIndexHead, error)
 	// Shutdown shuts...down?
 	Shutdown()
 }
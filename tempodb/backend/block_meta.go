@@ -29,6 +29,15 @@ type BlockMeta struct {
 	TotalRecords    uint32    `json:"totalRecords"`    // Total Records stored in the index file
 	DataEncoding    string    `json:"dataEncoding"`    // DataEncoding is a string provided externally, but tracked by tempodb that indicates the way the bytes are encoded
 	BloomShardCount uint16    `json:"bloomShards"`     // Number of bloom filter shards
+
+	Encryption *EncryptionMeta `json:"encryption,omitempty"` // Set if the objects of the block are encrypted
+}
+
+// EncryptionMeta describes the keys used to encrypt the objects of a block.
+type EncryptionMeta struct {
+	KeyProvider string `json:"keyProvider"` // Name of the provider of the key encryption key
+	KeyID       string `json:"keyID"`       // ID of the key encryption key that wraps the data key
+	DataKeyID   string `json:"dataKeyID"`   // Hash of the wrapped data key
 }
 
 func NewBlockMeta(tenantID string, blockID uuid.UUID, version string, encoding Encoding, dataEncoding string) *BlockMeta {
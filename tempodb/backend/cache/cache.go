@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/tempo/pkg/cache"
@@ -13,9 +14,12 @@ import (
 )
 
 type readerWriter struct {
-	nextReader backend.RawReader
-	nextWriter backend.RawWriter
-	cache      cache.Cache
+	nextReader  backend.RawReader
+	nextWriter  backend.RawWriter
+	cache       cache.Cache
+	cacheRanges bool
+
+	cacheTenantIndex bool
 }
 
 func NewCache(nextReader backend.RawReader, nextWriter backend.RawWriter, cache cache.Cache) (backend.RawReader, backend.RawWriter, error) {
@@ -28,6 +32,33 @@ func NewCache(nextReader backend.RawReader, nextWriter backend.RawWriter, cache
 	return rw, rw, nil
 }
 
+// NewCacheWithRanges returns a cache that, in addition to objects, caches the results of ReadRange. This is
+// meant for large local caches that can hold data and index pages of blocks. Ranges are cached by offset and
+// length, so only identical requests hit the cache.
+func NewCacheWithRanges(nextReader backend.RawReader, nextWriter backend.RawWriter, cache cache.Cache) (backend.RawReader, backend.RawWriter, error) {
+	rw := &readerWriter{
+		cache:       cache,
+		nextReader:  nextReader,
+		nextWriter:  nextWriter,
+		cacheRanges: true,
+	}
+
+	return rw, rw, nil
+}
+
+// NewCacheWithTenantIndex returns a cache that, in addition to objects, caches the tenant index. The tenant
+// index is replaced regularly, so the cache must expire it, e.g. by a TTL of a tiered cache.
+func NewCacheWithTenantIndex(nextReader backend.RawReader, nextWriter backend.RawWriter, cache cache.Cache) (backend.RawReader, backend.RawWriter, error) {
+	rw := &readerWriter{
+		cache:            cache,
+		nextReader:       nextReader,
+		nextWriter:       nextWriter,
+		cacheTenantIndex: true,
+	}
+
+	return rw, rw, nil
+}
+
 // List implements backend.RawReader
 func (r *readerWriter) List(ctx context.Context, keypath backend.KeyPath) ([]string, error) {
 	return r.nextReader.List(ctx, keypath)
@@ -35,6 +66,8 @@ func (r *readerWriter) List(ctx context.Context, keypath backend.KeyPath) ([]str
 
 // Read implements backend.RawReader
 func (r *readerWriter) Read(ctx context.Context, name string, keypath backend.KeyPath, shouldCache bool) (io.ReadCloser, int64, error) {
+	shouldCache = shouldCache || r.isCachedTenantIndex(name)
+
 	var k string
 	if shouldCache {
 		k = key(keypath, name)
@@ -59,7 +92,28 @@ func (r *readerWriter) Read(ctx context.Context, name string, keypath backend.Ke
 
 // ReadRange implements backend.RawReader
 func (r *readerWriter) ReadRange(ctx context.Context, name string, keypath backend.KeyPath, offset uint64, buffer []byte) error {
-	return r.nextReader.ReadRange(ctx, name, keypath, offset, buffer)
+	if !r.cacheRanges {
+		return r.nextReader.ReadRange(ctx, name, keypath, offset, buffer)
+	}
+
+	k := rangeKey(keypath, name, offset, len(buffer))
+	found, vals, _ := r.cache.Fetch(ctx, []string{k})
+	if len(found) > 0 && len(vals[0]) == len(buffer) {
+		copy(buffer, vals[0])
+		return nil
+	}
+
+	err := r.nextReader.ReadRange(ctx, name, keypath, offset, buffer)
+	if err != nil {
+		return err
+	}
+
+	// the buffer belongs to the caller, so a copy is stored
+	b := make([]byte, len(buffer))
+	copy(b, buffer)
+	r.cache.Store(ctx, []string{k}, [][]byte{b})
+
+	return nil
 }
 
 // Shutdown implements backend.RawReader
@@ -75,7 +129,7 @@ func (r *readerWriter) Write(ctx context.Context, name string, keypath backend.K
 		return err
 	}
 
-	if shouldCache {
+	if shouldCache || r.isCachedTenantIndex(name) {
 		r.cache.Store(ctx, []string{key(keypath, name)}, [][]byte{b})
 	}
 	return r.nextWriter.Write(ctx, name, keypath, bytes.NewReader(b), int64(len(b)), false)
@@ -91,6 +145,14 @@ func (r *readerWriter) CloseAppend(ctx context.Context, tracker backend.AppendTr
 	return r.nextWriter.CloseAppend(ctx, tracker)
 }
 
+func (r *readerWriter) isCachedTenantIndex(name string) bool {
+	return r.cacheTenantIndex && name == backend.TenantIndexName
+}
+
 func key(keypath backend.KeyPath, name string) string {
 	return strings.Join(keypath, ":") + ":" + name
 }
+
+func rangeKey(keypath backend.KeyPath, name string, offset uint64, length int) string {
+	return key(keypath, name) + ":" + strconv.FormatUint(offset, 10) + ":" + strconv.Itoa(length)
+}
@@ -5,10 +5,12 @@ import (
 	"context"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/grafana/tempo/pkg/cache"
 	"github.com/grafana/tempo/tempodb/backend"
+	"github.com/grafana/tempo/tempodb/encoding/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -141,3 +143,83 @@ func TestList(t *testing.T) {
 		})
 	}
 }
+
+func TestReadRange(t *testing.T) {
+	keypath := backend.KeyPathForBlock(uuid.New(), "test")
+	ctx := context.Background()
+
+	mockR := &backend.MockRawReader{
+		Range: []byte{0x01, 0x02},
+	}
+	mockW := &backend.MockRawWriter{}
+
+	// ranges are not cached by default
+	r, _, _ := NewCache(mockR, mockW, NewMockClient())
+	buffer := make([]byte, 2)
+	assert.NoError(t, r.ReadRange(ctx, "foo", keypath, 10, buffer))
+	assert.Equal(t, []byte{0x01, 0x02}, buffer)
+
+	mockR.Range = []byte{0x03, 0x04}
+	assert.NoError(t, r.ReadRange(ctx, "foo", keypath, 10, buffer))
+	assert.Equal(t, []byte{0x03, 0x04}, buffer)
+
+	r, _, _ = NewCacheWithRanges(mockR, mockW, NewMockClient())
+	assert.NoError(t, r.ReadRange(ctx, "foo", keypath, 10, buffer))
+	assert.Equal(t, []byte{0x03, 0x04}, buffer)
+
+	// same range is served from the cache, a different one is not
+	mockR.Range = []byte{0x05, 0x06}
+	buffer = make([]byte, 2)
+	assert.NoError(t, r.ReadRange(ctx, "foo", keypath, 10, buffer))
+	assert.Equal(t, []byte{0x03, 0x04}, buffer)
+
+	assert.NoError(t, r.ReadRange(ctx, "foo", keypath, 12, buffer))
+	assert.Equal(t, []byte{0x05, 0x06}, buffer)
+}
+
+func TestTenantIndex(t *testing.T) {
+	keypath := backend.KeyPath([]string{"test"})
+
+	mockR := &backend.MockRawReader{
+		R: []byte{0x01},
+	}
+	mockW := &backend.MockRawWriter{}
+
+	// the tenant index is read without caching, so it is not cached by default
+	r, _, _ := NewCache(mockR, mockW, NewMockClient())
+	assert.Equal(t, []byte{0x01}, read(t, r, backend.TenantIndexName, keypath))
+	mockR.R = []byte{0x02}
+	assert.Equal(t, []byte{0x02}, read(t, r, backend.TenantIndexName, keypath))
+
+	r, _, _ = NewCacheWithTenantIndex(mockR, mockW, NewMockClient())
+	assert.Equal(t, []byte{0x02}, read(t, r, backend.TenantIndexName, keypath))
+	mockR.R = []byte{0x03}
+	assert.Equal(t, []byte{0x02}, read(t, r, backend.TenantIndexName, keypath))
+
+	// other objects are still not cached
+	assert.Equal(t, []byte{0x03}, read(t, r, "foo", keypath))
+}
+
+func TestTTL(t *testing.T) {
+	cfg := &TTLConfig{
+		Bloom:       time.Second,
+		Index:       2 * time.Second,
+		TenantIndex: 3 * time.Second,
+		Default:     4 * time.Second,
+	}
+	blockKeypath := backend.KeyPathForBlock(uuid.New(), "test")
+
+	assert.Equal(t, time.Second, cfg.TTL(key(blockKeypath, common.BloomName(3))))
+	assert.Equal(t, 2*time.Second, cfg.TTL(key(blockKeypath, common.NameIndex)))
+	assert.Equal(t, 3*time.Second, cfg.TTL(key(backend.KeyPath([]string{"test"}), backend.TenantIndexName)))
+	assert.Equal(t, 4*time.Second, cfg.TTL(key(blockKeypath, "search-header")))
+}
+
+func read(t *testing.T, r backend.RawReader, name string, keypath backend.KeyPath) []byte {
+	obj, _, err := r.Read(context.Background(), name, keypath, false)
+	assert.NoError(t, err)
+
+	b, err := io.ReadAll(obj)
+	assert.NoError(t, err)
+	return b
+}
@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"strings"
+	"time"
+
+	"github.com/grafana/tempo/tempodb/backend"
+	"github.com/grafana/tempo/tempodb/encoding/common"
+)
+
+// bloomPrefix is the prefix of the names of bloom filter objects, see common.BloomName
+const bloomPrefix = "bloom-"
+
+// TTLConfig holds the time to live of cached objects by their type. 0 means objects never expire.
+type TTLConfig struct {
+	Bloom       time.Duration `yaml:"bloom"`
+	Index       time.Duration `yaml:"index"`
+	TenantIndex time.Duration `yaml:"tenant_index"`
+	Default     time.Duration `yaml:"default"`
+}
+
+// TTL returns the time to live of the object cached under key.
+func (cfg *TTLConfig) TTL(key string) time.Duration {
+	name := key[strings.LastIndex(key, ":")+1:]
+
+	switch {
+	case strings.HasPrefix(name, bloomPrefix):
+		return cfg.Bloom
+	case name == common.NameIndex:
+		return cfg.Index
+	case name == backend.TenantIndexName:
+		return cfg.TenantIndex
+	default:
+		return cfg.Default
+	}
+}
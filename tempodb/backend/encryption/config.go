@@ -0,0 +1,66 @@
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+const (
+	// KeyProviderKeyring wraps data keys with keys read from a local file. See KeyringConfig.
+	KeyProviderKeyring = "keyring"
+)
+
+// Config configures client side encryption of block objects.
+type Config struct {
+	// KeyProvider is the name of the provider used to wrap and unwrap data keys. Encryption is disabled if empty.
+	KeyProvider string `yaml:"key_provider"`
+
+	Keyring KeyringConfig `yaml:"keyring"`
+}
+
+// Enabled returns true if a key provider is configured.
+func (c *Config) Enabled() bool {
+	return c != nil && c.KeyProvider != ""
+}
+
+// KeyProvider encrypts (wraps) and decrypts (unwraps) the per tenant data keys with a key encryption key
+// that never leaves the provider, e.g. a KMS.
+type KeyProvider interface {
+	// WrapKey wraps the data key of the tenant with the current key encryption key. It returns the id of the
+	// key encryption key and the wrapped data key.
+	WrapKey(ctx context.Context, tenantID string, dataKey []byte) (keyID string, wrapped []byte, err error)
+	// UnwrapKey returns the data key that was wrapped by WrapKey.
+	UnwrapKey(ctx context.Context, tenantID string, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// KeyProviderFactory creates a KeyProvider from the config.
+type KeyProviderFactory func(cfg *Config) (KeyProvider, error)
+
+var (
+	keyProvidersMtx sync.RWMutex
+	keyProviders    = map[string]KeyProviderFactory{
+		KeyProviderKeyring: newKeyringProvider,
+	}
+)
+
+// RegisterKeyProvider makes a key provider available under the given name.
+func RegisterKeyProvider(name string, factory KeyProviderFactory) {
+	keyProvidersMtx.Lock()
+	defer keyProvidersMtx.Unlock()
+
+	keyProviders[name] = factory
+}
+
+// NewKeyProvider creates the key provider configured in cfg.
+func NewKeyProvider(cfg *Config) (KeyProvider, error) {
+	keyProvidersMtx.RLock()
+	factory, ok := keyProviders[cfg.KeyProvider]
+	keyProvidersMtx.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key provider %s", cfg.KeyProvider)
+	}
+
+	return factory(cfg)
+}
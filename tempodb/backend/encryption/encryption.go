@@ -0,0 +1,603 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+
+	tempo_io "github.com/grafana/tempo/pkg/io"
+	"github.com/grafana/tempo/tempodb/backend"
+)
+
+// Encrypted objects start with a header followed by the object encrypted in chunks with AES-256-GCM:
+//
+//	magic (8) | version (1) | header length (4) | key id length (2) | key id | wrapped key length (2) | wrapped key | salt (16)
+//
+// Every object is encrypted with its own key, derived from the data key of the tenant and the random salt
+// of the header. Each chunk holds the length of its data followed by up to chunkDataSize bytes of data and
+// is padded to chunkSize, so all sealed chunks have the same size and ReadRange only has to read and
+// decrypt the chunks of the range. The path of the object and the index of the chunk are authenticated
+// with each chunk, which prevents chunks from being moved within or between objects. Only the last chunk
+// is not full, which detects truncated objects.
+var headerMagic = []byte("TEMPOENC")
+
+const (
+	headerVersion    = 1
+	headerPrefixSize = 8 + 1 + 4
+	saltSize         = 16
+
+	// chunkSize is the size of the plain chunks, chunkDataSize the data they hold and sealedChunkSize the
+	// size of the encrypted chunks in the backend
+	chunkSize       = 16 * 1024
+	chunkLengthSize = 4
+	chunkDataSize   = chunkSize - chunkLengthSize
+	sealedChunkSize = chunkSize + 16 // GCM tag
+
+	// headersCacheSize is the number of object headers kept in memory for ReadRange
+	headersCacheSize = 10000
+)
+
+type header struct {
+	keyID   string
+	wrapped []byte
+	salt    []byte
+	size    int
+}
+
+type readerWriter struct {
+	nextReader backend.RawReader
+	nextWriter backend.RawWriter
+	keys       *Keys
+
+	headersMtx sync.Mutex
+	headers    *simplelru.LRU
+}
+
+type appendTracker struct {
+	next    backend.AppendTracker
+	name    string
+	keypath backend.KeyPath
+	cipher  *objectCipher
+	index   uint64 // index of the next chunk
+	pending []byte // data that doesn't fill a chunk yet
+	header  []byte // header that is not written yet
+}
+
+// NewEncryption returns a reader and writer that encrypt the objects of blocks with the data key of the
+// tenant. The block meta is not encrypted, but records the keys used, and neither is the tenant index.
+// Both are read and copied by the backends directly and only contain metadata. Objects without a header,
+// e.g. written before encryption was enabled, are read as is.
+func NewEncryption(nextReader backend.RawReader, nextWriter backend.RawWriter, keys *Keys) (backend.RawReader, backend.RawWriter, error) {
+	headers, err := simplelru.NewLRU(headersCacheSize, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rw := &readerWriter{
+		nextReader: nextReader,
+		nextWriter: nextWriter,
+		keys:       keys,
+		headers:    headers,
+	}
+
+	return rw, rw, nil
+}
+
+// List implements backend.RawReader
+func (r *readerWriter) List(ctx context.Context, keypath backend.KeyPath) ([]string, error) {
+	return r.nextReader.List(ctx, keypath)
+}
+
+// Read implements backend.RawReader
+func (r *readerWriter) Read(ctx context.Context, name string, keypath backend.KeyPath, shouldCache bool) (io.ReadCloser, int64, error) {
+	object, size, err := r.nextReader.Read(ctx, name, keypath, shouldCache)
+	if err != nil || !shouldEncrypt(name, keypath) {
+		return object, size, err
+	}
+	defer object.Close()
+
+	b, err := tempo_io.ReadAllWithEstimate(object, size)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if !bytes.HasPrefix(b, headerMagic) {
+		return io.NopCloser(bytes.NewReader(b)), int64(len(b)), nil
+	}
+
+	h, err := unmarshalHeader(b)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read encryption header of %s: %w", name, err)
+	}
+
+	c, err := r.cipher(ctx, name, keypath, h)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sealed := b[h.size:]
+	if len(sealed) == 0 || len(sealed)%sealedChunkSize != 0 {
+		return nil, 0, fmt.Errorf("encrypted object %s is truncated", name)
+	}
+
+	// chunks are decrypted in place, their data is moved to the front of the buffer
+	chunks := uint64(len(sealed) / sealedChunkSize)
+	plain := sealed[:0]
+	for i := uint64(0); i < chunks; i++ {
+		data, err := c.open(i, sealed[i*sealedChunkSize:(i+1)*sealedChunkSize])
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to decrypt %s: %w", name, err)
+		}
+
+		// only the last chunk is not full
+		if last := i == chunks-1; last == (len(data) == chunkDataSize) {
+			return nil, 0, fmt.Errorf("encrypted object %s is truncated", name)
+		}
+
+		plain = append(plain, data...)
+	}
+
+	return io.NopCloser(bytes.NewReader(plain)), int64(len(plain)), nil
+}
+
+// ReadRange implements backend.RawReader
+func (r *readerWriter) ReadRange(ctx context.Context, name string, keypath backend.KeyPath, offset uint64, buffer []byte) error {
+	if !shouldEncrypt(name, keypath) || len(buffer) == 0 {
+		return r.nextReader.ReadRange(ctx, name, keypath, offset, buffer)
+	}
+
+	h, err := r.readHeader(ctx, name, keypath)
+	if err != nil {
+		return err
+	}
+	if h == nil {
+		return r.nextReader.ReadRange(ctx, name, keypath, offset, buffer)
+	}
+
+	first := offset / chunkDataSize
+	last := (offset + uint64(len(buffer)) - 1) / chunkDataSize
+
+	sealed := make([]byte, (last-first+1)*sealedChunkSize)
+	err = r.nextReader.ReadRange(ctx, name, keypath, uint64(h.size)+first*sealedChunkSize, sealed)
+	if err != nil {
+		return err
+	}
+
+	c, err := r.cipher(ctx, name, keypath, h)
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	skip := offset - first*chunkDataSize
+	for i := first; i <= last; i++ {
+		data, err := c.open(i, sealed[(i-first)*sealedChunkSize:(i-first+1)*sealedChunkSize])
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", name, err)
+		}
+		if uint64(len(data)) < skip {
+			break
+		}
+
+		n += copy(buffer[n:], data[skip:])
+		skip = 0
+	}
+	if n < len(buffer) {
+		return fmt.Errorf("range %d-%d is beyond the end of %s", offset, offset+uint64(len(buffer)), name)
+	}
+
+	return nil
+}
+
+// Shutdown implements backend.RawReader
+func (r *readerWriter) Shutdown() {
+	r.nextReader.Shutdown()
+}
+
+// Write implements backend.Writer
+func (r *readerWriter) Write(ctx context.Context, name string, keypath backend.KeyPath, data io.Reader, size int64, shouldCache bool) error {
+	if name == backend.MetaName {
+		return r.writeMeta(ctx, name, keypath, data, size, shouldCache)
+	}
+
+	if !shouldEncrypt(name, keypath) {
+		return r.nextWriter.Write(ctx, name, keypath, data, size, shouldCache)
+	}
+
+	b, err := tempo_io.ReadAllWithEstimate(data, size)
+	if err != nil {
+		return err
+	}
+
+	headerBytes, c, err := r.newObject(ctx, name, keypath)
+	if err != nil {
+		return err
+	}
+
+	encrypted := make([]byte, 0, len(headerBytes)+sealedSize(len(b)))
+	encrypted = append(encrypted, headerBytes...)
+	encrypted, _, _ = c.sealChunks(encrypted, 0, b, true)
+
+	return r.nextWriter.Write(ctx, name, keypath, bytes.NewReader(encrypted), int64(len(encrypted)), shouldCache)
+}
+
+// Append implements backend.Writer. Data is only written once it fills a chunk, the rest is written by
+// CloseAppend.
+func (r *readerWriter) Append(ctx context.Context, name string, keypath backend.KeyPath, tracker backend.AppendTracker, buffer []byte) (backend.AppendTracker, error) {
+	if !shouldEncrypt(name, keypath) {
+		return r.nextWriter.Append(ctx, name, keypath, tracker, buffer)
+	}
+
+	var a *appendTracker
+	if tracker == nil {
+		headerBytes, c, err := r.newObject(ctx, name, keypath)
+		if err != nil {
+			return nil, err
+		}
+
+		a = &appendTracker{
+			name:    name,
+			keypath: keypath,
+			cipher:  c,
+			header:  headerBytes,
+		}
+	} else {
+		var ok bool
+		a, ok = tracker.(*appendTracker)
+		if !ok {
+			return nil, fmt.Errorf("unexpected append tracker %T", tracker)
+		}
+	}
+
+	a.pending = append(a.pending, buffer...)
+	if len(a.pending) < chunkDataSize {
+		return a, nil
+	}
+
+	var encrypted []byte
+	var rest []byte
+	encrypted, a.index, rest = a.cipher.sealChunks(a.header, a.index, a.pending, false)
+	a.pending = append(a.pending[:0], rest...)
+
+	if err := a.write(ctx, r.nextWriter, encrypted); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// CloseAppend implements backend.Writer
+func (r *readerWriter) CloseAppend(ctx context.Context, tracker backend.AppendTracker) error {
+	if a, ok := tracker.(*appendTracker); ok {
+		encrypted, _, _ := a.cipher.sealChunks(a.header, a.index, a.pending, true)
+		if err := a.write(ctx, r.nextWriter, encrypted); err != nil {
+			return err
+		}
+		tracker = a.next
+	}
+	return r.nextWriter.CloseAppend(ctx, tracker)
+}
+
+func (a *appendTracker) write(ctx context.Context, w backend.RawWriter, encrypted []byte) error {
+	next, err := w.Append(ctx, a.name, a.keypath, a.next, encrypted)
+	if err != nil {
+		return err
+	}
+
+	a.next = next
+	a.header = nil
+	return nil
+}
+
+// writeMeta records the encryption keys of the tenant in the block meta.
+func (r *readerWriter) writeMeta(ctx context.Context, name string, keypath backend.KeyPath, data io.Reader, size int64, shouldCache bool) error {
+	b, err := tempo_io.ReadAllWithEstimate(data, size)
+	if err != nil {
+		return err
+	}
+
+	meta := &backend.BlockMeta{}
+	err = json.Unmarshal(b, meta)
+	if err != nil {
+		return err
+	}
+
+	meta.Encryption, err = r.keys.blockMeta(ctx, meta.TenantID)
+	if err != nil {
+		return err
+	}
+
+	b, err = json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	return r.nextWriter.Write(ctx, name, keypath, bytes.NewReader(b), int64(len(b)), shouldCache)
+}
+
+// newObject returns the header and the cipher to encrypt a new object of the tenant.
+func (r *readerWriter) newObject(ctx context.Context, name string, keypath backend.KeyPath) ([]byte, *objectCipher, error) {
+	dk, err := r.keys.dataKey(ctx, keypath[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, err
+	}
+
+	c, err := newObjectCipher(dk.key, salt, name, keypath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return marshalHeader(dk.keyID, dk.wrapped, salt), c, nil
+}
+
+// cipher returns the cipher to decrypt the object described by h.
+func (r *readerWriter) cipher(ctx context.Context, name string, keypath backend.KeyPath, h *header) (*objectCipher, error) {
+	key, err := r.keys.unwrap(ctx, keypath[0], h.keyID, h.wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+
+	return newObjectCipher(key, h.salt, name, keypath)
+}
+
+// readHeader returns the header of the object or nil if it is not encrypted. Headers are cached, objects of
+// blocks are never rewritten.
+func (r *readerWriter) readHeader(ctx context.Context, name string, keypath backend.KeyPath) (*header, error) {
+	cacheKey := objectPath(name, keypath)
+
+	r.headersMtx.Lock()
+	cached, ok := r.headers.Get(cacheKey)
+	r.headersMtx.Unlock()
+	if ok {
+		return cached.(*header), nil
+	}
+
+	prefix := make([]byte, headerPrefixSize)
+	err := r.nextReader.ReadRange(ctx, name, keypath, 0, prefix)
+	if errors.Is(err, backend.ErrDoesNotExist) {
+		return nil, err
+	}
+	if err != nil {
+		// objects written before encryption was enabled can be shorter than the prefix and backends fail
+		// to read ranges beyond the end of an object
+		prefix, err = r.readPrefix(ctx, name, keypath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var h *header
+	if bytes.HasPrefix(prefix, headerMagic) {
+		b := make([]byte, binary.BigEndian.Uint32(prefix[9:]))
+		if len(b) < headerPrefixSize {
+			return nil, fmt.Errorf("invalid encryption header of %s", name)
+		}
+
+		err = r.nextReader.ReadRange(ctx, name, keypath, 0, b)
+		if err != nil {
+			return nil, err
+		}
+
+		h, err = unmarshalHeader(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption header of %s: %w", name, err)
+		}
+	}
+
+	r.headersMtx.Lock()
+	r.headers.Add(cacheKey, h)
+	r.headersMtx.Unlock()
+
+	return h, nil
+}
+
+// readPrefix reads the header prefix of the object with a plain read. The prefix is shorter if the object is.
+func (r *readerWriter) readPrefix(ctx context.Context, name string, keypath backend.KeyPath) ([]byte, error) {
+	object, _, err := r.nextReader.Read(ctx, name, keypath, false)
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+
+	prefix := make([]byte, headerPrefixSize)
+	n, err := io.ReadFull(object, prefix)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+
+	return prefix[:n], nil
+}
+
+// shouldEncrypt returns true for all objects of a block except the block meta.
+func shouldEncrypt(name string, keypath backend.KeyPath) bool {
+	if len(keypath) < 2 {
+		return false
+	}
+	return name != backend.MetaName && name != backend.CompactedMetaName
+}
+
+func marshalHeader(keyID string, wrapped []byte, salt []byte) []byte {
+	size := headerPrefixSize + 2 + len(keyID) + 2 + len(wrapped) + len(salt)
+
+	b := make([]byte, size)
+	copy(b, headerMagic)
+	b[8] = headerVersion
+	binary.BigEndian.PutUint32(b[9:], uint32(size))
+
+	i := headerPrefixSize
+	binary.BigEndian.PutUint16(b[i:], uint16(len(keyID)))
+	i += 2
+	i += copy(b[i:], keyID)
+	binary.BigEndian.PutUint16(b[i:], uint16(len(wrapped)))
+	i += 2
+	i += copy(b[i:], wrapped)
+	copy(b[i:], salt)
+
+	return b
+}
+
+func unmarshalHeader(b []byte) (*header, error) {
+	if len(b) < headerPrefixSize || !bytes.HasPrefix(b, headerMagic) {
+		return nil, fmt.Errorf("missing header")
+	}
+	if b[8] != headerVersion {
+		return nil, fmt.Errorf("unsupported header version %d", b[8])
+	}
+
+	h := &header{
+		size: int(binary.BigEndian.Uint32(b[9:])),
+	}
+	if h.size > len(b) {
+		return nil, fmt.Errorf("header truncated")
+	}
+
+	rest := b[headerPrefixSize:h.size]
+	next := func(n int) ([]byte, error) {
+		if len(rest) < n {
+			return nil, fmt.Errorf("header truncated")
+		}
+		v := rest[:n]
+		rest = rest[n:]
+		return v, nil
+	}
+
+	l, err := next(2)
+	if err != nil {
+		return nil, err
+	}
+	keyID, err := next(int(binary.BigEndian.Uint16(l)))
+	if err != nil {
+		return nil, err
+	}
+	h.keyID = string(keyID)
+
+	l, err = next(2)
+	if err != nil {
+		return nil, err
+	}
+	h.wrapped, err = next(int(binary.BigEndian.Uint16(l)))
+	if err != nil {
+		return nil, err
+	}
+
+	h.salt, err = next(saltSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// objectCipher encrypts and decrypts the chunks of an object.
+type objectCipher struct {
+	aead cipher.AEAD
+	path string
+}
+
+// newObjectCipher returns the cipher of the object with the key derived from the data key and the salt.
+func newObjectCipher(dataKey []byte, salt []byte, name string, keypath backend.KeyPath) (*objectCipher, error) {
+	mac := hmac.New(sha256.New, dataKey)
+	_, _ = mac.Write(salt)
+
+	block, err := aes.NewCipher(mac.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &objectCipher{
+		aead: aead,
+		path: objectPath(name, keypath),
+	}, nil
+}
+
+// sealChunks appends the sealed chunks of data to dst, starting with chunk index. If final is not set, data
+// that doesn't fill a chunk is returned instead of sealed. Otherwise it is sealed as the last chunk, which
+// is empty if data fills all chunks. The index of the next chunk is returned as well.
+func (c *objectCipher) sealChunks(dst []byte, index uint64, data []byte, final bool) ([]byte, uint64, []byte) {
+	plain := make([]byte, chunkSize)
+	for len(data) >= chunkDataSize || final {
+		n := len(data)
+		if n > chunkDataSize {
+			n = chunkDataSize
+		}
+
+		for i := range plain {
+			plain[i] = 0
+		}
+		binary.BigEndian.PutUint32(plain, uint32(n))
+		copy(plain[chunkLengthSize:], data[:n])
+
+		dst = c.aead.Seal(dst, c.nonce(index), plain, c.additionalData(index))
+		index++
+		data = data[n:]
+
+		if final && n < chunkDataSize {
+			break
+		}
+	}
+
+	return dst, index, data
+}
+
+// open decrypts the sealed chunk in place and returns its data.
+func (c *objectCipher) open(index uint64, sealed []byte) ([]byte, error) {
+	plain, err := c.aead.Open(sealed[:0], c.nonce(index), sealed, c.additionalData(index))
+	if err != nil {
+		return nil, fmt.Errorf("chunk %d: %w", index, err)
+	}
+
+	n := binary.BigEndian.Uint32(plain)
+	if n > chunkDataSize {
+		return nil, fmt.Errorf("chunk %d: invalid length %d", index, n)
+	}
+
+	return plain[chunkLengthSize : chunkLengthSize+n], nil
+}
+
+// nonce returns the index of the chunk as nonce. Nonces are unique since every object has its own key.
+func (c *objectCipher) nonce(index uint64) []byte {
+	nonce := make([]byte, c.aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], index)
+	return nonce
+}
+
+// additionalData returns the path of the object and the index of the chunk, which are authenticated with
+// the chunk.
+func (c *objectCipher) additionalData(index uint64) []byte {
+	b := make([]byte, len(c.path)+8)
+	copy(b, c.path)
+	binary.BigEndian.PutUint64(b[len(c.path):], index)
+	return b
+}
+
+// sealedSize returns the size of size bytes of data once sealed.
+func sealedSize(size int) int {
+	return (size/chunkDataSize + 1) * sealedChunkSize
+}
+
+func objectPath(name string, keypath backend.KeyPath) string {
+	return strings.Join(keypath, "/") + "/" + name
+}
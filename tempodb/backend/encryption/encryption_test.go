@@ -0,0 +1,282 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/tempo/tempodb/backend"
+	"github.com/grafana/tempo/tempodb/backend/local"
+)
+
+const testTenant = "tenant"
+
+func newTestKeyring(t *testing.T) *Config {
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "keyring.yaml")
+	err = os.WriteFile(path, []byte("active_key: key-1\nkeys:\n  key-1: "+base64.StdEncoding.EncodeToString(key)+"\n"), 0600)
+	require.NoError(t, err)
+
+	return &Config{
+		KeyProvider: KeyProviderKeyring,
+		Keyring:     KeyringConfig{Path: path},
+	}
+}
+
+func newTestEncryption(t *testing.T) (backend.RawReader, backend.RawWriter, backend.RawReader) {
+	rawR, rawW, _, err := local.New(&local.Config{Path: t.TempDir()})
+	require.NoError(t, err)
+
+	keys, err := NewKeys(newTestKeyring(t))
+	require.NoError(t, err)
+
+	r, w, err := NewEncryption(rawR, rawW, keys)
+	require.NoError(t, err)
+
+	return r, w, rawR
+}
+
+func readAll(t *testing.T, r backend.RawReader, name string, keypath backend.KeyPath) []byte {
+	obj, size, err := r.Read(context.Background(), name, keypath, false)
+	require.NoError(t, err)
+	defer obj.Close()
+
+	b, err := io.ReadAll(obj)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(b)), size)
+
+	return b
+}
+
+func TestReadWrite(t *testing.T) {
+	r, w, rawR := newTestEncryption(t)
+	ctx := context.Background()
+	keypath := backend.KeyPathForBlock(uuid.New(), testTenant)
+
+	data := make([]byte, 3*chunkDataSize+1000)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+
+	err = w.Write(ctx, "data", keypath, bytes.NewReader(data), int64(len(data)), false)
+	require.NoError(t, err)
+
+	// the object is encrypted in the backend
+	raw := readAll(t, rawR, "data", keypath)
+	assert.True(t, bytes.HasPrefix(raw, headerMagic))
+	assert.NotContains(t, string(raw), string(data[:100]))
+
+	assert.Equal(t, data, readAll(t, r, "data", keypath))
+
+	// ranges that start and end within and across chunks
+	for _, tc := range []struct{ offset, length int }{{0, 10}, {5, 20}, {chunkDataSize - 1, 2}, {chunkDataSize, chunkDataSize}, {17, 2*chunkDataSize + 100}, {len(data) - 1, 1}, {0, len(data)}} {
+		buffer := make([]byte, tc.length)
+		err = r.ReadRange(ctx, "data", keypath, uint64(tc.offset), buffer)
+		require.NoError(t, err)
+		assert.Equal(t, data[tc.offset:tc.offset+tc.length], buffer, "offset %d length %d", tc.offset, tc.length)
+	}
+
+	// ranges beyond the end of the object
+	err = r.ReadRange(ctx, "data", keypath, uint64(len(data)-1), make([]byte, 2))
+	assert.Error(t, err)
+
+	// data that fills all chunks is followed by an empty last chunk
+	data = data[:2*chunkDataSize]
+	err = w.Write(ctx, "full", keypath, bytes.NewReader(data), int64(len(data)), false)
+	require.NoError(t, err)
+	assert.Equal(t, data, readAll(t, r, "full", keypath))
+}
+
+func TestTamperedObjects(t *testing.T) {
+	r, w, rawR := newTestEncryption(t)
+	ctx := context.Background()
+	keypath := backend.KeyPathForBlock(uuid.New(), testTenant)
+	rawW := w.(*readerWriter).nextWriter
+
+	data := make([]byte, 2*chunkDataSize+10)
+	_, err := rand.Read(data)
+	require.NoError(t, err)
+
+	err = w.Write(ctx, "data", keypath, bytes.NewReader(data), int64(len(data)), false)
+	require.NoError(t, err)
+	raw := readAll(t, rawR, "data", keypath)
+	headerSize := len(raw) - 3*sealedChunkSize
+
+	write := func(name string, b []byte) {
+		err := rawW.Write(ctx, name, keypath, bytes.NewReader(b), int64(len(b)), false)
+		require.NoError(t, err)
+	}
+	assertCorrupted := func(name string) {
+		_, _, err := r.Read(ctx, name, keypath, false)
+		assert.Error(t, err, name)
+	}
+
+	// modified data
+	modified := append([]byte{}, raw...)
+	modified[headerSize+chunkSize/2] ^= 1
+	write("modified", modified)
+	assertCorrupted("modified")
+	err = r.ReadRange(ctx, "modified", keypath, chunkSize/2, make([]byte, 1))
+	assert.Error(t, err)
+
+	// truncated at a chunk boundary
+	write("truncated", raw[:len(raw)-sealedChunkSize])
+	assertCorrupted("truncated")
+
+	// reordered chunks
+	reordered := append([]byte{}, raw[:headerSize]...)
+	reordered = append(reordered, raw[headerSize+sealedChunkSize:headerSize+2*sealedChunkSize]...)
+	reordered = append(reordered, raw[headerSize:headerSize+sealedChunkSize]...)
+	reordered = append(reordered, raw[headerSize+2*sealedChunkSize:]...)
+	write("reordered", reordered)
+	assertCorrupted("reordered")
+
+	// copied to another object
+	write("copied", raw)
+	assertCorrupted("copied")
+}
+
+func TestAppend(t *testing.T) {
+	r, w, _ := newTestEncryption(t)
+	ctx := context.Background()
+	keypath := backend.KeyPathForBlock(uuid.New(), testTenant)
+
+	var expected []byte
+	var tracker backend.AppendTracker
+	for i := 0; i < 10; i++ {
+		buffer := bytes.Repeat([]byte{byte(i)}, 7*i+3)
+		expected = append(expected, buffer...)
+
+		var err error
+		tracker, err = w.Append(ctx, "data", keypath, tracker, buffer)
+		require.NoError(t, err)
+
+		// the buffer is not modified
+		assert.Equal(t, bytes.Repeat([]byte{byte(i)}, 7*i+3), buffer)
+	}
+	require.NoError(t, w.CloseAppend(ctx, tracker))
+
+	assert.Equal(t, expected, readAll(t, r, "data", keypath))
+
+	buffer := make([]byte, 50)
+	require.NoError(t, r.ReadRange(ctx, "data", keypath, 33, buffer))
+	assert.Equal(t, expected[33:83], buffer)
+
+	// appends that fill several chunks at once
+	expected = nil
+	tracker = nil
+	for i := 0; i < 5; i++ {
+		buffer := bytes.Repeat([]byte{byte(i)}, chunkDataSize+1000*i)
+		expected = append(expected, buffer...)
+
+		var err error
+		tracker, err = w.Append(ctx, "large", keypath, tracker, buffer)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.CloseAppend(ctx, tracker))
+
+	assert.Equal(t, expected, readAll(t, r, "large", keypath))
+}
+
+func TestBlockMeta(t *testing.T) {
+	r, w, _ := newTestEncryption(t)
+	ctx := context.Background()
+
+	meta := backend.NewBlockMeta(testTenant, uuid.New(), "v2", backend.EncNone, "")
+	err := backend.NewWriter(w).WriteBlockMeta(ctx, meta)
+	require.NoError(t, err)
+
+	// the meta is readable without the wrapper and records the keys
+	b := readAll(t, r, backend.MetaName, backend.KeyPathForBlock(meta.BlockID, testTenant))
+	actual := &backend.BlockMeta{}
+	require.NoError(t, json.Unmarshal(b, actual))
+
+	require.NotNil(t, actual.Encryption)
+	assert.Equal(t, KeyProviderKeyring, actual.Encryption.KeyProvider)
+	assert.Equal(t, "key-1", actual.Encryption.KeyID)
+	assert.NotEmpty(t, actual.Encryption.DataKeyID)
+}
+
+func TestUnencryptedObjects(t *testing.T) {
+	rawR, rawW, _, err := local.New(&local.Config{Path: t.TempDir()})
+	require.NoError(t, err)
+
+	keys, err := NewKeys(newTestKeyring(t))
+	require.NoError(t, err)
+
+	r, _, err := NewEncryption(rawR, rawW, keys)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	keypath := backend.KeyPathForBlock(uuid.New(), testTenant)
+	data := []byte("written before encryption was enabled")
+
+	err = rawW.Write(ctx, "data", keypath, bytes.NewReader(data), int64(len(data)), false)
+	require.NoError(t, err)
+
+	assert.Equal(t, data, readAll(t, r, "data", keypath))
+
+	buffer := make([]byte, 10)
+	require.NoError(t, r.ReadRange(ctx, "data", keypath, 15, buffer))
+	assert.Equal(t, data[15:25], buffer)
+
+	// objects shorter than the header prefix
+	data = []byte("short")
+	err = rawW.Write(ctx, "short", keypath, bytes.NewReader(data), int64(len(data)), false)
+	require.NoError(t, err)
+
+	buffer = make([]byte, 3)
+	require.NoError(t, r.ReadRange(ctx, "short", keypath, 1, buffer))
+	assert.Equal(t, data[1:4], buffer)
+}
+
+func TestDataKeyIsTenantScoped(t *testing.T) {
+	cfg := newTestKeyring(t)
+	provider, err := NewKeyProvider(cfg)
+	require.NoError(t, err)
+
+	keyID, wrapped, err := provider.WrapKey(context.Background(), "a", []byte("data key"))
+	require.NoError(t, err)
+
+	key, err := provider.UnwrapKey(context.Background(), "a", keyID, wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data key"), key)
+
+	_, err = provider.UnwrapKey(context.Background(), "b", keyID, wrapped)
+	assert.Error(t, err)
+}
+
+func TestKeyringErrors(t *testing.T) {
+	_, err := newKeyringProviderFromFile(keyringFile{ActiveKey: "missing"})
+	assert.Error(t, err)
+
+	_, err = newKeyringProviderFromFile(keyringFile{ActiveKey: "key", Keys: map[string]string{"key": base64.StdEncoding.EncodeToString([]byte("short"))}})
+	assert.Error(t, err)
+
+	_, err = NewKeyProvider(&Config{KeyProvider: "unknown"})
+	assert.Error(t, err)
+}
+
+func TestHeader(t *testing.T) {
+	salt := bytes.Repeat([]byte{1}, saltSize)
+	b := marshalHeader("key", []byte("wrapped"), salt)
+
+	h, err := unmarshalHeader(b)
+	require.NoError(t, err)
+	assert.Equal(t, &header{keyID: "key", wrapped: []byte("wrapped"), salt: salt, size: len(b)}, h)
+
+	_, err = unmarshalHeader(b[:len(b)-1])
+	assert.Error(t, err)
+}
@@ -0,0 +1,118 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// KeyringConfig configures the keyring key provider.
+type KeyringConfig struct {
+	// Path of a yaml file with the key encryption keys:
+	//
+	//   active_key: key-2
+	//   keys:
+	//     key-1: <base64 encoded 32 byte key>
+	//     key-2: <base64 encoded 32 byte key>
+	//
+	// New data keys are wrapped with the active key. Old keys have to be kept as long as objects
+	// encrypted with them exist.
+	Path string `yaml:"path"`
+}
+
+type keyringFile struct {
+	ActiveKey string            `yaml:"active_key"`
+	Keys      map[string]string `yaml:"keys"`
+}
+
+// keyringProvider is a KeyProvider that wraps data keys with AES-GCM using keys read from a local file.
+// It's meant for testing and small installations. The tenant ID is authenticated with the wrapped key,
+// so a data key can't be used for a different tenant.
+type keyringProvider struct {
+	activeKey string
+	keys      map[string]cipher.AEAD
+}
+
+func newKeyringProvider(cfg *Config) (KeyProvider, error) {
+	if cfg.Keyring.Path == "" {
+		return nil, fmt.Errorf("keyring path must be set")
+	}
+
+	b, err := os.ReadFile(cfg.Keyring.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keyring: %w", err)
+	}
+
+	var f keyringFile
+	if err := yaml.UnmarshalStrict(b, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse keyring: %w", err)
+	}
+
+	return newKeyringProviderFromFile(f)
+}
+
+func newKeyringProviderFromFile(f keyringFile) (*keyringProvider, error) {
+	p := &keyringProvider{
+		activeKey: f.ActiveKey,
+		keys:      make(map[string]cipher.AEAD, len(f.Keys)),
+	}
+
+	for id, encoded := range f.Keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode keyring key %s: %w", id, err)
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("keyring key %s must be 32 bytes", id)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		p.keys[id] = aead
+	}
+
+	if _, ok := p.keys[p.activeKey]; !ok {
+		return nil, fmt.Errorf("active keyring key %q not found", p.activeKey)
+	}
+
+	return p, nil
+}
+
+// WrapKey implements KeyProvider
+func (p *keyringProvider) WrapKey(_ context.Context, tenantID string, dataKey []byte) (string, []byte, error) {
+	aead := p.keys[p.activeKey]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+
+	return p.activeKey, aead.Seal(nonce, nonce, dataKey, []byte(tenantID)), nil
+}
+
+// UnwrapKey implements KeyProvider
+func (p *keyringProvider) UnwrapKey(_ context.Context, tenantID string, keyID string, wrapped []byte) ([]byte, error) {
+	aead, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("keyring key %q not found", keyID)
+	}
+
+	if len(wrapped) < aead.NonceSize() {
+		return nil, fmt.Errorf("wrapped key too short")
+	}
+
+	nonce, ciphertext := wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, []byte(tenantID))
+}
@@ -0,0 +1,143 @@
+package encryption
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+
+	"github.com/grafana/tempo/tempodb/backend"
+)
+
+const (
+	dataKeySize = 32
+
+	// unwrappedKeysCacheSize is the number of unwrapped data keys kept in memory. Every process creates
+	// one data key per tenant, so this only has to hold the keys of all writers of the active tenants.
+	unwrappedKeysCacheSize = 10000
+)
+
+// dataKey is a key used to encrypt objects of a tenant.
+type dataKey struct {
+	key     []byte
+	keyID   string // id of the key encryption key
+	wrapped []byte
+}
+
+// Keys manages the per tenant data keys. A data key is created for a tenant when the first object of the
+// tenant is written and kept for the lifetime of the process. Data keys are only stored wrapped, in the
+// header of every object they encrypt. Unwrapped keys are cached so the key provider is only called once
+// per data key.
+type Keys struct {
+	provider     KeyProvider
+	providerName string
+
+	mtx       sync.Mutex
+	dataKeys  map[string]*dataKey
+	unwrapped *simplelru.LRU
+}
+
+// NewKeys returns Keys that wraps data keys with the provider configured in cfg.
+func NewKeys(cfg *Config) (*Keys, error) {
+	provider, err := NewKeyProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return newKeys(provider, cfg.KeyProvider)
+}
+
+func newKeys(provider KeyProvider, providerName string) (*Keys, error) {
+	unwrapped, err := simplelru.NewLRU(unwrappedKeysCacheSize, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Keys{
+		provider:     provider,
+		providerName: providerName,
+		dataKeys:     make(map[string]*dataKey),
+		unwrapped:    unwrapped,
+	}, nil
+}
+
+// dataKey returns the data key used to encrypt new objects of the tenant.
+func (k *Keys) dataKey(ctx context.Context, tenantID string) (*dataKey, error) {
+	k.mtx.Lock()
+	defer k.mtx.Unlock()
+
+	if dk, ok := k.dataKeys[tenantID]; ok {
+		return dk, nil
+	}
+
+	key := make([]byte, dataKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	keyID, wrapped, err := k.provider.WrapKey(ctx, tenantID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	dk := &dataKey{
+		key:     key,
+		keyID:   keyID,
+		wrapped: wrapped,
+	}
+	k.dataKeys[tenantID] = dk
+	k.unwrapped.Add(unwrappedKey(tenantID, keyID, wrapped), key)
+
+	return dk, nil
+}
+
+// unwrap returns the unwrapped data key from an object header.
+func (k *Keys) unwrap(ctx context.Context, tenantID string, keyID string, wrapped []byte) ([]byte, error) {
+	cacheKey := unwrappedKey(tenantID, keyID, wrapped)
+
+	k.mtx.Lock()
+	key, ok := k.unwrapped.Get(cacheKey)
+	k.mtx.Unlock()
+	if ok {
+		return key.([]byte), nil
+	}
+
+	// unwrap outside of the lock, providers usually make a remote call
+	unwrappedKey, err := k.provider.UnwrapKey(ctx, tenantID, keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	k.mtx.Lock()
+	k.unwrapped.Add(cacheKey, unwrappedKey)
+	k.mtx.Unlock()
+
+	return unwrappedKey, nil
+}
+
+// blockMeta returns the encryption metadata recorded in the block meta of the tenant's blocks.
+func (k *Keys) blockMeta(ctx context.Context, tenantID string) (*backend.EncryptionMeta, error) {
+	dk, err := k.dataKey(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &backend.EncryptionMeta{
+		KeyProvider: k.providerName,
+		KeyID:       dk.keyID,
+		DataKeyID:   dataKeyID(dk.wrapped),
+	}, nil
+}
+
+func unwrappedKey(tenantID string, keyID string, wrapped []byte) string {
+	return tenantID + "\x00" + keyID + "\x00" + string(wrapped)
+}
+
+// dataKeyID identifies a data key without revealing it.
+func dataKeyID(wrapped []byte) string {
+	h := sha256.Sum256(wrapped)
+	return hex.EncodeToString(h[:8])
+}
@@ -92,6 +92,9 @@ type MockReader struct {
 	M             *BlockMeta // meta
 	BlockMetaFn   func(ctx context.Context, blockID uuid.UUID, tenantID string) (*BlockMeta, error)
 	TenantIndexFn func(ctx context.Context, tenantID string) (*TenantIndex, error)
+	SnapshotFn    func(ctx context.Context, tenantID string, builder int) (*TenantIndex, error)
+	DeltaFn       func(ctx context.Context, tenantID string, builder int, slot int) (*TenantIndexDelta, error)
+	HeadFn        func(ctx context.Context, tenantID string) (*TenantIndexHead, error)
 	R             []byte // read
 	Range         []byte // ReadRange
 	ReadFn        func(name string, blockID uuid.UUID, tenantID string) ([]byte, error)
@@ -140,12 +143,39 @@ func (m *MockReader) TenantIndex(ctx context.Context, tenantID string) (*TenantI
 	return &TenantIndex{}, nil
 }
 
+func (m *MockReader) TenantIndexSnapshot(ctx context.Context, tenantID string, builder int) (*TenantIndex, error) {
+	if m.SnapshotFn != nil {
+		return m.SnapshotFn(ctx, tenantID, builder)
+	}
+
+	return nil, ErrDoesNotExist
+}
+
+func (m *MockReader) TenantIndexDelta(ctx context.Context, tenantID string, builder int, slot int) (*TenantIndexDelta, error) {
+	if m.DeltaFn != nil {
+		return m.DeltaFn(ctx, tenantID, builder, slot)
+	}
+
+	return nil, ErrDoesNotExist
+}
+
+func (m *MockReader) TenantIndexHead(ctx context.Context, tenantID string) (*TenantIndexHead, error) {
+	if m.HeadFn != nil {
+		return m.HeadFn(ctx, tenantID)
+	}
+
+	return nil, ErrDoesNotExist
+}
+
 func (m *MockReader) Shutdown() {}
 
 // MockWriter
 type MockWriter struct {
 	IndexMeta          map[string][]*BlockMeta
 	IndexCompactedMeta map[string][]*CompactedBlockMeta
+	IndexVersion       map[string]uint64
+	IndexDeltas        map[string]map[int]*TenantIndexDelta
+	IndexHead          map[string]*TenantIndexHead
 }
 
 func (m *MockWriter) Write(ctx context.Context, name string, blockID uuid.UUID, tenantID string, buffer []byte, shouldCache bool) error {
@@ -174,3 +204,27 @@ func (m *MockWriter) WriteTenantIndex(ctx context.Context, tenantID string, meta
 	m.IndexCompactedMeta[tenantID] = compactedMeta
 	return nil
 }
+func (m *MockWriter) WriteTenantIndexSnapshot(ctx context.Context, tenantID string, builder int, generation string, version uint64, meta []*BlockMeta, compactedMeta []*CompactedBlockMeta) error {
+	if m.IndexVersion == nil {
+		m.IndexVersion = make(map[string]uint64)
+	}
+	m.IndexVersion[tenantID] = version
+	return nil
+}
+func (m *MockWriter) WriteTenantIndexDelta(ctx context.Context, tenantID string, builder int, slot int, delta *TenantIndexDelta) error {
+	if m.IndexDeltas == nil {
+		m.IndexDeltas = make(map[string]map[int]*TenantIndexDelta)
+	}
+	if m.IndexDeltas[tenantID] == nil {
+		m.IndexDeltas[tenantID] = make(map[int]*TenantIndexDelta)
+	}
+	m.IndexDeltas[tenantID][slot] = delta
+	return nil
+}
+func (m *MockWriter) WriteTenantIndexHead(ctx context.Context, tenantID string, head *TenantIndexHead) error {
+	if m.IndexHead == nil {
+		m.IndexHead = make(map[string]*TenantIndexHead)
+	}
+	m.IndexHead[tenantID] = head
+	return nil
+}
@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io"
 	"path"
+	"strconv"
+	"strings"
 
 	"github.com/google/uuid"
 
@@ -17,8 +19,23 @@ const (
 	MetaName          = "meta.json"
 	CompactedMetaName = "meta.compacted.json"
 	TenantIndexName   = "index.json.gz"
+
+	TenantIndexHeadName           = "index-head.json"
+	tenantIndexSnapshotNamePrefix = "index-base-"
+	tenantIndexDeltaNamePrefix    = "index-delta-"
+	tenantIndexDeltaNameSuffix    = ".json.gz"
 )
 
+// TenantIndexSnapshotName returns the name of the base snapshot of the tenant index deltas of the given builder
+func TenantIndexSnapshotName(builder int) string {
+	return tenantIndexSnapshotNamePrefix + strconv.Itoa(builder) + tenantIndexDeltaNameSuffix
+}
+
+// TenantIndexDeltaName returns the name of the tenant index delta of the given builder in the given slot
+func TenantIndexDeltaName(builder int, slot int) string {
+	return tenantIndexDeltaNamePrefix + strconv.Itoa(builder) + "-" + strconv.Itoa(slot) + tenantIndexDeltaNameSuffix
+}
+
 // KeyPath is an ordered set of strings that govern where data is read/written from the backend
 type KeyPath []string
 
@@ -84,14 +101,24 @@ func (w *writer) CloseAppend(ctx context.Context, tracker AppendTracker) error {
 }
 
 func (w *writer) WriteTenantIndex(ctx context.Context, tenantID string, meta []*BlockMeta, compactedMeta []*CompactedBlockMeta) error {
+	return w.writeTenantIndex(ctx, tenantID, TenantIndexName, newTenantIndex(meta, compactedMeta))
+}
+
+func (w *writer) WriteTenantIndexSnapshot(ctx context.Context, tenantID string, builder int, generation string, version uint64, meta []*BlockMeta, compactedMeta []*CompactedBlockMeta) error {
 	b := newTenantIndex(meta, compactedMeta)
+	b.Generation = generation
+	b.Version = version
 
+	return w.writeTenantIndex(ctx, tenantID, TenantIndexSnapshotName(builder), b)
+}
+
+func (w *writer) writeTenantIndex(ctx context.Context, tenantID string, name string, b *TenantIndex) error {
 	indexBytes, err := b.marshal()
 	if err != nil {
 		return err
 	}
 
-	err = w.w.Write(ctx, TenantIndexName, KeyPath([]string{tenantID}), bytes.NewReader(indexBytes), int64(len(indexBytes)), false)
+	err = w.w.Write(ctx, name, KeyPath([]string{tenantID}), bytes.NewReader(indexBytes), int64(len(indexBytes)), false)
 	if err != nil {
 		return err
 	}
@@ -99,6 +126,24 @@ func (w *writer) WriteTenantIndex(ctx context.Context, tenantID string, meta []*
 	return nil
 }
 
+func (w *writer) WriteTenantIndexDelta(ctx context.Context, tenantID string, builder int, slot int, delta *TenantIndexDelta) error {
+	deltaBytes, err := marshalGzipJSON(delta)
+	if err != nil {
+		return err
+	}
+
+	return w.w.Write(ctx, TenantIndexDeltaName(builder, slot), KeyPath([]string{tenantID}), bytes.NewReader(deltaBytes), int64(len(deltaBytes)), false)
+}
+
+func (w *writer) WriteTenantIndexHead(ctx context.Context, tenantID string, head *TenantIndexHead) error {
+	headBytes, err := json.Marshal(head)
+	if err != nil {
+		return err
+	}
+
+	return w.w.Write(ctx, TenantIndexHeadName, KeyPath([]string{tenantID}), bytes.NewReader(headBytes), int64(len(headBytes)), false)
+}
+
 type reader struct {
 	r RawReader
 }
@@ -142,7 +187,7 @@ func (r *reader) Blocks(ctx context.Context, tenantID string) ([]uuid.UUID, erro
 	for _, id := range objects {
 		// TODO: this line exists due to behavior differences in backends: https://github.com/grafana/tempo/issues/880
 		// revisit once #880 is resolved.
-		if id == TenantIndexName || id == "" {
+		if isTenantIndexObject(id) || id == "" {
 			continue
 		}
 		uuid, err := uuid.Parse(id)
@@ -177,12 +222,15 @@ func (r *reader) BlockMeta(ctx context.Context, blockID uuid.UUID, tenantID stri
 }
 
 func (r *reader) TenantIndex(ctx context.Context, tenantID string) (*TenantIndex, error) {
-	reader, size, err := r.r.Read(ctx, TenantIndexName, KeyPath([]string{tenantID}), false)
-	if err != nil {
-		return nil, err
-	}
+	return r.readTenantIndex(ctx, tenantID, TenantIndexName)
+}
 
-	bytes, err := tempo_io.ReadAllWithEstimate(reader, size)
+func (r *reader) TenantIndexSnapshot(ctx context.Context, tenantID string, builder int) (*TenantIndex, error) {
+	return r.readTenantIndex(ctx, tenantID, TenantIndexSnapshotName(builder))
+}
+
+func (r *reader) readTenantIndex(ctx context.Context, tenantID string, name string) (*TenantIndex, error) {
+	bytes, err := r.readTenantObject(ctx, tenantID, name)
 	if err != nil {
 		return nil, err
 	}
@@ -196,6 +244,46 @@ func (r *reader) TenantIndex(ctx context.Context, tenantID string) (*TenantIndex
 	return i, nil
 }
 
+func (r *reader) TenantIndexDelta(ctx context.Context, tenantID string, builder int, slot int) (*TenantIndexDelta, error) {
+	bytes, err := r.readTenantObject(ctx, tenantID, TenantIndexDeltaName(builder, slot))
+	if err != nil {
+		return nil, err
+	}
+
+	d := &TenantIndexDelta{}
+	err = unmarshalGzipJSON(bytes, d)
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (r *reader) TenantIndexHead(ctx context.Context, tenantID string) (*TenantIndexHead, error) {
+	bytes, err := r.readTenantObject(ctx, tenantID, TenantIndexHeadName)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &TenantIndexHead{}
+	err = json.Unmarshal(bytes, h)
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+func (r *reader) readTenantObject(ctx context.Context, tenantID string, name string) ([]byte, error) {
+	reader, size, err := r.r.Read(ctx, name, KeyPath([]string{tenantID}), false)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return tempo_io.ReadAllWithEstimate(reader, size)
+}
+
 func (r *reader) Shutdown() {
 	r.r.Shutdown()
 }
@@ -224,3 +312,10 @@ func CompactedMetaFileName(blockID uuid.UUID, tenantID string) string {
 func RootPath(blockID uuid.UUID, tenantID string) string {
 	return path.Join(tenantID, blockID.String())
 }
+
+// isTenantIndexObject returns true for the objects of the tenant index that are stored next to the blocks
+func isTenantIndexObject(name string) bool {
+	return name == TenantIndexName || name == TenantIndexHeadName ||
+		((strings.HasPrefix(name, tenantIndexSnapshotNamePrefix) || strings.HasPrefix(name, tenantIndexDeltaNamePrefix)) &&
+			strings.HasSuffix(name, tenantIndexDeltaNameSuffix))
+}
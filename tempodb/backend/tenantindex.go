@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/klauspost/compress/gzip"
 )
 
@@ -18,6 +19,9 @@ type TenantIndex struct {
 	CreatedAt     time.Time             `json:"created_at"`
 	Meta          []*BlockMeta          `json:"meta"`
 	CompactedMeta []*CompactedBlockMeta `json:"compacted"`
+	// Generation and Version are set if the tenant index is the base snapshot of deltas, see TenantIndexHead
+	Generation string `json:"generation,omitempty"`
+	Version    uint64 `json:"version,omitempty"`
 }
 
 func newTenantIndex(meta []*BlockMeta, compactedMeta []*CompactedBlockMeta) *TenantIndex {
@@ -30,12 +34,64 @@ func newTenantIndex(meta []*BlockMeta, compactedMeta []*CompactedBlockMeta) *Ten
 
 // marshal converts to json and compresses the bucketindex
 func (b *TenantIndex) marshal() ([]byte, error) {
+	return marshalGzipJSON(b)
+}
+
+// unmarshal decompresses and unmarshals the results from json
+func (b *TenantIndex) unmarshal(buffer []byte) error {
+	return unmarshalGzipJSON(buffer, b)
+}
+
+// TenantIndexHead points at the current version of a tenant index that is updated by deltas. The tenant
+// index with the base version is stored as a base snapshot and every following version is a delta to the
+// previous one. Snapshots and deltas are only written by the builder recorded in the head, under names
+// that include the builder, so builders never overwrite each other's objects. Deltas are stored in slots
+// numbered by their distance to the base version, so they are overwritten after the next base snapshot.
+//
+// Every base snapshot has a random generation and every delta a random id and the id of the previous
+// delta, or the generation for the first one. The head records the generation and the id of the latest
+// delta, so readers detect objects that were overwritten while they were read.
+// it is stored in /<tenantid>/index-head.json
+type TenantIndexHead struct {
+	// CreatedAt is updated whenever the head is written, even without a new delta
+	CreatedAt   time.Time `json:"created_at"`
+	Builder     int       `json:"builder"`
+	Generation  string    `json:"generation"`
+	LatestID    string    `json:"latest_id"`
+	BaseVersion uint64    `json:"base_version"`
+	Version     uint64    `json:"version"`
+}
+
+// DeltaSlot returns the slot of the delta with the given version.
+func (h *TenantIndexHead) DeltaSlot(version uint64) int {
+	return int(version - h.BaseVersion)
+}
+
+// TenantIndexDelta holds the changes of a tenant index from the previous version. A block that is
+// compacted is in CompactedMeta and a block that is removed from the backend is in Removed.
+// it is stored in /<tenantid>/index-delta-<builder>-<slot>.json.gz
+type TenantIndexDelta struct {
+	CreatedAt     time.Time             `json:"created_at"`
+	ID            string                `json:"id"`
+	PreviousID    string                `json:"previous_id"`
+	Version       uint64                `json:"version"`
+	Meta          []*BlockMeta          `json:"meta"`
+	CompactedMeta []*CompactedBlockMeta `json:"compacted"`
+	Removed       []uuid.UUID           `json:"removed"`
+}
+
+// Empty returns true if the delta holds no changes.
+func (d *TenantIndexDelta) Empty() bool {
+	return len(d.Meta) == 0 && len(d.CompactedMeta) == 0 && len(d.Removed) == 0
+}
+
+func marshalGzipJSON(v interface{}) ([]byte, error) {
 	buffer := &bytes.Buffer{}
 
 	gzip := gzip.NewWriter(buffer)
 	gzip.Name = internalFilename
 
-	jsonBytes, err := json.Marshal(b)
+	jsonBytes, err := json.Marshal(v)
 	if err != nil {
 		return nil, err
 	}
@@ -53,8 +109,7 @@ func (b *TenantIndex) marshal() ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
-// unmarshal decompresses and unmarshals the results from json
-func (b *TenantIndex) unmarshal(buffer []byte) error {
+func unmarshalGzipJSON(buffer []byte, v interface{}) error {
 	gzipReader, err := gzip.NewReader(bytes.NewReader(buffer))
 	if err != nil {
 		return err
@@ -62,5 +117,5 @@ func (b *TenantIndex) unmarshal(buffer []byte) error {
 	defer gzipReader.Close()
 
 	d := json.NewDecoder(gzipReader)
-	return d.Decode(b)
+	return d.Decode(v)
 }
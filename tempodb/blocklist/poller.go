@@ -49,6 +49,26 @@ var (
 		Name:      "blocklist_tenant_index_age_seconds",
 		Help:      "Age in seconds of the last pulled tenant index.",
 	}, []string{"tenant"})
+	metricTenantIndexSnapshotsPulled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempodb",
+		Name:      "blocklist_tenant_index_snapshots_pulled_total",
+		Help:      "Total number of tenant index base snapshots pulled to apply deltas to.",
+	}, []string{"tenant"})
+	metricTenantIndexDeltasPulled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempodb",
+		Name:      "blocklist_tenant_index_deltas_pulled_total",
+		Help:      "Total number of tenant index deltas pulled.",
+	}, []string{"tenant"})
+	metricTenantIndexSnapshotsWritten = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempodb",
+		Name:      "blocklist_tenant_index_snapshots_written_total",
+		Help:      "Total number of tenant index base snapshots written.",
+	}, []string{"tenant"})
+	metricTenantIndexDeltasWritten = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempodb",
+		Name:      "blocklist_tenant_index_deltas_written_total",
+		Help:      "Total number of tenant index deltas written.",
+	}, []string{"tenant"})
 )
 
 // Config is used to configure the poller
@@ -57,6 +77,12 @@ type PollerConfig struct {
 	PollFallback        bool
 	TenantIndexBuilders int
 	StaleTenantIndex    time.Duration
+	// TenantIndexDeltas is the number of deltas written before a new base snapshot. 0 rewrites the whole
+	// tenant index on every poll.
+	TenantIndexDeltas int
+	// PollInterval is the interval Do is called at. Builders use it to detect that the builder writing the
+	// tenant index deltas stopped.
+	PollInterval time.Duration
 }
 
 // JobSharder is used to determine if a particular job is owned by this process
@@ -86,6 +112,10 @@ type Poller struct {
 
 	sharder JobSharder
 	logger  log.Logger
+
+	// tenantIndexes holds the tenant indexes that deltas are applied to. Do is not called concurrently, so it
+	// is not protected by a lock.
+	tenantIndexes map[string]*tenantIndexState
 }
 
 // NewPoller creates the Poller
@@ -98,6 +128,8 @@ func NewPoller(cfg *PollerConfig, sharder JobSharder, reader backend.Reader, com
 		cfg:     cfg,
 		sharder: sharder,
 		logger:  logger,
+
+		tenantIndexes: map[string]*tenantIndexState{},
 	}
 }
 
@@ -133,10 +165,11 @@ func (p *Poller) Do() (PerTenant, PerTenantCompacted, error) {
 
 func (p *Poller) pollTenantAndCreateIndex(ctx context.Context, tenantID string) ([]*backend.BlockMeta, []*backend.CompactedBlockMeta, error) {
 	// are we a tenant index builder?
-	if !p.buildTenantIndex(tenantID) {
+	builder, ok := p.tenantIndexBuilder(tenantID)
+	if !ok {
 		metricTenantIndexBuilder.WithLabelValues(tenantID).Set(0)
 
-		i, err := p.reader.TenantIndex(ctx, tenantID)
+		i, err := p.pullTenantIndex(ctx, tenantID)
 		err = p.tenantIndexPollError(i, err)
 		if err == nil {
 			// success! return the retrieved index
@@ -166,7 +199,11 @@ func (p *Poller) pollTenantAndCreateIndex(ctx context.Context, tenantID string)
 
 	// everything is happy, write this tenant index
 	level.Info(p.logger).Log("msg", "writing tenant index", "tenant", tenantID, "metas", len(blocklist), "compactedMetas", len(compactedBlocklist))
-	err = p.writer.WriteTenantIndex(ctx, tenantID, blocklist, compactedBlocklist)
+	if p.cfg.TenantIndexDeltas > 0 && ok {
+		err = p.writeTenantIndexDelta(ctx, tenantID, builder, blocklist, compactedBlocklist)
+	} else {
+		err = p.writer.WriteTenantIndex(ctx, tenantID, blocklist, compactedBlocklist)
+	}
 	if err != nil {
 		metricTenantIndexErrors.WithLabelValues(tenantID).Inc()
 		level.Error(p.logger).Log("msg", "failed to write tenant index", "tenant", tenantID, "err", err)
@@ -253,15 +290,17 @@ func (p *Poller) pollBlock(ctx context.Context, tenantID string, blockID uuid.UU
 	return blockMeta, compactedBlockMeta, nil
 }
 
-func (p *Poller) buildTenantIndex(tenant string) bool {
+// tenantIndexBuilder returns the lowest tenant index builder of the tenant that is owned by this process and
+// if any is.
+func (p *Poller) tenantIndexBuilder(tenant string) (int, bool) {
 	for i := 0; i < p.cfg.TenantIndexBuilders; i++ {
 		job := jobPrefix + strconv.Itoa(i) + "-" + tenant
 		if p.sharder.Owns(job) {
-			return true
+			return i, true
 		}
 	}
 
-	return false
+	return 0, false
 }
 
 func (p *Poller) tenantIndexPollError(idx *backend.TenantIndex, err error) error {
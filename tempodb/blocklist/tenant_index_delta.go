@@ -0,0 +1,308 @@
+package blocklist
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/google/uuid"
+	"github.com/grafana/tempo/tempodb/backend"
+)
+
+const (
+	// tenantIndexPullAttempts is the number of times the deltas are read if they change while they are read
+	tenantIndexPullAttempts = 3
+
+	// tenantIndexLeasePolls is the number of poll intervals after which a builder takes over writing the
+	// deltas from a lower builder that stopped updating the head
+	tenantIndexLeasePolls = 3
+)
+
+// errTenantIndexChanged is returned if the snapshot or a delta doesn't match the head, e.g. because it was
+// overwritten by a new snapshot after the head was read
+var errTenantIndexChanged = errors.New("tenant index changed while it was read")
+
+// tenantIndexState is the tenant index of a tenant as of a version of the tenant index deltas
+type tenantIndexState struct {
+	builder     int
+	generation  string
+	latestID    string
+	baseVersion uint64
+	version     uint64
+	createdAt   time.Time
+
+	metas     map[uuid.UUID]*backend.BlockMeta
+	compacted map[uuid.UUID]*backend.CompactedBlockMeta
+}
+
+func newTenantIndexState(builder int, generation string, version uint64, metas []*backend.BlockMeta, compactedMetas []*backend.CompactedBlockMeta) *tenantIndexState {
+	s := &tenantIndexState{
+		builder:     builder,
+		generation:  generation,
+		latestID:    generation,
+		baseVersion: version,
+		version:     version,
+		metas:       make(map[uuid.UUID]*backend.BlockMeta, len(metas)),
+		compacted:   make(map[uuid.UUID]*backend.CompactedBlockMeta, len(compactedMetas)),
+	}
+
+	for _, m := range metas {
+		s.metas[m.BlockID] = m
+	}
+	for _, cm := range compactedMetas {
+		s.compacted[cm.BlockID] = cm
+	}
+
+	return s
+}
+
+// diff returns the delta from the state to the given metas
+func (s *tenantIndexState) diff(metas []*backend.BlockMeta, compactedMetas []*backend.CompactedBlockMeta) *backend.TenantIndexDelta {
+	delta := &backend.TenantIndexDelta{}
+	current := make(map[uuid.UUID]struct{}, len(metas)+len(compactedMetas))
+
+	for _, m := range metas {
+		current[m.BlockID] = struct{}{}
+		if _, ok := s.metas[m.BlockID]; !ok {
+			delta.Meta = append(delta.Meta, m)
+		}
+	}
+	for _, cm := range compactedMetas {
+		current[cm.BlockID] = struct{}{}
+		if _, ok := s.compacted[cm.BlockID]; !ok {
+			delta.CompactedMeta = append(delta.CompactedMeta, cm)
+		}
+	}
+
+	for id := range s.metas {
+		if _, ok := current[id]; !ok {
+			delta.Removed = append(delta.Removed, id)
+		}
+	}
+	for id := range s.compacted {
+		if _, ok := current[id]; !ok {
+			delta.Removed = append(delta.Removed, id)
+		}
+	}
+
+	return delta
+}
+
+// apply applies the delta. It is idempotent, so a delta that was already applied does no harm.
+func (s *tenantIndexState) apply(delta *backend.TenantIndexDelta) {
+	for _, m := range delta.Meta {
+		delete(s.compacted, m.BlockID)
+		s.metas[m.BlockID] = m
+	}
+	for _, cm := range delta.CompactedMeta {
+		delete(s.metas, cm.BlockID)
+		s.compacted[cm.BlockID] = cm
+	}
+	for _, id := range delta.Removed {
+		delete(s.metas, id)
+		delete(s.compacted, id)
+	}
+
+	s.version = delta.Version
+	s.latestID = delta.ID
+}
+
+// lists returns the metas and compacted metas ordered by start time like a polled blocklist
+func (s *tenantIndexState) lists() ([]*backend.BlockMeta, []*backend.CompactedBlockMeta) {
+	metas := make([]*backend.BlockMeta, 0, len(s.metas))
+	for _, m := range s.metas {
+		metas = append(metas, m)
+	}
+	sort.Slice(metas, func(i, j int) bool {
+		return lessBlock(metas[i].StartTime, metas[j].StartTime, metas[i].BlockID, metas[j].BlockID)
+	})
+
+	compactedMetas := make([]*backend.CompactedBlockMeta, 0, len(s.compacted))
+	for _, cm := range s.compacted {
+		compactedMetas = append(compactedMetas, cm)
+	}
+	sort.Slice(compactedMetas, func(i, j int) bool {
+		return lessBlock(compactedMetas[i].StartTime, compactedMetas[j].StartTime, compactedMetas[i].BlockID, compactedMetas[j].BlockID)
+	})
+
+	return metas, compactedMetas
+}
+
+// lessBlock orders blocks by start time and then by id, so that the order does not depend on map iteration
+func lessBlock(startI, startJ time.Time, idI, idJ uuid.UUID) bool {
+	if !startI.Equal(startJ) {
+		return startI.Before(startJ)
+	}
+	return bytes.Compare(idI[:], idJ[:]) < 0
+}
+
+// pullTenantIndex retrieves the tenant index. If deltas are enabled only the deltas since the last pull are
+// read. The full tenant index is the fallback if no deltas have been written yet or they keep changing while
+// they are read.
+func (p *Poller) pullTenantIndex(ctx context.Context, tenantID string) (*backend.TenantIndex, error) {
+	if p.cfg.TenantIndexDeltas <= 0 {
+		return p.reader.TenantIndex(ctx, tenantID)
+	}
+
+	for attempt := 0; attempt < tenantIndexPullAttempts; attempt++ {
+		head, err := p.reader.TenantIndexHead(ctx, tenantID)
+		if err == backend.ErrDoesNotExist {
+			return p.reader.TenantIndex(ctx, tenantID)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		state, err := p.updateTenantIndexState(ctx, tenantID, head)
+		if errors.Is(err, errTenantIndexChanged) {
+			level.Debug(p.logger).Log("msg", "tenant index deltas changed while they were read. retrying", "tenant", tenantID, "err", err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		metas, compactedMetas := state.lists()
+		return &backend.TenantIndex{
+			CreatedAt:     state.createdAt,
+			Meta:          metas,
+			CompactedMeta: compactedMetas,
+			Version:       state.version,
+		}, nil
+	}
+
+	// the full tenant index is written along with every delta, so it is as recent
+	level.Warn(p.logger).Log("msg", "tenant index deltas keep changing while they are read. pulling the full tenant index", "tenant", tenantID)
+	return p.reader.TenantIndex(ctx, tenantID)
+}
+
+// updateTenantIndexState brings the state of the tenant up to the version of the head. The base snapshot is
+// only read if the head points at a new base snapshot or the deltas don't continue the state.
+func (p *Poller) updateTenantIndexState(ctx context.Context, tenantID string, head *backend.TenantIndexHead) (*tenantIndexState, error) {
+	state := p.tenantIndexes[tenantID]
+	// the state is dropped on errors, it might have been partially updated
+	delete(p.tenantIndexes, tenantID)
+
+	if state == nil || state.builder != head.Builder || state.generation != head.Generation || state.version > head.Version ||
+		(state.version == head.Version && state.latestID != head.LatestID) {
+		i, err := p.reader.TenantIndexSnapshot(ctx, tenantID, head.Builder)
+		if err != nil {
+			return nil, err
+		}
+		if i.Generation != head.Generation || i.Version != head.BaseVersion {
+			return nil, fmt.Errorf("%w: snapshot has generation %s version %d, expected generation %s base version %d", errTenantIndexChanged, i.Generation, i.Version, head.Generation, head.BaseVersion)
+		}
+
+		state = newTenantIndexState(head.Builder, head.Generation, head.BaseVersion, i.Meta, i.CompactedMeta)
+		metricTenantIndexSnapshotsPulled.WithLabelValues(tenantID).Inc()
+	}
+
+	for v := state.version + 1; v <= head.Version; v++ {
+		delta, err := p.reader.TenantIndexDelta(ctx, tenantID, head.Builder, head.DeltaSlot(v))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tenant index delta %d: %w", v, err)
+		}
+		if delta.Version != v || delta.PreviousID != state.latestID {
+			return nil, fmt.Errorf("%w: delta %d does not continue version %d", errTenantIndexChanged, delta.Version, state.version)
+		}
+
+		state.apply(delta)
+		metricTenantIndexDeltasPulled.WithLabelValues(tenantID).Inc()
+	}
+
+	if state.latestID != head.LatestID {
+		return nil, fmt.Errorf("%w: latest delta %s, expected %s", errTenantIndexChanged, state.latestID, head.LatestID)
+	}
+
+	state.createdAt = head.CreatedAt
+	p.tenantIndexes[tenantID] = state
+
+	return state, nil
+}
+
+// writeTenantIndexDelta writes the changes from the current version of the tenant index as a delta. A new
+// base snapshot is written if there is no tenant index yet, the maximum number of deltas is reached or the
+// deltas were written by another builder. Only one builder writes deltas, the lowest one that keeps updating
+// the head. The others take over once the head is not updated for tenantIndexLeasePolls poll intervals. The
+// full tenant index is written as well, it is read by components that don't read deltas.
+func (p *Poller) writeTenantIndexDelta(ctx context.Context, tenantID string, builder int, metas []*backend.BlockMeta, compactedMetas []*backend.CompactedBlockMeta) error {
+	var state *tenantIndexState
+	var latestVersion uint64
+
+	now := time.Now()
+
+	head, err := p.reader.TenantIndexHead(ctx, tenantID)
+	switch err {
+	case nil:
+		if head.Builder < builder && now.Sub(head.CreatedAt) <= tenantIndexLeasePolls*p.cfg.PollInterval {
+			return nil
+		}
+		latestVersion = head.Version
+
+		// catches up with deltas written before a restart
+		if head.Builder == builder {
+			state, err = p.updateTenantIndexState(ctx, tenantID, head)
+			if err != nil {
+				level.Warn(p.logger).Log("msg", "failed to read tenant index deltas. writing a new snapshot", "tenant", tenantID, "err", err)
+			}
+		}
+	case backend.ErrDoesNotExist:
+	default:
+		return err
+	}
+
+	err = p.writer.WriteTenantIndex(ctx, tenantID, metas, compactedMetas)
+	if err != nil {
+		return err
+	}
+
+	if state == nil || int(state.version+1-state.baseVersion) > p.cfg.TenantIndexDeltas {
+		version := latestVersion + 1
+		generation := uuid.New().String()
+		err = p.writer.WriteTenantIndexSnapshot(ctx, tenantID, builder, generation, version, metas, compactedMetas)
+		if err != nil {
+			return err
+		}
+
+		state = newTenantIndexState(builder, generation, version, metas, compactedMetas)
+		metricTenantIndexSnapshotsWritten.WithLabelValues(tenantID).Inc()
+	} else {
+		delta := state.diff(metas, compactedMetas)
+		if !delta.Empty() {
+			delta.CreatedAt = now
+			delta.ID = uuid.New().String()
+			delta.PreviousID = state.latestID
+			delta.Version = state.version + 1
+
+			err = p.writer.WriteTenantIndexDelta(ctx, tenantID, builder, int(delta.Version-state.baseVersion), delta)
+			if err != nil {
+				return err
+			}
+
+			state.apply(delta)
+			metricTenantIndexDeltasWritten.WithLabelValues(tenantID).Inc()
+		}
+	}
+
+	// the head is written on every poll, it records how recent the tenant index is
+	err = p.writer.WriteTenantIndexHead(ctx, tenantID, &backend.TenantIndexHead{
+		CreatedAt:   now,
+		Builder:     builder,
+		Generation:  state.generation,
+		LatestID:    state.latestID,
+		BaseVersion: state.baseVersion,
+		Version:     state.version,
+	})
+	if err != nil {
+		return err
+	}
+
+	state.createdAt = now
+	p.tenantIndexes[tenantID] = state
+
+	return nil
+}
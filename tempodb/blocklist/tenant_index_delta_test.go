@@ -0,0 +1,319 @@
+package blocklist
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-kit/log"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/tempo/tempodb/backend"
+	"github.com/grafana/tempo/tempodb/backend/local"
+)
+
+func TestTenantIndexDeltas(t *testing.T) {
+	tenant := "delta-test"
+	ctx := context.Background()
+
+	rawR, rawW, c, err := local.New(&local.Config{Path: t.TempDir()})
+	require.NoError(t, err)
+	r, w := backend.NewReader(rawR), backend.NewWriter(rawW)
+
+	cfg := &PollerConfig{
+		PollConcurrency:     testPollConcurrency,
+		TenantIndexBuilders: testBuilders,
+		TenantIndexDeltas:   2,
+	}
+	builder := NewPoller(cfg, &mockJobSharder{owns: true}, r, c, w, log.NewNopLogger())
+	puller := NewPoller(cfg, &mockJobSharder{}, r, c, w, log.NewNopLogger())
+
+	writeBlock := func(start time.Time) *backend.BlockMeta {
+		meta := backend.NewBlockMeta(tenant, uuid.New(), "v2", backend.EncNone, "")
+		meta.StartTime = start
+		require.NoError(t, w.WriteBlockMeta(ctx, meta))
+		return meta
+	}
+	assertHead := func(base, version uint64) {
+		head, err := r.TenantIndexHead(ctx, tenant)
+		require.NoError(t, err)
+		assert.Equal(t, base, head.BaseVersion)
+		assert.Equal(t, version, head.Version)
+	}
+	assertPulled := func(expectedBlocks, expectedCompacted int, snapshots, deltas float64) {
+		blocklist, compacted, err := puller.Do()
+		require.NoError(t, err)
+		assert.Len(t, blocklist[tenant], expectedBlocks)
+		assert.Len(t, compacted[tenant], expectedCompacted)
+		assert.Equal(t, snapshots, testutil.ToFloat64(metricTenantIndexSnapshotsPulled.WithLabelValues(tenant)))
+		assert.Equal(t, deltas, testutil.ToFloat64(metricTenantIndexDeltasPulled.WithLabelValues(tenant)))
+
+		// the pulled blocklist matches the polled one
+		expectedList, expectedCompactedList, err := builder.pollTenantBlocks(ctx, tenant)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, blockIDs(expectedList, expectedCompactedList), blockIDs(blocklist[tenant], compacted[tenant]))
+	}
+
+	now := time.Now()
+	first := writeBlock(now)
+	writeBlock(now.Add(time.Minute))
+
+	// the first poll writes a snapshot, which also is the full tenant index
+	_, _, err = builder.Do()
+	require.NoError(t, err)
+	assertHead(1, 1)
+	i, err := r.TenantIndex(ctx, tenant)
+	require.NoError(t, err)
+	assert.Len(t, i.Meta, 2)
+	assertPulled(2, 0, 1, 0)
+
+	// a new block is a delta
+	writeBlock(now.Add(2 * time.Minute))
+	_, _, err = builder.Do()
+	require.NoError(t, err)
+	assertHead(1, 2)
+	assertPulled(3, 0, 1, 1)
+
+	// the full tenant index is written as well
+	i, err = r.TenantIndex(ctx, tenant)
+	require.NoError(t, err)
+	assert.Len(t, i.Meta, 3)
+
+	// no changes, no delta
+	_, _, err = builder.Do()
+	require.NoError(t, err)
+	assertHead(1, 2)
+
+	// a compacted block is a delta
+	require.NoError(t, c.MarkBlockCompacted(first.BlockID, tenant))
+	_, _, err = builder.Do()
+	require.NoError(t, err)
+	assertHead(1, 3)
+	assertPulled(2, 1, 1, 2)
+
+	// a removed block is a new snapshot, all delta slots are used
+	require.NoError(t, c.ClearBlock(first.BlockID, tenant))
+	_, _, err = builder.Do()
+	require.NoError(t, err)
+	assertHead(4, 4)
+	assertPulled(2, 0, 2, 2)
+
+	// the deltas of the last snapshot are overwritten
+	writeBlock(now.Add(3 * time.Minute))
+	_, _, err = builder.Do()
+	require.NoError(t, err)
+	assertHead(4, 5)
+	assertPulled(3, 0, 2, 3)
+
+	// a restarted builder continues from the head. it pulls the snapshot and deltas itself, which is counted as well
+	builder = NewPoller(cfg, &mockJobSharder{owns: true}, r, c, w, log.NewNopLogger())
+	writeBlock(now.Add(4 * time.Minute))
+	_, _, err = builder.Do()
+	require.NoError(t, err)
+	assertHead(4, 6)
+	assertPulled(4, 0, 3, 5)
+}
+
+func TestTenantIndexDeltasBuilders(t *testing.T) {
+	tenant := "delta-builders-test"
+	ctx := context.Background()
+
+	rawR, rawW, c, err := local.New(&local.Config{Path: t.TempDir()})
+	require.NoError(t, err)
+	r, w := backend.NewReader(rawR), backend.NewWriter(rawW)
+
+	newCfg := func() *PollerConfig {
+		return &PollerConfig{
+			PollConcurrency:     testPollConcurrency,
+			TenantIndexBuilders: 2,
+			TenantIndexDeltas:   5,
+			PollInterval:        time.Hour,
+		}
+	}
+	builder0 := NewPoller(newCfg(), &mockBuilderSharder{builder: 0}, r, c, w, log.NewNopLogger())
+	builder1Cfg := newCfg()
+	builder1 := NewPoller(builder1Cfg, &mockBuilderSharder{builder: 1}, r, c, w, log.NewNopLogger())
+	puller := NewPoller(newCfg(), &mockJobSharder{}, r, c, w, log.NewNopLogger())
+
+	writeBlock := func() {
+		meta := backend.NewBlockMeta(tenant, uuid.New(), "v2", backend.EncNone, "")
+		require.NoError(t, w.WriteBlockMeta(ctx, meta))
+	}
+	assertHead := func(builder int, version uint64) {
+		head, err := r.TenantIndexHead(ctx, tenant)
+		require.NoError(t, err)
+		assert.Equal(t, builder, head.Builder)
+		assert.Equal(t, version, head.Version)
+	}
+	assertPulled := func(expected int) {
+		blocklist, _, err := puller.Do()
+		require.NoError(t, err)
+		assert.Len(t, blocklist[tenant], expected)
+	}
+
+	writeBlock()
+	_, _, err = builder0.Do()
+	require.NoError(t, err)
+	assertHead(0, 1)
+
+	// the higher builder doesn't write while the lower one updates the head
+	writeBlock()
+	_, _, err = builder1.Do()
+	require.NoError(t, err)
+	assertHead(0, 1)
+
+	_, _, err = builder0.Do()
+	require.NoError(t, err)
+	assertHead(0, 2)
+	assertPulled(2)
+
+	// the higher builder takes over with its own snapshot once the head is not updated
+	builder1Cfg.PollInterval = 0
+	writeBlock()
+	_, _, err = builder1.Do()
+	require.NoError(t, err)
+	assertHead(1, 3)
+	assertPulled(3)
+
+	// and the lower builder takes back over
+	writeBlock()
+	_, _, err = builder0.Do()
+	require.NoError(t, err)
+	assertHead(0, 4)
+	assertPulled(4)
+}
+
+func TestTenantIndexDeltasChanged(t *testing.T) {
+	tenant := "delta-changed-test"
+	ctx := context.Background()
+
+	rawR, rawW, c, err := local.New(&local.Config{Path: t.TempDir()})
+	require.NoError(t, err)
+	r, w := backend.NewReader(rawR), backend.NewWriter(rawW)
+
+	cfg := &PollerConfig{
+		PollConcurrency:     testPollConcurrency,
+		TenantIndexBuilders: testBuilders,
+		TenantIndexDeltas:   5,
+	}
+	builder := NewPoller(cfg, &mockJobSharder{owns: true}, r, c, w, log.NewNopLogger())
+	puller := NewPoller(cfg, &mockJobSharder{}, r, c, w, log.NewNopLogger())
+
+	writeBlock := func() {
+		meta := backend.NewBlockMeta(tenant, uuid.New(), "v2", backend.EncNone, "")
+		require.NoError(t, w.WriteBlockMeta(ctx, meta))
+	}
+
+	writeBlock()
+	_, _, err = builder.Do()
+	require.NoError(t, err)
+	writeBlock()
+	_, _, err = builder.Do()
+	require.NoError(t, err)
+
+	head, err := r.TenantIndexHead(ctx, tenant)
+	require.NoError(t, err)
+	delta, err := r.TenantIndexDelta(ctx, tenant, 0, 1)
+	require.NoError(t, err)
+
+	// a delta overwritten by another writer doesn't continue the snapshot, the full tenant index is pulled
+	require.NoError(t, w.WriteTenantIndexDelta(ctx, tenant, 0, 1, &backend.TenantIndexDelta{
+		ID:         "other",
+		PreviousID: "other-generation",
+		Version:    2,
+		Meta:       []*backend.BlockMeta{{BlockID: uuid.New()}},
+	}))
+	blocklist, _, err := puller.Do()
+	require.NoError(t, err)
+	assert.Len(t, blocklist[tenant], 2)
+	_, ok := puller.tenantIndexes[tenant]
+	assert.False(t, ok)
+
+	// a snapshot rewritten after the head was read
+	require.NoError(t, w.WriteTenantIndexDelta(ctx, tenant, 0, 1, delta))
+	require.NoError(t, w.WriteTenantIndexSnapshot(ctx, tenant, 0, "other-generation", head.BaseVersion, nil, nil))
+	blocklist, _, err = puller.Do()
+	require.NoError(t, err)
+	assert.Len(t, blocklist[tenant], 2)
+	_, ok = puller.tenantIndexes[tenant]
+	assert.False(t, ok)
+
+	// a restarted builder fails to catch up and writes a new snapshot, the deltas are pulled again
+	builder = NewPoller(cfg, &mockJobSharder{owns: true}, r, c, w, log.NewNopLogger())
+	_, _, err = builder.Do()
+	require.NoError(t, err)
+	blocklist, _, err = puller.Do()
+	require.NoError(t, err)
+	assert.Len(t, blocklist[tenant], 2)
+	_, ok = puller.tenantIndexes[tenant]
+	assert.True(t, ok)
+}
+
+type mockBuilderSharder struct {
+	builder int
+}
+
+func (m *mockBuilderSharder) Owns(job string) bool {
+	return strings.HasPrefix(job, jobPrefix+strconv.Itoa(m.builder)+"-")
+}
+
+func blockIDs(metas []*backend.BlockMeta, compactedMetas []*backend.CompactedBlockMeta) []uuid.UUID {
+	var ids []uuid.UUID
+	for _, m := range metas {
+		ids = append(ids, m.BlockID)
+	}
+	for _, cm := range compactedMetas {
+		ids = append(ids, cm.BlockID)
+	}
+	return ids
+}
+
+func TestTenantIndexDeltasFallback(t *testing.T) {
+	r := newMockReader(PerTenant{"test": []*backend.BlockMeta{}}, nil, false)
+	r.(*backend.MockReader).TenantIndexFn = func(ctx context.Context, tenantID string) (*backend.TenantIndex, error) {
+		return &backend.TenantIndex{
+			CreatedAt: time.Now(),
+			Meta:      []*backend.BlockMeta{{BlockID: uuid.New()}},
+		}, nil
+	}
+
+	// without a head the full tenant index is pulled
+	poller := NewPoller(&PollerConfig{
+		PollConcurrency:     testPollConcurrency,
+		TenantIndexBuilders: testBuilders,
+		TenantIndexDeltas:   5,
+	}, &mockJobSharder{}, r, &backend.MockCompactor{}, &backend.MockWriter{}, log.NewNopLogger())
+
+	blocklist, _, err := poller.Do()
+	require.NoError(t, err)
+	assert.Len(t, blocklist["test"], 1)
+}
+
+func TestTenantIndexStateDiff(t *testing.T) {
+	a, b, c := &backend.BlockMeta{BlockID: uuid.New()}, &backend.BlockMeta{BlockID: uuid.New()}, &backend.BlockMeta{BlockID: uuid.New()}
+	compactedA := &backend.CompactedBlockMeta{BlockMeta: *a}
+
+	s := newTenantIndexState(0, "generation", 1, []*backend.BlockMeta{a, b}, nil)
+
+	delta := s.diff([]*backend.BlockMeta{c}, []*backend.CompactedBlockMeta{compactedA})
+	assert.Equal(t, []*backend.BlockMeta{c}, delta.Meta)
+	assert.Equal(t, []*backend.CompactedBlockMeta{compactedA}, delta.CompactedMeta)
+	assert.Equal(t, []uuid.UUID{b.BlockID}, delta.Removed)
+
+	delta.Version = 2
+	delta.ID = "delta"
+	s.apply(delta)
+	s.apply(delta)
+
+	metas, compactedMetas := s.lists()
+	assert.Equal(t, []*backend.BlockMeta{c}, metas)
+	assert.Equal(t, []*backend.CompactedBlockMeta{compactedA}, compactedMetas)
+	assert.Equal(t, uint64(2), s.version)
+	assert.Equal(t, "delta", s.latestID)
+	assert.True(t, s.diff(metas, compactedMetas).Empty())
+}
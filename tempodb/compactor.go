@@ -12,6 +12,7 @@ import (
 	"github.com/grafana/tempo/tempodb/encoding"
 	"github.com/grafana/tempo/tempodb/encoding/common"
 	"github.com/grafana/tempo/tempodb/metrics"
+	"github.com/grafana/tempo/tempodb/search"
 )
 
 const (
@@ -148,6 +149,14 @@ func (rw *readerWriter) compact(blockMetas []*backend.BlockMeta, tenantID string
 		return err
 	}
 
+	// carry the search headers over to the new blocks. tag lookups are best effort, so a failure here doesn't fail
+	// the compaction
+	err = search.CompactSearchHeaders(ctx, rw.r, rw.w, tenantID, blockMetas, newCompactedBlocks)
+	if err != nil {
+		level.Error(rw.logger).Log("msg", "unable to compact search headers", "tenantID", tenantID, "err", err)
+		metrics.MetricCompactionErrors.Inc()
+	}
+
 	// mark old blocks compacted so they don't show up in polling
 	markCompacted(rw, tenantID, blockMetas, newCompactedBlocks)
 
@@ -175,7 +184,7 @@ func markCompacted(rw *readerWriter, tenantID string, oldBlocks []*backend.Block
 	}
 
 	// Update blocklist in memory
-	rw.blocklist.Update(tenantID, newBlocks, oldBlocks, newCompactions, nil)
+	rw.updateBlocklist(tenantID, newBlocks, oldBlocks, newCompactions, nil)
 }
 
 func measureOutstandingBlocks(tenantID string, blockSelector CompactionBlockSelector, owned func(hash string) bool) {
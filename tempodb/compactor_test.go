@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/binary"
 	"math/rand"
+	"os"
 	"path"
 	"testing"
 	"time"
@@ -17,6 +18,7 @@ import (
 	"github.com/grafana/tempo/pkg/model"
 	"github.com/grafana/tempo/pkg/model/trace"
 	v1 "github.com/grafana/tempo/pkg/model/v1"
+	"github.com/grafana/tempo/pkg/tempofb"
 	"github.com/grafana/tempo/pkg/tempopb"
 	"github.com/grafana/tempo/pkg/util/test"
 	"github.com/grafana/tempo/tempodb/backend"
@@ -25,6 +27,7 @@ import (
 	"github.com/grafana/tempo/tempodb/encoding/common"
 	"github.com/grafana/tempo/tempodb/metrics"
 	"github.com/grafana/tempo/tempodb/pool"
+	"github.com/grafana/tempo/tempodb/search"
 	"github.com/grafana/tempo/tempodb/wal"
 )
 
@@ -575,6 +578,104 @@ func TestCompactionIteratesThroughTenants(t *testing.T) {
 	assert.Equal(t, 1, len(rw.blocklist.Metas(testTenantID2)))
 }
 
+func TestCompactionSearchHeaders(t *testing.T) {
+	tempDir := t.TempDir()
+
+	r, w, c, err := New(&Config{
+		Backend: "local",
+		Pool: &pool.Config{
+			MaxWorkers: 10,
+			QueueDepth: 100,
+		},
+		Local: &local.Config{
+			Path: path.Join(tempDir, "traces"),
+		},
+		Block: &common.BlockConfig{
+			IndexDownsampleBytes: 11,
+			BloomFP:              .01,
+			BloomShardSizeBytes:  100_000,
+			Encoding:             backend.EncLZ4_64k,
+			IndexPageSizeBytes:   1000,
+		},
+		WAL: &wal.Config{
+			Filepath: path.Join(tempDir, "wal"),
+		},
+		BlocklistPoll: 0,
+	}, log.NewNopLogger())
+	require.NoError(t, err)
+
+	c.EnableCompaction(&CompactorConfig{
+		ChunkSizeBytes:          10,
+		MaxCompactionRange:      24 * time.Hour,
+		BlockRetention:          0,
+		CompactedBlockRetention: 0,
+	}, &mockSharder{}, &mockOverrides{})
+
+	r.EnablePolling(&mockJobSharder{})
+
+	// blocks are completed to a local backend and written through LocalBlock.Write, the same way the ingesters flush them
+	l, err := local.NewBackend(&local.Config{
+		Path: path.Join(tempDir, "local"),
+	})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	dec := model.MustNewSegmentDecoder(model.CurrentEncoding)
+	services := []string{"foo", "bar"}
+
+	for i, service := range services {
+		blockID := uuid.New()
+		head, err := w.WAL().NewBlock(blockID, testTenantID, model.CurrentEncoding)
+		require.NoError(t, err)
+
+		f, err := os.OpenFile(path.Join(tempDir, blockID.String()+".search"), os.O_CREATE|os.O_RDWR, 0644)
+		require.NoError(t, err)
+		searchBlock, err := search.NewStreamingSearchBlockForFile(f, blockID, backend.EncNone, false)
+		require.NoError(t, err)
+
+		for j := 0; j < 10; j++ {
+			id := makeTraceID(i, j)
+			writeTraceToWal(t, head, dec, id, test.MakeTrace(1, id), 0, 0)
+
+			entry := (&tempofb.SearchEntryMutable{
+				TraceID: id,
+				Tags: tempofb.NewSearchDataMapWithData(map[string][]string{
+					"service.name": {service},
+				}),
+			}).ToBytes()
+			require.NoError(t, searchBlock.Append(ctx, id, [][]byte{entry}))
+		}
+
+		backendBlock, err := w.CompleteBlockWithBackend(ctx, head, &mockCombiner{}, nil, backend.NewReader(l), backend.NewWriter(l))
+		require.NoError(t, err)
+
+		_, err = w.CompleteSearchBlockWithBackend(searchBlock, blockID, testTenantID, nil, backend.NewReader(l), backend.NewWriter(l))
+		require.NoError(t, err)
+
+		localBlock, err := wal.NewLocalBlock(ctx, backendBlock, l)
+		require.NoError(t, err)
+		require.NoError(t, w.WriteBlock(ctx, localBlock))
+	}
+
+	rw := r.(*readerWriter)
+	rw.pollBlocklist()
+
+	metas := rw.blocklist.Metas(testTenantID)
+	require.Len(t, metas, len(services))
+	require.NoError(t, rw.compact(metas, testTenantID))
+
+	metas = rw.blocklist.Metas(testTenantID)
+	require.Len(t, metas, 1)
+
+	tags := map[string]tempofb.TagInfo{}
+	require.NoError(t, rw.SearchTags(ctx, testTenantID, metas[0].BlockID, tags))
+	require.Contains(t, tags, "service.name")
+
+	values := map[string]uint32{}
+	require.NoError(t, rw.SearchTagValues(ctx, testTenantID, metas[0].BlockID, "service.name", values))
+	require.Equal(t, map[string]uint32{"foo": 10, "bar": 10}, values)
+}
+
 func cutTestBlocks(t testing.TB, w Writer, tenantID string, blockCount int, recordCount int) []common.BackendBlock {
 	blocks := make([]common.BackendBlock, 0)
 	dec := model.MustNewSegmentDecoder(model.CurrentEncoding)
@@ -7,8 +7,10 @@ import (
 
 	"github.com/grafana/tempo/pkg/cache"
 	"github.com/grafana/tempo/tempodb/backend/azure"
+	backend_cache "github.com/grafana/tempo/tempodb/backend/cache"
 	"github.com/grafana/tempo/tempodb/backend/cache/memcached"
 	"github.com/grafana/tempo/tempodb/backend/cache/redis"
+	"github.com/grafana/tempo/tempodb/backend/encryption"
 	"github.com/grafana/tempo/tempodb/backend/gcs"
 	"github.com/grafana/tempo/tempodb/backend/local"
 	"github.com/grafana/tempo/tempodb/backend/s3"
@@ -40,6 +42,8 @@ type Config struct {
 	BlocklistPollFallback            bool          `yaml:"blocklist_poll_fallback"`
 	BlocklistPollTenantIndexBuilders int           `yaml:"blocklist_poll_tenant_index_builders"`
 	BlocklistPollStaleTenantIndex    time.Duration `yaml:"blocklist_poll_stale_tenant_index"`
+	BlocklistPollTenantIndexDeltas   int           `yaml:"blocklist_poll_tenant_index_deltas"`
+	BlocklistNotifications           bool          `yaml:"blocklist_notifications"`
 
 	// backends
 	Backend string        `yaml:"backend"`
@@ -48,6 +52,9 @@ type Config struct {
 	S3      *s3.Config    `yaml:"s3"`
 	Azure   *azure.Config `yaml:"azure"`
 
+	// client side encryption of block objects
+	Encryption *encryption.Config `yaml:"encryption"`
+
 	// caches
 	Cache                   string                  `yaml:"cache"`
 	CacheMinCompactionLevel uint8                   `yaml:"cache_min_compaction_level"`
@@ -55,6 +62,11 @@ type Config struct {
 	BackgroundCache         *cache.BackgroundConfig `yaml:"background_cache"`
 	Memcached               *memcached.Config       `yaml:"memcached"`
 	Redis                   *redis.Config           `yaml:"redis"`
+	DiskCache               *cache.DiskCacheConfig  `yaml:"disk_cache"`
+
+	// in-process cache stacked over memcached or redis
+	InProcessCache *cache.LRUConfig         `yaml:"in_process_cache"`
+	CacheTTL       *backend_cache.TTLConfig `yaml:"cache_ttl"`
 }
 
 type SearchConfig struct {
@@ -7,6 +7,8 @@ const (
 	NameObjects = "data"
 	// NameIndex names the backend index object
 	NameIndex = "index"
+	// NameSearchHeader names the backend search header object, it holds all tags and values of the block
+	NameSearchHeader = "search-header"
 	// nameBloomPrefix is the prefix used to build the bloom shards
 	nameBloomPrefix = "bloom-"
 )
@@ -61,6 +61,9 @@ type Iterator interface {
 	Close()
 }
 
+// ObjectFilter returns true if the object with the given id should be kept
+type ObjectFilter func(id ID, obj []byte) bool
+
 type BackendBlock interface {
 	Finder
 	Searcher
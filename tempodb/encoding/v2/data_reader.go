@@ -22,9 +22,9 @@ type dataReader struct {
 	compressedReader io.Reader
 }
 
-// constDataHeader is a singleton data header.  the data header is
-//  stateless b/c there are no fields.  to very minorly reduce allocations all
-//  data should just use this.
+// constDataHeader is a singleton data header without checksum.  it is only used
+//  to write pages without checksum, to very minorly reduce allocations.  pages are
+//  read with their own header b/c it may hold a checksum.
 var constDataHeader = &dataHeader{}
 
 // NewDataReader constructs a v2 DataReader that handles paged...reading
@@ -84,7 +84,12 @@ func (r *dataReader) Read(ctx context.Context, records []common.Record, pagesBuf
 	// read and strip page data
 	compressedPages := make([][]byte, 0, len(compressedPagesBuffer))
 	for _, v0Page := range compressedPagesBuffer {
-		page, err := unmarshalPageFromBytes(v0Page, constDataHeader)
+		header := &dataHeader{}
+		page, err := unmarshalPageFromBytes(v0Page, header)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = header.verify(page.data)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -137,15 +142,22 @@ func (r *dataReader) NextPage(buffer []byte) ([]byte, uint32, error) {
 		return nil, 0, err
 	}
 
-	page, err := unmarshalPageFromReader(reader, constDataHeader, r.pageBuffer)
+	header := &dataHeader{}
+	page, err := unmarshalPageFromReader(reader, header, r.pageBuffer)
 	if err != nil {
 		return nil, 0, err
 	}
 	r.pageBuffer = page.data
 
+	// the page was read completely, errors from here on only concern its content and the page can be skipped
+	err = header.verify(page.data)
+	if err != nil {
+		return nil, page.totalLength, err
+	}
+
 	compressedReader, err := r.getCompressedReader(page.data)
 	if err != nil {
-		return nil, 0, err
+		return nil, page.totalLength, fmt.Errorf("%w: %v", ErrCorruptPage, err)
 	}
 
 	// TODO: leaky abstraction. can a real programmer fix this in the future?
@@ -158,7 +170,7 @@ func (r *dataReader) NextPage(buffer []byte) ([]byte, uint32, error) {
 	}
 
 	if err != nil {
-		return nil, 0, err
+		return nil, page.totalLength, fmt.Errorf("%w: %v", ErrCorruptPage, err)
 	}
 	return buffer, page.totalLength, nil
 
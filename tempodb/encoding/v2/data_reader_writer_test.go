@@ -3,6 +3,7 @@ package v2
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"math/rand"
 	"testing"
@@ -31,6 +32,59 @@ func TestReaderRead(t *testing.T) {
 	testRead(t, totalObjects, enc, ids, objs, buffer, recs)
 }
 
+func TestReaderNextPageChecksum(t *testing.T) {
+	enc := backend.EncZstd
+
+	buffer := &bytes.Buffer{}
+	w, err := NewDataWriterWithChecksums(buffer, enc)
+	require.NoError(t, err)
+
+	var pageLengths []int
+	for i := 0; i < 3; i++ {
+		_, err = w.Write([]byte{byte(i)}, []byte{0x01, 0x02, 0x03})
+		require.NoError(t, err)
+		count, err := w.CutPage()
+		require.NoError(t, err)
+		pageLengths = append(pageLengths, count)
+	}
+	require.NoError(t, w.Complete())
+
+	// pages with checksums are readable
+	r, err := NewDataReader(backend.NewContextReaderWithAllReader(bytes.NewReader(buffer.Bytes())), enc)
+	require.NoError(t, err)
+	pages, _, err := r.Read(context.Background(), []common.Record{{Start: 0, Length: uint32(pageLengths[0])}}, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, pages, 1)
+
+	// flip the last byte of the second page
+	corrupt := buffer.Bytes()
+	corrupt[pageLengths[0]+pageLengths[1]-1] ^= 0xFF
+
+	r, err = NewDataReader(backend.NewContextReaderWithAllReader(bytes.NewReader(corrupt)), enc)
+	require.NoError(t, err)
+	defer r.Close()
+
+	o := NewObjectReaderWriter()
+	var ids [][]byte
+	for i := 0; ; i++ {
+		page, length, err := r.NextPage(nil)
+		if err == io.EOF {
+			break
+		}
+		assert.Equal(t, uint32(pageLengths[i]), length)
+		if i == 1 {
+			assert.True(t, errors.Is(err, ErrCorruptPage))
+			continue
+		}
+		require.NoError(t, err)
+
+		id, _, err := o.UnmarshalObjectFromReader(bytes.NewReader(page))
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	assert.Equal(t, [][]byte{{0}, {2}}, ids)
+}
+
 func BenchmarkReaderRead(b *testing.B) {
 	totalObjects := 10000
 	objsPerPage := 100
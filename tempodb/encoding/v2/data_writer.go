@@ -19,10 +19,22 @@ type dataWriter struct {
 
 	objectRW     common.ObjectReaderWriter
 	objectBuffer *bytes.Buffer
+
+	checksums bool
 }
 
 // NewDataWriter creates a paged page writer
 func NewDataWriter(writer io.Writer, encoding backend.Encoding) (common.DataWriter, error) {
+	return newDataWriter(writer, encoding, false)
+}
+
+// NewDataWriterWithChecksums creates a paged page writer that adds a checksum to every page. Pages with
+// checksums can only be read by versions of Tempo that support them, use it for WAL files only.
+func NewDataWriterWithChecksums(writer io.Writer, encoding backend.Encoding) (common.DataWriter, error) {
+	return newDataWriter(writer, encoding, true)
+}
+
+func newDataWriter(writer io.Writer, encoding backend.Encoding, checksums bool) (common.DataWriter, error) {
 	pool, err := GetWriterPool(encoding)
 	if err != nil {
 		return nil, err
@@ -41,6 +53,7 @@ func NewDataWriter(writer io.Writer, encoding backend.Encoding) (common.DataWrit
 		compressedBuffer:  compressedBuffer,
 		objectRW:          NewObjectReaderWriter(),
 		objectBuffer:      &bytes.Buffer{},
+		checksums:         checksums,
 	}, nil
 }
 
@@ -62,7 +75,11 @@ func (p *dataWriter) CutPage() (int, error) {
 	p.compressionWriter.Close()
 
 	// now marshal the buffer as a page to the output
-	bytesWritten, marshalErr := marshalPageToWriter(p.compressedBuffer.Bytes(), p.outputWriter, constDataHeader)
+	header := constDataHeader
+	if p.checksums {
+		header = newDataHeaderWithChecksum(p.compressedBuffer.Bytes())
+	}
+	bytesWritten, marshalErr := marshalPageToWriter(p.compressedBuffer.Bytes(), p.outputWriter, header)
 
 	// reset buffers for the next write
 	p.objectBuffer.Reset()
@@ -0,0 +1,40 @@
+package v2
+
+import (
+	"context"
+
+	"github.com/grafana/tempo/tempodb/encoding/common"
+)
+
+type filteringIterator struct {
+	iter common.Iterator
+	keep common.ObjectFilter
+}
+
+// NewFilteringIterator returns a filteringIterator.  This iterator is used to wrap another
+//  iterator.  It drops all objects the filter doesn't keep.
+func NewFilteringIterator(iter common.Iterator, keep common.ObjectFilter) common.Iterator {
+	return &filteringIterator{
+		iter: iter,
+		keep: keep,
+	}
+}
+
+// Next implements Iterator
+func (i *filteringIterator) Next(ctx context.Context) (common.ID, []byte, error) {
+	for {
+		id, obj, err := i.iter.Next(ctx)
+		if err != nil || id == nil {
+			return id, obj, err
+		}
+
+		if i.keep(id, obj) {
+			return id, obj, nil
+		}
+	}
+}
+
+// Close implements Iterator
+func (i *filteringIterator) Close() {
+	i.iter.Close()
+}
@@ -0,0 +1,71 @@
+package v2
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/tempo/tempodb/encoding/common"
+)
+
+func TestFilteringIterator(t *testing.T) {
+	tests := []struct {
+		name        string
+		ids         []common.ID
+		objs        [][]byte
+		keep        common.ObjectFilter
+		expectedIDs []common.ID
+	}{
+		{
+			name:        "empty",
+			keep:        func(common.ID, []byte) bool { return true },
+			expectedIDs: nil,
+		},
+		{
+			name:        "keep all",
+			ids:         []common.ID{{0x01}, {0x02}},
+			objs:        [][]byte{{0x01}, {0x02}},
+			keep:        func(common.ID, []byte) bool { return true },
+			expectedIDs: []common.ID{{0x01}, {0x02}},
+		},
+		{
+			name:        "drop all",
+			ids:         []common.ID{{0x01}, {0x02}},
+			objs:        [][]byte{{0x01}, {0x02}},
+			keep:        func(common.ID, []byte) bool { return false },
+			expectedIDs: nil,
+		},
+		{
+			name:        "drop some",
+			ids:         []common.ID{{0x01}, {0x02}, {0x03}, {0x04}},
+			objs:        [][]byte{{0x01}, {0x02}, {0x03}, {0x04}},
+			keep:        func(id common.ID, _ []byte) bool { return id[0]%2 == 0 },
+			expectedIDs: []common.ID{{0x02}, {0x04}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iter := NewFilteringIterator(&mockIterator{
+				ids:  tt.ids,
+				objs: tt.objs,
+			}, tt.keep)
+
+			var actualIDs []common.ID
+			for {
+				id, obj, err := iter.Next(context.Background())
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				assert.Equal(t, []byte(id), obj)
+				actualIDs = append(actualIDs, id)
+			}
+
+			assert.Equal(t, tt.expectedIDs, actualIDs)
+		})
+	}
+}
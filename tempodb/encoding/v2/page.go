@@ -44,7 +44,7 @@ func unmarshalPageFromBytes(b []byte, header pageHeader) (*page, error) {
 	}
 	b = b[headerLength:]
 
-	dataLength := int(totalLength) - totalHeaderSize
+	dataLength := int(totalLength) - baseHeaderSize - int(headerLength)
 	if len(b) != dataLength {
 		return nil, fmt.Errorf("expected data len %d does not match actual %d", dataLength, len(b))
 	}
@@ -57,8 +57,6 @@ func unmarshalPageFromBytes(b []byte, header pageHeader) (*page, error) {
 }
 
 func unmarshalPageFromReader(r io.Reader, header pageHeader, buffer []byte) (*page, error) {
-	totalHeaderSize := baseHeaderSize + header.headerLength()
-
 	var totalLength uint32
 	var headerLength uint16
 
@@ -79,7 +77,7 @@ func unmarshalPageFromReader(r io.Reader, header pageHeader, buffer []byte) (*pa
 	if err != nil {
 		return nil, err
 	}
-	dataLength := int(totalLength) - totalHeaderSize
+	dataLength := int(totalLength) - baseHeaderSize - int(headerLength)
 
 	if dataLength < 0 {
 		return nil, fmt.Errorf("unexpected negative dataLength unmarshalling page: %d", dataLength)
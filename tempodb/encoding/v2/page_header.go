@@ -4,6 +4,8 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+
+	"github.com/cespare/xxhash"
 )
 
 type pageHeader interface {
@@ -18,23 +20,66 @@ const DataHeaderLength = 0
 // IndexHeaderLength is the length in bytes for the record header
 const IndexHeaderLength = int(uint64Size) // 64bit checksum (xxhash)
 
-// dataHeader implements a pageHeader that has no fields
+// DataChecksumHeaderLength is the length in bytes for the data header with checksum
+const DataChecksumHeaderLength = int(uint64Size) // 64bit checksum (xxhash)
+
+// ErrCorruptPage is returned if the framing of a data page is intact, but its content is not, e.g. because
+// the checksum does not match. The page can be skipped.
+var ErrCorruptPage = errors.New("corrupt page")
+
+// dataHeader implements a pageHeader for data pages. Data pages of backend blocks have no fields.
+// Data pages of WAL files have a checksum to detect corrupted pages on replay.
+//   checksum - 64 bit xxhash of the page bytes
 type dataHeader struct {
+	checksum    uint64
+	hasChecksum bool
+}
+
+func newDataHeaderWithChecksum(page []byte) *dataHeader {
+	return &dataHeader{
+		checksum:    xxhash.Sum64(page),
+		hasChecksum: true,
+	}
 }
 
 func (h *dataHeader) unmarshalHeader(b []byte) error {
-	if len(b) != 0 {
-		return errors.New("unexpected non-zero len data header")
+	switch len(b) {
+	case DataHeaderLength:
+		h.hasChecksum = false
+	case DataChecksumHeaderLength:
+		h.checksum = binary.LittleEndian.Uint64(b[:uint64Size])
+		h.hasChecksum = true
+	default:
+		return fmt.Errorf("unexpected data header len of %d", len(b))
 	}
 
 	return nil
 }
 
 func (h *dataHeader) headerLength() int {
+	if h.hasChecksum {
+		return DataChecksumHeaderLength
+	}
 	return DataHeaderLength
 }
 
 func (h *dataHeader) marshalHeader(b []byte) error {
+	if len(b) != h.headerLength() {
+		return fmt.Errorf("unexpected data header len of %d", len(b))
+	}
+
+	if h.hasChecksum {
+		binary.LittleEndian.PutUint64(b, h.checksum)
+	}
+
+	return nil
+}
+
+// verify returns ErrCorruptPage if the page has a checksum that does not match the page bytes
+func (h *dataHeader) verify(page []byte) error {
+	if h.hasChecksum && xxhash.Sum64(page) != h.checksum {
+		return fmt.Errorf("%w: checksum mismatch", ErrCorruptPage)
+	}
 	return nil
 }
 
@@ -142,7 +142,7 @@ func TestCorruptHeader(t *testing.T) {
 
 	page, err = unmarshalPageFromReader(bytes.NewReader(buffBytes), constDataHeader, nil)
 	assert.Nil(t, page)
-	assert.EqualError(t, err, "unexpected non-zero len data header")
+	assert.EqualError(t, err, "unexpected data header len of 65535")
 }
 
 func TestIncompletePageDetected(t *testing.T) {
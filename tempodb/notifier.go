@@ -0,0 +1,117 @@
+package tempodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-kit/log/level"
+	"github.com/google/uuid"
+
+	"github.com/grafana/tempo/tempodb/backend"
+)
+
+// BlocklistNotifier announces changes of the blocklist made by this component to other components, so they
+// don't have to wait for the next poll to learn about them.
+type BlocklistNotifier interface {
+	NotifyBlocklistUpdate(tenantID string, change *BlocklistChange)
+}
+
+// BlocklistChange is a change of the blocklist of a tenant as announced to other components. It only holds
+// the ids of the blocks, the metas of new blocks are read from the backend by the components applying it.
+type BlocklistChange struct {
+	Add             []uuid.UUID      `json:"add,omitempty"`
+	Remove          []uuid.UUID      `json:"remove,omitempty"`
+	Compacted       []CompactionMark `json:"compacted,omitempty"`
+	CompactedRemove []uuid.UUID      `json:"compacted_remove,omitempty"`
+}
+
+// CompactionMark records that a block was marked compacted.
+type CompactionMark struct {
+	BlockID       uuid.UUID `json:"block_id"`
+	CompactedTime time.Time `json:"compacted_time"`
+}
+
+func newBlocklistChange(add []*backend.BlockMeta, remove []*backend.BlockMeta, compactedAdd []*backend.CompactedBlockMeta, compactedRemove []*backend.CompactedBlockMeta) *BlocklistChange {
+	c := &BlocklistChange{}
+	for _, m := range add {
+		c.Add = append(c.Add, m.BlockID)
+	}
+	for _, m := range remove {
+		c.Remove = append(c.Remove, m.BlockID)
+	}
+	for _, m := range compactedAdd {
+		c.Compacted = append(c.Compacted, CompactionMark{BlockID: m.BlockID, CompactedTime: m.CompactedTime})
+	}
+	for _, m := range compactedRemove {
+		c.CompactedRemove = append(c.CompactedRemove, m.BlockID)
+	}
+	return c
+}
+
+// EnableBlocklistNotifications announces blocks written, compacted and deleted by this component. It must
+// be called before writing blocks or enabling compaction.
+func (rw *readerWriter) EnableBlocklistNotifications(n BlocklistNotifier) {
+	rw.notifier = n
+}
+
+// ApplyBlocklistUpdate applies a change of the blocklist announced by another component. The metas of new
+// blocks are read from the backend. Compacted blocks are taken from the blocklist, or their compacted meta
+// is read from the backend if they are not in it yet. Like local changes it is kept until it has been
+// reconciled by the next poll.
+func (rw *readerWriter) ApplyBlocklistUpdate(ctx context.Context, tenantID string, change *BlocklistChange) {
+	var add, remove []*backend.BlockMeta
+	var compactedAdd, compactedRemove []*backend.CompactedBlockMeta
+
+	for _, id := range change.Add {
+		m, err := rw.r.BlockMeta(ctx, id, tenantID)
+		if err != nil {
+			// the block might have been compacted already, it is picked up by the next poll
+			level.Warn(rw.logger).Log("msg", "failed to read meta of announced block", "tenant", tenantID, "blockID", id, "err", err)
+			continue
+		}
+		add = append(add, m)
+	}
+
+	for _, id := range change.Remove {
+		remove = append(remove, &backend.BlockMeta{BlockID: id})
+	}
+
+	if len(change.Compacted) > 0 {
+		metas := make(map[uuid.UUID]*backend.BlockMeta)
+		for _, m := range rw.blocklist.Metas(tenantID) {
+			metas[m.BlockID] = m
+		}
+		for _, m := range add {
+			metas[m.BlockID] = m
+		}
+
+		for _, mark := range change.Compacted {
+			if m, ok := metas[mark.BlockID]; ok {
+				compactedAdd = append(compactedAdd, &backend.CompactedBlockMeta{BlockMeta: *m, CompactedTime: mark.CompactedTime})
+				continue
+			}
+
+			cm, err := rw.c.CompactedBlockMeta(mark.BlockID, tenantID)
+			if err != nil {
+				level.Warn(rw.logger).Log("msg", "failed to read compacted meta of announced block", "tenant", tenantID, "blockID", mark.BlockID, "err", err)
+				continue
+			}
+			compactedAdd = append(compactedAdd, cm)
+		}
+	}
+
+	for _, id := range change.CompactedRemove {
+		compactedRemove = append(compactedRemove, &backend.CompactedBlockMeta{BlockMeta: backend.BlockMeta{BlockID: id}})
+	}
+
+	rw.blocklist.Update(tenantID, add, remove, compactedAdd, compactedRemove)
+}
+
+// updateBlocklist applies a change of the blocklist made by this component and announces it
+func (rw *readerWriter) updateBlocklist(tenantID string, add []*backend.BlockMeta, remove []*backend.BlockMeta, compactedAdd []*backend.CompactedBlockMeta, compactedRemove []*backend.CompactedBlockMeta) {
+	rw.blocklist.Update(tenantID, add, remove, compactedAdd, compactedRemove)
+
+	if rw.notifier != nil {
+		rw.notifier.NotifyBlocklistUpdate(tenantID, newBlocklistChange(add, remove, compactedAdd, compactedRemove))
+	}
+}
@@ -0,0 +1,49 @@
+package tempodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/tempo/pkg/model"
+	"github.com/grafana/tempo/tempodb/backend"
+)
+
+func TestApplyBlocklistUpdate(t *testing.T) {
+	r, w, _, _ := testConfig(t, backend.EncNone, 0)
+	rw := r.(*readerWriter)
+	ctx := context.Background()
+
+	head, err := w.WAL().NewBlock(uuid.New(), testTenantID, model.CurrentEncoding)
+	require.NoError(t, err)
+	block, err := w.CompleteBlock(head, &mockCombiner{})
+	require.NoError(t, err)
+	blockID := block.BlockMeta().BlockID
+
+	// the meta of a new block is read from the backend, unknown blocks are skipped
+	r.ApplyBlocklistUpdate(ctx, testTenantID, &BlocklistChange{Add: []uuid.UUID{blockID, uuid.New()}})
+	metas := r.BlockMetas(testTenantID)
+	require.Len(t, metas, 1)
+	assert.Equal(t, blockID, metas[0].BlockID)
+	assert.Equal(t, block.BlockMeta().TotalObjects, metas[0].TotalObjects)
+
+	// a compacted block keeps its meta
+	compactedTime := time.Now()
+	r.ApplyBlocklistUpdate(ctx, testTenantID, &BlocklistChange{
+		Remove:    []uuid.UUID{blockID},
+		Compacted: []CompactionMark{{BlockID: blockID, CompactedTime: compactedTime}},
+	})
+	assert.Empty(t, r.BlockMetas(testTenantID))
+	compacted := rw.blocklist.CompactedMetas(testTenantID)
+	require.Len(t, compacted, 1)
+	assert.Equal(t, blockID, compacted[0].BlockID)
+	assert.Equal(t, compactedTime, compacted[0].CompactedTime)
+	assert.Equal(t, metas[0].StartTime, compacted[0].StartTime)
+
+	r.ApplyBlocklistUpdate(ctx, testTenantID, &BlocklistChange{CompactedRemove: []uuid.UUID{blockID}})
+	assert.Empty(t, rw.blocklist.CompactedMetas(testTenantID))
+}
@@ -58,7 +58,7 @@ func (rw *readerWriter) retainTenant(tenantID string) {
 			} else {
 				metricMarkedForDeletion.Inc()
 
-				rw.blocklist.Update(tenantID, nil, []*backend.BlockMeta{b}, []*backend.CompactedBlockMeta{
+				rw.updateBlocklist(tenantID, nil, []*backend.BlockMeta{b}, []*backend.CompactedBlockMeta{
 					{
 						BlockMeta:     *b,
 						CompactedTime: time.Now(),
@@ -81,7 +81,7 @@ func (rw *readerWriter) retainTenant(tenantID string) {
 			} else {
 				metricDeleted.Inc()
 
-				rw.blocklist.Update(tenantID, nil, nil, nil, []*backend.CompactedBlockMeta{b})
+				rw.updateBlocklist(tenantID, nil, nil, nil, []*backend.CompactedBlockMeta{b})
 			}
 		}
 	}
@@ -1,6 +1,7 @@
 package search
 
 import (
+	"bytes"
 	"context"
 	"io"
 
@@ -23,8 +24,9 @@ type BackendSearchBlock struct {
 
 // NewBackendSearchBlock iterates through the given WAL search data and writes it to the persistent backend
 // in a more efficient paged form. Multiple traces are written in the same page to make sure of the flatbuffer
-// CreateSharedString feature which dedupes strings across the entire buffer.
-func NewBackendSearchBlock(input *StreamingSearchBlock, rw backend.Writer, blockID uuid.UUID, tenantID string, enc backend.Encoding, pageSizeBytes int) error {
+// CreateSharedString feature which dedupes strings across the entire buffer. If keep is set, only the entries
+// it keeps are written.
+func NewBackendSearchBlock(input *StreamingSearchBlock, rw backend.Writer, blockID uuid.UUID, tenantID string, enc backend.Encoding, pageSizeBytes int, keep common.ObjectFilter) error {
 	var err error
 	ctx := context.TODO()
 	indexPageSize := 100 * 1024
@@ -64,6 +66,10 @@ func NewBackendSearchBlock(input *StreamingSearchBlock, rw backend.Writer, block
 			continue
 		}
 
+		if keep != nil && !keep(id, data) {
+			continue
+		}
+
 		s.Reset(data)
 
 		header.AddEntry(s)
@@ -76,8 +82,9 @@ func NewBackendSearchBlock(input *StreamingSearchBlock, rw backend.Writer, block
 
 		for i, l := 0, s.TagsLength(); i < l; i++ {
 			s.Tags(kv, i)
+			info := kv.Info()
 			for j, ll := 0, kv.ValueLength(); j < ll; j++ {
-				entry.AddTag(string(kv.Key()), string(kv.Value(j)))
+				entry.AddTagWithInfo(string(kv.Key()), string(kv.Value(j)), info)
 			}
 		}
 
@@ -106,7 +113,7 @@ func NewBackendSearchBlock(input *StreamingSearchBlock, rw backend.Writer, block
 
 	// Write header
 	hb := header.ToBytes()
-	err = rw.Write(ctx, "search-header", blockID, tenantID, hb, true)
+	err = rw.Write(ctx, common.NameSearchHeader, blockID, tenantID, hb, true)
 	if err != nil {
 		return err
 	}
@@ -135,7 +142,7 @@ func (s *BackendSearchBlock) BlockID() uuid.UUID {
 	return s.id
 }
 
-func (s *BackendSearchBlock) Tags(ctx context.Context, tags map[string]struct{}) error {
+func (s *BackendSearchBlock) Tags(ctx context.Context, tags map[string]tempofb.TagInfo) error {
 	header, err := s.readSearchHeader(ctx)
 	if err != nil {
 		return err
@@ -144,17 +151,13 @@ func (s *BackendSearchBlock) Tags(ctx context.Context, tags map[string]struct{})
 	kv := &tempofb.KeyValues{}
 	for i, ii := 0, header.TagsLength(); i < ii; i++ {
 		header.Tags(kv, i)
-		key := string(kv.Key())
-		// check the tag is already set, this is more performant with repetitive values
-		if _, ok := tags[key]; !ok {
-			tags[key] = struct{}{}
-		}
+		AddTagInfo(tags, string(kv.Key()), kv.Info())
 	}
 
 	return nil
 }
 
-func (s *BackendSearchBlock) TagValues(ctx context.Context, tagName string, tagValues map[string]struct{}) error {
+func (s *BackendSearchBlock) TagValues(ctx context.Context, tagName string, tagValues map[string]uint32) error {
 	header, err := s.readSearchHeader(ctx)
 	if err != nil {
 		return err
@@ -162,12 +165,13 @@ func (s *BackendSearchBlock) TagValues(ctx context.Context, tagName string, tagV
 
 	kv := tempofb.FindTag(header, &tempofb.KeyValues{}, []byte(tagName))
 	if kv != nil {
+		counts := kv.ValueCountLength()
 		for j, valueLength := 0, kv.ValueLength(); j < valueLength; j++ {
-			value := string(kv.Value(j))
-			// check the value is already set, this is more performant with repetitive values
-			if _, ok := tagValues[value]; !ok {
-				tagValues[value] = struct{}{}
+			var count uint32
+			if j < counts {
+				count = kv.ValueCount(j)
 			}
+			tagValues[string(kv.Value(j))] += count
 		}
 	}
 	return nil
@@ -197,7 +201,7 @@ func (s *BackendSearchBlock) Search(ctx context.Context, p Pipeline, sr *Results
 
 	// Read header
 	// Verify something in the block matches by checking the header
-	hb, err := s.r.Read(ctx, "search-header", s.id, s.tenantID, true)
+	hb, err := s.r.Read(ctx, common.NameSearchHeader, s.id, s.tenantID, true)
 	if err != nil {
 		return err
 	}
@@ -286,9 +290,81 @@ func (s *BackendSearchBlock) Search(ctx context.Context, p Pipeline, sr *Results
 }
 
 func (s *BackendSearchBlock) readSearchHeader(ctx context.Context) (*tempofb.SearchBlockHeader, error) {
-	hb, err := s.r.Read(ctx, "search-header", s.id, s.tenantID, true)
+	hb, err := s.r.Read(ctx, common.NameSearchHeader, s.id, s.tenantID, true)
 	if err != nil {
 		return nil, err
 	}
 	return tempofb.GetRootAsSearchBlockHeader(hb, 0), nil
 }
+
+// CompactSearchHeaders writes the search header of every output block of a compaction, so the tags of the
+// compacted blocks can still be looked up. Blocks only have their search header in the backend, so the header of an
+// output is built from the headers of the inputs whose id range overlaps the range of the output. It can hold tags
+// of traces that were written to another output. The trace counts of an input are split evenly across the outputs
+// it overlaps. Inputs without a search header are skipped, no header is written for an output without any.
+func CompactSearchHeaders(ctx context.Context, r backend.Reader, w backend.Writer, tenantID string, inputs []*backend.BlockMeta, outputs []*backend.BlockMeta) error {
+	headers := make([]*tempofb.SearchBlockHeaderMutable, len(outputs))
+	kv := &tempofb.KeyValues{} // buffer
+
+	for _, input := range inputs {
+		header, err := OpenBackendSearchBlock(input.BlockID, tenantID, r).readSearchHeader(ctx)
+		if err == backend.ErrDoesNotExist {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		overlapping := make([]int, 0, len(outputs))
+		for i, output := range outputs {
+			if bytes.Compare(output.MinID, input.MaxID) <= 0 && bytes.Compare(input.MinID, output.MaxID) <= 0 {
+				overlapping = append(overlapping, i)
+			}
+		}
+
+		for _, i := range overlapping {
+			if headers[i] == nil {
+				headers[i] = tempofb.NewSearchBlockHeaderMutable()
+			}
+			mergeSearchHeader(headers[i], header, uint32(len(overlapping)), kv)
+		}
+	}
+
+	for i, header := range headers {
+		if header == nil {
+			continue
+		}
+
+		err := w.Write(ctx, common.NameSearchHeader, outputs[i].BlockID, tenantID, header.ToBytes(), true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeSearchHeader adds the tags, values and durations of src to dst. The trace count of every value is divided
+// by parts, rounded up.
+func mergeSearchHeader(dst *tempofb.SearchBlockHeaderMutable, src *tempofb.SearchBlockHeader, parts uint32, kv *tempofb.KeyValues) {
+	for i, ii := 0, src.TagsLength(); i < ii; i++ {
+		src.Tags(kv, i)
+		key := string(kv.Key())
+		info := kv.Info()
+		counts := kv.ValueCountLength()
+		for j, jj := 0, kv.ValueLength(); j < jj; j++ {
+			var count uint32
+			if j < counts {
+				count = (kv.ValueCount(j) + parts - 1) / parts
+			}
+			dst.Tags.AddWithInfo(key, string(kv.Value(j)), info, count)
+		}
+	}
+
+	if min := src.MinDurationNanos(); min > 0 && (dst.MinDur == 0 || min < dst.MinDur) {
+		dst.MinDur = min
+	}
+	if max := src.MaxDurationNanos(); max > dst.MaxDur {
+		dst.MaxDur = max
+	}
+}
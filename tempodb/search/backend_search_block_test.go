@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/tempo/pkg/tempopb"
 	"github.com/grafana/tempo/tempodb/backend"
 	"github.com/grafana/tempo/tempodb/backend/local"
+	"github.com/grafana/tempo/tempodb/encoding/common"
 	"github.com/stretchr/testify/require"
 )
 
@@ -33,7 +34,7 @@ func newBackendSearchBlockWithTraces(t testing.TB, traceCount int, enc backend.E
 	f, err := os.OpenFile(path.Join(t.TempDir(), "searchdata"), os.O_CREATE|os.O_RDWR, 0644)
 	require.NoError(t, err)
 
-	b1, err := NewStreamingSearchBlockForFile(f, uuid.New(), enc)
+	b1, err := NewStreamingSearchBlockForFile(f, uuid.New(), enc, false)
 	require.NoError(t, err)
 
 	for i := 0; i < traceCount; i++ {
@@ -48,7 +49,7 @@ func newBackendSearchBlockWithTraces(t testing.TB, traceCount int, enc backend.E
 	require.NoError(t, err)
 
 	blockID := uuid.New()
-	err = NewBackendSearchBlock(b1, backend.NewWriter(l), blockID, testTenantID, enc, pageSizeBytes)
+	err = NewBackendSearchBlock(b1, backend.NewWriter(l), blockID, testTenantID, enc, pageSizeBytes, nil)
 	require.NoError(t, err)
 
 	b2 := OpenBackendSearchBlock(blockID, testTenantID, backend.NewReader(l))
@@ -87,6 +88,67 @@ func TestBackendSearchBlockSearch(t *testing.T) {
 	}
 }
 
+func TestBackendSearchBlockCompactSearchHeaders(t *testing.T) {
+	ctx := context.Background()
+
+	l, err := local.NewBackend(&local.Config{
+		Path: t.TempDir(),
+	})
+	require.NoError(t, err)
+	r, w := backend.NewReader(l), backend.NewWriter(l)
+
+	traceID := func(i byte) []byte {
+		id := make([]byte, 16)
+		id[15] = i
+		return id
+	}
+
+	meta := func(minID, maxID byte) *backend.BlockMeta {
+		m := backend.NewBlockMeta(testTenantID, uuid.New(), "v2", backend.EncNone, "")
+		m.MinID, m.MaxID = traceID(minID), traceID(maxID)
+		return m
+	}
+
+	// writeBlock writes the search header of an input block with a trace per service. Like flushed ingester
+	// blocks it has no other search data.
+	writeBlock := func(minID, maxID byte, services map[byte]string) *backend.BlockMeta {
+		header := tempofb.NewSearchBlockHeaderMutable()
+		for i, service := range services {
+			entry := &tempofb.SearchEntryMutable{TraceID: traceID(i)}
+			entry.AddTagWithInfo("service.name", service, tempofb.TagInfo{Scope: tempofb.TagScopeResource, Type: tempofb.TagTypeString})
+			header.AddEntry(tempofb.NewSearchEntryFromBytes(entry.ToBytes()))
+		}
+
+		m := meta(minID, maxID)
+		require.NoError(t, w.Write(ctx, common.NameSearchHeader, m.BlockID, testTenantID, header.ToBytes(), true))
+		return m
+	}
+
+	input1 := writeBlock(1, 5, map[byte]string{1: "a", 2: "a", 5: "b"})
+	input2 := writeBlock(3, 6, map[byte]string{3: "b", 6: "c"})
+	input3 := writeBlock(8, 9, map[byte]string{8: "d", 9: "d"})
+	// blocks without search data are skipped
+	input4 := meta(1, 9)
+	outputs := []*backend.BlockMeta{meta(1, 3), meta(5, 6), meta(8, 9), meta(10, 11)}
+
+	err = CompactSearchHeaders(ctx, r, w, testTenantID, []*backend.BlockMeta{input1, input2, input3, input4}, outputs)
+	require.NoError(t, err)
+
+	values := func(m *backend.BlockMeta) map[string]uint32 {
+		values := map[string]uint32{}
+		require.NoError(t, OpenBackendSearchBlock(m.BlockID, testTenantID, r).TagValues(ctx, "service.name", values))
+		return values
+	}
+	// the outputs get the values of all inputs they overlap, counts are split across the outputs of an input
+	require.Equal(t, map[string]uint32{"a": 1, "b": 2, "c": 1}, values(outputs[0]))
+	require.Equal(t, map[string]uint32{"a": 1, "b": 2, "c": 1}, values(outputs[1]))
+	require.Equal(t, map[string]uint32{"d": 2}, values(outputs[2]))
+
+	// no header is written for an output that doesn't overlap an input with a search header
+	tags := map[string]tempofb.TagInfo{}
+	require.Equal(t, backend.ErrDoesNotExist, OpenBackendSearchBlock(outputs[3].BlockID, testTenantID, r).Tags(ctx, tags))
+}
+
 func TestBackendSearchBlockFinalSize(t *testing.T) {
 	traceCount := 10000
 	pageSizesMB := []float32{1}
@@ -94,7 +156,7 @@ func TestBackendSearchBlockFinalSize(t *testing.T) {
 	f, err := os.OpenFile(path.Join(t.TempDir(), "searchdata"), os.O_CREATE|os.O_RDWR, 0644)
 	require.NoError(t, err)
 
-	b1, err := NewStreamingSearchBlockForFile(f, uuid.New(), backend.EncNone)
+	b1, err := NewStreamingSearchBlockForFile(f, uuid.New(), backend.EncNone, false)
 	require.NoError(t, err)
 
 	for i := 0; i < traceCount; i++ {
@@ -113,7 +175,7 @@ func TestBackendSearchBlockFinalSize(t *testing.T) {
 	for _, enc := range backend.SupportedEncoding {
 		for _, sz := range pageSizesMB {
 
-			err := NewBackendSearchBlock(b1, backend.NewWriter(l), blockID, testTenantID, enc, int(sz*1024*1024))
+			err := NewBackendSearchBlock(b1, backend.NewWriter(l), blockID, testTenantID, enc, int(sz*1024*1024), nil)
 			require.NoError(t, err)
 
 			_, len, err := l.Read(context.TODO(), "search", backend.KeyPathForBlock(blockID, testTenantID), false)
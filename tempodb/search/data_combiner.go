@@ -30,8 +30,9 @@ func (*DataCombiner) Combine(_ string, searchData ...[]byte) ([]byte, bool, erro
 		sd.Reset(sb)
 		for i, ii := 0, sd.TagsLength(); i < ii; i++ {
 			sd.Tags(kv, i)
+			info := kv.Info()
 			for j, jj := 0, kv.ValueLength(); j < jj; j++ {
-				data.AddTag(string(kv.Key()), string(kv.Value(j)))
+				data.AddTagWithInfo(string(kv.Key()), string(kv.Value(j)), info)
 			}
 		}
 
@@ -37,24 +37,29 @@ func RescanBlocks(walPath string) ([]*StreamingSearchBlock, error) {
 		// here f.Name() does not have full path
 		b, warning, err := newStreamingSearchBlockFromWALReplay(searchFilepath, f.Name())
 
-		remove := false
 		if err != nil {
-			// wal replay failed, clear and warn
-			level.Warn(log.Logger).Log("msg", "failed to replay block. removing.", "file", f.Name(), "err", err)
-			remove = true
-		}
-
-		if b != nil && b.appender.Length() == 0 {
-			level.Warn(log.Logger).Log("msg", "empty wal file. ignoring.", "file", f.Name(), "err", err)
-			remove = true
+			// wal replay failed, keep the file for inspection and warn
+			level.Warn(log.Logger).Log("msg", "failed to replay block. quarantining.", "file", f.Name(), "err", err)
+			err = wal.QuarantineFile(searchFilepath, f.Name())
+			if err != nil {
+				return nil, err
+			}
+			continue
 		}
 
 		if warning != nil {
 			level.Warn(log.Logger).Log("msg", "received warning while replaying block. partial replay likely.", "file", f.Name(), "warning", warning, "records", b.appender.Length())
 		}
 
-		if remove {
-			err = os.Remove(filepath.Join(searchFilepath, f.Name()))
+		if b.appender.Length() == 0 {
+			_ = b.file.Close()
+			if warning != nil {
+				level.Warn(log.Logger).Log("msg", "no records could be replayed from wal file. quarantining.", "file", f.Name())
+				err = wal.QuarantineFile(searchFilepath, f.Name())
+			} else {
+				level.Warn(log.Logger).Log("msg", "empty wal file. ignoring.", "file", f.Name())
+				err = os.Remove(filepath.Join(searchFilepath, f.Name()))
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -76,18 +81,20 @@ func newStreamingSearchBlockFromWALReplay(searchFilepath, filename string) (*Str
 		return nil, nil, err
 	}
 
-	blockID, _, _, enc, _, err := wal.ParseFilename(filename)
+	blockID, tenantID, _, enc, _, err := wal.ParseFilename(filename)
 	if err != nil {
+		_ = f.Close()
 		return nil, nil, err
 	}
 
 	blockHeader := tempofb.NewSearchBlockHeaderMutable()
-	records, warning, err := wal.ReplayWALAndGetRecords(f, enc, func(bytes []byte) error {
+	records, warning, err := wal.ReplayWALAndGetRecords(f, tenantID, enc, func(bytes []byte) error {
 		entry := tempofb.NewSearchEntryFromBytes(bytes)
 		blockHeader.AddEntry(entry)
 		return nil
 	})
 	if err != nil {
+		_ = f.Close()
 		return nil, nil, err
 	}
 	return &StreamingSearchBlock{
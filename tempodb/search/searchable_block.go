@@ -2,11 +2,13 @@ package search
 
 import (
 	"context"
+
+	"github.com/grafana/tempo/pkg/tempofb"
 )
 
 type SearchableBlock interface {
-	Tags(ctx context.Context, tags map[string]struct{}) error
-	TagValues(ctx context.Context, tagName string, tagValues map[string]struct{}) error
+	Tags(ctx context.Context, tags map[string]tempofb.TagInfo) error
+	TagValues(ctx context.Context, tagName string, tagValues map[string]uint32) error
 	Search(ctx context.Context, p Pipeline, sr *Results) error
 }
 
@@ -42,8 +42,8 @@ func (s *StreamingSearchBlock) Clear() error {
 }
 
 // NewStreamingSearchBlockForFile creates a new streaming block that will read/write the given file.
-// File must be opened for read/write permissions.
-func NewStreamingSearchBlockForFile(f *os.File, blockID uuid.UUID, enc backend.Encoding) (*StreamingSearchBlock, error) {
+// File must be opened for read/write permissions. If checksums is true every page is written with a checksum.
+func NewStreamingSearchBlockForFile(f *os.File, blockID uuid.UUID, enc backend.Encoding, checksums bool) (*StreamingSearchBlock, error) {
 	s := &StreamingSearchBlock{
 		blockID: blockID,
 		file:    f,
@@ -52,7 +52,13 @@ func NewStreamingSearchBlockForFile(f *os.File, blockID uuid.UUID, enc backend.E
 	}
 
 	// Use versioned encoding to create paged entries
-	dataWriter, err := v2.NewDataWriter(f, enc)
+	var dataWriter common.DataWriter
+	var err error
+	if checksums {
+		dataWriter, err = v2.NewDataWriterWithChecksums(f, enc)
+	} else {
+		dataWriter, err = v2.NewDataWriter(f, enc)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -87,22 +93,22 @@ func (s *StreamingSearchBlock) Append(ctx context.Context, id common.ID, searchD
 	return s.appender.Append(id, combined)
 }
 
-func (s *StreamingSearchBlock) Tags(ctx context.Context, tags map[string]struct{}) error {
-	s.header.Tags.RangeKeys(func(k string) {
-		// check the tag is already set, this is more performant with repetitive values
-		if _, ok := tags[k]; !ok {
-			tags[k] = struct{}{}
-		}
+func (s *StreamingSearchBlock) Tags(ctx context.Context, tags map[string]tempofb.TagInfo) error {
+	s.headerMtx.RLock()
+	defer s.headerMtx.RUnlock()
+
+	s.header.Tags.RangeKeysWithInfo(func(k string, info tempofb.TagInfo) {
+		AddTagInfo(tags, k, info)
 	})
 	return nil
 }
 
-func (s *StreamingSearchBlock) TagValues(ctx context.Context, tagName string, tagValues map[string]struct{}) error {
-	s.header.Tags.RangeKeyValues(tagName, func(v string) {
-		// check the value is already set, this is more performant with repetitive values
-		if _, ok := tagValues[v]; !ok {
-			tagValues[v] = struct{}{}
-		}
+func (s *StreamingSearchBlock) TagValues(ctx context.Context, tagName string, tagValues map[string]uint32) error {
+	s.headerMtx.RLock()
+	defer s.headerMtx.RUnlock()
+
+	s.header.Tags.RangeKeyValuesWithCount(tagName, func(v string, count uint32) {
+		tagValues[v] += count
 	})
 	return nil
 }
@@ -32,7 +32,7 @@ func newStreamingSearchBlockWithTraces(t testing.TB, traceCount int, enc backend
 	f, err := os.OpenFile(path.Join(tmpDir, "search", fmt.Sprintf("1c505e8b-26cd-4621-ba7d-792bb55282d5:single-tenant:v2:%s:", enc.String())), os.O_CREATE|os.O_RDWR, 0644)
 	require.NoError(t, err)
 
-	sb, err := NewStreamingSearchBlockForFile(f, uuid.New(), enc)
+	sb, err := NewStreamingSearchBlockForFile(f, uuid.New(), enc, true)
 	require.NoError(t, err)
 
 	for i := 0; i < traceCount; i++ {
@@ -98,6 +98,34 @@ func TestStreamingSearchBlockReplay(t *testing.T) {
 	}
 }
 
+func TestStreamingSearchBlockReplayCorrupt(t *testing.T) {
+	traceCount := 10
+	walDir, sb := newStreamingSearchBlockWithTraces(t, traceCount, backend.EncSnappy)
+	filename := filepath.Base(sb.file.Name())
+
+	// corrupt the last byte of the first record
+	r := sb.appender.Records()[0]
+	b := make([]byte, 1)
+	_, err := sb.file.ReadAt(b, int64(r.Start)+int64(r.Length)-1)
+	require.NoError(t, err)
+	_, err = sb.file.WriteAt([]byte{b[0] ^ 0xFF}, int64(r.Start)+int64(r.Length)-1)
+	require.NoError(t, err)
+	require.NoError(t, sb.Close())
+
+	// create a file that can't be replayed
+	unreadable := "not-a-block"
+	require.NoError(t, os.WriteFile(filepath.Join(walDir, "search", unreadable), []byte{0x01}, 0644))
+
+	blocks, err := RescanBlocks(walDir)
+	require.NoError(t, err)
+	require.Len(t, blocks, 1)
+	assert.Equal(t, traceCount-1, len(blocks[0].appender.Records()))
+	assert.Equal(t, filename, filepath.Base(blocks[0].file.Name()))
+
+	require.NoFileExists(t, filepath.Join(walDir, "search", unreadable))
+	require.FileExists(t, filepath.Join(walDir, "search", "quarantine", unreadable))
+}
+
 func TestStreamingSearchBlockSearchBlock(t *testing.T) {
 	traceCount := 10
 	_, sb := newStreamingSearchBlockWithTraces(t, traceCount, backend.EncNone)
@@ -186,7 +214,7 @@ func TestStreamingSearchBlockIteratorDedupes(t *testing.T) {
 			f, err := os.OpenFile(path.Join(t.TempDir(), "searchdata"), os.O_CREATE|os.O_RDWR, 0644)
 			require.NoError(t, err)
 
-			b1, err := NewStreamingSearchBlockForFile(f, uuid.New(), backend.EncNone)
+			b1, err := NewStreamingSearchBlockForFile(f, uuid.New(), backend.EncNone, false)
 			require.NoError(t, err)
 
 			id := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}
@@ -1,6 +1,8 @@
 package search
 
 import (
+	"sort"
+
 	"github.com/grafana/tempo/pkg/model/trace"
 	"github.com/grafana/tempo/pkg/tempofb"
 	"github.com/grafana/tempo/pkg/tempopb"
@@ -60,3 +62,98 @@ func CombineSearchResults(existing *tempopb.TraceSearchMetadata, incoming *tempo
 		existing.DurationMs = incoming.DurationMs
 	}
 }
+
+// GetVirtualTagInfo returns the scope and value type of a virtual tag.
+func GetVirtualTagInfo(tagName string) tempofb.TagInfo {
+	switch tagName {
+
+	case trace.ErrorTag:
+		return tempofb.TagInfo{Scope: tempofb.TagScopeIntrinsic, Type: tempofb.TagTypeBool}
+	}
+
+	return tempofb.TagInfo{}
+}
+
+// AddTagInfo merges the scope and value type of the tag. It only writes to the map if the tag is new
+// or the info changed, this is more performant with repetitive tags.
+func AddTagInfo(tags map[string]tempofb.TagInfo, tagName string, info tempofb.TagInfo) {
+	existing, ok := tags[tagName]
+	if !ok {
+		tags[tagName] = info
+		return
+	}
+	if merged := existing.Merge(info); merged != existing {
+		tags[tagName] = merged
+	}
+}
+
+// GetSearchTagsResponse returns the sorted tag names with their scopes and value types.
+func GetSearchTagsResponse(tags map[string]tempofb.TagInfo) *tempopb.SearchTagsResponse {
+	resp := &tempopb.SearchTagsResponse{
+		TagNames: make([]string, 0, len(tags)),
+		Tags:     make([]*tempopb.SearchTag, 0, len(tags)),
+	}
+	for k := range tags {
+		resp.TagNames = append(resp.TagNames, k)
+	}
+	sort.Strings(resp.TagNames)
+
+	for _, k := range resp.TagNames {
+		info := tags[k]
+		resp.Tags = append(resp.Tags, &tempopb.SearchTag{
+			Name:   k,
+			Scopes: info.Scope.Strings(),
+			Types:  info.Type.Strings(),
+		})
+	}
+
+	return resp
+}
+
+// GetSearchTagValuesResponse returns the sorted tag values with the number of traces they were found in.
+func GetSearchTagValuesResponse(tagValues map[string]uint32) *tempopb.SearchTagValuesResponse {
+	resp := &tempopb.SearchTagValuesResponse{
+		TagValues: make([]string, 0, len(tagValues)),
+		Values:    make([]*tempopb.SearchTagValue, 0, len(tagValues)),
+	}
+	for v := range tagValues {
+		resp.TagValues = append(resp.TagValues, v)
+	}
+	sort.Strings(resp.TagValues)
+
+	for _, v := range resp.TagValues {
+		resp.Values = append(resp.Values, &tempopb.SearchTagValue{
+			Value: v,
+			Count: tagValues[v],
+		})
+	}
+
+	return resp
+}
+
+// CombineSearchTagsResponse adds the tags of the response. Responses without scopes and value types,
+// i.e. from components that don't record them yet, only contribute the tag names.
+func CombineSearchTagsResponse(tags map[string]tempofb.TagInfo, resp *tempopb.SearchTagsResponse) {
+	for _, k := range resp.TagNames {
+		AddTagInfo(tags, k, tempofb.TagInfo{})
+	}
+	for _, t := range resp.Tags {
+		AddTagInfo(tags, t.Name, tempofb.TagInfo{
+			Scope: tempofb.ParseTagScope(t.Scopes),
+			Type:  tempofb.ParseTagType(t.Types),
+		})
+	}
+}
+
+// CombineSearchTagValuesResponse adds the tag values of the response and sums the number of traces.
+// The number of traces is approximate, a trace in multiple ingesters or blocks is counted multiple times.
+func CombineSearchTagValuesResponse(tagValues map[string]uint32, resp *tempopb.SearchTagValuesResponse) {
+	for _, v := range resp.TagValues {
+		if _, ok := tagValues[v]; !ok {
+			tagValues[v] = 0
+		}
+	}
+	for _, v := range resp.Values {
+		tagValues[v.Value] += v.Count
+	}
+}
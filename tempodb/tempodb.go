@@ -17,6 +17,7 @@ import (
 
 	pkg_cache "github.com/grafana/tempo/pkg/cache"
 	"github.com/grafana/tempo/pkg/model"
+	"github.com/grafana/tempo/pkg/tempofb"
 	"github.com/grafana/tempo/pkg/tempopb"
 	"github.com/grafana/tempo/pkg/util/log"
 	"github.com/grafana/tempo/tempodb/backend"
@@ -24,12 +25,14 @@ import (
 	"github.com/grafana/tempo/tempodb/backend/cache"
 	"github.com/grafana/tempo/tempodb/backend/cache/memcached"
 	"github.com/grafana/tempo/tempodb/backend/cache/redis"
+	"github.com/grafana/tempo/tempodb/backend/encryption"
 	"github.com/grafana/tempo/tempodb/backend/gcs"
 	"github.com/grafana/tempo/tempodb/backend/local"
 	"github.com/grafana/tempo/tempodb/backend/s3"
 	"github.com/grafana/tempo/tempodb/blocklist"
 	"github.com/grafana/tempo/tempodb/encoding"
 	"github.com/grafana/tempo/tempodb/encoding/common"
+	v2 "github.com/grafana/tempo/tempodb/encoding/v2"
 	"github.com/grafana/tempo/tempodb/pool"
 	"github.com/grafana/tempo/tempodb/search"
 	"github.com/grafana/tempo/tempodb/wal"
@@ -69,9 +72,10 @@ var (
 type Writer interface {
 	WriteBlock(ctx context.Context, block WriteableBlock) error
 	CompleteBlock(block *wal.AppendBlock, combiner model.ObjectCombiner) (common.BackendBlock, error)
-	CompleteBlockWithBackend(ctx context.Context, block *wal.AppendBlock, combiner model.ObjectCombiner, r backend.Reader, w backend.Writer) (common.BackendBlock, error)
-	CompleteSearchBlockWithBackend(block *search.StreamingSearchBlock, blockID uuid.UUID, tenantID string, r backend.Reader, w backend.Writer) (*search.BackendSearchBlock, error)
+	CompleteBlockWithBackend(ctx context.Context, block *wal.AppendBlock, combiner model.ObjectCombiner, keep common.ObjectFilter, r backend.Reader, w backend.Writer) (common.BackendBlock, error)
+	CompleteSearchBlockWithBackend(block *search.StreamingSearchBlock, blockID uuid.UUID, tenantID string, keep common.ObjectFilter, r backend.Reader, w backend.Writer) (*search.BackendSearchBlock, error)
 	WAL() *wal.WAL
+	EnableBlocklistNotifications(n BlocklistNotifier)
 }
 
 type IterateObjectCallback func(id common.ID, obj []byte) bool
@@ -79,8 +83,12 @@ type IterateObjectCallback func(id common.ID, obj []byte) bool
 type Reader interface {
 	Find(ctx context.Context, tenantID string, id common.ID, blockStart string, blockEnd string, timeStart int64, timeEnd int64) ([]*tempopb.Trace, []error, error)
 	Search(ctx context.Context, meta *backend.BlockMeta, req *tempopb.SearchRequest, opts common.SearchOptions) (*tempopb.SearchResponse, error)
+	SearchTags(ctx context.Context, tenantID string, blockID uuid.UUID, tags map[string]tempofb.TagInfo) error
+	SearchTagValues(ctx context.Context, tenantID string, blockID uuid.UUID, tagName string, tagValues map[string]uint32) error
 	BlockMetas(tenantID string) []*backend.BlockMeta
+	CompactedBlockMetas(tenantID string) []*backend.CompactedBlockMeta
 	EnablePolling(sharder blocklist.JobSharder)
+	ApplyBlocklistUpdate(ctx context.Context, tenantID string, change *BlocklistChange)
 
 	Shutdown()
 }
@@ -119,6 +127,7 @@ type readerWriter struct {
 
 	blocklistPoller *blocklist.Poller
 	blocklist       *blocklist.List
+	notifier        BlocklistNotifier
 
 	compactorCfg          *CompactorConfig
 	compactorSharder      CompactorSharder
@@ -154,25 +163,77 @@ func New(cfg *Config, logger gkLog.Logger) (Reader, Writer, Compactor, error) {
 		return nil, nil, nil, err
 	}
 
-	uncachedReader := backend.NewReader(rawR)
-	uncachedWriter := backend.NewWriter(rawW)
+	// objects are encrypted before they are cached, so caches only hold encrypted objects
+	var keys *encryption.Keys
+	uncachedRawR, uncachedRawW := rawR, rawW
+	if cfg.Encryption.Enabled() {
+		keys, err = encryption.NewKeys(cfg.Encryption)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		uncachedRawR, uncachedRawW, err = encryption.NewEncryption(rawR, rawW, keys)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	uncachedReader := backend.NewReader(uncachedRawR)
+	uncachedWriter := backend.NewWriter(uncachedRawW)
 
 	var cacheBackend pkg_cache.Cache
 
 	switch cfg.Cache {
 	case "redis":
-		cacheBackend = redis.NewClient(cfg.Redis, cfg.BackgroundCache, logger)
+		cacheBackend = redis.NewClient(cfg.Redis, cfg.BackgroundCache, "tempo", logger)
 	case "memcached":
-		cacheBackend = memcached.NewClient(cfg.Memcached, cfg.BackgroundCache, logger)
+		cacheBackend = memcached.NewClient(cfg.Memcached, cfg.BackgroundCache, "tempo", logger)
 	}
 
-	if cacheBackend != nil {
+	if cfg.InProcessCache != nil && cfg.InProcessCache.MaxSizeBytes > 0 {
+		ttl := cfg.CacheTTL
+		if ttl == nil {
+			ttl = &cache.TTLConfig{}
+		}
+
+		lru := pkg_cache.NewLRU(*cfg.InProcessCache, "tempo", prometheus.DefaultRegisterer)
+		cacheBackend = pkg_cache.NewTiered("tempo", lru, cacheBackend, ttl.TTL, prometheus.DefaultRegisterer)
+
+		// with a ttl the tenant index is cached as well
+		if ttl.TenantIndex > 0 {
+			rawR, rawW, err = cache.NewCacheWithTenantIndex(rawR, rawW, cacheBackend)
+		} else {
+			rawR, rawW, err = cache.NewCache(rawR, rawW, cacheBackend)
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	} else if cacheBackend != nil {
 		rawR, rawW, err = cache.NewCache(rawR, rawW, cacheBackend)
 		if err != nil {
 			return nil, nil, nil, err
 		}
 	}
 
+	if cfg.DiskCache != nil && cfg.DiskCache.Path != "" {
+		diskCache, err := pkg_cache.NewDiskCache(*cfg.DiskCache, "tempo", prometheus.DefaultRegisterer, logger)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		rawR, rawW, err = cache.NewCacheWithRanges(rawR, rawW, diskCache)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	if keys != nil {
+		rawR, rawW, err = encryption.NewEncryption(rawR, rawW, keys)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
 	r := backend.NewReader(rawR)
 	w := backend.NewWriter(rawW)
 	rw := &readerWriter{
@@ -197,17 +258,25 @@ func New(cfg *Config, logger gkLog.Logger) (Reader, Writer, Compactor, error) {
 
 func (rw *readerWriter) WriteBlock(ctx context.Context, c WriteableBlock) error {
 	w := rw.getWriterForBlock(c.BlockMeta(), time.Now())
-	return c.Write(ctx, w)
+	err := c.Write(ctx, w)
+	if err != nil {
+		return err
+	}
+
+	if rw.notifier != nil {
+		rw.notifier.NotifyBlocklistUpdate(c.BlockMeta().TenantID, &BlocklistChange{Add: []uuid.UUID{c.BlockMeta().BlockID}})
+	}
+	return nil
 }
 
 // CompleteBlock iterates the given WAL block and flushes it to the TempoDB backend.
 func (rw *readerWriter) CompleteBlock(block *wal.AppendBlock, combiner model.ObjectCombiner) (common.BackendBlock, error) {
-	return rw.CompleteBlockWithBackend(context.TODO(), block, combiner, rw.r, rw.w)
+	return rw.CompleteBlockWithBackend(context.TODO(), block, combiner, nil, rw.r, rw.w)
 }
 
 // CompleteBlock iterates the given WAL block but flushes it to the given backend instead of the default TempoDB backend. The
-// new block will have the same ID as the input block.
-func (rw *readerWriter) CompleteBlockWithBackend(ctx context.Context, block *wal.AppendBlock, combiner model.ObjectCombiner, r backend.Reader, w backend.Writer) (common.BackendBlock, error) {
+// new block will have the same ID as the input block. If keep is set, only the objects it keeps are written.
+func (rw *readerWriter) CompleteBlockWithBackend(ctx context.Context, block *wal.AppendBlock, combiner model.ObjectCombiner, keep common.ObjectFilter, r backend.Reader, w backend.Writer) (common.BackendBlock, error) {
 
 	// Try to use same version and encoding as the WAL
 	vers, err := encoding.FromVersion(block.Meta().Version)
@@ -221,6 +290,9 @@ func (rw *readerWriter) CompleteBlockWithBackend(ctx context.Context, block *wal
 	if err != nil {
 		return nil, err
 	}
+	if keep != nil {
+		iter = v2.NewFilteringIterator(iter, keep)
+	}
 
 	walMeta := block.Meta()
 
@@ -250,8 +322,8 @@ func (rw *readerWriter) CompleteBlockWithBackend(ctx context.Context, block *wal
 	return backendBlock, nil
 }
 
-func (rw *readerWriter) CompleteSearchBlockWithBackend(block *search.StreamingSearchBlock, blockID uuid.UUID, tenantID string, r backend.Reader, w backend.Writer) (*search.BackendSearchBlock, error) {
-	err := search.NewBackendSearchBlock(block, w, blockID, tenantID, rw.cfg.Block.SearchEncoding, rw.cfg.Block.SearchPageSizeBytes)
+func (rw *readerWriter) CompleteSearchBlockWithBackend(block *search.StreamingSearchBlock, blockID uuid.UUID, tenantID string, keep common.ObjectFilter, r backend.Reader, w backend.Writer) (*search.BackendSearchBlock, error) {
+	err := search.NewBackendSearchBlock(block, w, blockID, tenantID, rw.cfg.Block.SearchEncoding, rw.cfg.Block.SearchPageSizeBytes, keep)
 	if err != nil {
 		return nil, err
 	}
@@ -268,6 +340,10 @@ func (rw *readerWriter) BlockMetas(tenantID string) []*backend.BlockMeta {
 	return rw.blocklist.Metas(tenantID)
 }
 
+func (rw *readerWriter) CompactedBlockMetas(tenantID string) []*backend.CompactedBlockMeta {
+	return rw.blocklist.CompactedMetas(tenantID)
+}
+
 func (rw *readerWriter) Find(ctx context.Context, tenantID string, id common.ID, blockStart string, blockEnd string, timeStart int64, timeEnd int64) ([]*tempopb.Trace, []error, error) {
 	// tracing instrumentation
 	logger := log.WithContext(ctx, log.Logger)
@@ -357,6 +433,25 @@ func (rw *readerWriter) Search(ctx context.Context, meta *backend.BlockMeta, req
 	return block.Search(ctx, req, opts)
 }
 
+// SearchTags adds the tags in the search header of the block to tags. Blocks without search data are skipped.
+func (rw *readerWriter) SearchTags(ctx context.Context, tenantID string, blockID uuid.UUID, tags map[string]tempofb.TagInfo) error {
+	err := search.OpenBackendSearchBlock(blockID, tenantID, rw.r).Tags(ctx, tags)
+	if err == backend.ErrDoesNotExist {
+		return nil
+	}
+	return err
+}
+
+// SearchTagValues adds the values of the tag in the search header of the block to tagValues. Blocks without
+// search data are skipped.
+func (rw *readerWriter) SearchTagValues(ctx context.Context, tenantID string, blockID uuid.UUID, tagName string, tagValues map[string]uint32) error {
+	err := search.OpenBackendSearchBlock(blockID, tenantID, rw.r).TagValues(ctx, tagName, tagValues)
+	if err == backend.ErrDoesNotExist {
+		return nil
+	}
+	return err
+}
+
 func (rw *readerWriter) Shutdown() {
 	// todo: stop blocklist poll
 	rw.pool.Shutdown()
@@ -412,6 +507,8 @@ func (rw *readerWriter) EnablePolling(sharder blocklist.JobSharder) {
 		PollFallback:        rw.cfg.BlocklistPollFallback,
 		TenantIndexBuilders: rw.cfg.BlocklistPollTenantIndexBuilders,
 		StaleTenantIndex:    rw.cfg.BlocklistPollStaleTenantIndex,
+		TenantIndexDeltas:   rw.cfg.BlocklistPollTenantIndexDeltas,
+		PollInterval:        rw.cfg.BlocklistPoll,
 	}, sharder, rw.r, rw.c, rw.w, rw.logger)
 
 	rw.blocklistPoller = blocklistPoller
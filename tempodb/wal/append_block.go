@@ -31,9 +31,11 @@ type AppendBlock struct {
 	filepath string
 	readFile *os.File
 	once     sync.Once
+
+	createdTime time.Time
 }
 
-func newAppendBlock(id uuid.UUID, tenantID string, filepath string, e backend.Encoding, dataEncoding string, ingestionSlack time.Duration) (*AppendBlock, error) {
+func newAppendBlock(id uuid.UUID, tenantID string, filepath string, e backend.Encoding, dataEncoding string, ingestionSlack time.Duration, checksums bool) (*AppendBlock, error) {
 	if strings.ContainsRune(dataEncoding, ':') ||
 		len([]rune(dataEncoding)) > maxDataEncodingLength {
 		return nil, fmt.Errorf("dataEncoding %s is invalid", dataEncoding)
@@ -43,6 +45,7 @@ func newAppendBlock(id uuid.UUID, tenantID string, filepath string, e backend.En
 		meta:           backend.NewBlockMeta(tenantID, id, v2.VersionString, e, dataEncoding),
 		filepath:       filepath,
 		ingestionSlack: ingestionSlack,
+		createdTime:    time.Now(),
 	}
 
 	name := h.fullFilename()
@@ -53,7 +56,12 @@ func newAppendBlock(id uuid.UUID, tenantID string, filepath string, e backend.En
 	}
 	h.appendFile = f
 
-	dataWriter, err := v2.NewDataWriter(f, e)
+	var dataWriter common.DataWriter
+	if checksums {
+		dataWriter, err = v2.NewDataWriterWithChecksums(f, e)
+	} else {
+		dataWriter, err = v2.NewDataWriter(f, e)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -76,6 +84,7 @@ func newAppendBlockFromFile(filename string, path string, ingestionSlack time.Du
 		meta:           backend.NewBlockMeta(tenantID, blockID, version, e, dataEncoding),
 		filepath:       path,
 		ingestionSlack: ingestionSlack,
+		createdTime:    time.Now(),
 	}
 
 	// replay file to extract records
@@ -87,7 +96,7 @@ func newAppendBlockFromFile(filename string, path string, ingestionSlack time.Du
 	blockStart := uint32(math.MaxUint32)
 	blockEnd := uint32(0)
 
-	records, warning, err := ReplayWALAndGetRecords(f, e, func(bytes []byte) error {
+	records, lostRecords, warning, err := replayWAL(f, tenantID, e, func(bytes []byte) error {
 		start, end, err := fn(bytes, dataEncoding)
 		if err != nil {
 			return err
@@ -104,6 +113,9 @@ func newAppendBlockFromFile(filename string, path string, ingestionSlack time.Du
 	if err != nil {
 		return nil, nil, err
 	}
+	if lostRecords > 0 {
+		metricReplayLostTraces.WithLabelValues(tenantID).Add(float64(lostRecords))
+	}
 
 	b.appender = v2.NewRecordAppender(records)
 	b.meta.TotalObjects = b.appender.Length()
@@ -137,6 +149,11 @@ func (a *AppendBlock) Meta() *backend.BlockMeta {
 	return a.meta
 }
 
+// CreatedTime returns the time the block was created or replayed
+func (a *AppendBlock) CreatedTime() time.Time {
+	return a.createdTime
+}
+
 func (a *AppendBlock) Iterator(combiner model.ObjectCombiner) (common.Iterator, error) {
 	if a.appendFile != nil {
 		err := a.appendFile.Close()
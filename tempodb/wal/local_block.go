@@ -24,6 +24,7 @@ type LocalBlock struct {
 	writer backend.Writer
 
 	flushedTime atomic.Int64 // protecting flushedTime b/c it's accessed from the store on flush and from the ingester instance checking flush time
+	createdTime time.Time
 }
 
 var _ common.Finder = (*LocalBlock)(nil)
@@ -34,6 +35,7 @@ func NewLocalBlock(ctx context.Context, existingBlock common.BackendBlock, l *lo
 		BackendBlock: existingBlock,
 		reader:       backend.NewReader(l),
 		writer:       backend.NewWriter(l),
+		createdTime:  time.Now(),
 	}
 
 	flushedBytes, err := c.reader.Read(ctx, nameFlushed, c.BlockMeta().BlockID, c.BlockMeta().TenantID, false)
@@ -62,6 +64,25 @@ func (c *LocalBlock) FlushedTime() time.Time {
 	return time.Unix(unixTime, 0)
 }
 
+// CreatedTime returns the time the block was created from a completed wal block or rediscovered
+func (c *LocalBlock) CreatedTime() time.Time {
+	return c.createdTime
+}
+
+// writeSearchHeader copies the search header of the block to the remote backend so the tags of the block can
+// be looked up. Blocks without search data don't have a search header.
+func (c *LocalBlock) writeSearchHeader(ctx context.Context, w backend.Writer) error {
+	b, err := c.reader.Read(ctx, common.NameSearchHeader, c.BlockMeta().BlockID, c.BlockMeta().TenantID, false)
+	if err == backend.ErrDoesNotExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return w.Write(ctx, common.NameSearchHeader, c.BlockMeta().BlockID, c.BlockMeta().TenantID, b, true)
+}
+
 func (c *LocalBlock) SetFlushed(ctx context.Context) error {
 	flushedTime := time.Now()
 	flushedBytes, err := flushedTime.MarshalText()
@@ -79,7 +100,13 @@ func (c *LocalBlock) SetFlushed(ctx context.Context) error {
 }
 
 func (c *LocalBlock) Write(ctx context.Context, w backend.Writer) error {
-	err := encoding.CopyBlock(ctx, c.BlockMeta(), c.reader, w)
+	// the search header is copied first, it must be available as soon as the block is
+	err := c.writeSearchHeader(ctx, w)
+	if err != nil {
+		return errors.Wrap(err, "error copying search header from local to remote backend")
+	}
+
+	err = encoding.CopyBlock(ctx, c.BlockMeta(), c.reader, w)
 	if err != nil {
 		return errors.Wrap(err, "error copying block from local to remote backend")
 	}
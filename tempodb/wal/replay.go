@@ -2,6 +2,7 @@ package wal
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -11,18 +12,34 @@ import (
 	v2 "github.com/grafana/tempo/tempodb/encoding/v2"
 )
 
-// ReplayWALAndGetRecords replays a WAL file that could contain either traces or searchdata
-func ReplayWALAndGetRecords(file *os.File, enc backend.Encoding, handleObj func([]byte) error) ([]common.Record, error, error) {
+// ReplayWALAndGetRecords replays a WAL file that could contain either traces or searchdata. Corrupted records are
+// skipped and replay continues with the next record. If the framing of a record is broken, e.g. by a torn write,
+// the rest of the file can't be read and replay stops. Lost bytes are recorded per tenant and summarized with
+// the lost records in the returned warning.
+func ReplayWALAndGetRecords(file *os.File, tenantID string, enc backend.Encoding, handleObj func([]byte) error) ([]common.Record, error, error) {
+	records, _, warning, err := replayWAL(file, tenantID, enc, handleObj)
+	return records, warning, err
+}
+
+// replayWAL is ReplayWALAndGetRecords, but also returns the number of lost records
+func replayWAL(file *os.File, tenantID string, enc backend.Encoding, handleObj func([]byte) error) ([]common.Record, int, error, error) {
 	dataReader, err := v2.NewDataReader(backend.NewContextReaderWithAllReader(file), enc)
 	if err != nil {
-		return nil, nil, err
+		return nil, 0, nil, err
+	}
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return nil, 0, nil, err
 	}
 
 	var buffer []byte
 	var records []common.Record
-	var warning error
 	var pageLen uint32
 	var id []byte
+	var lostRecords int
+	var lostBytes uint64
+	var lastErr error
 	objectReader := v2.NewObjectReaderWriter()
 	currentOffset := uint64(0)
 	for {
@@ -30,29 +47,28 @@ func ReplayWALAndGetRecords(file *os.File, enc backend.Encoding, handleObj func(
 		if err == io.EOF {
 			break
 		}
-		if err != nil {
-			warning = fmt.Errorf("accessing NextPage while replaying wal: %w", err)
-			break
+		if errors.Is(err, v2.ErrCorruptPage) {
+			lostRecords++
+			lostBytes += uint64(pageLen)
+			lastErr = fmt.Errorf("accessing NextPage while replaying wal: %w", err)
+			currentOffset += uint64(pageLen)
+			continue
 		}
-
-		reader := bytes.NewReader(buffer)
-		id, buffer, err = objectReader.UnmarshalObjectFromReader(reader)
 		if err != nil {
-			warning = fmt.Errorf("unmarshalling object while replaying wal: %w", err)
-			break
-		}
-		// wal should only ever have one object per page, test that here
-		_, _, err = objectReader.UnmarshalObjectFromReader(reader)
-		if err != io.EOF {
-			warning = fmt.Errorf("expected EOF while replaying wal: %w", err)
+			// the page boundaries are lost, count the rest of the file as a single lost record
+			lostRecords++
+			lostBytes += uint64(fileInfo.Size()) - currentOffset
+			lastErr = fmt.Errorf("accessing NextPage while replaying wal: %w", err)
 			break
 		}
 
-		// handleObj is primarily used by search replay to record search data in block header
-		err = handleObj(buffer)
+		id, err = replayRecord(objectReader, buffer, handleObj)
 		if err != nil {
-			warning = fmt.Errorf("custom obj handler while replaying wal: %w", err)
-			break
+			lostRecords++
+			lostBytes += uint64(pageLen)
+			lastErr = err
+			currentOffset += uint64(pageLen)
+			continue
 		}
 
 		// make a copy so we don't hold onto the iterator buffer
@@ -67,5 +83,33 @@ func ReplayWALAndGetRecords(file *os.File, enc backend.Encoding, handleObj func(
 
 	common.SortRecords(records)
 
-	return records, warning, nil
+	var warning error
+	if lostRecords > 0 {
+		metricReplayLostBytes.WithLabelValues(tenantID).Add(float64(lostBytes))
+		warning = fmt.Errorf("skipped %d corrupted records (%d bytes) while replaying wal, last error: %w", lostRecords, lostBytes, lastErr)
+	}
+
+	return records, lostRecords, warning, nil
+}
+
+// replayRecord unmarshals the single object in the page, passes it to handleObj and returns its id
+func replayRecord(objectReader common.ObjectReaderWriter, page []byte, handleObj func([]byte) error) ([]byte, error) {
+	reader := bytes.NewReader(page)
+	id, obj, err := objectReader.UnmarshalObjectFromReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("unmarshalling object while replaying wal: %w", err)
+	}
+	// wal should only ever have one object per page, test that here
+	_, _, err = objectReader.UnmarshalObjectFromReader(reader)
+	if err != io.EOF {
+		return nil, fmt.Errorf("expected EOF while replaying wal: %w", err)
+	}
+
+	// handleObj is primarily used by search replay to record search data in block header
+	err = handleObj(obj)
+	if err != nil {
+		return nil, fmt.Errorf("custom obj handler while replaying wal: %w", err)
+	}
+
+	return id, nil
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -26,6 +27,21 @@ var (
 		Name:      "warnings_total",
 		Help:      "The total number of warnings per tenant with reason.",
 	}, []string{"tenant", "reason"})
+	metricReplayLostBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "wal_replay_lost_bytes_total",
+		Help:      "The total number of bytes of corrupted records skipped while replaying the wal.",
+	}, []string{"tenant"})
+	metricReplayLostTraces = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "wal_replay_lost_traces_total",
+		Help:      "The total number of corrupted trace records skipped while replaying the wal.",
+	}, []string{"tenant"})
+	metricQuarantinedFiles = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "tempo",
+		Name:      "wal_quarantined_files_total",
+		Help:      "The total number of wal files moved to quarantine because they could not be replayed.",
+	})
 )
 
 // extracts a time range from an object. start/end times returned are unix epoch
@@ -33,10 +49,15 @@ var (
 type RangeFunc func(obj []byte, dataEncoding string) (uint32, uint32, error)
 
 const (
-	completedDir = "completed"
-	blocksDir    = "blocks"
+	completedDir  = "completed"
+	blocksDir     = "blocks"
+	quarantineDir = "quarantine"
 )
 
+// maxQuarantinedFiles is the number of files kept in a quarantine folder. When a file is quarantined
+// the oldest files beyond this are removed.
+const maxQuarantinedFiles = 10
+
 type WAL struct {
 	c *Config
 	l *local.Backend
@@ -49,6 +70,7 @@ type Config struct {
 	Encoding          backend.Encoding `yaml:"encoding"`
 	SearchEncoding    backend.Encoding `yaml:"search_encoding"`
 	IngestionSlack    time.Duration    `yaml:"ingestion_time_range_slack"`
+	PageChecksums     bool             `yaml:"page_checksums"`
 }
 
 func New(c *Config) (*WAL, error) {
@@ -118,24 +140,28 @@ func (w *WAL) RescanBlocks(fn RangeFunc, additionalStartSlack time.Duration, log
 		level.Info(log).Log("msg", "beginning replay", "file", f.Name(), "size", fileInfo.Size())
 		b, warning, err := newAppendBlockFromFile(f.Name(), w.c.Filepath, w.c.IngestionSlack, additionalStartSlack, fn)
 
-		remove := false
 		if err != nil {
-			// wal replay failed, clear and warn
-			level.Warn(log).Log("msg", "failed to replay block. removing.", "file", f.Name(), "err", err)
-			remove = true
-		}
-
-		if b != nil && b.appender.Length() == 0 {
-			level.Warn(log).Log("msg", "empty wal file. ignoring.", "file", f.Name(), "err", err)
-			remove = true
+			// wal replay failed, keep the file for inspection and warn
+			level.Warn(log).Log("msg", "failed to replay block. quarantining.", "file", f.Name(), "err", err)
+			err = QuarantineFile(w.c.Filepath, f.Name())
+			if err != nil {
+				return nil, err
+			}
+			continue
 		}
 
 		if warning != nil {
 			level.Warn(log).Log("msg", "received warning while replaying block. partial replay likely.", "file", f.Name(), "warning", warning, "records", b.appender.Length())
 		}
 
-		if remove {
-			err = os.Remove(filepath.Join(w.c.Filepath, f.Name()))
+		if b.appender.Length() == 0 {
+			if warning != nil {
+				level.Warn(log).Log("msg", "no records could be replayed from wal file. quarantining.", "file", f.Name())
+				err = QuarantineFile(w.c.Filepath, f.Name())
+			} else {
+				level.Warn(log).Log("msg", "empty wal file. ignoring.", "file", f.Name())
+				err = os.Remove(filepath.Join(w.c.Filepath, f.Name()))
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -150,8 +176,71 @@ func (w *WAL) RescanBlocks(fn RangeFunc, additionalStartSlack time.Duration, log
 	return blocks, nil
 }
 
+// QuarantineFile moves a file that can't be replayed from dir to the quarantine folder in dir. Directories are
+// skipped on replay, so the file is kept for inspection without being replayed again. Only the most recently
+// quarantined files are kept.
+func QuarantineFile(dir string, filename string) error {
+	p := filepath.Join(dir, quarantineDir)
+	err := os.MkdirAll(p, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(filepath.Join(dir, filename), filepath.Join(p, filename))
+	if err != nil {
+		return err
+	}
+
+	// os.Rename keeps the modification time, touch the file so it is the newest in the quarantine
+	now := time.Now()
+	err = os.Chtimes(filepath.Join(p, filename), now, now)
+	if err != nil {
+		return err
+	}
+
+	metricQuarantinedFiles.Inc()
+	return pruneQuarantine(p, maxQuarantinedFiles)
+}
+
+// pruneQuarantine removes the oldest files in the quarantine folder p until at most max are left
+func pruneQuarantine(p string, max int) error {
+	entries, err := os.ReadDir(p)
+	if err != nil {
+		return err
+	}
+
+	files := make([]os.FileInfo, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return err
+		}
+		files = append(files, info)
+	}
+
+	if len(files) <= max {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].ModTime().Before(files[j].ModTime())
+	})
+
+	for _, f := range files[:len(files)-max] {
+		err = os.Remove(filepath.Join(p, f.Name()))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (w *WAL) NewBlock(id uuid.UUID, tenantID string, dataEncoding string) (*AppendBlock, error) {
-	return newAppendBlock(id, tenantID, w.c.Filepath, w.c.Encoding, dataEncoding, w.c.IngestionSlack)
+	return newAppendBlock(id, tenantID, w.c.Filepath, w.c.Encoding, dataEncoding, w.c.IngestionSlack, w.c.PageChecksums)
 }
 
 func (w *WAL) NewFile(blockid uuid.UUID, tenantid string, dir string) (*os.File, backend.Encoding, error) {
@@ -218,6 +307,11 @@ func ParseFilename(filename string) (uuid.UUID, string, string, backend.Encoding
 	return id, tenant, version, encoding, dataEncoding, nil
 }
 
+// PageChecksums returns true if new WAL files are written with a checksum on every page
+func (w *WAL) PageChecksums() bool {
+	return w.c.PageChecksums
+}
+
 func (w *WAL) GetFilepath() string {
 	return w.c.Filepath
 }
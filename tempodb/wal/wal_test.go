@@ -3,6 +3,7 @@ package wal
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"math/rand"
 	"os"
@@ -14,6 +15,7 @@ import (
 	"github.com/go-kit/log"
 	"github.com/golang/protobuf/proto"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -160,11 +162,125 @@ func TestErrorConditions(t *testing.T) {
 
 	require.Equal(t, objects, blocks[0].appender.Length())
 
-	// confirm block has been removed
-	require.NoFileExists(t, filepath.Join(tempDir, "fe0b83eb-a86b-4b6c-9a74-dc272cd5700e:tenant:v2:gzip"))
+	// confirm unparseable block has been quarantined and empty block has been removed
+	require.NoFileExists(t, filepath.Join(tempDir, "fe0b83eb-a86b-4b6c-9a74-dc272cd5700e:tenant:v2:notanencoding"))
+	require.FileExists(t, filepath.Join(tempDir, quarantineDir, "fe0b83eb-a86b-4b6c-9a74-dc272cd5700e:tenant:v2:notanencoding"))
 	require.NoFileExists(t, filepath.Join(tempDir, "fe0b83eb-a86b-4b6c-9a74-dc272cd5700e:blerg:v2:gzip"))
 }
 
+func TestReplayCorruptRecords(t *testing.T) {
+	tempDir := t.TempDir()
+
+	wal, err := New(&Config{
+		Filepath:      tempDir,
+		Encoding:      backend.EncSnappy,
+		PageChecksums: true,
+	})
+	require.NoError(t, err, "unexpected error creating temp wal")
+
+	tenantID := "corrupt-records"
+	block, err := wal.NewBlock(uuid.New(), tenantID, "")
+	require.NoError(t, err, "unexpected error creating block")
+
+	objects := 10
+	ids := make([][]byte, 0, objects)
+	for i := 0; i < objects; i++ {
+		id := make([]byte, 16)
+		rand.Read(id)
+		obj := test.MakeTrace(rand.Int()%10+1, id)
+		bObj, err := proto.Marshal(obj)
+		require.NoError(t, err)
+
+		err = block.Append(id, bObj, 0, 0)
+		require.NoError(t, err, "unexpected error writing req")
+		ids = append(ids, id)
+	}
+
+	// corrupt the last byte of the third record
+	var third uint64
+	var corruptedBytes uint64
+	for _, r := range block.appender.Records() {
+		if bytes.Equal(r.ID, ids[2]) {
+			third = r.Start + uint64(r.Length) - 1
+			corruptedBytes = uint64(r.Length)
+		}
+	}
+	require.NotZero(t, third)
+
+	f, err := os.OpenFile(block.fullFilename(), os.O_RDWR, 0600)
+	require.NoError(t, err)
+	b := make([]byte, 1)
+	_, err = f.ReadAt(b, int64(third))
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte{b[0] ^ 0xFF}, int64(third))
+	require.NoError(t, err)
+
+	// and add a torn write at the end
+	info, err := f.Stat()
+	require.NoError(t, err)
+	_, err = f.WriteAt([]byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01}, info.Size())
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	blocks, err := wal.RescanBlocks(func([]byte, string) (uint32, uint32, error) {
+		return 0, 0, nil
+	}, 0, log.NewNopLogger())
+	require.NoError(t, err, "unexpected error getting blocks")
+	require.Len(t, blocks, 1)
+
+	// all records but the corrupted one are replayed and can be read
+	require.Equal(t, objects-1, blocks[0].appender.Length())
+	for i, id := range ids {
+		obj, err := blocks[0].Find(id, &mockCombiner{})
+		require.NoError(t, err)
+		if i == 2 {
+			require.Nil(t, obj)
+		} else {
+			require.NotNil(t, obj)
+		}
+	}
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(metricReplayLostTraces.WithLabelValues(tenantID)))
+	assert.Equal(t, float64(corruptedBytes+11), testutil.ToFloat64(metricReplayLostBytes.WithLabelValues(tenantID)))
+
+	// a file without any readable record is quarantined
+	filename := filepath.Base(blocks[0].fullFilename())
+	err = os.WriteFile(filepath.Join(tempDir, filename), []byte{0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01, 0x01}, 0644)
+	require.NoError(t, err)
+
+	blocks, err = wal.RescanBlocks(func([]byte, string) (uint32, uint32, error) {
+		return 0, 0, nil
+	}, 0, log.NewNopLogger())
+	require.NoError(t, err, "unexpected error getting blocks")
+	require.Len(t, blocks, 0)
+	require.NoFileExists(t, filepath.Join(tempDir, filename))
+	require.FileExists(t, filepath.Join(tempDir, quarantineDir, filename))
+}
+
+func TestQuarantineFileLimit(t *testing.T) {
+	tempDir := t.TempDir()
+
+	for i := 0; i < maxQuarantinedFiles+3; i++ {
+		filename := fmt.Sprintf("file-%d", i)
+		require.NoError(t, os.WriteFile(filepath.Join(tempDir, filename), []byte{0x01}, 0644))
+		require.NoError(t, QuarantineFile(tempDir, filename))
+
+		// make sure modification times differ and are older than the next quarantined file
+		modTime := time.Now().Add(-time.Hour + time.Duration(i)*time.Second)
+		require.NoError(t, os.Chtimes(filepath.Join(tempDir, quarantineDir, filename), modTime, modTime))
+	}
+
+	entries, err := os.ReadDir(filepath.Join(tempDir, quarantineDir))
+	require.NoError(t, err)
+	require.Len(t, entries, maxQuarantinedFiles)
+
+	// the oldest files are removed
+	for i := 0; i < 3; i++ {
+		require.NoFileExists(t, filepath.Join(tempDir, quarantineDir, fmt.Sprintf("file-%d", i)))
+	}
+	require.FileExists(t, filepath.Join(tempDir, quarantineDir, fmt.Sprintf("file-%d", maxQuarantinedFiles+2)))
+}
+
 func TestAppendBlockStartEnd(t *testing.T) {
 	wal, err := New(&Config{
 		Filepath:       t.TempDir(),